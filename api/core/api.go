@@ -27,10 +27,12 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 
 	"github.com/superkkt/cherry/api"
+	"github.com/superkkt/cherry/network"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/davecgh/go-spew/spew"
@@ -43,13 +45,30 @@ var (
 
 type API struct {
 	api.Server
+	// Network serves the requests about the switches such as the statistics.
+	Network Network
+}
+
+type Network interface {
+	// SetPortConfig changes the administrative settings of the port on the device
+	// whose ID is deviceID. It returns network.ErrPortNotFound if there is no such
+	// port.
+	SetPortConfig(deviceID string, port uint32, config network.PortConfig) error
+	// Stats returns the statistics collected from all the devices.
+	Stats() ([]network.DeviceReport, error)
 }
 
 func (r *API) Serve() error {
+	if r.Network == nil {
+		return errors.New("nil network controller")
+	}
+
 	return r.Server.Serve(
 		rest.Post("/api/v1/status", r.status),
 		rest.Post("/api/v1/remove", r.remove),
 		rest.Post("/api/v1/announce", r.announce),
+		rest.Post("/api/v1/stats", r.stats),
+		rest.Post("/api/v1/port/config", r.portConfig),
 	)
 }
 
@@ -158,3 +177,126 @@ func (r *announceParam) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+func (r *API) stats(w rest.ResponseWriter, req *rest.Request) {
+	logger.Debugf("stats request from %v", req.RemoteAddr)
+
+	reports, err := r.Network.Stats()
+	if err != nil {
+		w.WriteJson(api.Response{Status: api.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	stats := make([]api.DeviceStats, 0, len(reports))
+	for _, v := range reports {
+		stats = append(stats, newDeviceStats(v))
+	}
+	w.WriteJson(api.Response{Status: api.StatusOkay, Data: stats})
+}
+
+func newDeviceStats(v network.DeviceReport) api.DeviceStats {
+	stats := api.DeviceStats{
+		ID:           v.ID,
+		ActiveFlows:  v.Stats.ActiveFlows,
+		LookupCount:  v.Stats.LookupCount,
+		MatchedCount: v.Stats.MatchedCount,
+		Ports:        make([]api.PortStats, 0, len(v.Ports)),
+		PacketIn: api.PacketInStats{
+			Dispatched:    v.PacketIn.Dispatched,
+			DeviceDropped: v.PacketIn.DeviceDropped,
+			PortDropped:   v.PacketIn.PortDropped,
+			QueueDropped:  v.PacketIn.QueueDropped,
+			Queued:        v.PacketIn.Queued,
+		},
+		Events: api.EventStats{
+			Dispatched: v.Events.Dispatched,
+			Failed:     v.Events.Failed,
+			Blocked:    v.Events.Blocked,
+			Queued:     v.Events.Queued,
+			MaxQueued:  v.Events.MaxQueued,
+			AvgWait:    v.Events.AvgWait.Seconds(),
+		},
+		Timestamp: v.Stats.Timestamp,
+	}
+	for _, p := range v.Ports {
+		s := p.Stats
+		stats.Ports = append(stats.Ports, api.PortStats{
+			Number:        p.Number,
+			RxPackets:     s.Counters.RxPackets,
+			TxPackets:     s.Counters.TxPackets,
+			RxBytes:       s.Counters.RxBytes,
+			TxBytes:       s.Counters.TxBytes,
+			RxDropped:     s.Counters.RxDropped,
+			TxDropped:     s.Counters.TxDropped,
+			RxErrors:      s.Counters.RxErrors,
+			TxErrors:      s.Counters.TxErrors,
+			RxBps:         s.Rates.RxBps,
+			TxBps:         s.Rates.TxBps,
+			RxPps:         s.Rates.RxPps,
+			TxPps:         s.Rates.TxPps,
+			RxDroppedRate: s.Rates.RxDropped,
+			TxDroppedRate: s.Rates.TxDropped,
+			RxErrorsRate:  s.Rates.RxErrors,
+			TxErrorsRate:  s.Rates.TxErrors,
+			Timestamp:     s.Timestamp,
+		})
+	}
+
+	return stats
+}
+
+func (r *API) portConfig(w rest.ResponseWriter, req *rest.Request) {
+	p := new(portConfigParam)
+	if err := req.DecodeJsonPayload(p); err != nil {
+		w.WriteJson(api.Response{Status: api.StatusInvalidParameter, Message: err.Error()})
+		return
+	}
+	logger.Debugf("port config request from %v: %v", req.RemoteAddr, spew.Sdump(p))
+
+	config := network.PortConfig{
+		Down:       p.Config.Down,
+		NoRecv:     p.Config.NoRecv,
+		NoForward:  p.Config.NoForward,
+		NoPacketIn: p.Config.NoPacketIn,
+	}
+	if err := r.Network.SetPortConfig(p.DeviceID, p.Port, config); err != nil {
+		if err == network.ErrPortNotFound {
+			w.WriteJson(api.Response{Status: api.StatusNotFound, Message: err.Error()})
+			return
+		}
+		w.WriteJson(api.Response{Status: api.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	logger.Infof("changed the port configuration by %v: device=%v, port=%v", req.RemoteAddr, p.DeviceID, p.Port)
+
+	w.WriteJson(api.Response{Status: api.StatusOkay})
+}
+
+type portConfigParam struct {
+	DeviceID string
+	Port     uint32
+	Config   api.PortConfig
+}
+
+func (r *portConfigParam) UnmarshalJSON(data []byte) error {
+	v := struct {
+		DeviceID string  `json:"device_id"`
+		Port     *uint32 `json:"port"`
+		api.PortConfig
+	}{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if len(v.DeviceID) == 0 {
+		return errors.New("empty device ID")
+	}
+	if v.Port == nil {
+		return errors.New("missing port number")
+	}
+	r.DeviceID = v.DeviceID
+	r.Port = *v.Port
+	r.Config = v.PortConfig
+
+	return nil
+}
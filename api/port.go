@@ -0,0 +1,35 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package api
+
+// PortConfig is the administrative settings of a switch port. The nil fields are
+// not changed.
+type PortConfig struct {
+	// Port is administratively down.
+	Down *bool `json:"down,omitempty"`
+	// Drop all the packets received by the port.
+	NoRecv *bool `json:"no_recv,omitempty"`
+	// Drop the packets forwarded to the port.
+	NoForward *bool `json:"no_fwd,omitempty"`
+	// Do not send PACKET_IN messages for the port.
+	NoPacketIn *bool `json:"no_packet_in,omitempty"`
+}
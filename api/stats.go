@@ -0,0 +1,82 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package api
+
+import (
+	"time"
+)
+
+type DeviceStats struct {
+	ID           string        `json:"id"`
+	ActiveFlows  uint32        `json:"active_flows"`
+	LookupCount  uint64        `json:"lookup_count"`
+	MatchedCount uint64        `json:"matched_count"`
+	Ports        []PortStats   `json:"ports"`
+	PacketIn     PacketInStats `json:"packet_in"`
+	Events       EventStats    `json:"events"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// EventStats is the counters of the events of a device dispatched to the applications.
+type EventStats struct {
+	Dispatched uint64 `json:"dispatched"`
+	Failed     uint64 `json:"failed"`
+	// Number of the times that the controller has been blocked because the
+	// applications are slower than the events.
+	Blocked   uint64 `json:"blocked"`
+	Queued    int    `json:"queued"`
+	MaxQueued int    `json:"max_queued"`
+	// Average waiting time in seconds until the applications receive an event.
+	AvgWait float64 `json:"avg_wait"`
+}
+
+// PacketInStats is the counters of the PACKET_IN messages dropped by the rate
+// limits of the controller.
+type PacketInStats struct {
+	Dispatched    uint64 `json:"dispatched"`
+	DeviceDropped uint64 `json:"device_dropped"`
+	PortDropped   uint64 `json:"port_dropped"`
+	QueueDropped  uint64 `json:"queue_dropped"`
+	Queued        int    `json:"queued"`
+}
+
+type PortStats struct {
+	Number    uint32 `json:"number"`
+	RxPackets uint64 `json:"rx_packets"`
+	TxPackets uint64 `json:"tx_packets"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxDropped uint64 `json:"tx_dropped"`
+	RxErrors  uint64 `json:"rx_errors"`
+	TxErrors  uint64 `json:"tx_errors"`
+	// Per-second rates
+	RxBps         float64   `json:"rx_bps"`
+	TxBps         float64   `json:"tx_bps"`
+	RxPps         float64   `json:"rx_pps"`
+	TxPps         float64   `json:"tx_pps"`
+	RxDroppedRate float64   `json:"rx_dropped_rate"`
+	TxDroppedRate float64   `json:"tx_dropped_rate"`
+	RxErrorsRate  float64   `json:"rx_errors_rate"`
+	TxErrorsRate  float64   `json:"tx_errors_rate"`
+	Timestamp     time.Time `json:"timestamp"`
+}
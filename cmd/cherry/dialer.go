@@ -0,0 +1,171 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superkkt/cherry/network"
+
+	"github.com/pkg/errors"
+	"github.com/superkkt/viper"
+)
+
+const (
+	dialTimeout     = 10 * time.Second
+	minDialBackoff  = 1 * time.Second
+	maxDialBackoff  = 1 * time.Minute
+	stableConnLimit = 1 * time.Minute
+)
+
+// dialer makes the connections to the switches that wait for the controller in
+// the passive mode, e.g., Open vSwitch with "ptcp:". It only dials while this
+// controller is the master, and reconnects to a switch with the exponential
+// backoff whenever the connection is closed.
+type dialer struct {
+	ctx        context.Context
+	endpoints  []string
+	controller *network.Controller
+
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+}
+
+func newDialer(ctx context.Context, endpoints []string, controller *network.Controller) *dialer {
+	return &dialer{
+		ctx:        ctx,
+		endpoints:  endpoints,
+		controller: controller,
+	}
+}
+
+// OnElectionChanged starts dialing to the switches if master is true. Otherwise,
+// it stops dialing. The established connections are closed by the controller.
+func (r *dialer) OnElectionChanged(master bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	if !master {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.cancel = cancel
+	for _, v := range r.endpoints {
+		go r.run(ctx, v)
+	}
+}
+
+func (r *dialer) run(ctx context.Context, endpoint string) {
+	logger.Infof("started dialing to the switch %v", endpoint)
+	defer logger.Infof("stopped dialing to the switch %v", endpoint)
+
+	backoff := minDialBackoff
+	for {
+		started := time.Now()
+		closed, err := r.dial(ctx, endpoint)
+		if err != nil {
+			logger.Errorf("failed to connect to the switch %v: %v", endpoint, err)
+		} else {
+			// Wait until the connection is closed.
+			select {
+			case <-ctx.Done():
+				return
+			case <-closed:
+				logger.Infof("connection to the switch %v is closed", endpoint)
+			}
+		}
+
+		// Reset the backoff if the connection has been stable for a while.
+		if err == nil && time.Since(started) > stableConnLimit {
+			backoff = minDialBackoff
+		}
+		logger.Debugf("reconnecting to the switch %v after %v", endpoint, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxDialBackoff {
+			backoff = maxDialBackoff
+		}
+	}
+}
+
+// dial connects to the endpoint, and then hands the connection to the controller.
+// The returned channel is closed when the connection is closed.
+func (r *dialer) dial(ctx context.Context, endpoint string) (closed <-chan struct{}, err error) {
+	d := net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("connected to the switch %v", endpoint)
+	enableKeepAlive(conn)
+
+	c := &notifyingConn{Conn: conn, closed: make(chan struct{})}
+	secured, err := tlsConfig.secureClient(c, endpoint)
+	if err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "failed to do the TLS handshake")
+	}
+	r.controller.AddConnection(ctx, secured)
+
+	return c.closed, nil
+}
+
+// notifyingConn closes the closed channel when the connection is closed.
+type notifyingConn struct {
+	net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (r *notifyingConn) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return r.Conn.Close()
+}
+
+func parseActiveSwitches() ([]string, error) {
+	v := strings.Replace(viper.GetString("default.active_switches"), " ", "", -1)
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	result := make([]string, 0)
+	for _, endpoint := range strings.Split(v, ",") {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return nil, errors.Wrap(err, "invalid default.active_switches")
+		}
+		result = append(result, endpoint)
+	}
+
+	return result, nil
+}
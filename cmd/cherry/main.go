@@ -41,6 +41,8 @@ import (
 	"github.com/superkkt/cherry/log"
 	"github.com/superkkt/cherry/network"
 	"github.com/superkkt/cherry/northbound"
+	"github.com/superkkt/cherry/openflow/nicira"
+	"github.com/superkkt/cherry/openflow/transceiver"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/pkg/errors"
@@ -57,6 +59,7 @@ const (
 var (
 	logger            = logging.MustGetLogger("main")
 	loggerLeveled     logging.LeveledBackend
+	tlsConfig         *tlsManager
 	showVersion       = flag.Bool("version", false, "Show program version and exit")
 	defaultConfigFile = flag.String("config", fmt.Sprintf("/usr/local/etc/%v.yaml", programName), "absolute path of the configuration file")
 )
@@ -73,6 +76,9 @@ func main() {
 	if err := initLog(getLogLevel(viper.GetString("default.log_level"))); err != nil {
 		logger.Fatalf("failed to init log: %v", err)
 	}
+	if err := initTLS(); err != nil {
+		logger.Fatalf("failed to init TLS: %v", err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	db, err := database.NewMySQL()
@@ -80,8 +86,21 @@ func main() {
 		logger.Fatalf("failed to init MySQL database: %v", err)
 	}
 
-	observer := initElectionObserver(ctx, db)
+	// Decode the Nicira extension messages of Open vSwitch, and make it send NXT_PACKET_IN2.
+	transceiver.RegisterExperimenter(nicira.NX_VENDOR_ID, nicira.NewCodec(), nil)
 	controller := network.NewController(db)
+	// Verify the DPIDs of the devices against their certificates.
+	controller.SetAuthenticator(tlsConfig)
+	controller.SetAdmissionConfig(getAdmissionConfig())
+	if viper.IsSet("packet_in.miss_send_len") {
+		controller.SetMissSendLength(uint16(viper.GetInt("packet_in.miss_send_len")))
+	}
+	controller.SetEventWorkers(viper.GetInt("default.event_workers"))
+	switches, err := parseActiveSwitches()
+	if err != nil {
+		logger.Fatalf("failed to parse active switches: %v", err)
+	}
+	observer := initElectionObserver(ctx, db, controller, newDialer(ctx, switches, controller))
 	initAPIServer(observer, controller)
 	manager, err := createAppManager(db)
 	if err != nil {
@@ -91,7 +110,7 @@ func main() {
 
 	initSignalHandler(controller, manager, cancel)
 
-	listen(ctx, viper.GetInt("default.port"), controller, observer)
+	listen(ctx, viper.GetInt("default.port"), controller)
 }
 
 func initConfig() {
@@ -111,6 +130,12 @@ func initConfig() {
 			// Set log level for all modules
 			loggerLeveled.SetLevel(getLogLevel(viper.GetString("default.log_level")), "")
 		}
+		if tlsConfig != nil {
+			// Keep the previous TLS configuration if the new one is invalid.
+			if err := tlsConfig.reload(); err != nil {
+				logger.Errorf("failed to reload the TLS configuration: %v", err)
+			}
+		}
 	})
 	viper.WatchConfig()
 	if err := validateConfig(); err != nil {
@@ -135,12 +160,83 @@ func validateConfig() error {
 	if vlanID < 0 || vlanID > 4095 {
 		return errors.New("invalid default.vlan_id in the config file")
 	}
+	if _, err := parseActiveSwitches(); err != nil {
+		return err
+	}
+	if viper.GetInt("default.event_workers") < 0 {
+		return errors.New("invalid default.event_workers")
+	}
+	for _, key := range []string{"device_rate", "device_burst", "port_rate", "port_burst", "high_device_rate", "high_device_burst", "high_port_rate", "high_port_burst", "queue_size"} {
+		if viper.GetFloat64("packet_in."+key) < 0 {
+			return fmt.Errorf("invalid packet_in.%v", key)
+		}
+	}
+	if v := viper.GetInt("packet_in.miss_send_len"); v < 0 || v > 0xFFFF {
+		return errors.New("invalid packet_in.miss_send_len")
+	}
+	if viper.GetBool("tls.enable") {
+		if len(viper.GetString("tls.cert_file")) == 0 {
+			return errors.New("invalid tls.cert_file")
+		}
+		if len(viper.GetString("tls.key_file")) == 0 {
+			return errors.New("invalid tls.key_file")
+		}
+	}
 
 	return nil
 }
 
-func initElectionObserver(ctx context.Context, db *database.MySQL) *election.Observer {
+// getAdmissionConfig returns the PACKET_IN rate limits. The default values are
+// used for the ones missing in the config file.
+func getAdmissionConfig() network.AdmissionConfig {
+	c := network.DefaultAdmissionConfig()
+	if viper.IsSet("packet_in.device_rate") {
+		c.DeviceRate = viper.GetFloat64("packet_in.device_rate")
+	}
+	if viper.IsSet("packet_in.device_burst") {
+		c.DeviceBurst = viper.GetFloat64("packet_in.device_burst")
+	}
+	if viper.IsSet("packet_in.port_rate") {
+		c.PortRate = viper.GetFloat64("packet_in.port_rate")
+	}
+	if viper.IsSet("packet_in.port_burst") {
+		c.PortBurst = viper.GetFloat64("packet_in.port_burst")
+	}
+	if viper.IsSet("packet_in.high_device_rate") {
+		c.HighDeviceRate = viper.GetFloat64("packet_in.high_device_rate")
+	}
+	if viper.IsSet("packet_in.high_device_burst") {
+		c.HighDeviceBurst = viper.GetFloat64("packet_in.high_device_burst")
+	}
+	if viper.IsSet("packet_in.high_port_rate") {
+		c.HighPortRate = viper.GetFloat64("packet_in.high_port_rate")
+	}
+	if viper.IsSet("packet_in.high_port_burst") {
+		c.HighPortBurst = viper.GetFloat64("packet_in.high_port_burst")
+	}
+	if viper.IsSet("packet_in.queue_size") {
+		c.QueueSize = viper.GetInt("packet_in.queue_size")
+	}
+
+	return c
+}
+
+func initTLS() error {
+	v, err := newTLSManager()
+	if err != nil {
+		return err
+	}
+	tlsConfig = v
+
+	return nil
+}
+
+func initElectionObserver(ctx context.Context, db *database.MySQL, controller *network.Controller, dialer *dialer) *election.Observer {
 	observer := election.New(db)
+	// Promote the connections to the master when we are elected as the master.
+	observer.AddListener(controller)
+	// Only the master dials to the switches.
+	observer.AddListener(dialer)
 	go func() {
 		if err := observer.Run(ctx); err != nil {
 			logger.Fatalf("failed to run the election observer: %v", err)
@@ -162,7 +258,7 @@ func initAPIServer(observer *election.Observer, controller *network.Controller)
 		s.Observer = observer
 		s.Controller = controller
 
-		srv := &core.API{Server: s}
+		srv := &core.API{Server: s, Network: controller}
 		if err := srv.Serve(); err != nil {
 			logger.Fatalf("failed to run the API server: %v", err)
 		}
@@ -221,12 +317,28 @@ func getLogLevel(level string) logging.Level {
 	return ret
 }
 
-func listen(ctx context.Context, port int, controller *network.Controller, observer *election.Observer) {
+func enableKeepAlive(conn net.Conn) {
 	type KeepAliver interface {
 		SetKeepAlive(keepalive bool) error
 		SetKeepAlivePeriod(d time.Duration) error
 	}
 
+	v, ok := conn.(KeepAliver)
+	if !ok {
+		return
+	}
+	logger.Debug("trying to enable socket keepalive..")
+	if err := v.SetKeepAlive(true); err != nil {
+		logger.Errorf("failed to enable socket keepalive: %v", err)
+		return
+	}
+	logger.Debug("setting socket keepalive period...")
+	// Makes a broken connection will be disconnected within 45 seconds.
+	// http://felixge.de/2014/08/26/tcp-keepalive-with-golang.html
+	v.SetKeepAlivePeriod(time.Duration(5) * time.Second)
+}
+
+func listen(ctx context.Context, port int, controller *network.Controller) {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
 	if err != nil {
 		logger.Errorf("failed to listen on %v port: %v", port, err)
@@ -243,16 +355,21 @@ func listen(ctx context.Context, port int, controller *network.Controller, obser
 				continue
 			}
 			logger.Infof("new device is connected from %v", conn.RemoteAddr())
-
-			// Only the master controller can serve the connections!
-			if observer.IsMaster() == false {
-				logger.Warningf("disconnecting the newly connected device (%v) because we are not the master controller!", conn.RemoteAddr())
-				conn.Close()
-				continue
-			}
-
-			// Pass the new connection into the backlog queue.
-			c <- conn
+			enableKeepAlive(conn)
+			// Non-master controllers also accept the connections, and hold them as
+			// slaves so that they can take over the devices quickly on failover.
+
+			// Do the TLS handshake in a separate goroutine not to block other connections.
+			go func(conn net.Conn) {
+				secured, err := tlsConfig.secure(conn)
+				if err != nil {
+					logger.Errorf("failed to do the TLS handshake with %v: %v", conn.RemoteAddr(), err)
+					conn.Close()
+					return
+				}
+				// Pass the new connection into the backlog queue.
+				c <- secured
+			}(conn)
 		}
 	}
 	backlog := make(chan net.Conn, 32)
@@ -266,17 +383,6 @@ func listen(ctx context.Context, port int, controller *network.Controller, obser
 			return
 		case conn := <-backlog:
 			logger.Debug("fetching a new connection from the backlog..")
-			if v, ok := conn.(KeepAliver); ok {
-				logger.Debug("trying to enable socket keepalive..")
-				if err := v.SetKeepAlive(true); err == nil {
-					logger.Debug("setting socket keepalive period...")
-					// Makes a broken connection will be disconnected within 45 seconds.
-					// http://felixge.de/2014/08/26/tcp-keepalive-with-golang.html
-					v.SetKeepAlivePeriod(time.Duration(5) * time.Second)
-				} else {
-					logger.Errorf("failed to enable socket keepalive: %v", err)
-				}
-			}
 			controller.AddConnection(ctx, conn)
 		}
 	}
@@ -0,0 +1,203 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/superkkt/viper"
+)
+
+const (
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// tlsManager keeps the TLS configuration of the OpenFlow listener loaded from the
+// config file. It is reloaded whenever the config file changes, and the new
+// configuration is applied to the connections accepted after the reload.
+type tlsManager struct {
+	mutex sync.RWMutex
+	// config is nil if TLS is disabled.
+	config *tls.Config
+	// Certificate subjects pinned to the DPIDs in the RFC 2253 string form.
+	pinning map[uint64]string
+}
+
+func newTLSManager() (*tlsManager, error) {
+	v := new(tlsManager)
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *tlsManager) reload() error {
+	config, pinning, err := loadTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.config = config
+	r.pinning = pinning
+	logger.Infof("loaded the OpenFlow TLS configuration: enabled=%v, # of pinned devices=%v", config != nil, len(pinning))
+
+	return nil
+}
+
+func loadTLSConfig() (*tls.Config, map[uint64]string, error) {
+	if !viper.GetBool("tls.enable") {
+		return nil, nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(viper.GetString("tls.cert_file"), viper.GetString("tls.key_file"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load the TLS certificate")
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if file := viper.GetString("tls.client_ca_file"); len(file) > 0 {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, errors.New("no valid certificate in the client CA file")
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	pinning := make(map[uint64]string)
+	for k, v := range viper.GetStringMapString("tls.pinning") {
+		// Decimal or hexadecimal with the 0x prefix.
+		dpid, err := strconv.ParseUint(k, 0, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid DPID in tls.pinning: %v", k)
+		}
+		if len(v) == 0 {
+			return nil, nil, fmt.Errorf("empty certificate subject in tls.pinning: %v", k)
+		}
+		pinning[dpid] = v
+	}
+	if len(pinning) > 0 && config.ClientCAs == nil {
+		return nil, nil, errors.New("tls.pinning requires tls.client_ca_file")
+	}
+
+	return config, pinning, nil
+}
+
+// secure performs the TLS handshake on conn if TLS is enabled, and returns the
+// TLS connection. Otherwise, it returns conn as is.
+func (r *tlsManager) secure(conn net.Conn) (net.Conn, error) {
+	r.mutex.RLock()
+	config := r.config
+	r.mutex.RUnlock()
+
+	if config == nil {
+		return conn, nil
+	}
+
+	c := tls.Server(conn, config)
+	c.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := c.Handshake(); err != nil {
+		return nil, err
+	}
+	// Clear the deadline. The transceiver sets its own I/O timeouts.
+	c.SetDeadline(time.Time{})
+
+	return c, nil
+}
+
+// secureClient performs the TLS handshake as a client on conn that has been
+// connected to the endpoint if TLS is enabled. Our certificate is presented to
+// the switch, and the switch certificate is verified by the client CAs. The switch
+// certificate should have a subject alternative name, IP address or DNS name,
+// that matches the host of the endpoint.
+func (r *tlsManager) secureClient(conn net.Conn, endpoint string) (net.Conn, error) {
+	r.mutex.RLock()
+	config := r.config
+	r.mutex.RUnlock()
+
+	if config == nil {
+		return conn, nil
+	}
+
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	c := tls.Client(conn, &tls.Config{
+		Certificates: config.Certificates,
+		RootCAs:      config.ClientCAs,
+		ServerName:   host,
+		MinVersion:   config.MinVersion,
+	})
+	c.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := c.Handshake(); err != nil {
+		return nil, err
+	}
+	c.SetDeadline(time.Time{})
+
+	return c, nil
+}
+
+// Authenticate verifies that the client certificate of conn has the subject
+// pinned to the DPID. The whole subject is compared, not only its common name.
+// Devices whose DPID is not pinned are allowed if they pass the TLS handshake.
+func (r *tlsManager) Authenticate(conn net.Conn, dpid uint64) error {
+	r.mutex.RLock()
+	subject, ok := r.pinning[dpid]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	c, ok := conn.(*tls.Conn)
+	if !ok {
+		return errors.New("pinned device is connected without TLS")
+	}
+	certs := c.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("missing client certificate")
+	}
+	if v := certs[0].Subject.String(); v != subject {
+		return fmt.Errorf("mismatched certificate subject: expected=%v, got=%v", subject, v)
+	}
+
+	return nil
+}
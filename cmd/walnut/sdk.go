@@ -20,6 +20,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/superkkt/cherry/api"
 )
 
 type coreSDK struct {
@@ -70,6 +72,29 @@ func (r *coreSDK) RemoveFlowsByMAC(mac net.HardwareAddr) error {
 	return r.call("POST", "/api/v1/remove", arg, nil)
 }
 
+func (r *coreSDK) SetPortConfig(deviceID string, port uint32, config api.PortConfig) error {
+	arg := &struct {
+		DeviceID string `json:"device_id"`
+		Port     uint32 `json:"port"`
+		api.PortConfig
+	}{
+		DeviceID:   deviceID,
+		Port:       port,
+		PortConfig: config,
+	}
+
+	return r.call("POST", "/api/v1/port/config", arg, nil)
+}
+
+func (r *coreSDK) Stats() ([]api.DeviceStats, error) {
+	res := make([]api.DeviceStats, 0)
+	if err := r.call("POST", "/api/v1/stats", nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (r *coreSDK) IsMaster() bool {
 	res := new(struct {
 		Master bool `json:"master"`
@@ -45,8 +45,15 @@ type Observer struct {
 	uid string
 	db  Database
 
-	mutex  sync.Mutex
-	master bool
+	mutex     sync.Mutex
+	master    bool
+	listeners []Listener
+}
+
+type Listener interface {
+	// OnElectionChanged is called when this controller is elected as the master,
+	// or loses the mastership.
+	OnElectionChanged(master bool)
 }
 
 type Database interface {
@@ -90,11 +97,30 @@ func (r *Observer) Run(ctx context.Context) error {
 
 			if prev != elected {
 				logger.Warningf("master controller has been changed: prev=%v, new=%v", prev, elected)
+				for _, l := range r.getListeners() {
+					l.OnElectionChanged(elected)
+				}
 			}
 		}
 	}
 }
 
+// AddListener adds l that will be notified when the election result changes. It
+// should be called before running the observer.
+func (r *Observer) AddListener(l Listener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.listeners = append(r.listeners, l)
+}
+
+func (r *Observer) getListeners() []Listener {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.listeners
+}
+
 func (r *Observer) IsMaster() bool {
 	return r.getMaster()
 }
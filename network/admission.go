@@ -0,0 +1,259 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/superkkt/cherry/protocol"
+)
+
+// AdmissionConfig is the PACKET_IN rate limits of a device. The packets exceeding
+// the limits are dropped before they are passed to the event listeners. A rate
+// is the number of packets per second, and a burst is the number of packets that
+// can be admitted at once. A zero or negative rate means no limit.
+type AdmissionConfig struct {
+	// Limit of all the packets from a device.
+	DeviceRate, DeviceBurst float64
+	// Limit of the packets from each ingress port of a device.
+	PortRate, PortBurst float64
+	// Limits of the high priority packets, such as ARP replies. They have their
+	// own token buckets, which are usually larger than the ones above, so that
+	// the data packets cannot starve them while a flood of them is still limited.
+	HighDeviceRate, HighDeviceBurst float64
+	HighPortRate, HighPortBurst     float64
+	// Capacity of each priority lane of the queue that holds the admitted
+	// packets until the event listeners process them.
+	QueueSize int
+}
+
+func DefaultAdmissionConfig() AdmissionConfig {
+	return AdmissionConfig{
+		DeviceRate:  1000,
+		DeviceBurst: 2000,
+		PortRate:    200,
+		PortBurst:   400,
+		// Twice the data packets.
+		HighDeviceRate:  2000,
+		HighDeviceBurst: 4000,
+		HighPortRate:    400,
+		HighPortBurst:   800,
+		QueueSize:       1024,
+	}
+}
+
+// PacketInStats is the counters of the PACKET_IN admission of a device.
+type PacketInStats struct {
+	// Number of the packets passed to the event listeners.
+	Dispatched uint64
+	// Number of the packets dropped by the rate limit of the device.
+	DeviceDropped uint64
+	// Number of the packets dropped by the rate limit of their ingress port.
+	PortDropped uint64
+	// Number of the packets dropped because their lane of the queue is full.
+	QueueDropped uint64
+	// Number of the packets waiting in the queue.
+	Queued int
+}
+
+// tokenBucket is a token bucket rate limiter. It is not safe for concurrent use.
+type tokenBucket struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// allow takes a token from the bucket. It returns false if there is no token.
+func (r *tokenBucket) allow(now time.Time) bool {
+	if r.rate <= 0 {
+		return true
+	}
+
+	if !r.last.IsZero() && now.After(r.last) {
+		r.tokens += now.Sub(r.last).Seconds() * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+
+	return true
+}
+
+// rateLimiter limits the packets from a device and each ingress port of the device.
+// It is not safe for concurrent use.
+type rateLimiter struct {
+	device              *tokenBucket
+	ports               map[uint32]*tokenBucket
+	portRate, portBurst float64
+}
+
+func newRateLimiter(deviceRate, deviceBurst, portRate, portBurst float64) *rateLimiter {
+	return &rateLimiter{
+		device:    newTokenBucket(deviceRate, deviceBurst),
+		ports:     make(map[uint32]*tokenBucket),
+		portRate:  portRate,
+		portBurst: portBurst,
+	}
+}
+
+// port returns the token bucket of the ingress port whose number is num.
+func (r *rateLimiter) port(num uint32) *tokenBucket {
+	v, ok := r.ports[num]
+	if !ok {
+		v = newTokenBucket(r.portRate, r.portBurst)
+		r.ports[num] = v
+	}
+
+	return v
+}
+
+type packetInEvent struct {
+	ingress  *Port
+	ethernet *protocol.Ethernet
+	packetIn *PacketIn
+}
+
+// admission admits the PACKET_IN messages of a device under the rate limits, and
+// then queues them by their priority. The control packets, such as ARP replies,
+// are queued in the high priority lane that has its own rate limits and is always
+// processed ahead of the low priority one.
+type admission struct {
+	mutex     sync.Mutex
+	highLimit *rateLimiter
+	lowLimit  *rateLimiter
+	stats     PacketInStats
+	high      chan packetInEvent
+	low       chan packetInEvent
+}
+
+func newAdmission(c AdmissionConfig) *admission {
+	size := c.QueueSize
+	if size <= 0 {
+		size = DefaultAdmissionConfig().QueueSize
+	}
+
+	return &admission{
+		highLimit: newRateLimiter(c.HighDeviceRate, c.HighDeviceBurst, c.HighPortRate, c.HighPortBurst),
+		lowLimit:  newRateLimiter(c.DeviceRate, c.DeviceBurst, c.PortRate, c.PortBurst),
+		high:      make(chan packetInEvent, size),
+		low:       make(chan packetInEvent, size),
+	}
+}
+
+// isHighPriority returns whether the packet should be processed ahead of the data
+// packets. LLDP packets are not queued at all because they are processed by the
+// session itself.
+func isHighPriority(eth *protocol.Ethernet) bool {
+	// ARP reply?
+	return eth.Type == 0x0806 && len(eth.Payload) >= 8 && eth.Payload[6] == 0 && eth.Payload[7] == 2
+}
+
+// admit queues the event if it is admitted. It returns false if the event is
+// dropped, and then the caller should release the packet buffered in the device.
+func (r *admission) admit(v packetInEvent, now time.Time) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	lane, limit := r.low, r.lowLimit
+	if isHighPriority(v.ethernet) {
+		lane, limit = r.high, r.highLimit
+	}
+	// Check the port limit first so that a chatty port does not consume the
+	// tokens of the device.
+	if !limit.port(v.ingress.Number()).allow(now) {
+		r.stats.PortDropped++
+		return false
+	}
+	if !limit.device.allow(now) {
+		r.stats.DeviceDropped++
+		return false
+	}
+
+	select {
+	case lane <- v:
+		return true
+	default:
+		r.stats.QueueDropped++
+		return false
+	}
+}
+
+func (r *admission) Stats() PacketInStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stats := r.stats
+	stats.Queued = len(r.high) + len(r.low)
+
+	return stats
+}
+
+func (r *admission) dispatched() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stats.Dispatched++
+}
+
+// run passes the queued events to fn until ctx is canceled. The high priority
+// events are passed ahead of the low priority ones.
+func (r *admission) run(ctx context.Context, fn func(packetInEvent)) {
+	for {
+		// Drain the high priority lane first.
+		select {
+		case v := <-r.high:
+			fn(v)
+			r.dispatched()
+			continue
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case v := <-r.high:
+			fn(v)
+		case v := <-r.low:
+			fn(v)
+		}
+		r.dispatched()
+	}
+}
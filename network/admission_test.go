@@ -0,0 +1,84 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/protocol"
+)
+
+func TestAdmission(t *testing.T) {
+	a := newAdmission(AdmissionConfig{
+		DeviceRate: 10, DeviceBurst: 3, PortRate: 10, PortBurst: 2,
+		HighDeviceRate: 10, HighDeviceBurst: 2, HighPortRate: 10, HighPortBurst: 1,
+		QueueSize: 8,
+	})
+	now := time.Now()
+	data := func(port uint32) packetInEvent {
+		return packetInEvent{ingress: NewPort(nil, port), ethernet: &protocol.Ethernet{Type: 0x0800}}
+	}
+
+	// The port burst is 2.
+	if !a.admit(data(1), now) || !a.admit(data(1), now) || a.admit(data(1), now) {
+		t.Fatal("unexpected port limit")
+	}
+	// The device burst is 3, and the rejected packet above did not consume it.
+	if !a.admit(data(2), now) || a.admit(data(2), now) {
+		t.Fatal("unexpected device limit")
+	}
+	// ARP replies have their own limits that the data packets have not consumed.
+	reply := &protocol.Ethernet{Type: 0x0806, Payload: []byte{0, 1, 8, 0, 6, 4, 0, 2}}
+	control := func(port uint32) packetInEvent {
+		return packetInEvent{ingress: NewPort(nil, port), ethernet: reply}
+	}
+	if !a.admit(control(1), now) {
+		t.Fatal("ARP reply is dropped")
+	}
+	// But a flood of them is still limited by the high port burst 1 and device burst 2.
+	if a.admit(control(1), now) || !a.admit(control(2), now) || a.admit(control(3), now) {
+		t.Fatal("unexpected high priority limit")
+	}
+	// Tokens are refilled by the rate.
+	if !a.admit(data(3), now.Add(100*time.Millisecond)) {
+		t.Fatal("token is not refilled")
+	}
+
+	stats := a.Stats()
+	if stats.PortDropped != 2 || stats.DeviceDropped != 2 || stats.Queued != 6 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// The high priority event is dispatched first.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan packetInEvent, 6)
+	go a.run(ctx, func(v packetInEvent) { c <- v })
+	if v := <-c; v.ethernet != reply {
+		t.Fatalf("unexpected first event: %+v", v.ethernet)
+	}
+	for i := 0; i < 5; i++ {
+		<-c
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/transceiver"
+)
+
+var (
+	ErrUnsupportedBundle = errors.New("device does not support bundles")
+	ErrBundleDiscarded   = errors.New("bundle is discarded because the device has rejected some of its messages")
+	errBundleDone        = errors.New("bundle is already committed or discarded")
+)
+
+// Bundle is a set of messages that a device applies atomically and in order when
+// the bundle is committed. Bundles are supported by the OpenFlow 1.4 devices and
+// the OpenFlow 1.3 devices that implement the ONF bundle extension.
+type Bundle struct {
+	device *Device
+	id     uint32
+	flags  openflow.BundleFlag
+	msgs   []transceiver.Request
+	done   bool
+}
+
+// OpenBundle opens a new bundle on the device. It returns ErrUnsupportedBundle
+// if the device does not support bundles.
+func (r *Device) OpenBundle(ctx context.Context) (*Bundle, error) {
+	// Write lock
+	r.mutex.Lock()
+	f := r.factory
+	noBundle := r.noBundle
+	r.bundleID++
+	id := r.bundleID
+	r.mutex.Unlock()
+
+	if f == nil {
+		return nil, errors.New("not negotiated device")
+	}
+	if noBundle {
+		return nil, ErrUnsupportedBundle
+	}
+	if _, err := f.NewBundleControl(); err != nil {
+		// The protocol version does not have bundles.
+		return nil, ErrUnsupportedBundle
+	}
+
+	bundle := &Bundle{
+		device: r,
+		id:     id,
+		flags:  openflow.BundleAtomic | openflow.BundleOrdered,
+	}
+	if err := bundle.control(ctx, openflow.BundleOpen); err != nil {
+		if _, ok := err.(*transceiver.ErrorReply); ok && f.ProtocolVersion() == openflow.OF13_VERSION {
+			// The device does not implement the ONF bundle extension.
+			logger.Infof("device %v does not support the ONF bundle extension: %v", r.ID(), err)
+			r.setNoBundle()
+			return nil, ErrUnsupportedBundle
+		}
+		return nil, err
+	}
+	logger.Debugf("opened a bundle: device=%v, id=%v", r.ID(), id)
+
+	return bundle, nil
+}
+
+func (r *Device) setNoBundle() {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.noBundle = true
+}
+
+// ID returns the bundle ID.
+func (r *Bundle) ID() uint32 {
+	return r.id
+}
+
+// Add adds msg into the bundle. The messages are sent to the device when the
+// bundle is committed.
+func (r *Bundle) Add(msg openflow.BundleMessage) error {
+	if r.done {
+		return errBundleDone
+	}
+
+	add, err := r.device.Factory().NewBundleAdd()
+	if err != nil {
+		return err
+	}
+	add.SetBundleID(r.id)
+	add.SetFlags(r.flags)
+	add.SetBundledMessage(msg)
+	if err := add.Error(); err != nil {
+		return err
+	}
+	r.msgs = append(r.msgs, add)
+
+	return nil
+}
+
+// Len returns the number of the messages in the bundle.
+func (r *Bundle) Len() int {
+	return len(r.msgs)
+}
+
+// Commit sends the messages in the bundle to the device, and then commits the
+// bundle. The returned errors are the results of the messages in the order they
+// were added: nil if the device has accepted the message, or *transceiver.ErrorReply
+// if it has rejected. The bundle is discarded and ErrBundleDiscarded is returned
+// if any of the messages has been rejected, in which case none of the messages
+// is applied.
+func (r *Bundle) Commit(ctx context.Context) ([]error, error) {
+	if r.done {
+		return nil, errBundleDone
+	}
+	r.done = true
+
+	errs, err := r.device.session.transceiver.Transaction(ctx, r.msgs)
+	if err != nil {
+		if err == transceiver.ErrClosed {
+			return nil, ErrClosedDevice
+		}
+		return nil, err
+	}
+	for _, v := range errs {
+		if v == nil {
+			continue
+		}
+		if err := r.control(ctx, openflow.BundleDiscard); err != nil {
+			logger.Errorf("failed to discard the bundle: device=%v, id=%v, err=%v", r.device.ID(), r.id, err)
+		}
+		return errs, ErrBundleDiscarded
+	}
+
+	if err := r.control(ctx, openflow.BundleCommit); err != nil {
+		return nil, err
+	}
+	logger.Debugf("committed the bundle: device=%v, id=%v, # of messages=%v", r.device.ID(), r.id, len(r.msgs))
+
+	return errs, nil
+}
+
+// Discard discards the bundle without applying its messages.
+func (r *Bundle) Discard(ctx context.Context) error {
+	if r.done {
+		return errBundleDone
+	}
+	r.done = true
+
+	return r.control(ctx, openflow.BundleDiscard)
+}
+
+// control sends the bundle control request, and then waits for its reply.
+func (r *Bundle) control(ctx context.Context, t openflow.BundleCtrlType) error {
+	req, err := r.device.Factory().NewBundleControl()
+	if err != nil {
+		return err
+	}
+	req.SetBundleID(r.id)
+	req.SetControlType(t)
+	req.SetFlags(r.flags)
+
+	replies, err := r.device.request(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, v := range replies {
+		reply, ok := v.(openflow.BundleControl)
+		if !ok || !reply.IsReply() || reply.ControlType() != t || reply.BundleID() != r.id {
+			return fmt.Errorf("unexpected bundle control reply: %+v", v)
+		}
+	}
+
+	return nil
+}
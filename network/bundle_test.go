@@ -0,0 +1,178 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+func newTestBundleMessage(t *testing.T, device *Device, flow Flow) openflow.BundleMessage {
+	device.mutex.RLock()
+	defer device.mutex.RUnlock()
+
+	msg, err := device.newFlowMod(openflow.FlowAdd, flow, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return msg
+}
+
+func TestBundleCommit(t *testing.T) {
+	sw := &testFlowSwitch{bundles: true}
+	device, disconnect := newTestDevice(t, sw.handle)
+	defer disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	bundle, err := device.OpenBundle(ctx)
+	if err != nil {
+		t.Fatalf("failed to open a bundle: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := bundle.Add(newTestBundleMessage(t, device, newTestFlow(uint32(i), 10))); err != nil {
+			t.Fatalf("failed to add a message: %v", err)
+		}
+	}
+	if bundle.Len() != 2 {
+		t.Fatalf("unexpected number of the messages: %v", bundle.Len())
+	}
+	errs, err := bundle.Commit(ctx)
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if !reflect.DeepEqual(errs, []error{nil, nil}) {
+		t.Fatalf("unexpected results: %v", errs)
+	}
+
+	expected := []openflow.BundleCtrlType{openflow.BundleOpen, openflow.BundleCommit}
+	if !reflect.DeepEqual(sw.receivedControls(), expected) {
+		t.Fatalf("unexpected bundle controls: expected=%v, got=%v", expected, sw.receivedControls())
+	}
+	if n := len(sw.received()); n != 2 {
+		t.Fatalf("unexpected number of the bundled FLOW_MODs: %v", n)
+	}
+
+	// A committed bundle cannot be used again.
+	if _, err := bundle.Commit(ctx); err != errBundleDone {
+		t.Fatalf("unexpected error: expected=%v, got=%v", errBundleDone, err)
+	}
+	if err := bundle.Add(newTestBundleMessage(t, device, newTestFlow(3, 10))); err != errBundleDone {
+		t.Fatalf("unexpected error: expected=%v, got=%v", errBundleDone, err)
+	}
+}
+
+func TestFlowTransactionBundle(t *testing.T) {
+	ns := NewCookieNamespace("bundle")
+	// The switch rejects the second operation.
+	sw := &testFlowSwitch{
+		bundles: true,
+		reject:  func(v *of13.FlowMod) bool { return v.Priority() == 2 },
+	}
+	device, disconnect := newTestDevice(t, sw.handle)
+	defer disconnect()
+
+	tx := device.NewFlowTransaction(ns)
+	tx.SetAtomic(true)
+	for i := 1; i <= 3; i++ {
+		flow := newTestFlow(uint32(i), 10)
+		flow.Priority = uint16(i)
+		tx.Add(flow)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// The bundle is discarded instead of being committed.
+	expected := []openflow.BundleCtrlType{openflow.BundleOpen, openflow.BundleDiscard}
+	if !reflect.DeepEqual(sw.receivedControls(), expected) {
+		t.Fatalf("unexpected bundle controls: expected=%v, got=%v", expected, sw.receivedControls())
+	}
+	if len(results) != 3 {
+		t.Fatalf("unexpected number of the results: %v", len(results))
+	}
+	for i, v := range results {
+		if v.Flow.Priority != uint16(i+1) {
+			t.Fatalf("unexpected result #%v: priority=%v", i, v.Flow.Priority)
+		}
+		if i == 1 {
+			if e, ok := v.Err.(*FlowModError); !ok || e.Code != of13.OFPFMFC_TABLE_FULL {
+				t.Fatalf("expected a FlowModError of the result #%v: %v", i, v.Err)
+			}
+			continue
+		}
+		// The accepted operations are not applied either.
+		if v.Err != ErrBundleDiscarded {
+			t.Fatalf("unexpected error of the result #%v: expected=%v, got=%v", i, ErrBundleDiscarded, v.Err)
+		}
+	}
+}
+
+func TestFlowTransactionFallback(t *testing.T) {
+	ns := NewCookieNamespace("bundle")
+	sw := &testFlowSwitch{}
+	// Message types received by the switch in order.
+	types := make([]uint8, 0)
+	device, disconnect := newTestDevice(t, func(packet []byte) [][]byte {
+		types = append(types, packet[1])
+		return sw.handle(packet)
+	})
+	defer disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		tx := device.NewFlowTransaction(ns)
+		tx.SetAtomic(true)
+		tx.Add(newTestFlow(1, 10))
+		results, err := tx.Commit(ctx)
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		if len(Failed(results)) != 0 {
+			t.Fatalf("unexpected failed results: %v", Failed(results))
+		}
+	}
+
+	// The switch rejects the first bundle, and then the transactions fall back
+	// to the FLOW_MODs followed by a barrier without trying the bundle again.
+	expected := []uint8{
+		of13.OFPT_EXPERIMENTER,
+		of13.OFPT_FLOW_MOD, of13.OFPT_BARRIER_REQUEST,
+		of13.OFPT_FLOW_MOD, of13.OFPT_BARRIER_REQUEST,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("unexpected message types: expected=%v, got=%v", expected, types)
+	}
+	if _, err := device.OpenBundle(ctx); err != ErrUnsupportedBundle {
+		t.Fatalf("unexpected error: expected=%v, got=%v", ErrUnsupportedBundle, err)
+	}
+}
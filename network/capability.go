@@ -0,0 +1,209 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// TableCapability describes what a flow table of a device supports.
+type TableCapability struct {
+	ID             uint8
+	Name           string
+	MaxEntries     uint32
+	Instructions   []openflow.InstructionType
+	NextTables     []uint8
+	WriteActions   []openflow.ActionType
+	ApplyActions   []openflow.ActionType
+	MatchFields    []openflow.MatchField
+	Wildcards      []openflow.MatchField
+	WriteSetFields []openflow.MatchField
+	ApplySetFields []openflow.MatchField
+}
+
+func newTableCapability(v openflow.TableFeatures) TableCapability {
+	return TableCapability{
+		ID:             v.TableID(),
+		Name:           v.Name(),
+		MaxEntries:     v.MaxEntries(),
+		Instructions:   v.Instructions(),
+		NextTables:     v.NextTables(),
+		WriteActions:   v.WriteActions(),
+		ApplyActions:   v.ApplyActions(),
+		MatchFields:    v.MatchFields(),
+		Wildcards:      v.Wildcards(),
+		WriteSetFields: v.WriteSetFields(),
+		ApplySetFields: v.ApplySetFields(),
+	}
+}
+
+func (r TableCapability) SupportsInstruction(t openflow.InstructionType) bool {
+	for _, v := range r.Instructions {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r TableCapability) SupportsApplyAction(t openflow.ActionType) bool {
+	for _, v := range r.ApplyActions {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsMatch returns whether this table can match all the fields.
+func (r TableCapability) SupportsMatch(fields ...openflow.MatchField) bool {
+	for _, f := range fields {
+		found := false
+		for _, v := range r.MatchFields {
+			if v == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CanGoto returns whether packets can be sent from this table to the table whose ID is id.
+func (r TableCapability) CanGoto(id uint8) bool {
+	if !r.SupportsInstruction(openflow.InstructionGotoTable) {
+		return false
+	}
+	for _, v := range r.NextTables {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Capabilities is the capability model of a device built from its table features.
+type Capabilities struct {
+	// Tables sorted by the table ID. It is empty if we don't know the capabilities of
+	// the device, e.g., OpenFlow 1.0 devices do not support the table features request.
+	Tables []TableCapability
+}
+
+func newCapabilities(features []openflow.TableFeatures) Capabilities {
+	tables := make([]TableCapability, 0)
+	for _, v := range features {
+		tables = append(tables, newTableCapability(v))
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].ID < tables[j].ID })
+
+	return Capabilities{Tables: tables}
+}
+
+// Known returns whether we know the capabilities of the device.
+func (r Capabilities) Known() bool {
+	return len(r.Tables) > 0
+}
+
+func (r Capabilities) Table(id uint8) (table TableCapability, ok bool) {
+	for _, v := range r.Tables {
+		if v.ID == id {
+			return v, true
+		}
+	}
+
+	return TableCapability{}, false
+}
+
+// isFlowTable returns whether the table can install the normal flows that match
+// the fields and then apply the output action.
+func (r TableCapability) isFlowTable(fields []openflow.MatchField) bool {
+	return r.SupportsMatch(fields...) &&
+		r.SupportsInstruction(openflow.InstructionApplyActions) &&
+		r.SupportsApplyAction(openflow.ActionOutput)
+}
+
+// flowTablePath finds the first table, starting from the table 0 along the goto-table graph,
+// that can install the normal flows that match the fields. It returns the table IDs on the
+// shortest path from the table 0 to the found table, so the last ID is the found table.
+func (r Capabilities) flowTablePath(fields ...openflow.MatchField) ([]uint8, error) {
+	if !r.Known() {
+		return nil, errors.New("unknown device capabilities")
+	}
+
+	prev := map[uint8]uint8{}
+	visited := map[uint8]bool{0: true}
+	queue := []uint8{0}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		table, ok := r.Table(id)
+		if !ok {
+			continue
+		}
+		if table.isFlowTable(fields) {
+			path := []uint8{id}
+			for id != 0 {
+				id = prev[id]
+				path = append([]uint8{id}, path...)
+			}
+			return path, nil
+		}
+
+		for _, next := range table.NextTables {
+			if visited[next] || !table.CanGoto(next) {
+				continue
+			}
+			visited[next] = true
+			prev[next] = id
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, errors.New("no flow table that supports the required match fields")
+}
+
+func (r *Device) Capabilities() Capabilities {
+	// Read lock
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.capabilities
+}
+
+func (r *Device) setCapabilities(c Capabilities) {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.capabilities = c
+}
@@ -0,0 +1,68 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func TestFlowTablePath(t *testing.T) {
+	gotoOnly := []openflow.InstructionType{openflow.InstructionGotoTable}
+	full := []openflow.InstructionType{openflow.InstructionGotoTable, openflow.InstructionApplyActions}
+	output := []openflow.ActionType{openflow.ActionOutput}
+
+	// Similar to the HP 2920 pipeline: table 0 is a start table, table 100 is
+	// a hardware table that cannot match MAC addresses, and table 200 is a
+	// software table that can match everything.
+	caps := Capabilities{
+		Tables: []TableCapability{
+			{ID: 0, Instructions: gotoOnly, NextTables: []uint8{100, 200}},
+			{ID: 100, Instructions: full, NextTables: []uint8{200}, ApplyActions: output, MatchFields: []openflow.MatchField{openflow.MatchFieldIPv4Dst}},
+			{ID: 200, Instructions: full, ApplyActions: output, MatchFields: []openflow.MatchField{openflow.MatchFieldEthDst, openflow.MatchFieldVLANID}},
+		},
+	}
+	path, err := caps.flowTablePath(openflow.MatchFieldEthDst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(path, []uint8{0, 200}) {
+		t.Fatalf("unexpected path: %v", path)
+	}
+
+	path, err = caps.flowTablePath(openflow.MatchFieldIPv4Dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(path, []uint8{0, 100}) {
+		t.Fatalf("unexpected path: %v", path)
+	}
+
+	if _, err := caps.flowTablePath(openflow.MatchFieldTunnelID); err == nil {
+		t.Fatal("expected an error for the unsupported match field")
+	}
+	if _, err := (Capabilities{}).flowTablePath(openflow.MatchFieldEthDst); err == nil {
+		t.Fatal("expected an error for the unknown capabilities")
+	}
+}
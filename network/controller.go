@@ -23,7 +23,9 @@ package network
 
 import (
 	"context"
+	"errors"
 	"net"
+	"time"
 
 	"github.com/superkkt/cherry/openflow"
 	"github.com/superkkt/cherry/protocol"
@@ -33,12 +35,32 @@ import (
 
 var (
 	logger = logging.MustGetLogger("network")
+
+	ErrPortNotFound = errors.New("unknown device or port")
+)
+
+const (
+	// Timeout to wait the result of a PORT_MOD request.
+	portModTimeout = 5 * time.Second
 )
 
 type database interface {
 	Location(mac net.HardwareAddr) (dpid string, port uint32, status LocationStatus, err error)
 }
 
+// PortConfig is the administrative settings of a switch port. The nil fields are
+// not changed.
+type PortConfig struct {
+	// Port is administratively down.
+	Down *bool
+	// Drop all the packets received by the port.
+	NoRecv *bool
+	// Drop the packets forwarded to the port.
+	NoForward *bool
+	// Do not send PACKET_IN messages for the port.
+	NoPacketIn *bool
+}
+
 type LocationStatus int
 
 const (
@@ -56,43 +78,117 @@ type EventListener interface {
 }
 
 type ControllerEventListener interface {
-	OnPacketIn(Finder, *Port, *protocol.Ethernet) error
+	// OnPacketIn is called with the packet received by the ingress port and its
+	// metadata. See PacketIn for the buffered packets.
+	OnPacketIn(Finder, *Port, *protocol.Ethernet, *PacketIn) error
 	OnPortUp(Finder, *Port) error
 	OnPortDown(Finder, *Port) error
 	OnDeviceUp(Finder, *Device) error
 	OnDeviceDown(Finder, *Device) error
 	OnFlowRemoved(Finder, openflow.FlowRemoved) error
+	// OnExperimenter is called with an experimenter message from the device. See
+	// transceiver.RegisterExperimenter for the type of the message.
+	OnExperimenter(Finder, *Device, openflow.Header) error
 }
 
 type TopologyEventListener interface {
 	OnTopologyChange(Finder) error
 }
 
+// Authenticator verifies whether a device is allowed to use the DPID on the connection.
+type Authenticator interface {
+	Authenticate(conn net.Conn, dpid uint64) error
+}
+
 type Controller struct {
-	topo     *topology
-	listener EventListener
+	topo          *topology
+	listener      EventListener
+	role          *roleManager
+	authenticator Authenticator
+	admission     AdmissionConfig
+	// Maximum length of the packets that the devices send to the controller.
+	missSendLength uint16
+	// Number of the workers that pass the events to the listener.
+	workers    int
+	dispatcher *dispatcher
 }
 
 func NewController(db database) *Controller {
 	return &Controller{
-		topo: newTopology(db),
+		topo:           newTopology(db),
+		role:           newRoleManager(),
+		admission:      DefaultAdmissionConfig(),
+		missSendLength: DefaultMissSendLength,
 	}
 }
 
 func (r *Controller) AddConnection(ctx context.Context, c net.Conn) {
 	conf := sessionConfig{
-		conn:     c,
-		watcher:  r.topo,
-		finder:   r.topo,
-		listener: r.listener,
+		conn:           c,
+		watcher:        r.topo,
+		finder:         r.topo,
+		listener:       r.listener,
+		role:           r.role,
+		auth:           r.authenticator,
+		admission:      r.admission,
+		missSendLength: r.missSendLength,
 	}
 	session := newSession(conf)
 	go session.Run(ctx)
 }
 
+// OnElectionChanged promotes all the sessions to the master if master is true.
+// Otherwise, it demotes all the sessions to slaves without disconnecting them.
+func (r *Controller) OnElectionChanged(master bool) {
+	for _, s := range r.role.setMaster(master) {
+		if master {
+			s.promote()
+		} else {
+			s.demote()
+		}
+	}
+}
+
+// SetAuthenticator sets the authenticator that verifies the DPID of the devices
+// connected after calling this function.
+func (r *Controller) SetAuthenticator(a Authenticator) {
+	r.authenticator = a
+}
+
+// SetAdmissionConfig sets the PACKET_IN rate limits of the devices. It is applied
+// to the devices connected after this call.
+func (r *Controller) SetAdmissionConfig(c AdmissionConfig) {
+	r.admission = c
+}
+
+// DefaultMissSendLength is enough for the Ethernet and ARP headers that the
+// applications parse.
+const DefaultMissSendLength = 128
+
+// SetMissSendLength sets the maximum length of the packets that the devices send
+// to the controller by PACKET_IN. The devices buffer the packets and send only
+// their first length bytes, unless length is openflow.NoBufferLength that makes
+// the devices send the whole packets without buffering. It is applied to the
+// devices connected after this call.
+func (r *Controller) SetMissSendLength(length uint16) {
+	r.missSendLength = length
+}
+
+// SetEventWorkers sets the number of the goroutines that pass the events to the
+// event listener concurrently. The default is 4 times the number of CPUs. It
+// should be called before SetEventListener.
+func (r *Controller) SetEventWorkers(n int) {
+	r.workers = n
+}
+
+// SetEventListener sets the event listener that receives the events from all the
+// devices. The events of a device are passed to l one by one in order, while the
+// events of different devices are passed concurrently. It should be called only
+// once before adding connections.
 func (r *Controller) SetEventListener(l EventListener) {
-	r.listener = l
-	r.topo.setEventListener(l)
+	r.dispatcher = newDispatcher(l, r.workers)
+	r.listener = r.dispatcher
+	r.topo.setEventListener(r.dispatcher)
 }
 
 func (r *Controller) String() string {
@@ -114,7 +210,7 @@ func (r *Controller) Announce(ip net.IP, mac net.HardwareAddr) error {
 func (r *Controller) RemoveFlows() error {
 	for _, device := range r.topo.Devices() {
 		logger.Infof("removing all flows from %v", device.ID())
-		if err := device.RemoveFlows(); err != nil {
+		if err := device.RemoveFlows(AllNamespaces); err != nil {
 			logger.Warningf("failed to remove all flows on %v device: %v", device.ID(), err)
 			continue
 		}
@@ -125,7 +221,7 @@ func (r *Controller) RemoveFlows() error {
 
 func (r *Controller) RemoveFlowsByMAC(mac net.HardwareAddr) error {
 	for _, device := range r.topo.Devices() {
-		if err := device.RemoveFlowByMAC(mac); err != nil {
+		if err := device.RemoveFlowByMAC(AllNamespaces, mac); err != nil {
 			logger.Errorf("failed to remove flows for %v from %v: %v", mac, device.ID(), err)
 			continue
 		}
@@ -134,3 +230,65 @@ func (r *Controller) RemoveFlowsByMAC(mac net.HardwareAddr) error {
 
 	return nil
 }
+
+// SetPortConfig changes the administrative settings of the port on the device
+// whose ID is deviceID. It returns ErrPortNotFound if there is no such port.
+func (r *Controller) SetPortConfig(deviceID string, port uint32, config PortConfig) error {
+	device := r.topo.Device(deviceID)
+	if device == nil {
+		return ErrPortNotFound
+	}
+	p := device.Port(port)
+	if p == nil {
+		return ErrPortNotFound
+	}
+
+	var c, mask openflow.PortConfig
+	set := func(flag openflow.PortConfig, v *bool) {
+		if v == nil {
+			return
+		}
+		mask |= flag
+		if *v {
+			c |= flag
+		}
+	}
+	set(openflow.PortDown, config.Down)
+	set(openflow.PortNoRecv, config.NoRecv)
+	set(openflow.PortNoForward, config.NoForward)
+	set(openflow.PortNoPacketIn, config.NoPacketIn)
+	if mask == 0 {
+		// Nothing to change.
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), portModTimeout)
+	defer cancel()
+
+	return p.SetConfig(ctx, c, mask)
+}
+
+// Stats returns the statistics collected from all the devices.
+func (r *Controller) Stats() ([]DeviceReport, error) {
+	result := make([]DeviceReport, 0)
+	for _, device := range r.topo.Devices() {
+		v := DeviceReport{
+			ID:       device.ID(),
+			Stats:    device.Stats(),
+			PacketIn: device.PacketInStats(),
+			Ports:    make([]PortReport, 0),
+		}
+		if r.dispatcher != nil {
+			v.Events = r.dispatcher.Stats(device.ID())
+		}
+		for _, port := range device.Ports() {
+			v.Ports = append(v.Ports, PortReport{
+				Number: port.Number(),
+				Stats:  port.Stats(),
+			})
+		}
+		result = append(result, v)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// The 64-bit flow cookie is divided into three parts:
+//
+//	bit 63:     reserved for the special flows installed by the controller itself, e.g., table-miss.
+//	bit 48-62:  cookie namespace of the northbound application that owns the flow.
+//	bit 0-47:   free to be used by the owner application.
+const (
+	cookieNamespaceShift = 48
+	cookieNamespaceMask  = uint64(0x7FFF) << cookieNamespaceShift
+	cookieValueMask      = uint64(1)<<cookieNamespaceShift - 1
+)
+
+// CookieNamespace identifies the northbound application that owns flows. All the
+// flow operations of Device are scoped to a namespace: the installed flows are
+// stamped with the namespace, and removing or querying flows only affects the
+// flows in the namespace.
+//
+// OpenFlow 1.0 does not support the cookie mask, so removing flows from an
+// OpenFlow 1.0 device affects the flows of all the namespaces.
+type CookieNamespace uint16
+
+const (
+	// NoNamespace is the namespace of the flows that are not owned by any application.
+	NoNamespace CookieNamespace = 0
+	// AllNamespaces selects the flows of all the namespaces. It can only be used
+	// to remove or query flows, e.g., by the administrator.
+	AllNamespaces CookieNamespace = 0xFFFF
+)
+
+// NewCookieNamespace returns the cookie namespace derived from the application
+// name. It is stable across restarts so that the flows installed before a restart
+// still belong to the same application.
+func NewCookieNamespace(name string) CookieNamespace {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	v := CookieNamespace(h.Sum32() & 0x7FFF)
+	// Zero is reserved for no owner.
+	if v == 0 {
+		v = 1
+	}
+
+	return v
+}
+
+func (r CookieNamespace) String() string {
+	if r == AllNamespaces {
+		return "all"
+	}
+
+	return fmt.Sprintf("0x%04x", uint16(r))
+}
+
+// Cookie returns the flow cookie whose namespace part is this namespace and
+// the lower 48 bits are value.
+func (r CookieNamespace) Cookie(value uint64) uint64 {
+	if r == AllNamespaces {
+		return value & cookieValueMask
+	}
+
+	return uint64(r)<<cookieNamespaceShift | value&cookieValueMask
+}
+
+// Mask returns the cookie mask that selects the flows in this namespace. The
+// special flows installed by the controller itself are never selected.
+func (r CookieNamespace) Mask() uint64 {
+	if r == AllNamespaces {
+		return reservedCookie
+	}
+
+	return reservedCookie | cookieNamespaceMask
+}
+
+// stamp stamps the flow with this namespace. flow.Cookie should only use the lower 48 bits.
+func (r CookieNamespace) stamp(flow Flow) (Flow, error) {
+	if r == AllNamespaces {
+		return Flow{}, errors.New("cannot install a flow into all the cookie namespaces")
+	}
+	if flow.Cookie&^cookieValueMask != 0 {
+		return Flow{}, fmt.Errorf("flow cookie exceeds 48 bits: 0x%x", flow.Cookie)
+	}
+	flow.Cookie = r.Cookie(flow.Cookie)
+
+	return flow, nil
+}
+
+// Owns returns whether the flow cookie belongs to this namespace.
+func (r CookieNamespace) Owns(cookie uint64) bool {
+	return cookie&r.Mask() == r.Cookie(0)
+}
+
+// AppFlows is the flows of a device that belong to a cookie namespace. It is a
+// shorthand that passes the namespace to the flow operations of the device.
+type AppFlows struct {
+	device    *Device
+	namespace CookieNamespace
+}
+
+func (r *Device) AppFlows(ns CookieNamespace) *AppFlows {
+	return &AppFlows{device: r, namespace: ns}
+}
+
+func (r *AppFlows) Device() *Device {
+	return r.device
+}
+
+func (r *AppFlows) Namespace() CookieNamespace {
+	return r.namespace
+}
+
+func (r *AppFlows) SetFlow(match openflow.Match, port openflow.OutPort) error {
+	return r.device.SetFlow(r.namespace, match, port)
+}
+
+func (r *AppFlows) InstallFlow(flow Flow) error {
+	return r.device.InstallFlow(r.namespace, flow)
+}
+
+func (r *AppFlows) ModifyFlow(flow Flow) error {
+	return r.device.ModifyFlow(r.namespace, flow)
+}
+
+func (r *AppFlows) DeleteFlow(flow Flow) error {
+	return r.device.DeleteFlow(r.namespace, flow)
+}
+
+func (r *AppFlows) RemoveFlow(match openflow.Match, port openflow.OutPort) error {
+	return r.device.RemoveFlow(r.namespace, match, port)
+}
+
+func (r *AppFlows) RemoveFlowByMAC(mac net.HardwareAddr) error {
+	return r.device.RemoveFlowByMAC(r.namespace, mac)
+}
+
+func (r *AppFlows) RemoveFlows() error {
+	return r.device.RemoveFlows(r.namespace)
+}
+
+func (r *AppFlows) FlowStats(ctx context.Context, match openflow.Match) ([]openflow.FlowStats, error) {
+	return r.device.FlowStats(ctx, r.namespace, match)
+}
+
+func (r *AppFlows) NewTransaction() *FlowTransaction {
+	return r.device.NewFlowTransaction(r.namespace)
+}
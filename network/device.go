@@ -22,6 +22,7 @@
 package network
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
@@ -62,6 +63,10 @@ type Device struct {
 	closed       bool
 	flowCache    *flowCache
 	vlanID       uint16
+	stats        DeviceStats
+	capabilities Capabilities
+	bundleID     uint32 // Last bundle ID that we have opened
+	noBundle     bool   // Whether the device has rejected to open a bundle
 }
 
 var (
@@ -116,6 +121,22 @@ func (r *Device) setID(id string) {
 	r.id = id
 }
 
+// reset clears the states obtained from the device during its initialization so
+// that we can initialize it again on the same connection.
+func (r *Device) reset() {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.id = ""
+	r.features = Features{}
+	r.ports = make(map[uint32]*Port)
+	r.flowTableID = 0
+	r.stats = DeviceStats{}
+	r.capabilities = Capabilities{}
+	r.flowCache.purge()
+}
+
 func (r *Device) isReady() bool {
 	// Read lock
 	r.mutex.RLock()
@@ -264,8 +285,44 @@ func (r *Device) IsClosed() bool {
 	return r.closed
 }
 
-// SetFlow installs a normal flow entry for packet switching and routing into the switch device.
-func (r *Device) SetFlow(match openflow.Match, port openflow.OutPort) error {
+// setDefaultVLANID sets the default VLAN ID to the match of a normal flow. It
+// is necessary to use the L2 MAC flow table of Dell SXXX switches. The caller
+// should hold the device lock.
+func (r *Device) setDefaultVLANID(match openflow.Match) {
+	// Skip if the flow table cannot match the VLAN ID.
+	if table, ok := r.capabilities.Table(r.flowTableID); ok && !table.SupportsMatch(openflow.MatchFieldVLANID) {
+		return
+	}
+	match.SetVLANID(r.vlanID)
+}
+
+// NewFlowMatch returns a new match for the normal flows that will be installed
+// into the flow table whose ID is FlowTableID(). The default VLAN ID is already
+// set if the flow table can match it.
+func (r *Device) NewFlowMatch() (openflow.Match, error) {
+	// Read lock
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.factory == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+	match, err := r.factory.NewMatch()
+	if err != nil {
+		return nil, err
+	}
+	r.setDefaultVLANID(match)
+
+	return match, nil
+}
+
+// SetFlow installs a normal flow entry for packet switching and routing into the
+// switch device. The flow is stamped with the cookie namespace ns.
+func (r *Device) SetFlow(ns CookieNamespace, match openflow.Match, port openflow.OutPort) error {
+	if ns == AllNamespaces {
+		return errors.New("cannot install a flow into all the cookie namespaces")
+	}
+
 	// Write lock
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -274,8 +331,7 @@ func (r *Device) SetFlow(match openflow.Match, port openflow.OutPort) error {
 		return ErrClosedDevice
 	}
 
-	// Set the default VLAN ID. It is necessary to use the L2 MAC flow table of Dell SXXX switches.
-	match.SetVLANID(r.vlanID)
+	r.setDefaultVLANID(match)
 
 	action, err := r.factory.NewAction()
 	if err != nil {
@@ -299,6 +355,7 @@ func (r *Device) SetFlow(match openflow.Match, port openflow.OutPort) error {
 	if err != nil {
 		return err
 	}
+	flow.SetCookie(ns.Cookie(0))
 	flow.SetTableID(r.flowTableID)
 	// This idle timeout is actually useless because we update the installed flows
 	// more frequently than this timeout. However, this can be useful if there are
@@ -333,8 +390,9 @@ func (r *Device) SetFlow(match openflow.Match, port openflow.OutPort) error {
 	return r.session.Write(barrier)
 }
 
-// RemoveFlows removes all the normal flows except special ones for table miss and ARP packets.
-func (r *Device) RemoveFlows() error {
+// RemoveFlows removes all the normal flows in the cookie namespace ns. The special
+// ones for table miss and ARP packets are not removed.
+func (r *Device) RemoveFlows(ns CookieNamespace) error {
 	// Write lock
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -348,7 +406,7 @@ func (r *Device) RemoveFlows() error {
 		return err
 	}
 	// Default VLAN ID specified for the normal flows.
-	match.SetVLANID(r.vlanID)
+	r.setDefaultVLANID(match)
 
 	// Set output port to OFPP_NONE
 	port := openflow.NewOutPort()
@@ -359,7 +417,8 @@ func (r *Device) RemoveFlows() error {
 		return err
 	}
 	// Remove all the normal flows, except the special table miss and ARP flows whose MSB is 1.
-	flowmod.SetCookieMask(0x1 << 63)
+	flowmod.SetCookie(ns.Cookie(0))
+	flowmod.SetCookieMask(ns.Mask())
 	flowmod.SetTableID(0xFF) // ALL
 	flowmod.SetFlowMatch(match)
 	flowmod.SetOutPort(port)
@@ -374,7 +433,7 @@ func (r *Device) RemoveFlows() error {
 // TODO:
 // Remove the flow caches that match the removed flows. This is not a critical
 // issue, but same flows cannot be installed until the caches are expired.
-func (r *Device) RemoveFlow(match openflow.Match, port openflow.OutPort) error {
+func (r *Device) RemoveFlow(ns CookieNamespace, match openflow.Match, port openflow.OutPort) error {
 	// Write lock
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -384,14 +443,15 @@ func (r *Device) RemoveFlow(match openflow.Match, port openflow.OutPort) error {
 	}
 
 	// Default VLAN ID specified for the normal flows.
-	match.SetVLANID(r.vlanID)
+	r.setDefaultVLANID(match)
 
 	flowmod, err := r.factory.NewFlowMod(openflow.FlowDelete)
 	if err != nil {
 		return err
 	}
 	// Remove all the normal flows, except the table miss and ARP flows whose MSB is 1.
-	flowmod.SetCookieMask(0x1 << 63)
+	flowmod.SetCookie(ns.Cookie(0))
+	flowmod.SetCookieMask(ns.Mask())
 	flowmod.SetTableID(0xFF) // ALL
 	flowmod.SetFlowMatch(match)
 	flowmod.SetOutPort(port)
@@ -402,7 +462,7 @@ func (r *Device) RemoveFlow(match openflow.Match, port openflow.OutPort) error {
 // TODO:
 // Remove the flow caches that match the removed flows. This is not a critical
 // issue, but same flows cannot be installed until the caches are expired.
-func (r *Device) RemoveFlowByMAC(mac net.HardwareAddr) error {
+func (r *Device) RemoveFlowByMAC(ns CookieNamespace, mac net.HardwareAddr) error {
 	// Write lock
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -416,7 +476,7 @@ func (r *Device) RemoveFlowByMAC(mac net.HardwareAddr) error {
 		return err
 	}
 	// Default VLAN ID specified for the normal flows.
-	match.SetVLANID(r.vlanID)
+	r.setDefaultVLANID(match)
 	match.SetDstMAC(mac)
 
 	port := openflow.NewOutPort()
@@ -427,7 +487,8 @@ func (r *Device) RemoveFlowByMAC(mac net.HardwareAddr) error {
 		return err
 	}
 	// Remove all the normal flows, except the table miss and ARP flows whose MSB is 1.
-	flowmod.SetCookieMask(0x1 << 63)
+	flowmod.SetCookie(ns.Cookie(0))
+	flowmod.SetCookieMask(ns.Mask())
 	flowmod.SetTableID(0xFF) // ALL
 	flowmod.SetFlowMatch(match)
 	flowmod.SetOutPort(port)
@@ -465,7 +526,7 @@ func (r *Device) SendARPAnnouncement(ip net.IP, mac net.HardwareAddr) error {
 		return err
 	}
 
-	return r.flood(nil, announcement)
+	return r.flood(nil, openflow.NoBuffer, announcement)
 }
 
 func (r *Device) SendARPProbe(sha net.HardwareAddr, tpa net.IP) error {
@@ -482,7 +543,7 @@ func (r *Device) SendARPProbe(sha net.HardwareAddr, tpa net.IP) error {
 		return err
 	}
 
-	return r.flood(nil, probe)
+	return r.flood(nil, openflow.NoBuffer, probe)
 }
 
 func makeARPProbe(sha net.HardwareAddr, tpa net.IP) ([]byte, error) {
@@ -514,11 +575,77 @@ func (r *Device) Flood(ingress *Port, packet []byte) error {
 		return ErrClosedDevice
 	}
 
-	return r.flood(ingress, packet)
+	return r.flood(ingress, openflow.NoBuffer, packet)
 }
 
-// flood broadcasts the packet to all ports of this device, except the ingress port if ingress is not nil.
-func (r *Device) flood(ingress *Port, packet []byte) error {
+// FloodPacketIn is same as Flood except that it floods the packet buffered in
+// this device instead of sending the packet again if the packet reported by
+// packetIn is buffered. ingress should be the ingress port of the packet. It
+// returns an error if the packet is truncated and cannot be sent by its buffer.
+func (r *Device) FloodPacketIn(ingress *Port, packetIn *PacketIn, packet []byte) error {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrClosedDevice
+	}
+	if ingress == nil {
+		panic("nil ingress port")
+	}
+
+	bufferID := uint32(openflow.NoBuffer)
+	if packetIn != nil && ingress.Device() == r {
+		bufferID = packetIn.TakeBuffer()
+	}
+	if bufferID == openflow.NoBuffer && packetIn != nil && packetIn.IsTruncated() {
+		return fmt.Errorf("failed to flood the truncated packet: ingress=%v", ingress.ID())
+	}
+
+	return r.flood(ingress, bufferID, packet)
+}
+
+// dropBuffer releases the packet buffered in this device without sending it.
+// inPort is the ingress port of the packet.
+func (r *Device) dropBuffer(inPort, bufferID uint32) error {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrClosedDevice
+	}
+
+	port := openflow.NewInPort()
+	port.SetValue(inPort)
+	out, err := r.factory.NewPacketOut()
+	if err != nil {
+		return err
+	}
+	out.SetInPort(port)
+	// The device drops the packet since there is no action.
+	out.SetBufferID(bufferID)
+
+	return r.session.Write(out)
+}
+
+// releasePacketIn drops the packet buffered in this device unless its buffer has
+// been taken by the event listeners. Otherwise, the buffer is wasted until it
+// expires in the device.
+func (r *Device) releasePacketIn(ingress *Port, packetIn *PacketIn) {
+	bufferID := packetIn.TakeBuffer()
+	if bufferID == openflow.NoBuffer {
+		return
+	}
+	if err := r.dropBuffer(ingress.Number(), bufferID); err != nil {
+		logger.Debugf("failed to release the packet buffer: ingress=%v, bufferID=%#x, err=%v", ingress.ID(), bufferID, err)
+	}
+}
+
+// flood broadcasts the packet to all ports of this device, except the ingress
+// port if ingress is not nil. The packet buffered in this device is sent instead
+// of the packet if bufferID is not openflow.NoBuffer.
+func (r *Device) flood(ingress *Port, bufferID uint32, packet []byte) error {
 	inPort := openflow.NewInPort()
 	if ingress != nil {
 		inPort.SetValue(ingress.Number())
@@ -542,11 +669,77 @@ func (r *Device) flood(ingress *Port, packet []byte) error {
 	}
 	out.SetInPort(inPort)
 	out.SetAction(action)
+	out.SetBufferID(bufferID)
 	out.SetData(packet)
 
 	return r.session.Write(out)
 }
 
+// FlowStats queries all the flow entries in the cookie namespace ns that match the
+// match from all the flow tables of this device, and then returns them with their
+// counters. match can be nil to query all the flow entries. It blocks until all
+// the parts of the reply are received, or ctx is canceled.
+func (r *Device) FlowStats(ctx context.Context, ns CookieNamespace, match openflow.Match) ([]openflow.FlowStats, error) {
+	f := r.Factory()
+	if f == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+
+	if match == nil {
+		var err error
+		// Wildcard
+		if match, err = f.NewMatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := f.NewFlowStatsRequest()
+	if err != nil {
+		return nil, err
+	}
+	req.SetTableID(0xFF) // ALL
+	req.SetMatch(match)
+	req.SetCookie(ns.Cookie(0))
+	req.SetCookieMask(ns.Mask())
+
+	replies, err := r.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openflow.FlowStats, 0)
+	for _, v := range replies {
+		reply, ok := v.(openflow.FlowStatsReply)
+		if !ok {
+			return nil, fmt.Errorf("unexpected reply message: type=%v", v.Type())
+		}
+		for _, stats := range reply.Stats() {
+			// OpenFlow 1.0 does not support the cookie mask of a request.
+			if !ns.Owns(stats.Cookie()) {
+				continue
+			}
+			result = append(result, stats)
+		}
+	}
+
+	return result, nil
+}
+
+// request sends the request message, and then waits for its reply messages
+// until we get the last part of the multipart reply or ctx is canceled.
+func (r *Device) request(ctx context.Context, req transceiver.Request) ([]openflow.Header, error) {
+	if r.IsClosed() {
+		return nil, ErrClosedDevice
+	}
+
+	replies, err := r.session.transceiver.Request(ctx, req)
+	if err == transceiver.ErrClosed {
+		return nil, ErrClosedDevice
+	}
+
+	return replies, err
+}
+
 func (r *Device) Close() {
 	// Write lock
 	r.mutex.Lock()
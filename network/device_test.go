@@ -0,0 +1,270 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+	"github.com/superkkt/cherry/openflow/transceiver"
+)
+
+// testHandler ignores the messages that are not the replies to the requests.
+type testHandler struct {
+	transceiver.Handler
+	negotiated chan struct{}
+}
+
+func (r *testHandler) OnHello(f openflow.Factory, w transceiver.Writer, v openflow.Hello) error {
+	close(r.negotiated)
+	return nil
+}
+
+func (r *testHandler) OnBarrierReply(f openflow.Factory, w transceiver.Writer, v openflow.BarrierReply) error {
+	return nil
+}
+
+// newTestDevice returns an OpenFlow 1.3 device connected to a fake switch that
+// replies to each packet from the device with the packets returned by handle.
+// handle is called by a single goroutine. Call the returned function to close
+// the connection.
+func newTestDevice(t *testing.T, handle func(packet []byte) [][]byte) (*Device, func()) {
+	local, remote := net.Pipe()
+	handler := &testHandler{negotiated: make(chan struct{})}
+	s := new(session)
+	s.transceiver = transceiver.NewTransceiver(transceiver.NewStream(local, 0xFFFF), handler)
+	s.device = &Device{
+		id:        "1",
+		session:   s,
+		ports:     make(map[uint32]*Port),
+		flowCache: newFlowCache(time.Second),
+		factory:   of13.NewFactory(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.transceiver.Run(ctx)
+	}()
+	disconnect := func() {
+		cancel()
+		local.Close()
+		remote.Close()
+		<-done
+	}
+
+	hello := openflow.NewMessage(openflow.OF13_VERSION, of13.OFPT_HELLO, 0)
+	packet, err := hello.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handler.negotiated:
+	case <-time.After(5 * time.Second):
+		disconnect()
+		t.Fatal("timeout on the version negotiation")
+	}
+	go serveTestSwitch(remote, handle)
+
+	return s.device, disconnect
+}
+
+func serveTestSwitch(conn net.Conn, handle func(packet []byte) [][]byte) {
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		packet := make([]byte, binary.BigEndian.Uint16(header[2:4]))
+		copy(packet, header)
+		if _, err := io.ReadFull(conn, packet[8:]); err != nil {
+			return
+		}
+		for _, v := range handle(packet) {
+			if _, err := conn.Write(v); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// newTestReply returns a reply message of the type whose transaction ID is same
+// with the request packet's one.
+func newTestReply(request []byte, msgType uint8, payload []byte) []byte {
+	msg := openflow.NewMessage(request[0], msgType, binary.BigEndian.Uint32(request[4:8]))
+	msg.SetPayload(payload)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	return packet
+}
+
+// newTestErrorReply returns an ERROR message for the request packet.
+func newTestErrorReply(request []byte, class, code uint16) []byte {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint16(payload[0:2], class)
+	binary.BigEndian.PutUint16(payload[2:4], code)
+	// The data has at least 64 bytes of the failed request.
+	if len(request) > 64 {
+		request = request[:64]
+	}
+
+	return newTestReply(request, of13.OFPT_ERROR, append(payload, request...))
+}
+
+// newTestFlowStatsReply returns a FLOW_STATS reply for the request packet that
+// has the flows whose cookie is used as it is.
+func newTestFlowStatsReply(request []byte, flows []Flow) []byte {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint16(payload[0:2], of13.OFPMP_FLOW)
+	for _, flow := range flows {
+		match, err := flow.Match.MarshalBinary()
+		if err != nil {
+			panic(err)
+		}
+		var inst []byte
+		if len(flow.Instructions) > 0 {
+			v, err := flow.instruction(of13.NewFactory())
+			if err != nil {
+				panic(err)
+			}
+			if inst, err = v.MarshalBinary(); err != nil {
+				panic(err)
+			}
+		}
+
+		v := make([]byte, 48)
+		binary.BigEndian.PutUint16(v[0:2], uint16(48+len(match)+len(inst)))
+		v[2] = flow.TableID
+		binary.BigEndian.PutUint16(v[12:14], flow.Priority)
+		binary.BigEndian.PutUint16(v[14:16], flow.IdleTimeout)
+		binary.BigEndian.PutUint16(v[16:18], flow.HardTimeout)
+		binary.BigEndian.PutUint64(v[24:32], flow.Cookie)
+		v = append(v, match...)
+		payload = append(payload, append(v, inst...)...)
+	}
+
+	return newTestReply(request, of13.OFPT_MULTIPART_REPLY, payload)
+}
+
+// testFlowSwitch is a fake switch that has the flows and records the FLOW_MODs
+// received from the device. It rejects the bundles unless bundles is true.
+type testFlowSwitch struct {
+	mutex    sync.Mutex
+	flows    []Flow
+	flowMods []testFlowMod
+	// reject returns whether the switch rejects the FLOW_MOD.
+	reject func(*of13.FlowMod) bool
+	// bundles makes the switch implement the ONF bundle extension.
+	bundles bool
+	// Bundle control requests received from the device.
+	controls []openflow.BundleCtrlType
+}
+
+type testFlowMod struct {
+	command uint8
+	msg     *of13.FlowMod
+}
+
+func (r *testFlowSwitch) handle(packet []byte) [][]byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch packet[1] {
+	case of13.OFPT_MULTIPART_REQUEST:
+		return [][]byte{newTestFlowStatsReply(packet, r.flows)}
+	case of13.OFPT_EXPERIMENTER:
+		if !r.bundles {
+			return [][]byte{newTestErrorReply(packet, of13.OFPET_BAD_REQUEST, of13.OFPBRC_BAD_EXPERIMENTER)}
+		}
+		return r.handleBundle(packet)
+	case of13.OFPT_FLOW_MOD:
+		if !r.addFlowMod(packet) {
+			return [][]byte{newTestErrorReply(packet, of13.OFPET_FLOW_MOD_FAILED, of13.OFPFMFC_TABLE_FULL)}
+		}
+	case of13.OFPT_BARRIER_REQUEST:
+		return [][]byte{newTestReply(packet, of13.OFPT_BARRIER_REPLY, nil)}
+	}
+
+	return nil
+}
+
+// addFlowMod records the FLOW_MOD packet. It returns false if the switch rejects it.
+func (r *testFlowSwitch) addFlowMod(packet []byte) bool {
+	msg := new(of13.FlowMod)
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		panic(err)
+	}
+	// The command follows the cookie, cookie mask and table ID.
+	r.flowMods = append(r.flowMods, testFlowMod{command: packet[25], msg: msg})
+
+	return r.reject == nil || !r.reject(msg)
+}
+
+func (r *testFlowSwitch) handleBundle(packet []byte) [][]byte {
+	if of13.IsBundleControl(packet) {
+		req := new(of13.BundleControl)
+		if err := req.UnmarshalBinary(packet); err != nil {
+			panic(err)
+		}
+		r.controls = append(r.controls, req.ControlType())
+		// The reply type is the request type plus one.
+		reply := append([]byte{}, packet...)
+		binary.BigEndian.PutUint16(reply[20:22], binary.BigEndian.Uint16(packet[20:22])+1)
+		return [][]byte{reply}
+	}
+
+	// The bundled message follows the ONF experimenter header and the bundle ID
+	// and flags. An error caused by the message has the xid of the BundleAdd.
+	if !r.addFlowMod(packet[24:]) {
+		return [][]byte{newTestErrorReply(packet, of13.OFPET_FLOW_MOD_FAILED, of13.OFPFMFC_TABLE_FULL)}
+	}
+
+	return nil
+}
+
+func (r *testFlowSwitch) received() []testFlowMod {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]testFlowMod{}, r.flowMods...)
+}
+
+func (r *testFlowSwitch) receivedControls() []openflow.BundleCtrlType {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]openflow.BundleCtrlType{}, r.controls...)
+}
@@ -0,0 +1,306 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/protocol"
+)
+
+const (
+	// Maximum number of the pending events of a device. Event senders are
+	// blocked until the queue has a room if it is full.
+	eventQueueSize = 1024
+	// Queue key of the events that do not belong to a device.
+	globalEventKey = ""
+)
+
+// EventStats is the counters of the events dispatched to the event listeners.
+type EventStats struct {
+	// Number of the events processed by the listeners.
+	Dispatched uint64
+	// Number of the events whose listeners have returned an error.
+	Failed uint64
+	// Number of the times that an event sender has been blocked because the
+	// queue is full.
+	Blocked uint64
+	// Number of the events waiting in the queue.
+	Queued int
+	// Maximum number of the events that have waited in the queue.
+	MaxQueued int
+	// Average time that an event waits in the queue until a worker picks it.
+	AvgWait time.Duration
+}
+
+type event struct {
+	device    *Device
+	name      string
+	fn        func() error
+	timestamp time.Time
+	// result receives the error of fn if it is not nil. Then, the sender handles
+	// the error instead of the dispatcher.
+	result chan error
+}
+
+type eventQueue struct {
+	events []event
+	// scheduled is true if a worker is processing the queue or the queue is
+	// waiting for a worker.
+	scheduled bool
+	stats     EventStats
+	// Number of the events taken by the workers and their total waiting time.
+	taken     uint64
+	totalWait time.Duration
+}
+
+// dispatcher passes the events to the listener using a pool of workers. The events
+// of a device are processed one by one in the order they are sent, while the
+// events of different devices are processed concurrently. The events that do not
+// belong to a device, such as topology changes and flow removals, are ordered in
+// a separate global queue.
+type dispatcher struct {
+	listener EventListener
+	mutex    sync.Mutex
+	// work is signaled when a queue is scheduled.
+	work *sync.Cond
+	// space is signaled when an event is taken from a queue.
+	space  *sync.Cond
+	queues map[string]*eventQueue
+	// Queues waiting for a worker in FIFO order.
+	ready []*eventQueue
+}
+
+// newDispatcher returns a dispatcher running the workers. The number of workers
+// is 4 times the number of CPUs if workers is not positive because the listeners
+// usually wait for I/O, e.g., querying the database.
+func newDispatcher(l EventListener, workers int) *dispatcher {
+	if l == nil {
+		panic("nil event listener")
+	}
+	if workers <= 0 {
+		workers = 4 * runtime.NumCPU()
+	}
+
+	v := &dispatcher{
+		listener: l,
+		queues:   make(map[string]*eventQueue),
+	}
+	v.work = sync.NewCond(&v.mutex)
+	v.space = sync.NewCond(&v.mutex)
+	for i := 0; i < workers; i++ {
+		go v.worker()
+	}
+
+	return v
+}
+
+// call queues the event into the queue of key, and then waits for the listener
+// to process the event. It returns the error of the listener.
+func (r *dispatcher) call(key string, v event) error {
+	v.result = make(chan error, 1)
+	r.send(key, v)
+
+	return <-v.result
+}
+
+// send queues the event into the queue of key. It blocks while the queue is full.
+func (r *dispatcher) send(key string, v event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	q, ok := r.queues[key]
+	if !ok {
+		q = new(eventQueue)
+		r.queues[key] = q
+	}
+	if len(q.events) >= eventQueueSize {
+		q.stats.Blocked++
+		logger.Debugf("event queue is full: key=%v, event=%v", key, v.name)
+		for len(q.events) >= eventQueueSize {
+			r.space.Wait()
+		}
+	}
+
+	v.timestamp = time.Now()
+	q.events = append(q.events, v)
+	if len(q.events) > q.stats.MaxQueued {
+		q.stats.MaxQueued = len(q.events)
+	}
+	if !q.scheduled {
+		q.scheduled = true
+		r.ready = append(r.ready, q)
+		r.work.Signal()
+	}
+}
+
+func (r *dispatcher) worker() {
+	for {
+		q, v := r.next()
+		err := v.fn()
+
+		r.mutex.Lock()
+		q.stats.Dispatched++
+		if err != nil {
+			q.stats.Failed++
+		}
+		// Put the queue at the end of the ready list so that the other devices
+		// are not starved by a busy device.
+		if len(q.events) > 0 {
+			r.ready = append(r.ready, q)
+			r.work.Signal()
+		} else {
+			q.scheduled = false
+		}
+		r.mutex.Unlock()
+
+		if v.result != nil {
+			v.result <- err
+		} else if err != nil {
+			r.handleError(v, err)
+		}
+	}
+}
+
+// next waits for a scheduled queue, and then takes the first event from the queue.
+func (r *dispatcher) next() (*eventQueue, event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for len(r.ready) == 0 {
+		r.work.Wait()
+	}
+	q := r.ready[0]
+	r.ready[0] = nil
+	r.ready = r.ready[1:]
+
+	v := q.events[0]
+	q.events[0] = event{}
+	q.events = q.events[1:]
+	q.taken++
+	q.totalWait += time.Since(v.timestamp)
+	r.space.Broadcast()
+
+	return q, v
+}
+
+func (r *dispatcher) handleError(v event, err error) {
+	if v.device == nil || isTemporaryErr(err) {
+		logger.Errorf("%v: %v", v.name, err)
+		return
+	}
+	// Close the device as if the error is returned from its session handler so
+	// that the device reconnects and the listeners get a fresh start.
+	logger.Errorf("%v: closing the device due to the error: device=%v, error=%v", v.name, v.device.ID(), err)
+	v.device.session.close()
+}
+
+// Stats returns the counters of the queue of key.
+func (r *dispatcher) Stats(key string) EventStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	q, ok := r.queues[key]
+	if !ok {
+		return EventStats{}
+	}
+	stats := q.stats
+	stats.Queued = len(q.events)
+	if q.taken > 0 {
+		stats.AvgWait = q.totalWait / time.Duration(q.taken)
+	}
+
+	return stats
+}
+
+// OnPacketIn blocks until the listener processes the packet, and then returns the
+// error of the listener. The pending packets of a device wait in its admission
+// queue, which passes them by their priority, rather than in the event queue.
+func (r *dispatcher) OnPacketIn(finder Finder, ingress *Port, eth *protocol.Ethernet, packetIn *PacketIn) error {
+	device := ingress.Device()
+	return r.call(device.ID(), event{device: device, name: "OnPacketIn", fn: func() error {
+		defer device.releasePacketIn(ingress, packetIn)
+		return r.listener.OnPacketIn(finder, ingress, eth, packetIn)
+	}})
+}
+
+func (r *dispatcher) OnPortUp(finder Finder, port *Port) error {
+	device := port.Device()
+	r.send(device.ID(), event{device: device, name: "OnPortUp", fn: func() error {
+		return r.listener.OnPortUp(finder, port)
+	}})
+
+	return nil
+}
+
+func (r *dispatcher) OnPortDown(finder Finder, port *Port) error {
+	device := port.Device()
+	r.send(device.ID(), event{device: device, name: "OnPortDown", fn: func() error {
+		return r.listener.OnPortDown(finder, port)
+	}})
+
+	return nil
+}
+
+func (r *dispatcher) OnDeviceUp(finder Finder, device *Device) error {
+	r.send(device.ID(), event{device: device, name: "OnDeviceUp", fn: func() error {
+		return r.listener.OnDeviceUp(finder, device)
+	}})
+
+	return nil
+}
+
+// OnDeviceDown blocks until the listener processes the event, and then returns
+// the error of the listener. The caller resets the device after this returns, so
+// the listener should see the device before it is reset, e.g., its ID.
+func (r *dispatcher) OnDeviceDown(finder Finder, device *Device) error {
+	return r.call(device.ID(), event{device: device, name: "OnDeviceDown", fn: func() error {
+		return r.listener.OnDeviceDown(finder, device)
+	}})
+}
+
+func (r *dispatcher) OnFlowRemoved(finder Finder, flow openflow.FlowRemoved) error {
+	r.send(globalEventKey, event{name: "OnFlowRemoved", fn: func() error {
+		return r.listener.OnFlowRemoved(finder, flow)
+	}})
+
+	return nil
+}
+
+func (r *dispatcher) OnExperimenter(finder Finder, device *Device, msg openflow.Header) error {
+	r.send(device.ID(), event{device: device, name: "OnExperimenter", fn: func() error {
+		return r.listener.OnExperimenter(finder, device, msg)
+	}})
+
+	return nil
+}
+
+func (r *dispatcher) OnTopologyChange(finder Finder) error {
+	r.send(globalEventKey, event{name: "OnTopologyChange", fn: func() error {
+		return r.listener.OnTopologyChange(finder)
+	}})
+
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/protocol"
+)
+
+func TestDispatcherOrder(t *testing.T) {
+	d := &dispatcher{queues: make(map[string]*eventQueue)}
+	d.work = sync.NewCond(&d.mutex)
+	d.space = sync.NewCond(&d.mutex)
+	for i := 0; i < 4; i++ {
+		go d.worker()
+	}
+
+	var mutex sync.Mutex
+	result := make(map[string][]int)
+	var wg sync.WaitGroup
+	for _, key := range []string{"1", "2", "3"} {
+		for i := 0; i < 100; i++ {
+			key, i := key, i
+			wg.Add(1)
+			d.send(key, event{name: "test", fn: func() error {
+				defer wg.Done()
+				// Give the other workers a chance to take the same queue.
+				time.Sleep(10 * time.Microsecond)
+				mutex.Lock()
+				result[key] = append(result[key], i)
+				mutex.Unlock()
+				return nil
+			}})
+		}
+	}
+	wg.Wait()
+
+	for key, v := range result {
+		for i := range v {
+			if v[i] != i {
+				t.Fatalf("unordered events: key=%v, events=%v", key, v)
+			}
+		}
+	}
+	// The counter is updated after the last event returns.
+	for i := 0; d.Stats("1").Dispatched != 100; i++ {
+		if i == 100 {
+			t.Fatalf("unexpected stats: %+v", d.Stats("1"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type testPacketInListener struct {
+	EventListener
+	fn func(*protocol.Ethernet) error
+}
+
+func (r *testPacketInListener) OnPacketIn(finder Finder, ingress *Port, eth *protocol.Ethernet, packetIn *PacketIn) error {
+	return r.fn(eth)
+}
+
+func TestDispatcherPacketInPriority(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	processed := make(chan *protocol.Ethernet, 4)
+	first := true
+	l := &testPacketInListener{fn: func(eth *protocol.Ethernet) error {
+		if first {
+			first = false
+			// Block the first packet while the others are being admitted.
+			close(started)
+			<-unblock
+		}
+		processed <- eth
+		return nil
+	}}
+	d := newDispatcher(l, 4)
+	a := newAdmission(AdmissionConfig{QueueSize: 8})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.run(ctx, func(v packetInEvent) {
+		if err := d.OnPacketIn(nil, v.ingress, v.ethernet, v.packetIn); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	ingress := NewPort(&Device{id: "1"}, 1)
+	event := func(eth *protocol.Ethernet) packetInEvent {
+		return packetInEvent{ingress: ingress, ethernet: eth, packetIn: &PacketIn{BufferID: openflow.NoBuffer}}
+	}
+	data := []*protocol.Ethernet{{Type: 0x0800}, {Type: 0x0800}, {Type: 0x0800}}
+	reply := &protocol.Ethernet{Type: 0x0806, Payload: []byte{0, 1, 8, 0, 6, 4, 0, 2}}
+
+	now := time.Now()
+	a.admit(event(data[0]), now)
+	<-started
+	// The data packets are admitted ahead of the ARP reply.
+	a.admit(event(data[1]), now)
+	a.admit(event(data[2]), now)
+	a.admit(event(reply), now)
+	close(unblock)
+
+	expected := []*protocol.Ethernet{data[0], reply, data[1], data[2]}
+	for i, v := range expected {
+		if eth := <-processed; eth != v {
+			t.Fatalf("#%v: unexpected packet: expected=%+v, got=%+v", i, v, eth)
+		}
+	}
+}
+
+type testDeviceDownListener struct {
+	EventListener
+	id chan string
+}
+
+func (r *testDeviceDownListener) OnDeviceDown(finder Finder, device *Device) error {
+	r.id <- device.ID()
+	return nil
+}
+
+func TestDispatcherDeviceDown(t *testing.T) {
+	l := &testDeviceDownListener{id: make(chan string, 1)}
+	d := newDispatcher(l, 4)
+	device := &Device{id: "1", flowCache: newFlowCache(time.Second)}
+
+	// Delay the event queue of the device.
+	d.send(device.ID(), event{device: device, name: "test", fn: func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}})
+	if err := d.OnDeviceDown(nil, device); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The session resets the device after the event as it is released.
+	device.reset()
+
+	if id := <-l.id; id != "1" {
+		t.Fatalf("unexpected device ID in OnDeviceDown: %q", id)
+	}
+}
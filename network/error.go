@@ -21,6 +21,10 @@
 
 package network
 
+import (
+	"github.com/pkg/errors"
+)
+
 type networkErr struct {
 	temporary bool
 	err       error
@@ -33,3 +37,10 @@ func (r *networkErr) Error() string {
 func (r *networkErr) Temporary() bool {
 	return r.temporary
 }
+
+func isTemporaryErr(err error) bool {
+	e, ok := errors.Cause(err).(interface {
+		Temporary() bool
+	})
+	return ok && e.Temporary()
+}
@@ -0,0 +1,274 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// FlowInstruction is an instruction of a flow entry. Only the fields related to
+// Type are used: TableID for GotoTable, Metadata and MetadataMask for WriteMetadata,
+// Action for WriteActions and ApplyActions, and MeterID for Meter.
+type FlowInstruction struct {
+	Type         openflow.InstructionType
+	TableID      uint8
+	Metadata     uint64
+	MetadataMask uint64
+	Action       openflow.Action
+	MeterID      uint32
+}
+
+func GotoTable(tableID uint8) FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionGotoTable, TableID: tableID}
+}
+
+func WriteMetadata(metadata, mask uint64) FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionWriteMetadata, Metadata: metadata, MetadataMask: mask}
+}
+
+func WriteActions(action openflow.Action) FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionWriteActions, Action: action}
+}
+
+func ApplyActions(action openflow.Action) FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionApplyActions, Action: action}
+}
+
+func ClearActions() FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionClearActions}
+}
+
+func Meter(meterID uint32) FlowInstruction {
+	return FlowInstruction{Type: openflow.InstructionMeter, MeterID: meterID}
+}
+
+// Flow is a flow entry that will be installed into a specific flow table of a
+// device. Different stages of packet processing, e.g., ACL, forwarding and QoS,
+// can use their own tables chained by the GotoTable instruction.
+type Flow struct {
+	TableID      uint8
+	Priority     uint16
+	IdleTimeout  uint16
+	HardTimeout  uint16
+	Cookie       uint64
+	Match        openflow.Match
+	Instructions []FlowInstruction
+}
+
+// reservedCookie is the cookie bit that represents the special flows, e.g.,
+// table-miss entries, installed by the controller itself.
+const reservedCookie = uint64(0x1 << 63)
+
+func (r Flow) validate(c Capabilities) error {
+	if r.Match == nil {
+		return errors.New("nil flow match")
+	}
+	if r.Cookie&reservedCookie != 0 {
+		return errors.New("flow cookie uses the reserved MSB")
+	}
+
+	seen := make(map[openflow.InstructionType]bool)
+	for _, v := range r.Instructions {
+		if seen[v.Type] {
+			return fmt.Errorf("duplicated flow instruction: type=%v", v.Type)
+		}
+		seen[v.Type] = true
+
+		switch v.Type {
+		case openflow.InstructionGotoTable:
+			// The pipeline can only go forward.
+			if v.TableID <= r.TableID {
+				return fmt.Errorf("invalid goto table: from=%v, to=%v", r.TableID, v.TableID)
+			}
+		case openflow.InstructionWriteActions, openflow.InstructionApplyActions:
+			if v.Action == nil {
+				return errors.New("nil action of a flow instruction")
+			}
+		}
+	}
+
+	// Skip the capability checks if we don't know the capabilities of the device.
+	if !c.Known() {
+		return nil
+	}
+	table, ok := c.Table(r.TableID)
+	if !ok {
+		return fmt.Errorf("unknown flow table: id=%v", r.TableID)
+	}
+	for _, v := range r.Instructions {
+		if !table.SupportsInstruction(v.Type) {
+			return fmt.Errorf("unsupported flow instruction: table=%v, type=%v", r.TableID, v.Type)
+		}
+		if v.Type == openflow.InstructionGotoTable && !table.CanGoto(v.TableID) {
+			return fmt.Errorf("unreachable flow table: from=%v, to=%v", r.TableID, v.TableID)
+		}
+	}
+
+	return nil
+}
+
+func (r Flow) instruction(f openflow.Factory) (openflow.Instruction, error) {
+	inst, err := f.NewInstruction()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range r.Instructions {
+		switch v.Type {
+		case openflow.InstructionGotoTable:
+			inst.GotoTable(v.TableID)
+		case openflow.InstructionWriteMetadata:
+			inst.WriteMetadata(v.Metadata, v.MetadataMask)
+		case openflow.InstructionWriteActions:
+			inst.WriteAction(v.Action)
+		case openflow.InstructionApplyActions:
+			inst.ApplyAction(v.Action)
+		case openflow.InstructionClearActions:
+			inst.ClearActions()
+		case openflow.InstructionMeter:
+			inst.Meter(v.MeterID)
+		default:
+			return nil, fmt.Errorf("unexpected flow instruction type: %v", v.Type)
+		}
+	}
+	if err := inst.Error(); err != nil {
+		return nil, err
+	}
+
+	return inst, nil
+}
+
+// InstallFlow installs the flow into the flow table specified by flow.TableID
+// after stamping it with the cookie namespace ns. flow.Cookie should only use
+// the lower 48 bits. An existing flow whose match and priority are identical to
+// the new one is replaced, including its counters.
+func (r *Device) InstallFlow(ns CookieNamespace, flow Flow) error {
+	flow, err := ns.stamp(flow)
+	if err != nil {
+		return err
+	}
+
+	return r.writeFlow(openflow.FlowAdd, flow)
+}
+
+// ModifyFlow modifies the instructions of the flow in the cookie namespace ns
+// whose table, match and priority are exactly same as the flow, while keeping
+// its counters. The cookie and timeouts of the flow are not changed.
+func (r *Device) ModifyFlow(ns CookieNamespace, flow Flow) error {
+	flow, err := ns.stamp(flow)
+	if err != nil {
+		return err
+	}
+
+	return r.writeFlow(openflow.FlowModifyStrict, flow)
+}
+
+// newFlowMod makes a FLOW_MOD message of the command for the flow. The flows
+// whose cookie masked by mask is not same as flow.Cookie masked by mask are not
+// affected by the modify and delete commands. The caller should hold the device lock.
+func (r *Device) newFlowMod(cmd openflow.FlowModCmd, flow Flow, mask uint64) (openflow.FlowMod, error) {
+	if r.closed {
+		return nil, ErrClosedDevice
+	}
+
+	del := cmd == openflow.FlowDelete || cmd == openflow.FlowDeleteStrict
+	if del {
+		if flow.Match == nil {
+			return nil, errors.New("nil flow match")
+		}
+	} else {
+		if err := flow.validate(r.capabilities); err != nil {
+			return nil, err
+		}
+	}
+
+	flowmod, err := r.factory.NewFlowMod(cmd)
+	if err != nil {
+		return nil, err
+	}
+	flowmod.SetTableID(flow.TableID)
+	flowmod.SetPriority(flow.Priority)
+	flowmod.SetCookie(flow.Cookie)
+	if cmd != openflow.FlowAdd {
+		flowmod.SetCookieMask(mask)
+	}
+	flowmod.SetFlowMatch(flow.Match)
+	if del {
+		return flowmod, nil
+	}
+
+	flowmod.SetIdleTimeout(flow.IdleTimeout)
+	flowmod.SetHardTimeout(flow.HardTimeout)
+	// A flow without instructions drops the matched packets.
+	if len(flow.Instructions) > 0 {
+		inst, err := flow.instruction(r.factory)
+		if err != nil {
+			return nil, err
+		}
+		flowmod.SetFlowInstruction(inst)
+	}
+
+	return flowmod, nil
+}
+
+func (r *Device) writeFlow(cmd openflow.FlowModCmd, flow Flow) error {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Only modify the flow in the same cookie namespace.
+	flowmod, err := r.newFlowMod(cmd, flow, reservedCookie|cookieNamespaceMask)
+	if err != nil {
+		return err
+	}
+	if err := r.session.Write(flowmod); err != nil {
+		return err
+	}
+
+	barrier, err := r.factory.NewBarrierRequest()
+	if err != nil {
+		return err
+	}
+
+	return r.session.Write(barrier)
+}
+
+// DeleteFlow deletes the flow in the cookie namespace ns whose table, match and
+// priority are exactly same as the flow from the device. Instructions and timeouts
+// of the flow are ignored.
+func (r *Device) DeleteFlow(ns CookieNamespace, flow Flow) error {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Do not touch the special flows installed by the controller itself.
+	flow.Cookie = ns.Cookie(0)
+	flowmod, err := r.newFlowMod(openflow.FlowDeleteStrict, flow, ns.Mask())
+	if err != nil {
+		return err
+	}
+
+	return r.session.Write(flowmod)
+}
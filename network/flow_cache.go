@@ -61,6 +61,10 @@ func (r *flowCache) Add(match openflow.Match, port openflow.OutPort) error {
 	return nil
 }
 
+func (r *flowCache) purge() {
+	r.cache.Purge()
+}
+
 func (r *flowCache) key(match openflow.Match, port openflow.OutPort) (string, error) {
 	m, err := match.MarshalBinary()
 	if err != nil {
@@ -35,11 +35,14 @@ type of10Session struct {
 	// installed flows on the device have been removed, and then the ACL flow for
 	// ARP packes has been installed.
 	checkpoint bool
+	// Maximum length of the packets that the device sends to the controller.
+	missSendLength uint16
 }
 
-func newOF10Session(d *Device) *of10Session {
+func newOF10Session(d *Device, missSendLength uint16) *of10Session {
 	return &of10Session{
-		device: d,
+		device:         d,
+		missSendLength: missSendLength,
 	}
 }
 
@@ -47,7 +50,7 @@ func (r *of10Session) OnHello(f openflow.Factory, w transceiver.Writer, v openfl
 	if err := sendHello(f, w); err != nil {
 		return errors.Wrap(err, "failed to send HELLO")
 	}
-	if err := sendSetConfig(f, w); err != nil {
+	if err := sendSetConfig(f, w, r.missSendLength); err != nil {
 		return errors.Wrap(err, "failed to send SET_CONFIG")
 	}
 	if err := sendRemoveAllFlows(f, w); err != nil {
@@ -56,7 +59,7 @@ func (r *of10Session) OnHello(f openflow.Factory, w transceiver.Writer, v openfl
 	if err := setTemporaryDrop(f, w); err != nil {
 		return errors.Wrap(err, "failed to set the temporary drop rule")
 	}
-	if err := setARPSender(f, w); err != nil {
+	if err := setARPSender(f, w, r.missSendLength); err != nil {
 		return errors.Wrap(err, "failed to set the ARP sender")
 	}
 	if err := setLLDPSender(f, w); err != nil {
@@ -129,6 +132,31 @@ func (r *of10Session) OnPortDescReply(f openflow.Factory, w transceiver.Writer,
 	return nil
 }
 
+func (r *of10Session) OnFlowStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowStatsReply) error {
+	return nil
+}
+
+func (r *of10Session) OnPortStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.PortStatsReply) error {
+	return nil
+}
+
+func (r *of10Session) OnTableStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.TableStatsReply) error {
+	return nil
+}
+
+func (r *of10Session) OnQueueStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.QueueStatsReply) error {
+	return nil
+}
+
+func (r *of10Session) OnAggregateStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.AggregateStatsReply) error {
+	return nil
+}
+
+func (r *of10Session) OnTableFeaturesReply(f openflow.Factory, w transceiver.Writer, v openflow.TableFeaturesReply) error {
+	// Do nothing because OpenFlow 1.0 does not support TableFeaturesReply.
+	return nil
+}
+
 func (r *of10Session) OnPortStatus(f openflow.Factory, w transceiver.Writer, v openflow.PortStatus) error {
 	return nil
 }
@@ -140,3 +168,20 @@ func (r *of10Session) OnFlowRemoved(f openflow.Factory, w transceiver.Writer, v
 func (r *of10Session) OnPacketIn(f openflow.Factory, w transceiver.Writer, v openflow.PacketIn) error {
 	return nil
 }
+
+func (r *of10Session) OnRoleReply(f openflow.Factory, w transceiver.Writer, v openflow.RoleReply) error {
+	return nil
+}
+
+func (r *of10Session) OnGetAsyncReply(f openflow.Factory, w transceiver.Writer, v openflow.GetAsyncReply) error {
+	return nil
+}
+
+func (r *of10Session) OnFlowMonitorReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowMonitorReply) error {
+	// Do nothing because OpenFlow 1.0 does not support the flow monitor.
+	return nil
+}
+
+func (r *of10Session) OnExperimenter(f openflow.Factory, w transceiver.Writer, v openflow.Header) error {
+	return nil
+}
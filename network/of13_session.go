@@ -22,7 +22,10 @@
 package network
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/superkkt/cherry/openflow"
 	"github.com/superkkt/cherry/openflow/of13"
@@ -31,17 +34,32 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// Timeout to wait the table features reply from a device.
+	tableFeaturesTimeout = 10 * time.Second
+)
+
 type of13Session struct {
 	device *Device
+	role   *roleManager
+	// True after the device accepts us as the master, and then we start to
+	// initialize the device.
+	initialized bool
+	// Async config that we have sent to the device.
+	asyncConfig openflow.AsyncConfig
 	// True after we get the first barrier reply that means all the previously
 	// installed flows on the device have been removed, and then the ACL flow for
 	// ARP packes has been installed.
 	checkpoint bool
+	// Maximum length of the packets that the device sends to the controller.
+	missSendLength uint16
 }
 
-func newOF13Session(d *Device) *of13Session {
+func newOF13Session(d *Device, role *roleManager, missSendLength uint16) *of13Session {
 	return &of13Session{
-		device: d,
+		device:         d,
+		role:           role,
+		missSendLength: missSendLength,
 	}
 }
 
@@ -49,7 +67,115 @@ func (r *of13Session) OnHello(f openflow.Factory, w transceiver.Writer, v openfl
 	if err := sendHello(f, w); err != nil {
 		return errors.Wrap(err, "failed to send HELLO")
 	}
-	if err := sendSetConfig(f, w); err != nil {
+	// We will initialize the device after it accepts us as the master. Otherwise,
+	// we just keep the connection as a slave until we are elected as the master.
+	if err := r.role.sendRoleRequest(f, w); err != nil {
+		return errors.Wrap(err, "failed to send ROLE_REQUEST")
+	}
+	if err := r.setAsyncConfig(f, w); err != nil {
+		return errors.Wrap(err, "failed to set the async config")
+	}
+
+	return nil
+}
+
+// setAsyncConfig configures the asynchronous messages that we receive on this
+// connection for each role, and then queries the configuration back to verify it.
+// The master receives all of them. The slave receives nothing because we don't
+// track the device until we become the master.
+func (r *of13Session) setAsyncConfig(f openflow.Factory, w transceiver.Writer) error {
+	msg, err := f.NewSetAsync()
+	if err != nil {
+		return err
+	}
+	msg.SetPacketInReasons(openflow.RoleMaster, openflow.PacketInNoMatch, openflow.PacketInAction)
+	msg.SetPortStatusReasons(openflow.RoleMaster, openflow.PortAdded, openflow.PortDeleted, openflow.PortModified)
+	msg.SetFlowRemovedReasons(openflow.RoleMaster, openflow.FlowRemovedIdleTimeout, openflow.FlowRemovedHardTimeout, openflow.FlowRemovedDelete, openflow.FlowRemovedGroupDelete)
+	msg.SetPacketInReasons(openflow.RoleSlave)
+	msg.SetPortStatusReasons(openflow.RoleSlave)
+	msg.SetFlowRemovedReasons(openflow.RoleSlave)
+	if err := w.Write(msg); err != nil {
+		return err
+	}
+	r.asyncConfig = msg
+
+	req, err := f.NewGetAsyncRequest()
+	if err != nil {
+		return err
+	}
+
+	return w.Write(req)
+}
+
+func (r *of13Session) OnGetAsyncReply(f openflow.Factory, w transceiver.Writer, v openflow.GetAsyncReply) error {
+	if r.asyncConfig == nil {
+		return nil
+	}
+
+	for _, role := range []openflow.ControllerRole{openflow.RoleMaster, openflow.RoleSlave} {
+		if getAsyncMasks(v, role) != getAsyncMasks(r.asyncConfig, role) {
+			// We can still work with the device, but the slave may receive unnecessary messages.
+			logger.Warningf("device does not apply our async config: role=%v, packetIn=%v, portStatus=%v, flowRemoved=%v",
+				role, v.PacketInReasons(role), v.PortStatusReasons(role), v.FlowRemovedReasons(role))
+		}
+	}
+
+	return nil
+}
+
+func (r *of13Session) OnFlowMonitorReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowMonitorReply) error {
+	for _, update := range v.Updates() {
+		logger.Debugf("flow update (device=%v, event=%v, table=%v, priority=%v, cookie=%v)",
+			r.device.ID(), update.Event(), update.TableID(), update.Priority(), update.Cookie())
+	}
+
+	return nil
+}
+
+func (r *of13Session) OnExperimenter(f openflow.Factory, w transceiver.Writer, v openflow.Header) error {
+	return nil
+}
+
+func getAsyncMasks(c openflow.AsyncConfig, role openflow.ControllerRole) (masks [3]uint32) {
+	for _, v := range c.PacketInReasons(role) {
+		masks[0] |= 1 << v
+	}
+	for _, v := range c.PortStatusReasons(role) {
+		masks[1] |= 1 << v
+	}
+	for _, v := range c.FlowRemovedReasons(role) {
+		masks[2] |= 1 << v
+	}
+
+	return masks
+}
+
+func (r *of13Session) OnRoleReply(f openflow.Factory, w transceiver.Writer, v openflow.RoleReply) error {
+	switch v.Role() {
+	case openflow.RoleMaster:
+		if r.initialized {
+			return nil
+		}
+		logger.Infof("we are the master controller of the device: generationID=%v", v.GenerationID())
+		r.initialized = true
+		return r.initialize(f, w)
+	case openflow.RoleSlave:
+		logger.Infof("holding the connection as a slave controller: generationID=%v", v.GenerationID())
+		if r.initialized {
+			// We have been demoted. Initialize the device again when we
+			// are promoted.
+			r.initialized = false
+			r.checkpoint = false
+			r.device.session.release()
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected controller role: %v", v.Role())
+	}
+}
+
+func (r *of13Session) initialize(f openflow.Factory, w transceiver.Writer) error {
+	if err := sendSetConfig(f, w, r.missSendLength); err != nil {
 		return errors.Wrap(err, "failed to send SET_CONFIG")
 	}
 	if err := sendRemoveAllFlows(f, w); err != nil {
@@ -58,7 +184,7 @@ func (r *of13Session) OnHello(f openflow.Factory, w transceiver.Writer, v openfl
 	if err := setTemporaryDrop(f, w); err != nil {
 		return errors.Wrap(err, "failed to set the temporary drop rule")
 	}
-	if err := setARPSender(f, w); err != nil {
+	if err := setARPSender(f, w, r.missSendLength); err != nil {
 		return errors.Wrap(err, "failed to set the ARP sender")
 	}
 	if err := setLLDPSender(f, w); err != nil {
@@ -87,6 +213,12 @@ func (r *of13Session) OnBarrierReply(f openflow.Factory, w transceiver.Writer, v
 }
 
 func (r *of13Session) OnError(f openflow.Factory, w transceiver.Writer, v openflow.Error) error {
+	// Does the device reject our role request?
+	if v.Class() == of13.OFPET_ROLE_REQUEST_FAILED {
+		// Disconnect the device. It will reconnect to us, and then we will send a new role request.
+		return fmt.Errorf("role request is rejected: %v", v.Cause())
+	}
+
 	return nil
 }
 
@@ -102,14 +234,6 @@ func (r *of13Session) OnGetConfigReply(f openflow.Factory, w transceiver.Writer,
 	return nil
 }
 
-func isHP2920_24G(msg openflow.DescReply) bool {
-	return strings.HasPrefix(msg.Manufacturer(), "HP") && strings.HasPrefix(msg.Hardware(), "2920-24G")
-}
-
-func isAS460054_T(msg openflow.DescReply) bool {
-	return strings.Contains(msg.Hardware(), "AS4600-54T")
-}
-
 func (r *of13Session) setTableMiss(f openflow.Factory, w transceiver.Writer, tableID uint8, inst openflow.Instruction) error {
 	match, err := f.NewMatch() // Wildcard
 	if err != nil {
@@ -121,7 +245,7 @@ func (r *of13Session) setTableMiss(f openflow.Factory, w transceiver.Writer, tab
 		return err
 	}
 	// We use MSB to represent whether the flow is table miss or not
-	msg.SetCookie(0x1 << 63)
+	msg.SetCookie(reservedCookie)
 	msg.SetTableID(tableID)
 	// Permanent flow entry
 	msg.SetIdleTimeout(0)
@@ -134,98 +258,125 @@ func (r *of13Session) setTableMiss(f openflow.Factory, w transceiver.Writer, tab
 	return w.Write(msg)
 }
 
-func (r *of13Session) setHP2920TableMiss(f openflow.Factory, w transceiver.Writer) error {
-	// Table-100 is a hardware table, and Table-200 is a software table
-	// that has very low performance.
-	inst, err := f.NewInstruction()
-	if err != nil {
-		return err
+// setPipeline installs the table-miss flow entries that send the unmatched packets
+// along the tables in the path, and then sends them to the controller from the last
+// table of the path. The normal flows will be installed into the last table.
+func (r *of13Session) setPipeline(f openflow.Factory, w transceiver.Writer, path []uint8) error {
+	if len(path) == 0 {
+		panic("empty table path")
 	}
+	logger.Infof("flow table pipeline: DPID=%v, path=%v", r.device.ID(), path)
 
-	// 0 -> 100
-	inst.GotoTable(100)
-	if err := r.setTableMiss(f, w, 0, inst); err != nil {
-		return errors.Wrap(err, "failed to set table_miss flow entry")
-	}
-	// 100 -> 200
-	inst.GotoTable(200)
-	if err := r.setTableMiss(f, w, 100, inst); err != nil {
-		return errors.Wrap(err, "failed to set table_miss flow entry")
+	for i := 0; i < len(path)-1; i++ {
+		inst, err := f.NewInstruction()
+		if err != nil {
+			return err
+		}
+		inst.GotoTable(path[i+1])
+		if err := r.setTableMiss(f, w, path[i], inst); err != nil {
+			return errors.Wrap(err, "failed to set table_miss flow entry")
+		}
 	}
 
-	// 200 -> Controller
+	// Last table -> Controller
 	outPort := openflow.NewOutPort()
 	outPort.SetController()
+	outPort.SetMaxLength(r.missSendLength)
 	action, err := f.NewAction()
 	if err != nil {
 		return err
 	}
 	action.SetOutPort(outPort)
-
+	inst, err := f.NewInstruction()
+	if err != nil {
+		return err
+	}
 	inst.ApplyAction(action)
-	if err := r.setTableMiss(f, w, 200, inst); err != nil {
+
+	last := path[len(path)-1]
+	if err := r.setTableMiss(f, w, last, inst); err != nil {
 		return errors.Wrap(err, "failed to set table_miss flow entry")
 	}
-	r.device.setFlowTableID(200)
+	r.device.setFlowTableID(last)
 
 	return nil
 }
 
-func (r *of13Session) setAS4600TableMiss(f openflow.Factory, w transceiver.Writer) error {
-	// FIXME:
-	// AS460054-T gives an error (type=5, code=1) that means TABLE_FULL
-	// when we install a table-miss flow on Table-0 after we delete all
-	// flows already installed from the switch. Is this a bug of this switch??
-
-	return nil
+// hasNoTableMiss returns whether the device rejects the table-miss flow entries.
+//
+// FIXME:
+// AS460054-T gives an error (type=5, code=1) that means TABLE_FULL when we
+// install a table-miss flow on Table-0 after we delete all flows already
+// installed from the switch. Is this a bug of this switch??
+func hasNoTableMiss(msg openflow.DescReply) bool {
+	return strings.Contains(msg.Hardware(), "AS4600-54T")
 }
 
-func (r *of13Session) setDefaultTableMiss(f openflow.Factory, w transceiver.Writer) error {
-	inst, err := f.NewInstruction()
+func (r *of13Session) OnDescReply(f openflow.Factory, w transceiver.Writer, v openflow.DescReply) error {
+	// Query the table features to find the flow tables that we can use.
+	caps, err := r.queryCapabilities(f)
 	if err != nil {
-		return err
+		if _, ok := err.(*transceiver.ErrorReply); !ok {
+			return errors.Wrap(err, "failed to query the table features")
+		}
+		// The device does not support the table features request.
+		logger.Warningf("failed to query the table features: DPID=%v, error=%v", r.device.ID(), err)
 	}
-
-	// 0 -> Controller
-	outPort := openflow.NewOutPort()
-	outPort.SetController()
-	action, err := f.NewAction()
-	if err != nil {
-		return err
+	r.device.setCapabilities(caps)
+
+	if hasNoTableMiss(v) {
+		logger.Infof("skip installing the table-miss flow entries: DPID=%v, hardware=%v", r.device.ID(), v.Hardware())
+		r.device.setFlowTableID(0)
+	} else {
+		// Normal flows match the destination MAC address.
+		path, err := caps.flowTablePath(openflow.MatchFieldEthDst)
+		if err != nil {
+			logger.Warningf("failed to find the flow table: DPID=%v, err=%v", r.device.ID(), err)
+			// Fall back to the default pipeline that only uses the table 0.
+			path = []uint8{0}
+		}
+		if err := r.setPipeline(f, w, path); err != nil {
+			return err
+		}
 	}
-	action.SetOutPort(outPort)
 
-	inst.ApplyAction(action)
-	if err := r.setTableMiss(f, w, 0, inst); err != nil {
-		return errors.Wrap(err, "failed to set table_miss flow entry")
+	if err := sendPortDescriptionRequest(f, w); err != nil {
+		return errors.Wrap(err, "failed to send PORT_DESCRIPTION_REQUEST")
 	}
-	r.device.setFlowTableID(0)
 
 	return nil
 }
 
-func (r *of13Session) OnDescReply(f openflow.Factory, w transceiver.Writer, v openflow.DescReply) error {
-	var err error
-
-	// FIXME:
-	// Implement general routines for various table structures of OF1.3 switches
-	// based on table features reply
-	switch {
-	case isHP2920_24G(v):
-		err = r.setHP2920TableMiss(f, w)
-	case isAS460054_T(v):
-		err = r.setAS4600TableMiss(f, w)
-	default:
-		err = r.setDefaultTableMiss(f, w)
+// queryCapabilities sends a table features request to the device and builds the
+// capabilities from all the parts of its reply. It blocks the handler until the
+// reply arrives, which is fine since the device is still being initialized.
+func (r *of13Session) queryCapabilities(f openflow.Factory) (Capabilities, error) {
+	req, err := f.NewTableFeaturesRequest()
+	if err != nil {
+		return Capabilities{}, err
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tableFeaturesTimeout)
+	defer cancel()
+	replies, err := r.device.request(ctx, req)
 	if err != nil {
-		return err
+		return Capabilities{}, err
 	}
 
-	if err := sendPortDescriptionRequest(f, w); err != nil {
-		return errors.Wrap(err, "failed to send DESCRIPTION_REQUEST")
+	features := make([]openflow.TableFeatures, 0)
+	for _, v := range replies {
+		reply, ok := v.(openflow.TableFeaturesReply)
+		if !ok {
+			return Capabilities{}, fmt.Errorf("unexpected reply message: type=%v", v.Type())
+		}
+		features = append(features, reply.Features()...)
 	}
 
+	return newCapabilities(features), nil
+}
+
+func (r *of13Session) OnTableFeaturesReply(f openflow.Factory, w transceiver.Writer, v openflow.TableFeaturesReply) error {
+	// The replies of our requests are passed to the requester, not to here.
 	return nil
 }
 
@@ -254,6 +405,26 @@ func (r *of13Session) OnPortDescReply(f openflow.Factory, w transceiver.Writer,
 	return nil
 }
 
+func (r *of13Session) OnFlowStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowStatsReply) error {
+	return nil
+}
+
+func (r *of13Session) OnPortStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.PortStatsReply) error {
+	return nil
+}
+
+func (r *of13Session) OnTableStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.TableStatsReply) error {
+	return nil
+}
+
+func (r *of13Session) OnQueueStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.QueueStatsReply) error {
+	return nil
+}
+
+func (r *of13Session) OnAggregateStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.AggregateStatsReply) error {
+	return nil
+}
+
 func (r *of13Session) OnPortStatus(f openflow.Factory, w transceiver.Writer, v openflow.PortStatus) error {
 	return nil
 }
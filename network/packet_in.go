@@ -0,0 +1,110 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// PacketIn is the metadata of a packet that a device has sent to the controller
+// by a PACKET_IN message.
+type PacketIn struct {
+	// BufferID is the ID of the buffer in the device that holds the packet, or
+	// openflow.NoBuffer if the packet is not buffered. A buffered packet should
+	// be released by a PACKET_OUT message on the ingress device, and can be
+	// released only once. Use TakeBuffer to release it. The buffer that is not
+	// taken by the event listeners is released by dropping the packet after the
+	// listeners return.
+	BufferID uint32
+	// Length is the full length of the packet, which is longer than the data of
+	// the PACKET_IN message if the device has truncated the packet.
+	Length uint16
+	// Reason is the OFPR_* value of the message. The values of the OpenFlow 1.0
+	// and 1.3 devices are same with openflow.PacketInReason, and the OpenFlow 1.4
+	// devices additionally report the ones such as OFPR_ACTION_SET.
+	Reason  openflow.PacketInReason
+	TableID uint8
+	// Cookie is the cookie of the flow entry that has sent the packet. It is
+	// always zero on the OpenFlow 1.0 devices.
+	Cookie uint64
+	// Match is the pipeline fields of the packet reported by the device, such as
+	// the ingress port. It can be nil.
+	Match openflow.Match
+	// Timestamp is the time when the controller has received the packet.
+	Timestamp time.Time
+	// Data is the raw bytes of the packet that is parsed into the Ethernet frame
+	// passed along with this metadata. It may be truncated if the packet is
+	// buffered. Do not modify it.
+	Data []byte
+	// 1 if the buffer has been taken.
+	taken uint32
+}
+
+func newPacketIn(v openflow.PacketIn, timestamp time.Time) *PacketIn {
+	return &PacketIn{
+		BufferID:  v.BufferID(),
+		Length:    v.Length(),
+		Reason:    openflow.PacketInReason(v.Reason()),
+		TableID:   v.TableID(),
+		Cookie:    v.Cookie(),
+		Match:     v.Match(),
+		Timestamp: timestamp,
+		Data:      v.Data(),
+	}
+}
+
+func (r *PacketIn) String() string {
+	return fmt.Sprintf("BufferID=%#x, Length=%v, Reason=%v, TableID=%v, Cookie=%#x", r.BufferID, r.Length, r.Reason, r.TableID, r.Cookie)
+}
+
+// IsTableMiss returns whether the packet is sent by a table-miss flow entry,
+// rather than a flow entry that explicitly outputs the packet to the controller.
+func (r *PacketIn) IsTableMiss() bool {
+	return r.Reason == openflow.PacketInNoMatch
+}
+
+// IsBuffered returns whether the packet is buffered in the device.
+func (r *PacketIn) IsBuffered() bool {
+	return r.BufferID != openflow.NoBuffer
+}
+
+// IsTruncated returns whether Data has only the first part of the packet. The
+// whole packet can be sent only by its buffer in the ingress device.
+func (r *PacketIn) IsTruncated() bool {
+	return len(r.Data) < int(r.Length)
+}
+
+// TakeBuffer returns the buffer ID of the packet, and marks that the buffer has
+// been taken by the caller that should release it by a PACKET_OUT message on the
+// ingress device. It returns openflow.NoBuffer if the packet is not buffered, or
+// its buffer has been already taken.
+func (r *PacketIn) TakeBuffer() uint32 {
+	if !r.IsBuffered() || !atomic.CompareAndSwapUint32(&r.taken, 0, 1) {
+		return openflow.NoBuffer
+	}
+
+	return r.BufferID
+}
@@ -22,11 +22,14 @@
 package network
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/superkkt/cherry/graph"
 	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/transceiver"
 )
 
 // Port represents a switch port and also implements the graph.Point interface.
@@ -35,6 +38,7 @@ type Port struct {
 	device *Device
 	number uint32
 	value  openflow.Port
+	stats  PortStats
 }
 
 func NewPort(d *Device, num uint32) *Port {
@@ -83,3 +87,46 @@ func (r *Port) SetValue(p openflow.Port) {
 
 	r.value = p
 }
+
+// SetConfig changes the administrative settings of the port that are selected
+// by mask, e.g., SetConfig(ctx, openflow.PortDown, openflow.PortDown) shuts down
+// the port and SetConfig(ctx, 0, openflow.PortDown) brings it up again. It blocks
+// until the device processes the request or ctx is canceled, and returns
+// *transceiver.ErrorReply if the device has rejected the request. The device
+// reports the new settings by a PORT_STATUS message.
+func (r *Port) SetConfig(ctx context.Context, config, mask openflow.PortConfig) error {
+	value := r.Value()
+	if value == nil {
+		return errors.New("unknown port description")
+	}
+	f := r.device.Factory()
+	if f == nil {
+		return errors.New("not yet negotiated device")
+	}
+	if r.device.IsClosed() {
+		return ErrClosedDevice
+	}
+
+	mod, err := f.NewPortMod()
+	if err != nil {
+		return err
+	}
+	mod.SetPortNumber(r.number)
+	mod.SetMAC(value.MAC())
+	mod.SetConfig(config, mask)
+
+	errs, err := r.device.session.transceiver.Transaction(ctx, []transceiver.Request{mod})
+	if err != nil {
+		if err == transceiver.ErrClosed {
+			return ErrClosedDevice
+		}
+		return err
+	}
+	if errs[0] != nil {
+		logger.Errorf("PORT_MOD is rejected: device=%v, port=%v, error=%v", r.device.ID(), r.number, errs[0])
+		return errs[0]
+	}
+	logger.Infof("changed the port configuration: device=%v, port=%v, config=%#x, mask=%#x", r.device.ID(), r.number, config, mask)
+
+	return nil
+}
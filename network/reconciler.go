@@ -0,0 +1,316 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// Reconciler keeps the intended flow table of each device for a cookie namespace,
+// and reconciles the actual flows of the device with it: missing flows are added,
+// unexpected flows are deleted, and changed flows are modified. It only touches
+// the flows in its namespace.
+type Reconciler struct {
+	mutex     sync.Mutex
+	namespace CookieNamespace
+	// Intended flows keyed by the device ID.
+	devices map[string]map[flowKey]intendedFlow
+}
+
+// flowKey identifies a flow entry in a device. A flow entry is uniquely identified
+// by its table, priority and match.
+type flowKey struct {
+	tableID  uint8
+	priority uint16
+	match    string
+}
+
+type intendedFlow struct {
+	flow Flow
+	// Encoded instructions of the flow, which is used to compare with the actual one.
+	instruction []byte
+}
+
+// FlowDiff is a set of differences between the intended and actual flows of a
+// device that have been applied by the reconciler.
+type FlowDiff struct {
+	Added    []Flow
+	Modified []Flow
+	Deleted  []Flow
+	// Failed is the operations that the device has rejected.
+	Failed []FlowResult
+}
+
+func (r FlowDiff) Empty() bool {
+	return len(r.Added) == 0 && len(r.Modified) == 0 && len(r.Deleted) == 0 && len(r.Failed) == 0
+}
+
+func (r FlowDiff) String() string {
+	return fmt.Sprintf("added=%v, modified=%v, deleted=%v, failed=%v", len(r.Added), len(r.Modified), len(r.Deleted), len(r.Failed))
+}
+
+func NewReconciler(ns CookieNamespace) *Reconciler {
+	return &Reconciler{
+		namespace: ns,
+		devices:   make(map[string]map[flowKey]intendedFlow),
+	}
+}
+
+func getFlowKey(tableID uint8, priority uint16, match openflow.Match) (flowKey, error) {
+	v, err := match.MarshalBinary()
+	if err != nil {
+		return flowKey{}, err
+	}
+
+	return flowKey{tableID: tableID, priority: priority, match: string(v)}, nil
+}
+
+func marshalInstruction(inst openflow.Instruction) ([]byte, error) {
+	// A flow entry without instructions drops all the matched packets.
+	if inst == nil {
+		return nil, nil
+	}
+
+	return inst.MarshalBinary()
+}
+
+func (r *Reconciler) newIntendedFlow(device *Device, flow Flow) (flowKey, intendedFlow, error) {
+	if flow.Match == nil {
+		return flowKey{}, intendedFlow{}, errors.New("nil flow match")
+	}
+	key, err := getFlowKey(flow.TableID, flow.Priority, flow.Match)
+	if err != nil {
+		return flowKey{}, intendedFlow{}, err
+	}
+
+	var inst []byte
+	if len(flow.Instructions) > 0 {
+		f := device.Factory()
+		if f == nil {
+			return flowKey{}, intendedFlow{}, errors.New("not yet negotiated device")
+		}
+		v, err := flow.instruction(f)
+		if err != nil {
+			return flowKey{}, intendedFlow{}, err
+		}
+		if inst, err = marshalInstruction(v); err != nil {
+			return flowKey{}, intendedFlow{}, err
+		}
+	}
+
+	return key, intendedFlow{flow: flow, instruction: inst}, nil
+}
+
+// Set adds the flow into the intended flow table of the device, and then installs
+// it into the device. The flow is installed even if it is already intended because
+// the device may have removed it, e.g., by its timeout.
+func (r *Reconciler) Set(device *Device, flow Flow) error {
+	key, v, err := r.newIntendedFlow(device, flow)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	flows, ok := r.devices[device.ID()]
+	if !ok {
+		flows = make(map[flowKey]intendedFlow)
+		r.devices[device.ID()] = flows
+	}
+	flows[key] = v
+	r.mutex.Unlock()
+
+	return device.AppFlows(r.namespace).InstallFlow(flow)
+}
+
+// Delete removes the flow from the intended flow table of the device, and then
+// deletes it from the device.
+func (r *Reconciler) Delete(device *Device, flow Flow) error {
+	if flow.Match == nil {
+		return errors.New("nil flow match")
+	}
+	key, err := getFlowKey(flow.TableID, flow.Priority, flow.Match)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	if flows, ok := r.devices[device.ID()]; ok {
+		delete(flows, key)
+	}
+	r.mutex.Unlock()
+
+	return device.AppFlows(r.namespace).DeleteFlow(flow)
+}
+
+// Replace replaces the intended flow table of the device with the flows. It does
+// not touch the device; call Reconcile to apply the new intended flow table.
+func (r *Reconciler) Replace(device *Device, flows []Flow) error {
+	table := make(map[flowKey]intendedFlow)
+	for _, flow := range flows {
+		key, v, err := r.newIntendedFlow(device, flow)
+		if err != nil {
+			return err
+		}
+		table[key] = v
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.devices[device.ID()] = table
+
+	return nil
+}
+
+// Forget removes the intended flows of the device for which fn returns true. It
+// does not touch the device.
+func (r *Reconciler) Forget(device *Device, fn func(Flow) bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for k, v := range r.devices[device.ID()] {
+		if fn(v.flow) {
+			delete(r.devices[device.ID()], k)
+		}
+	}
+}
+
+// Flush removes the intended flow table of the device. It does not touch the device.
+func (r *Reconciler) Flush(device *Device) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.devices, device.ID())
+}
+
+// Flows returns the intended flows of the device.
+func (r *Reconciler) Flows(device *Device) []Flow {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]Flow, 0, len(r.devices[device.ID()]))
+	for _, v := range r.devices[device.ID()] {
+		result = append(result, v.flow)
+	}
+
+	return result
+}
+
+func (r *Reconciler) snapshot(device *Device) map[flowKey]intendedFlow {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make(map[flowKey]intendedFlow)
+	for k, v := range r.devices[device.ID()] {
+		result[k] = v
+	}
+
+	return result
+}
+
+// Reconcile compares the intended flow table of the device with the actual flows
+// queried from the device, and then applies the differences to the device in an
+// atomic flow transaction if the device supports bundles. It returns the
+// differences that have been applied and the ones rejected by the device.
+func (r *Reconciler) Reconcile(ctx context.Context, device *Device) (FlowDiff, error) {
+	flows := device.AppFlows(r.namespace)
+	stats, err := flows.FlowStats(ctx, nil)
+	if err != nil {
+		return FlowDiff{}, err
+	}
+	// Take the snapshot after querying the actual flows so that the flows set after
+	// the query are not deleted as unexpected ones.
+	intended := r.snapshot(device)
+
+	tx := flows.NewTransaction()
+	// Do not let the device forward packets with a half-reconciled flow table.
+	tx.SetAtomic(true)
+	actual := make(map[flowKey]openflow.FlowStats)
+	for _, v := range stats {
+		key, err := getFlowKey(v.TableID(), v.Priority(), v.Match())
+		if err != nil {
+			return FlowDiff{}, err
+		}
+		// Unexpected flow?
+		if _, ok := intended[key]; !ok {
+			tx.Delete(Flow{TableID: v.TableID(), Priority: v.Priority(), Cookie: v.Cookie(), Match: v.Match()})
+			continue
+		}
+		actual[key] = v
+	}
+
+	// Flows that should be modified by adding them again.
+	readded := make(map[int]bool)
+	for key, v := range intended {
+		stats, ok := actual[key]
+		// Missing flow?
+		if !ok {
+			tx.Add(v.flow)
+			continue
+		}
+
+		inst, err := marshalInstruction(stats.Instruction())
+		if err != nil {
+			return FlowDiff{}, err
+		}
+		sameInst := bytes.Equal(inst, v.instruction)
+		sameAttr := stats.Cookie() == r.namespace.Cookie(v.flow.Cookie) &&
+			stats.IdleTimeout() == v.flow.IdleTimeout && stats.HardTimeout() == v.flow.HardTimeout
+		switch {
+		case sameInst && sameAttr:
+			continue
+		case sameAttr:
+			// Only the instructions are changed. Modify them while keeping the counters.
+			tx.Modify(v.flow)
+		default:
+			// MODIFY cannot change the cookie and timeouts. ADD replaces the flow entry.
+			readded[tx.Len()] = true
+			tx.Add(v.flow)
+		}
+	}
+
+	results, err := tx.Commit(ctx)
+	if err != nil {
+		return FlowDiff{}, err
+	}
+
+	diff := FlowDiff{}
+	for i, v := range results {
+		switch {
+		case v.Err != nil:
+			diff.Failed = append(diff.Failed, v)
+		case v.Command == openflow.FlowDeleteStrict:
+			diff.Deleted = append(diff.Deleted, v.Flow)
+		case v.Command == openflow.FlowAdd && !readded[i]:
+			diff.Added = append(diff.Added, v.Flow)
+		default:
+			diff.Modified = append(diff.Modified, v.Flow)
+		}
+	}
+
+	return diff, nil
+}
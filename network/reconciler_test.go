@@ -0,0 +1,248 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+func newTestFlow(inPort, outPort uint32) Flow {
+	in := openflow.NewInPort()
+	in.SetValue(inPort)
+	match := of13.NewMatch()
+	match.SetInPort(in)
+	out := openflow.NewOutPort()
+	out.SetValue(outPort)
+	action := of13.NewAction()
+	action.SetOutPort(out)
+
+	return Flow{
+		TableID:      1,
+		Priority:     10,
+		Match:        match,
+		Instructions: []FlowInstruction{ApplyActions(action)},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ns := NewCookieNamespace("reconciler")
+	other := NewCookieNamespace("other")
+	// stamp returns the flow that the device has after installing it in the namespace.
+	stamp := func(ns CookieNamespace, flow Flow) Flow {
+		flow.Cookie = ns.Cookie(flow.Cookie)
+		return flow
+	}
+	withTimeout := newTestFlow(1, 2)
+	withTimeout.IdleTimeout = 10
+	withCookie := newTestFlow(1, 2)
+	withCookie.Cookie = 7
+	tableMiss := newTestFlow(1, 2)
+	tableMiss.Cookie = reservedCookie
+
+	tests := []struct {
+		name     string
+		intended []Flow
+		actual   []Flow
+		reject   bool
+		// Expected FLOW_MOD commands sent to the device.
+		commands []uint8
+		added    int
+		modified int
+		deleted  int
+		failed   int
+	}{
+		{
+			name:     "missing flow",
+			intended: []Flow{newTestFlow(1, 2)},
+			commands: []uint8{of13.OFPFC_ADD},
+			added:    1,
+		},
+		{
+			name:     "unexpected flow",
+			actual:   []Flow{stamp(ns, newTestFlow(1, 2))},
+			commands: []uint8{of13.OFPFC_DELETE_STRICT},
+			deleted:  1,
+		},
+		{
+			name:     "same flow",
+			intended: []Flow{newTestFlow(1, 2)},
+			actual:   []Flow{stamp(ns, newTestFlow(1, 2))},
+		},
+		{
+			name:     "changed instructions",
+			intended: []Flow{newTestFlow(1, 2)},
+			actual:   []Flow{stamp(ns, newTestFlow(1, 3))},
+			commands: []uint8{of13.OFPFC_MODIFY_STRICT},
+			modified: 1,
+		},
+		{
+			// MODIFY cannot change the timeouts.
+			name:     "changed timeout",
+			intended: []Flow{withTimeout},
+			actual:   []Flow{stamp(ns, newTestFlow(1, 2))},
+			commands: []uint8{of13.OFPFC_ADD},
+			modified: 1,
+		},
+		{
+			// MODIFY cannot change the cookie.
+			name:     "changed cookie",
+			intended: []Flow{withCookie},
+			actual:   []Flow{stamp(ns, newTestFlow(1, 2))},
+			commands: []uint8{of13.OFPFC_ADD},
+			modified: 1,
+		},
+		{
+			name:     "mixed",
+			intended: []Flow{newTestFlow(1, 2), newTestFlow(2, 1)},
+			actual:   []Flow{stamp(ns, newTestFlow(2, 3)), stamp(ns, newTestFlow(3, 1))},
+			commands: []uint8{of13.OFPFC_DELETE_STRICT, of13.OFPFC_ADD, of13.OFPFC_MODIFY_STRICT},
+			added:    1,
+			modified: 1,
+			deleted:  1,
+		},
+		{
+			// The flows of the other namespaces and the special flows are not ours.
+			name:   "other namespaces",
+			actual: []Flow{stamp(other, newTestFlow(1, 2)), stamp(NoNamespace, newTestFlow(2, 1)), tableMiss},
+		},
+		{
+			name:     "rejected flow",
+			intended: []Flow{newTestFlow(1, 2)},
+			reject:   true,
+			commands: []uint8{of13.OFPFC_ADD},
+			failed:   1,
+		},
+	}
+
+	for _, test := range tests {
+		sw := &testFlowSwitch{flows: test.actual}
+		if test.reject {
+			sw.reject = func(*of13.FlowMod) bool { return true }
+		}
+		device, disconnect := newTestDevice(t, sw.handle)
+
+		r := NewReconciler(ns)
+		if err := r.Replace(device, test.intended); err != nil {
+			t.Fatalf("%v: failed to replace the intended flows: %v", test.name, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		diff, err := r.Reconcile(ctx, device)
+		cancel()
+		disconnect()
+		if err != nil {
+			t.Fatalf("%v: failed to reconcile: %v", test.name, err)
+		}
+
+		if len(diff.Added) != test.added || len(diff.Modified) != test.modified || len(diff.Deleted) != test.deleted || len(diff.Failed) != test.failed {
+			t.Errorf("%v: unexpected diff: %v", test.name, diff)
+		}
+		flowMods := sw.received()
+		if len(flowMods) != len(test.commands) {
+			t.Errorf("%v: unexpected number of FLOW_MODs: expected=%v, got=%v", test.name, len(test.commands), len(flowMods))
+			continue
+		}
+		// Intended flows are applied in random order after the deletions.
+		deletes := 0
+		for _, v := range test.commands {
+			if v == of13.OFPFC_DELETE_STRICT {
+				deletes++
+			}
+		}
+		seen := make(map[uint8]int)
+		for i, v := range flowMods {
+			seen[v.command]++
+			if (v.command == of13.OFPFC_DELETE_STRICT) != (i < deletes) {
+				t.Errorf("%v: unexpected order of the FLOW_MOD commands: %v", test.name, flowMods)
+			}
+			// Only the flows in our namespace are touched.
+			if v.command != of13.OFPFC_ADD && v.msg.CookieMask() != ns.Mask() {
+				t.Errorf("%v: unexpected cookie mask: 0x%x", test.name, v.msg.CookieMask())
+			}
+			if !ns.Owns(v.msg.Cookie()) {
+				t.Errorf("%v: unexpected cookie: 0x%x", test.name, v.msg.Cookie())
+			}
+		}
+		for _, v := range test.commands {
+			seen[v]--
+		}
+		for cmd, n := range seen {
+			if n != 0 {
+				t.Errorf("%v: unexpected FLOW_MOD commands: command=%v, expected=%v", test.name, cmd, test.commands)
+			}
+		}
+	}
+}
+
+func TestReconcilerSet(t *testing.T) {
+	ns := NewCookieNamespace("reconciler")
+	sw := &testFlowSwitch{flows: []Flow{}}
+	device, disconnect := newTestDevice(t, sw.handle)
+	defer disconnect()
+
+	r := NewReconciler(ns)
+	flow := newTestFlow(1, 2)
+	// The same flow is installed again because the device may have removed it.
+	for i := 0; i < 2; i++ {
+		if err := r.Set(device, flow); err != nil {
+			t.Fatalf("failed to set the flow: %v", err)
+		}
+	}
+	flowMods := sw.received()
+	if len(flowMods) != 2 {
+		t.Fatalf("unexpected number of FLOW_MODs: %v", len(flowMods))
+	}
+	for _, v := range flowMods {
+		if v.command != of13.OFPFC_ADD || v.msg.Cookie() != ns.Cookie(0) {
+			t.Fatalf("unexpected FLOW_MOD: command=%v, cookie=0x%x", v.command, v.msg.Cookie())
+		}
+	}
+	if n := len(r.Flows(device)); n != 1 {
+		t.Fatalf("unexpected number of the intended flows: %v", n)
+	}
+
+	// The installed flow is kept by the reconciliation.
+	sw.mutex.Lock()
+	sw.flows = []Flow{{TableID: flow.TableID, Priority: flow.Priority, Cookie: ns.Cookie(0), Match: flow.Match, Instructions: flow.Instructions}}
+	sw.mutex.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	diff, err := r.Reconcile(ctx, device)
+	if err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("unexpected diff: %v", diff)
+	}
+
+	// Delete removes the flow from both of the intended flows and the device.
+	if err := r.Delete(device, flow); err != nil {
+		t.Fatalf("failed to delete the flow: %v", err)
+	}
+	if n := len(r.Flows(device)); n != 0 {
+		t.Fatalf("unexpected number of the intended flows: %v", n)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/transceiver"
+)
+
+// roleManager keeps the OpenFlow controller role of this controller instance, and
+// the sessions that should be promoted or demoted when the role changes.
+type roleManager struct {
+	mutex  sync.Mutex
+	master bool
+	// Last generation ID that we have used in the role requests.
+	generationID uint64
+	sessions     map[*session]struct{}
+}
+
+func newRoleManager() *roleManager {
+	return &roleManager{
+		sessions: make(map[*session]struct{}),
+	}
+}
+
+// register adds the session that will be notified when the role changes, and then
+// returns the current role of this controller. Any role change after the registration
+// will be notified to the session.
+func (r *roleManager) register(s *session) (master bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sessions[s] = struct{}{}
+	return r.master
+}
+
+func (r *roleManager) unregister(s *session) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.sessions, s)
+}
+
+// setMaster updates the role of this controller, and then returns the registered
+// sessions if the role has been changed.
+func (r *roleManager) setMaster(master bool) []*session {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.master == master {
+		return nil
+	}
+	r.master = master
+
+	sessions := make([]*session, 0, len(r.sessions))
+	for s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+
+	return sessions
+}
+
+// sendRoleRequest sends a role request for the current role of this controller to
+// the device. The role requests may be sent out of order if the role changes while
+// sending, but the device will reject the stale one by its generation ID.
+func (r *roleManager) sendRoleRequest(f openflow.Factory, w transceiver.Writer) error {
+	role, generationID := r.nextRoleRequest()
+
+	return sendRoleRequest(f, w, role, generationID)
+}
+
+// nextRoleRequest returns the current role of this controller and a new
+// generation ID for the role request of the role.
+func (r *roleManager) nextRoleRequest() (openflow.ControllerRole, uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	role := openflow.RoleSlave
+	if r.master {
+		role = openflow.RoleMaster
+	}
+
+	return role, r.nextGenerationID()
+}
+
+// nextGenerationID returns a new generation ID for the role requests. Switches
+// reject a role request whose generation ID is older than the one they have seen,
+// so the generation ID should increase monotonically across all the controllers
+// in the cluster. The election does not have any term number that we can use, so
+// we use the wall clock for that, which means the clocks of the controllers should
+// be synchronized by NTP. The caller should hold the lock.
+func (r *roleManager) nextGenerationID() uint64 {
+	id := uint64(time.Now().UnixNano())
+	if id <= r.generationID {
+		id = r.generationID + 1
+	}
+	r.generationID = id
+
+	return id
+}
@@ -54,6 +54,16 @@ type session struct {
 	watcher     watcher
 	finder      Finder
 	listener    ControllerEventListener
+	role        *roleManager
+	conn        net.Conn
+	// auth can be nil if we don't authenticate the device.
+	auth Authenticator
+	// Rate limiter and queue of the PACKET_IN messages.
+	admission *admission
+	// Maximum length of the packets that the device sends to the controller.
+	missSendLength uint16
+	// Canceller of the context that is used to run this session.
+	cancel context.CancelFunc
 }
 
 type sessionConfig struct {
@@ -61,6 +71,11 @@ type sessionConfig struct {
 	watcher  watcher
 	finder   Finder
 	listener ControllerEventListener
+	role     *roleManager
+	// Optional authenticator of the device.
+	auth           Authenticator
+	admission      AdmissionConfig
+	missSendLength uint16
 }
 
 func checkParam(c sessionConfig) {
@@ -76,6 +91,9 @@ func checkParam(c sessionConfig) {
 	if c.listener == nil {
 		panic("Listener is nil")
 	}
+	if c.role == nil {
+		panic("Role is nil")
+	}
 }
 
 func newSession(c sessionConfig) *session {
@@ -86,6 +104,11 @@ func newSession(c sessionConfig) *session {
 	v.watcher = c.watcher
 	v.finder = c.finder
 	v.listener = c.listener
+	v.role = c.role
+	v.conn = c.conn
+	v.auth = c.auth
+	v.admission = newAdmission(c.admission)
+	v.missSendLength = c.missSendLength
 	v.device = newDevice(v)
 	v.transceiver = transceiver.NewTransceiver(stream, v)
 
@@ -100,15 +123,24 @@ func (r *session) OnHello(f openflow.Factory, w transceiver.Writer, v openflow.H
 		return nil
 	}
 
+	r.device.setFactory(f)
+	// Role changes after this point will be notified to this session.
+	master := r.role.register(r)
+
 	switch v.Version() {
 	case openflow.OF10_VERSION:
-		r.handler = newOF10Session(r.device)
-	case openflow.OF13_VERSION:
-		r.handler = newOF13Session(r.device)
+		// OF10 does not have the role request, so we cannot hold the connection as a slave.
+		if !master {
+			return errors.New("disconnecting the OF10 device because we are not the master controller")
+		}
+		r.handler = newOF10Session(r.device, r.missSendLength)
+	case openflow.OF13_VERSION, openflow.OF14_VERSION:
+		// OF14 is handled by the OF13 session because OF14 is backward compatible
+		// with OF13 except the wire formats that are hidden by the factory.
+		r.handler = newOF13Session(r.device, r.role, r.missSendLength)
 	default:
 		return fmt.Errorf("unsupported OpenFlow version: %v", v.Version())
 	}
-	r.device.setFactory(f)
 	r.negotiated = true
 
 	return r.handler.OnHello(f, w, v)
@@ -116,13 +148,14 @@ func (r *session) OnHello(f openflow.Factory, w transceiver.Writer, v openflow.H
 
 func (r *session) OnError(f openflow.Factory, w transceiver.Writer, v openflow.Error) error {
 	// Is this the CHECK_OVERLAP error?
-	if v.Class() == 3 && v.Code() == 1 {
+	if isOverlapError(v) {
 		// Ignore this CHECK_OVERLAP error
 		logger.Debug("FLOW_MOD is overlapped")
 		return nil
 	}
 
-	logger.Errorf("ERROR (DPID=%v, class=%v, code=%v, data=%v)", r.device.ID(), v.Class(), v.Code(), v.Data())
+	cause := v.Cause()
+	logger.Errorf("ERROR (DPID=%v, xid=%v, error=%v, request=%v)", r.device.ID(), v.TransactionID(), cause, describeRequest(v))
 	if !r.negotiated {
 		return errNotNegotiated
 	}
@@ -130,6 +163,39 @@ func (r *session) OnError(f openflow.Factory, w transceiver.Writer, v openflow.E
 	return r.handler.OnError(f, w, v)
 }
 
+// isOverlapError returns whether v is the error for a FLOW_MOD with the CHECK_OVERLAP
+// flag that overlaps an existing flow.
+func isOverlapError(v openflow.Error) bool {
+	switch v.Version() {
+	case openflow.OF10_VERSION:
+		return v.Class() == of10.OFPET_FLOW_MOD_FAILED && v.Code() == of10.OFPFMFC_OVERLAP
+	case openflow.OF13_VERSION, openflow.OF14_VERSION:
+		// OF14 has the same numbers as OF13.
+		return v.Class() == of13.OFPET_FLOW_MOD_FAILED && v.Code() == of13.OFPFMFC_OVERLAP
+	default:
+		return false
+	}
+}
+
+// describeRequest returns a short description of the request message that has
+// caused the error v.
+func describeRequest(v openflow.Error) string {
+	req, err := v.Request()
+	if err != nil {
+		return fmt.Sprintf("unknown (%v)", err)
+	}
+
+	switch msg := req.(type) {
+	case openflow.FlowMod:
+		return fmt.Sprintf("FLOW_MOD(xid=%v, table=%v, priority=%v, cookie=%#x)", msg.TransactionID(), msg.TableID(), msg.Priority(), msg.Cookie())
+	case openflow.PacketOut:
+		inPort := msg.InPort()
+		return fmt.Sprintf("PACKET_OUT(xid=%v, inport=%v, len=%v)", msg.TransactionID(), inPort.Value(), len(msg.Data()))
+	default:
+		return fmt.Sprintf("type=%v, xid=%v", msg.Type(), msg.TransactionID())
+	}
+}
+
 func (r *session) OnFeaturesReply(f openflow.Factory, w transceiver.Writer, v openflow.FeaturesReply) error {
 	logger.Debugf("FEATURES_REPLY (DPID=%v, NumBufs=%v, NumTables=%v)", v.DPID(), v.NumBuffers(), v.NumTables())
 
@@ -147,6 +213,11 @@ func (r *session) OnFeaturesReply(f openflow.Factory, w transceiver.Writer, v op
 	}
 
 	// We got a first FeaturesReply packet! Let's initialize this device.
+	if r.auth != nil {
+		if err := r.auth.Authenticate(r.conn, v.DPID()); err != nil {
+			return fmt.Errorf("failed to authenticate the device (DPID=%v, remote=%v): %v", v.DPID(), r.conn.RemoteAddr(), err)
+		}
+	}
 	dpid := strconv.FormatUint(v.DPID(), 10)
 	// Already connected device?
 	if r.finder.Device(dpid) != nil {
@@ -199,6 +270,11 @@ func (r *session) OnDescReply(f openflow.Factory, w transceiver.Writer, v openfl
 	}
 	r.device.setDescriptions(desc)
 
+	// Configure the device for the registered experimenters before it sends their messages.
+	if err := transceiver.InitExperimenters(f, w, v); err != nil {
+		return fmt.Errorf("failed to initialize the experimenters: %v", err)
+	}
+
 	return r.handler.OnDescReply(f, w, v)
 }
 
@@ -212,6 +288,66 @@ func (r *session) OnPortDescReply(f openflow.Factory, w transceiver.Writer, v op
 	return r.handler.OnPortDescReply(f, w, v)
 }
 
+func (r *session) OnFlowStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowStatsReply) error {
+	logger.Debugf("FLOW_STATS_REPLY is received (device=%v, # of flows=%v, more=%v)", r.device.ID(), len(v.Stats()), v.More())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnFlowStatsReply(f, w, v)
+}
+
+func (r *session) OnPortStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.PortStatsReply) error {
+	logger.Debugf("PORT_STATS_REPLY is received (device=%v)", r.device.ID())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnPortStatsReply(f, w, v)
+}
+
+func (r *session) OnTableStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.TableStatsReply) error {
+	logger.Debugf("TABLE_STATS_REPLY is received (device=%v)", r.device.ID())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnTableStatsReply(f, w, v)
+}
+
+func (r *session) OnQueueStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.QueueStatsReply) error {
+	logger.Debugf("QUEUE_STATS_REPLY is received (device=%v)", r.device.ID())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnQueueStatsReply(f, w, v)
+}
+
+func (r *session) OnAggregateStatsReply(f openflow.Factory, w transceiver.Writer, v openflow.AggregateStatsReply) error {
+	logger.Debugf("AGGREGATE_STATS_REPLY is received (device=%v)", r.device.ID())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnAggregateStatsReply(f, w, v)
+}
+
+func (r *session) OnTableFeaturesReply(f openflow.Factory, w transceiver.Writer, v openflow.TableFeaturesReply) error {
+	logger.Debugf("TABLE_FEATURES_REPLY is received (device=%v, # of tables=%v, more=%v)", r.device.ID(), len(v.Features()), v.More())
+
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+
+	return r.handler.OnTableFeaturesReply(f, w, v)
+}
+
 func newLLDPEtherFrame(deviceID string, port openflow.Port) ([]byte, error) {
 	lldp := &protocol.LLDP{
 		ChassisID: protocol.LLDPChassisID{
@@ -300,7 +436,7 @@ func (r *session) updatePort(v openflow.PortStatus) {
 		if port.Number() > of10.OFPP_MAX {
 			return
 		}
-	case openflow.OF13_VERSION:
+	case openflow.OF13_VERSION, openflow.OF14_VERSION:
 		if port.Number() > of13.OFPP_MAX {
 			return
 		}
@@ -447,16 +583,18 @@ func (r *session) handleLLDP(inPort *Port, ethernet *protocol.Ethernet) error {
 }
 
 func (r *session) OnPacketIn(f openflow.Factory, w transceiver.Writer, v openflow.PacketIn) error {
+	timestamp := time.Now()
 	if !r.negotiated {
 		return errNotNegotiated
 	}
-	logger.Debugf("PACKET_IN is received (device=%v, inport=%v, reason=%v, tableID=%v, cookie=%v)",
-		r.device.ID(), v.InPort(), v.Reason(), v.TableID(), v.Cookie())
+	logger.Debugf("PACKET_IN is received (device=%v, inport=%v, bufferID=%#x, length=%v, reason=%v, tableID=%v, cookie=%v)",
+		r.device.ID(), v.InPort(), v.BufferID(), v.Length(), openflow.PacketInReason(v.Reason()), v.TableID(), v.Cookie())
 
 	// Do nothing if the ingress device is not yet ready.
 	if r.device.isReady() == false {
 		logger.Debugf("ignoring PACKET_IN: device is not ready: device=%v, inPort=%v", r.device.ID(), v.InPort())
 		// Drop the incoming packet.
+		r.dropPacketIn(v)
 		return nil
 	}
 
@@ -469,6 +607,7 @@ func (r *session) OnPacketIn(f openflow.Factory, w transceiver.Writer, v openflo
 	inPort := r.device.Port(v.InPort())
 	if inPort == nil {
 		logger.Errorf("failed to find a port: deviceID=%v, portNum=%v, so ignore PACKET_IN..", r.device.ID(), v.InPort())
+		r.dropPacketIn(v)
 		return nil
 	}
 	// Process LLDP, and then add an edge among two switches. This should be executed
@@ -479,6 +618,7 @@ func (r *session) OnPacketIn(f openflow.Factory, w transceiver.Writer, v openflo
 	// Do nothing if the ingress port is an edge between switches and is disabled by STP.
 	if r.finder.IsEdge(inPort) && !r.finder.IsEnabledBySTP(inPort) {
 		logger.Debugf("ignoring PACKET_IN from %v:%v by STP", r.device.ID(), v.InPort())
+		r.dropPacketIn(v)
 		return nil
 	}
 	// Call specific version handler
@@ -486,7 +626,46 @@ func (r *session) OnPacketIn(f openflow.Factory, w transceiver.Writer, v openflo
 		return err
 	}
 
-	return r.listener.OnPacketIn(r.finder, inPort, ethernet)
+	// The admission goroutine passes the packet to the listeners, and waits for
+	// them to process it, if the packet is admitted under the rate limits.
+	event := packetInEvent{ingress: inPort, ethernet: ethernet, packetIn: newPacketIn(v, timestamp)}
+	if !r.admission.admit(event, timestamp) {
+		logger.Debugf("dropping PACKET_IN by the admission control: device=%v, inPort=%v", r.device.ID(), v.InPort())
+		r.dropPacketIn(v)
+	}
+
+	return nil
+}
+
+// dropPacketIn releases the buffer of the packet that is not passed to the event
+// listeners. Otherwise, the buffer is wasted until it expires in the device.
+func (r *session) dropPacketIn(v openflow.PacketIn) {
+	if v.BufferID() == openflow.NoBuffer {
+		return
+	}
+	if err := r.device.dropBuffer(v.InPort(), v.BufferID()); err != nil {
+		logger.Debugf("failed to release the packet buffer: device=%v, inPort=%v, bufferID=%#x, err=%v", r.device.ID(), v.InPort(), v.BufferID(), err)
+	}
+}
+
+func (r *session) runAdmission(ctx context.Context) context.CancelFunc {
+	subCtx, canceller := context.WithCancel(ctx)
+
+	go r.admission.run(subCtx, func(v packetInEvent) {
+		err := r.listener.OnPacketIn(r.finder, v.ingress, v.ethernet, v.packetIn)
+		if err == nil {
+			return
+		}
+		if isTemporaryErr(err) {
+			logger.Errorf("failed to process PACKET_IN: device=%v, error=%v", r.device.ID(), err)
+			return
+		}
+		// Close the session as if the error is returned from the transceiver handler.
+		logger.Errorf("closing the session due to the PACKET_IN error: device=%v, error=%v", r.device.ID(), err)
+		r.close()
+	})
+
+	return canceller
 }
 
 func (r *session) OnBarrierReply(f openflow.Factory, w transceiver.Writer, v openflow.BarrierReply) error {
@@ -498,9 +677,113 @@ func (r *session) OnBarrierReply(f openflow.Factory, w transceiver.Writer, v ope
 	return r.handler.OnBarrierReply(f, w, v)
 }
 
+func (r *session) OnRoleReply(f openflow.Factory, w transceiver.Writer, v openflow.RoleReply) error {
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+	logger.Debugf("ROLE_REPLY is received (device=%v, role=%v, generationID=%v)", r.device.ID(), v.Role(), v.GenerationID())
+
+	return r.handler.OnRoleReply(f, w, v)
+}
+
+func (r *session) OnGetAsyncReply(f openflow.Factory, w transceiver.Writer, v openflow.GetAsyncReply) error {
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+	logger.Debugf("GET_ASYNC_REPLY is received (device=%v)", r.device.ID())
+
+	return r.handler.OnGetAsyncReply(f, w, v)
+}
+
+func (r *session) OnFlowMonitorReply(f openflow.Factory, w transceiver.Writer, v openflow.FlowMonitorReply) error {
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+	logger.Debugf("FLOW_MONITOR_REPLY is received (device=%v, # of updates=%v)", r.device.ID(), len(v.Updates()))
+
+	return r.handler.OnFlowMonitorReply(f, w, v)
+}
+
+func (r *session) OnExperimenter(f openflow.Factory, w transceiver.Writer, v openflow.Header) error {
+	if !r.negotiated {
+		return errNotNegotiated
+	}
+	logger.Debugf("EXPERIMENTER is received (device=%v, type=%T)", r.device.ID(), v)
+
+	// Do nothing if the device is not yet ready.
+	if r.device.isReady() == false {
+		logger.Debugf("ignoring EXPERIMENTER: device is not ready: device=%v", r.device.ID())
+		return nil
+	}
+
+	if err := r.listener.OnExperimenter(r.finder, r.device, v); err != nil {
+		logger.Errorf("error on OnExperimenter listeners: %v", err)
+		// Ignore this error and keep go on.
+	}
+
+	return r.handler.OnExperimenter(f, w, v)
+}
+
+// promote changes the role of this session to the master.
+func (r *session) promote() {
+	f := r.device.Factory()
+	if f.ProtocolVersion() < openflow.OF13_VERSION {
+		// Only OF13 or higher sessions can be held as a slave.
+		return
+	}
+
+	logger.Infof("promoting the session to the master: device=%v", r.device.ID())
+	if err := r.role.sendRoleRequest(f, r.device.Writer()); err != nil {
+		logger.Errorf("failed to send ROLE_REQUEST: %v", err)
+	}
+}
+
+// demote changes the role of this session to a slave. The connection is kept, and
+// the device is released when it accepts the new role.
+func (r *session) demote() {
+	f := r.device.Factory()
+	if f.ProtocolVersion() < openflow.OF13_VERSION {
+		// OF10 sessions cannot be held as a slave. The device will reconnect
+		// to the new master.
+		logger.Infof("disconnecting the device because we are no longer the master controller: device=%v", r.device.ID())
+		r.close()
+		return
+	}
+
+	logger.Infof("demoting the session to a slave: device=%v", r.device.ID())
+	if err := r.role.sendRoleRequest(f, r.device.Writer()); err != nil {
+		logger.Errorf("failed to send ROLE_REQUEST: %v", err)
+		r.close()
+	}
+}
+
+// release stops managing the device after it has accepted us as a slave, so that
+// the applications don't use the device anymore. We will initialize the device
+// again when we are promoted to the master.
+func (r *session) release() {
+	if !r.device.isReady() {
+		return
+	}
+	logger.Infof("releasing the device as a slave controller: device=%v", r.device.ID())
+
+	if err := r.listener.OnDeviceDown(r.finder, r.device); err != nil {
+		logger.Errorf("OnDeviceDown: %v", err)
+	}
+	r.watcher.DeviceRemoved(r.device)
+	r.device.reset()
+}
+
 func (r *session) Run(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	defer r.cancel()
+	// Don't notify the role changes anymore after this session is closed.
+	defer r.role.unregister(r)
+
 	stopExplorer := r.runDeviceExplorer(ctx)
 	logger.Debugf("started a new device explorer")
+	stopPoller := r.runStatsPoller(ctx)
+	logger.Debugf("started a new stats poller")
+	stopAdmission := r.runAdmission(ctx)
 
 	if err := r.transceiver.Run(ctx); err != nil {
 		logger.Errorf("openflow transceiver is unexpectedly closed: %v", err)
@@ -508,6 +791,8 @@ func (r *session) Run(ctx context.Context) {
 	logger.Infof("disconnected device (DPID=%v)", r.device.ID())
 
 	stopExplorer()
+	stopPoller()
+	stopAdmission()
 	r.transceiver.Close()
 	r.device.Close()
 	if r.device.isReady() {
@@ -518,6 +803,13 @@ func (r *session) Run(ctx context.Context) {
 	}
 }
 
+// close disconnects the device by canceling the context used to run this session.
+func (r *session) close() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
 func (r *session) runDeviceExplorer(ctx context.Context) context.CancelFunc {
 	subCtx, canceller := context.WithCancel(ctx)
 
@@ -548,7 +840,7 @@ func (r *session) runDeviceExplorer(ctx context.Context) context.CancelFunc {
 						continue
 					}
 					logger.Debugf("sent a FeaturesRequest packet to %v", r.device.ID())
-				case openflow.OF13_VERSION:
+				case openflow.OF13_VERSION, openflow.OF14_VERSION:
 					// OF13 provides ports information in the PortDescriptionReply packet.
 					if err := sendPortDescriptionRequest(r.device.Factory(), r.device.Writer()); err != nil {
 						logger.Errorf("failed to send a port description request: %v", err)
@@ -578,13 +870,27 @@ func sendHello(f openflow.Factory, w transceiver.Writer) error {
 	return w.Write(msg)
 }
 
-func sendSetConfig(f openflow.Factory, w transceiver.Writer) error {
+func sendRoleRequest(f openflow.Factory, w transceiver.Writer, role openflow.ControllerRole, generationID uint64) error {
+	msg, err := f.NewRoleRequest()
+	if err != nil {
+		return err
+	}
+	msg.SetRole(role)
+	msg.SetGenerationID(generationID)
+
+	return w.Write(msg)
+}
+
+// sendSetConfig makes the device buffer the packets sent to the controller, and
+// send only their first missSendLength bytes. On OF13 devices, each output action
+// to the controller has its own maximum length as well.
+func sendSetConfig(f openflow.Factory, w transceiver.Writer, missSendLength uint16) error {
 	msg, err := f.NewSetConfig()
 	if err != nil {
 		return err
 	}
 	msg.SetFlags(openflow.FragNormal)
-	msg.SetMissSendLength(0xFFFF)
+	msg.SetMissSendLength(missSendLength)
 
 	return w.Write(msg)
 }
@@ -625,25 +931,27 @@ func sendPortDescriptionRequest(f openflow.Factory, w transceiver.Writer) error
 	return w.Write(msg)
 }
 
-// setARPSender installs a flow that sends all ARP packets to the controller.
-func setARPSender(f openflow.Factory, w transceiver.Writer) error {
+// setARPSender installs a flow that sends all ARP packets to the controller. The
+// packets longer than maxLength are buffered in the device.
+func setARPSender(f openflow.Factory, w transceiver.Writer, maxLength uint16) error {
 	// Permanent flow.
-	return setSpecialFlow(f, w, 0x0806 /* ARP */, 100, 0, 0, false)
+	return setSpecialFlow(f, w, 0x0806 /* ARP */, 100, 0, 0, false, maxLength)
 }
 
 // setLLDPSender installs a flow that sends all LLDP packets to the controller.
 func setLLDPSender(f openflow.Factory, w transceiver.Writer) error {
-	// Permanent flow.
-	return setSpecialFlow(f, w, 0x88CC /* LLDP */, 100, 0, 0, false)
+	// Permanent flow. LLDP packets are consumed by the controller, so they are
+	// not buffered in the device.
+	return setSpecialFlow(f, w, 0x88CC /* LLDP */, 100, 0, 0, false, openflow.NoBufferLength)
 }
 
 // setTemporaryDrop installs a temporary flow that drops all the packets.
 func setTemporaryDrop(f openflow.Factory, w transceiver.Writer) error {
 	// Temporary flow that will be removed after a few seconds.
-	return setSpecialFlow(f, w, 0 /* wildcard */, 50, 0, 5, true)
+	return setSpecialFlow(f, w, 0 /* wildcard */, 50, 0, 5, true, 0)
 }
 
-func setSpecialFlow(f openflow.Factory, w transceiver.Writer, ethertype, priority, idleTimeout, hardTimeout uint16, allDrop bool) error {
+func setSpecialFlow(f openflow.Factory, w transceiver.Writer, ethertype, priority, idleTimeout, hardTimeout uint16, allDrop bool, maxLength uint16) error {
 	match, err := f.NewMatch()
 	if err != nil {
 		return err
@@ -667,6 +975,7 @@ func setSpecialFlow(f openflow.Factory, w transceiver.Writer, ethertype, priorit
 	if allDrop == false {
 		outPort := openflow.NewOutPort()
 		outPort.SetController()
+		outPort.SetMaxLength(maxLength)
 		action, err := f.NewAction()
 		if err != nil {
 			return err
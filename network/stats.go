@@ -0,0 +1,384 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+const (
+	statsPollerInterval = 10 * time.Second
+	// Timeout to wait the stats replies from a device.
+	statsPollerTimeout = 5 * time.Second
+)
+
+type PortCounters struct {
+	RxPackets uint64
+	TxPackets uint64
+	RxBytes   uint64
+	TxBytes   uint64
+	RxDropped uint64
+	TxDropped uint64
+	RxErrors  uint64
+	TxErrors  uint64
+}
+
+func (r *PortCounters) add(v PortCounters) {
+	r.RxPackets += v.RxPackets
+	r.TxPackets += v.TxPackets
+	r.RxBytes += v.RxBytes
+	r.TxBytes += v.TxBytes
+	r.RxDropped += v.RxDropped
+	r.TxDropped += v.TxDropped
+	r.RxErrors += v.RxErrors
+	r.TxErrors += v.TxErrors
+}
+
+// PortRates are the per-second rates calculated from two consecutive samples of the port counters.
+type PortRates struct {
+	RxBps     float64
+	TxBps     float64
+	RxPps     float64
+	TxPps     float64
+	RxDropped float64
+	TxDropped float64
+	RxErrors  float64
+	TxErrors  float64
+}
+
+func (r *PortRates) add(v PortRates) {
+	r.RxBps += v.RxBps
+	r.TxBps += v.TxBps
+	r.RxPps += v.RxPps
+	r.TxPps += v.TxPps
+	r.RxDropped += v.RxDropped
+	r.TxDropped += v.TxDropped
+	r.RxErrors += v.RxErrors
+	r.TxErrors += v.TxErrors
+}
+
+type PortStats struct {
+	Counters PortCounters
+	Rates    PortRates
+	// Timestamp is the time when we collected these stats. It is zero if we
+	// have not yet collected the stats of the port.
+	Timestamp time.Time
+}
+
+type DeviceStats struct {
+	// Number of active flow entries in all the flow tables.
+	ActiveFlows uint32
+	// Number of packets looked up in all the flow tables.
+	LookupCount uint64
+	// Number of packets that hit all the flow tables.
+	MatchedCount uint64
+	// Sum of the counters and rates of all the ports.
+	Counters PortCounters
+	Rates    PortRates
+	// Timestamp is the time when we collected these stats. It is zero if we
+	// have not yet collected the stats of the device.
+	Timestamp time.Time
+}
+
+// DeviceReport is the statistics of a device and its ports collected by the controller.
+type DeviceReport struct {
+	ID       string
+	Stats    DeviceStats
+	PacketIn PacketInStats
+	Events   EventStats
+	Ports    []PortReport
+}
+
+type PortReport struct {
+	Number uint32
+	Stats  PortStats
+}
+
+func rate(prev, cur uint64, elapsed time.Duration) float64 {
+	// Counters can be reset by the device.
+	if cur < prev || elapsed <= 0 {
+		return 0
+	}
+
+	return float64(cur-prev) / elapsed.Seconds()
+}
+
+func calculateRates(prev, cur PortCounters, elapsed time.Duration) PortRates {
+	return PortRates{
+		RxBps:     rate(prev.RxBytes, cur.RxBytes, elapsed) * 8,
+		TxBps:     rate(prev.TxBytes, cur.TxBytes, elapsed) * 8,
+		RxPps:     rate(prev.RxPackets, cur.RxPackets, elapsed),
+		TxPps:     rate(prev.TxPackets, cur.TxPackets, elapsed),
+		RxDropped: rate(prev.RxDropped, cur.RxDropped, elapsed),
+		TxDropped: rate(prev.TxDropped, cur.TxDropped, elapsed),
+		RxErrors:  rate(prev.RxErrors, cur.RxErrors, elapsed),
+		TxErrors:  rate(prev.TxErrors, cur.TxErrors, elapsed),
+	}
+}
+
+func (r *Port) Stats() PortStats {
+	// Read lock
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.stats
+}
+
+func (r *Port) updateStats(v openflow.PortStats, now time.Time) PortStats {
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counters := PortCounters{
+		RxPackets: v.RxPackets(),
+		TxPackets: v.TxPackets(),
+		RxBytes:   v.RxBytes(),
+		TxBytes:   v.TxBytes(),
+		RxDropped: v.RxDropped(),
+		TxDropped: v.TxDropped(),
+		RxErrors:  v.RxErrors(),
+		TxErrors:  v.TxErrors(),
+	}
+
+	var rates PortRates
+	// We need two samples at least to calculate the rates.
+	if !r.stats.Timestamp.IsZero() {
+		rates = calculateRates(r.stats.Counters, counters, now.Sub(r.stats.Timestamp))
+	}
+	r.stats = PortStats{
+		Counters:  counters,
+		Rates:     rates,
+		Timestamp: now,
+	}
+
+	return r.stats
+}
+
+func (r *Device) Stats() DeviceStats {
+	// Read lock
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.stats
+}
+
+// PacketInStats returns the counters of the PACKET_IN admission control.
+func (r *Device) PacketInStats() PacketInStats {
+	return r.session.admission.Stats()
+}
+
+func (r *Device) updateStats(tables []openflow.TableStats, ports []openflow.PortStats, now time.Time) {
+	stats := DeviceStats{Timestamp: now}
+	for _, v := range tables {
+		stats.ActiveFlows += v.ActiveCount()
+		stats.LookupCount += v.LookupCount()
+		stats.MatchedCount += v.MatchedCount()
+	}
+	for _, v := range ports {
+		port := r.Port(v.PortNumber())
+		// Skip the unknown ports such as the local port.
+		if port == nil {
+			continue
+		}
+		s := port.updateStats(v, now)
+		stats.Counters.add(s.Counters)
+		stats.Rates.add(s.Rates)
+	}
+
+	// Write lock
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.stats = stats
+}
+
+// PortStats queries the statistics of all the ports of this device.
+func (r *Device) PortStats(ctx context.Context) ([]openflow.PortStats, error) {
+	f := r.Factory()
+	if f == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+
+	req, err := f.NewPortStatsRequest()
+	if err != nil {
+		return nil, err
+	}
+	replies, err := r.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openflow.PortStats, 0)
+	for _, v := range replies {
+		reply, ok := v.(openflow.PortStatsReply)
+		if !ok {
+			return nil, fmt.Errorf("unexpected reply message: type=%v", v.Type())
+		}
+		result = append(result, reply.Stats()...)
+	}
+
+	return result, nil
+}
+
+// TableStats queries the statistics of all the flow tables of this device.
+func (r *Device) TableStats(ctx context.Context) ([]openflow.TableStats, error) {
+	f := r.Factory()
+	if f == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+
+	req, err := f.NewTableStatsRequest()
+	if err != nil {
+		return nil, err
+	}
+	replies, err := r.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openflow.TableStats, 0)
+	for _, v := range replies {
+		reply, ok := v.(openflow.TableStatsReply)
+		if !ok {
+			return nil, fmt.Errorf("unexpected reply message: type=%v", v.Type())
+		}
+		result = append(result, reply.Stats()...)
+	}
+
+	return result, nil
+}
+
+// QueueStats queries the statistics of all the queues of all the ports of this device.
+func (r *Device) QueueStats(ctx context.Context) ([]openflow.QueueStats, error) {
+	f := r.Factory()
+	if f == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+
+	req, err := f.NewQueueStatsRequest()
+	if err != nil {
+		return nil, err
+	}
+	replies, err := r.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openflow.QueueStats, 0)
+	for _, v := range replies {
+		reply, ok := v.(openflow.QueueStatsReply)
+		if !ok {
+			return nil, fmt.Errorf("unexpected reply message: type=%v", v.Type())
+		}
+		result = append(result, reply.Stats()...)
+	}
+
+	return result, nil
+}
+
+// AggregateStats queries the aggregated statistics of the flow entries that
+// match the match from all the flow tables. match can be nil to aggregate all
+// the flow entries.
+func (r *Device) AggregateStats(ctx context.Context, match openflow.Match) (openflow.AggregateStatsReply, error) {
+	f := r.Factory()
+	if f == nil {
+		return nil, errors.New("not yet negotiated device")
+	}
+
+	if match == nil {
+		var err error
+		// Wildcard
+		if match, err = f.NewMatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := f.NewAggregateStatsRequest()
+	if err != nil {
+		return nil, err
+	}
+	req.SetTableID(0xFF) // ALL
+	req.SetMatch(match)
+
+	replies, err := r.request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	reply, ok := replies[0].(openflow.AggregateStatsReply)
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply message: type=%v", replies[0].Type())
+	}
+
+	return reply, nil
+}
+
+func (r *session) runStatsPoller(ctx context.Context) context.CancelFunc {
+	subCtx, canceller := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(statsPollerInterval)
+		defer ticker.Stop()
+
+		// Infinite loop.
+		for {
+			select {
+			case <-subCtx.Done():
+				logger.Debugf("terminating the stats poller: deviceID=%v", r.device.ID())
+				return
+			case <-ticker.C:
+				if r.device.isReady() == false {
+					logger.Debug("skip to execute the stats poller due to incomplete device status")
+					continue
+				}
+				if err := r.pollStats(subCtx); err != nil {
+					logger.Errorf("failed to poll the stats: deviceID=%v, err=%v", r.device.ID(), err)
+					continue
+				}
+			}
+		}
+	}()
+
+	return canceller
+}
+
+func (r *session) pollStats(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, statsPollerTimeout)
+	defer cancel()
+
+	tables, err := r.device.TableStats(timeoutCtx)
+	if err != nil {
+		return fmt.Errorf("table stats: %v", err)
+	}
+	ports, err := r.device.PortStats(timeoutCtx)
+	if err != nil {
+		return fmt.Errorf("port stats: %v", err)
+	}
+	r.device.updateStats(tables, ports, time.Now())
+	logger.Debugf("updated the stats: deviceID=%v, # of tables=%v, # of ports=%v", r.device.ID(), len(tables), len(ports))
+
+	return nil
+}
@@ -0,0 +1,260 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/transceiver"
+)
+
+// FlowModError is the error reported by a device for a FLOW_MOD message that
+// the device has rejected.
+type FlowModError struct {
+	Class uint16 // Error type
+	Code  uint16
+	Data  []byte
+	// Decoded is the error type and code decoded into their names.
+	Decoded *openflow.ErrorCause
+}
+
+func (r *FlowModError) Error() string {
+	return fmt.Sprintf("FLOW_MOD is rejected by the device: %v", r.Decoded)
+}
+
+// Cause returns the decoded error so that errors.Cause returns it.
+func (r *FlowModError) Cause() error {
+	return r.Decoded
+}
+
+// FlowResult is the result of a flow operation in a transaction. Err is nil if
+// the device has accepted the operation, or *FlowModError if the device has
+// rejected it. In an atomic transaction, Err of the accepted operations is
+// ErrBundleDiscarded if the device has rejected any other operation.
+type FlowResult struct {
+	Command openflow.FlowModCmd
+	Flow    Flow
+	Err     error
+}
+
+type flowOp struct {
+	cmd  openflow.FlowModCmd
+	flow Flow
+	mask uint64
+}
+
+// FlowTransaction batches flow operations and sends them to a device at once
+// followed by a BARRIER_REQUEST. The device processes all the operations before
+// replying to the barrier, so the ERROR replies received before the BARRIER_REPLY
+// tell which operations have been rejected.
+type FlowTransaction struct {
+	device *Device
+	ops    []flowOp
+	// stamp is applied to the flows of the add and modify operations.
+	stamp func(Flow) (Flow, error)
+	// cookie and mask select the flows affected by the modify and delete operations.
+	cookie uint64
+	mask   uint64
+	atomic bool
+}
+
+// NewFlowTransaction returns a new flow transaction for this device whose operations
+// only affect the flows in the cookie namespace ns. The flows added or modified by
+// the transaction are stamped with the namespace.
+func (r *Device) NewFlowTransaction(ns CookieNamespace) *FlowTransaction {
+	return &FlowTransaction{
+		device: r,
+		stamp:  ns.stamp,
+		cookie: ns.Cookie(0),
+		mask:   ns.Mask(),
+	}
+}
+
+// Add adds an operation that installs the flow into the transaction.
+func (r *FlowTransaction) Add(flow Flow) {
+	r.ops = append(r.ops, flowOp{cmd: openflow.FlowAdd, flow: flow})
+}
+
+// Modify adds an operation that modifies the instructions of the flow into the transaction.
+func (r *FlowTransaction) Modify(flow Flow) {
+	r.ops = append(r.ops, flowOp{cmd: openflow.FlowModifyStrict, flow: flow})
+}
+
+// Delete adds an operation that deletes the flow into the transaction.
+func (r *FlowTransaction) Delete(flow Flow) {
+	r.ops = append(r.ops, flowOp{cmd: openflow.FlowDeleteStrict, flow: flow})
+}
+
+// SetAtomic makes Commit apply all the operations atomically in a bundle if the
+// device supports bundles. Otherwise, Commit falls back to the non-atomic one,
+// so the device may forward packets with the partially applied operations.
+func (r *FlowTransaction) SetAtomic(atomic bool) {
+	r.atomic = atomic
+}
+
+// Len returns the number of operations in the transaction.
+func (r *FlowTransaction) Len() int {
+	return len(r.ops)
+}
+
+func (r *FlowTransaction) prepare() ([]flowOp, error) {
+	result := make([]flowOp, len(r.ops))
+	for i, op := range r.ops {
+		switch op.cmd {
+		case openflow.FlowAdd:
+			flow, err := r.stamp(op.flow)
+			if err != nil {
+				return nil, err
+			}
+			op.flow = flow
+		case openflow.FlowModifyStrict:
+			flow, err := r.stamp(op.flow)
+			if err != nil {
+				return nil, err
+			}
+			op.flow = flow
+			op.mask = r.mask | cookieNamespaceMask
+		case openflow.FlowDeleteStrict:
+			op.flow.Cookie = r.cookie
+			op.mask = r.mask
+		default:
+			panic(fmt.Sprintf("unexpected flow command: %v", op.cmd))
+		}
+		result[i] = op
+	}
+
+	return result, nil
+}
+
+// flowMods makes FLOW_MOD messages of the operations.
+func (r *FlowTransaction) flowMods(ops []flowOp) ([]transceiver.Request, error) {
+	d := r.device
+	// Read lock
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	result := make([]transceiver.Request, 0, len(ops))
+	for _, op := range ops {
+		flowmod, err := d.newFlowMod(op.cmd, op.flow, op.mask)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, flowmod)
+	}
+
+	return result, nil
+}
+
+// Commit sends all the operations in the transaction to the device, and then
+// waits until the device processes them. It returns the result of each operation
+// in the order they were added. The returned error is not nil if the transaction
+// itself has failed, e.g., an invalid flow or a disconnected device, in which
+// case the operations may or may not have been applied.
+func (r *FlowTransaction) Commit(ctx context.Context) ([]FlowResult, error) {
+	if len(r.ops) == 0 {
+		return []FlowResult{}, nil
+	}
+	ops, err := r.prepare()
+	if err != nil {
+		return nil, err
+	}
+	// Make all the messages first so that an invalid flow does not leave the
+	// transaction partially sent.
+	msgs, err := r.flowMods(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, discarded, err := r.send(ctx, msgs)
+	if err != nil {
+		if err == transceiver.ErrClosed {
+			return nil, ErrClosedDevice
+		}
+		return nil, err
+	}
+
+	result := make([]FlowResult, len(ops))
+	for i, op := range ops {
+		result[i] = FlowResult{Command: op.cmd, Flow: op.flow}
+		e, ok := errs[i].(*transceiver.ErrorReply)
+		if !ok {
+			if discarded {
+				result[i].Err = ErrBundleDiscarded
+			}
+			continue
+		}
+		cause := e.Reply.Cause()
+		result[i].Err = &FlowModError{Class: e.Reply.Class(), Code: e.Reply.Code(), Data: e.Reply.Data(), Decoded: cause}
+		logger.Errorf("FLOW_MOD is rejected: device=%v, xid=%v, error=%v", r.device.ID(), msgs[i].TransactionID(), cause)
+	}
+
+	return result, nil
+}
+
+// send sends the messages in a bundle if the transaction is atomic and the device
+// supports bundles. Otherwise, it sends the messages followed by a barrier.
+func (r *FlowTransaction) send(ctx context.Context, msgs []transceiver.Request) (errs []error, discarded bool, err error) {
+	if r.atomic {
+		errs, err := r.sendBundle(ctx, msgs)
+		switch err {
+		case ErrUnsupportedBundle:
+			// Fall back to the non-atomic one.
+		case ErrBundleDiscarded:
+			return errs, true, nil
+		default:
+			return errs, false, err
+		}
+		logger.Debugf("falling back to the non-atomic flow transaction: device=%v", r.device.ID())
+	}
+
+	errs, err = r.device.session.transceiver.Transaction(ctx, msgs)
+	return errs, false, err
+}
+
+func (r *FlowTransaction) sendBundle(ctx context.Context, msgs []transceiver.Request) ([]error, error) {
+	bundle, err := r.device.OpenBundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range msgs {
+		if err := bundle.Add(v); err != nil {
+			bundle.Discard(ctx)
+			return nil, err
+		}
+	}
+
+	return bundle.Commit(ctx)
+}
+
+// Failed returns the results of the operations that the device has rejected.
+func Failed(results []FlowResult) []FlowResult {
+	failed := make([]FlowResult, 0)
+	for _, v := range results {
+		if v.Err != nil {
+			failed = append(failed, v)
+		}
+	}
+
+	return failed
+}
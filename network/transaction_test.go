@@ -0,0 +1,127 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+func TestFlowTransactionCommit(t *testing.T) {
+	ns := NewCookieNamespace("transaction")
+	// The switch rejects the second and the last operations.
+	sw := &testFlowSwitch{
+		reject: func(v *of13.FlowMod) bool { return v.Priority() == 2 || v.Priority() == 4 },
+	}
+	// Message types received by the switch in order.
+	types := make([]uint8, 0)
+	device, disconnect := newTestDevice(t, func(packet []byte) [][]byte {
+		types = append(types, packet[1])
+		return sw.handle(packet)
+	})
+	defer disconnect()
+
+	flows := make([]Flow, 4)
+	for i := range flows {
+		flows[i] = newTestFlow(uint32(i+1), 10)
+		flows[i].Priority = uint16(i + 1)
+	}
+	tx := device.NewFlowTransaction(ns)
+	tx.Add(flows[0])
+	tx.Add(flows[1])
+	tx.Modify(flows[2])
+	tx.Delete(flows[3])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// The operations are sent in order, and then the barrier ends the batch.
+	expectedTypes := []uint8{of13.OFPT_FLOW_MOD, of13.OFPT_FLOW_MOD, of13.OFPT_FLOW_MOD, of13.OFPT_FLOW_MOD, of13.OFPT_BARRIER_REQUEST}
+	if !bytes.Equal(types, expectedTypes) {
+		t.Fatalf("unexpected message types: expected=%v, got=%v", expectedTypes, types)
+	}
+
+	// The results are in the order the operations were added.
+	expected := []struct {
+		command  openflow.FlowModCmd
+		priority uint16
+		rejected bool
+	}{
+		{openflow.FlowAdd, 1, false},
+		{openflow.FlowAdd, 2, true},
+		{openflow.FlowModifyStrict, 3, false},
+		{openflow.FlowDeleteStrict, 4, true},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("unexpected number of the results: %v", len(results))
+	}
+	flowMods := sw.received()
+	for i, v := range expected {
+		result := results[i]
+		if result.Command != v.command || result.Flow.Priority != v.priority {
+			t.Fatalf("unexpected result #%v: command=%v, priority=%v", i, result.Command, result.Flow.Priority)
+		}
+		if !v.rejected {
+			if result.Err != nil {
+				t.Fatalf("unexpected error of the result #%v: %v", i, result.Err)
+			}
+			continue
+		}
+
+		e, ok := result.Err.(*FlowModError)
+		if !ok {
+			t.Fatalf("expected a FlowModError of the result #%v: %v", i, result.Err)
+		}
+		if e.Class != of13.OFPET_FLOW_MOD_FAILED || e.Code != of13.OFPFMFC_TABLE_FULL {
+			t.Fatalf("unexpected error of the result #%v: class=%v, code=%v", i, e.Class, e.Code)
+		}
+		if e.Decoded == nil || e.Decoded.CodeName != "OFPFMFC_TABLE_FULL" {
+			t.Fatalf("unexpected decoded error of the result #%v: %v", i, e.Decoded)
+		}
+		// The data of the error is the head of the rejected FLOW_MOD.
+		if len(e.Data) < 8 || binary.BigEndian.Uint32(e.Data[4:8]) != flowMods[i].msg.TransactionID() {
+			t.Fatalf("mismatched request of the error #%v: %v", i, e.Data)
+		}
+	}
+	if failed := Failed(results); len(failed) != 2 {
+		t.Fatalf("unexpected number of the failed operations: %v", len(failed))
+	}
+
+	// An empty transaction sends nothing.
+	results, err = device.NewFlowTransaction(ns).Commit(ctx)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("unexpected result of the empty transaction: results=%v, err=%v", results, err)
+	}
+	if len(sw.received()) != len(expected) {
+		t.Fatal("the empty transaction has sent messages")
+	}
+}
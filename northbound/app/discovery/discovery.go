@@ -159,10 +159,10 @@ func (r *processor) stopARPSender(deviceID string) {
 	delete(r.canceller, deviceID)
 }
 
-func (r *processor) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet) error {
+func (r *processor) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn) error {
 	// ARP?
 	if eth.Type != 0x0806 {
-		return r.BaseProcessor.OnPacketIn(finder, ingress, eth)
+		return r.BaseProcessor.OnPacketIn(finder, ingress, eth, packetIn)
 	}
 
 	arp := new(protocol.ARP)
@@ -173,7 +173,7 @@ func (r *processor) OnPacketIn(finder network.Finder, ingress *network.Port, eth
 
 	switch arp.Operation {
 	case 1:
-		return r.processARPRequest(finder, ingress, eth, arp)
+		return r.processARPRequest(finder, ingress, eth, packetIn, arp)
 	case 2:
 		return r.processARPReply(finder, ingress, eth, arp)
 	default:
@@ -183,7 +183,7 @@ func (r *processor) OnPacketIn(finder network.Finder, ingress *network.Port, eth
 	}
 }
 
-func (r *processor) processARPRequest(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, arp *protocol.ARP) error {
+func (r *processor) processARPRequest(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn, arp *protocol.ARP) error {
 	// Our ARP probe?
 	if bytes.Equal(arp.SHA, myMAC) {
 		// Drop this packet! This packet should not be propagated among switches.
@@ -191,7 +191,7 @@ func (r *processor) processARPRequest(finder network.Finder, ingress *network.Po
 		return nil
 	} else {
 		// Propagate this ARP request, wich is raised from a host, to the next processors.
-		return r.BaseProcessor.OnPacketIn(finder, ingress, eth)
+		return r.BaseProcessor.OnPacketIn(finder, ingress, eth, packetIn)
 	}
 }
 
@@ -222,9 +222,10 @@ func (r *processor) processARPReply(finder network.Finder, ingress *network.Port
 	// Remove installed flows for this host if the location has been changed.
 	if updated {
 		logger.Infof("update host location: IP=%v, MAC=%v, deviceID=%v, portNum=%v", arp.SPA, arp.SHA, swDPID, ingress.Number())
-		// Remove flows from all devices.
+		// Remove flows from all devices. The flows of all the applications heading
+		// to the host are stale because they forward packets to the old location.
 		for _, device := range finder.Devices() {
-			if err := device.RemoveFlowByMAC(arp.SHA); err != nil {
+			if err := device.RemoveFlowByMAC(network.AllNamespaces, arp.SHA); err != nil {
 				logger.Errorf("failed to remove flows from %v: %v", device.ID(), err)
 				continue
 			}
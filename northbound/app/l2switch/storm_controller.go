@@ -38,7 +38,7 @@ type stormController struct {
 }
 
 type broadcaster interface {
-	flood(ingress *network.Port, packet []byte) error
+	flood(ingress *network.Port, packetIn *network.PacketIn, packet []byte) error
 }
 
 // max is the number of broadcasts that are allowed per second.
@@ -57,7 +57,7 @@ func newStormController(max uint, bcaster broadcaster) *stormController {
 	}
 }
 
-func (r *stormController) broadcast(ingress *network.Port, packet []byte) error {
+func (r *stormController) broadcast(ingress *network.Port, packetIn *network.PacketIn, packet []byte) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -66,15 +66,16 @@ func (r *stormController) broadcast(ingress *network.Port, packet []byte) error
 	l := uint(len(bcasts))
 	if l <= r.max {
 		r.broadcasts = bcasts
-		return r.bcaster.flood(ingress, packet)
+		return r.bcaster.flood(ingress, packetIn, packet)
 	}
 	// Only allows r.max broadcasts per 1 second
 	if t.Sub(bcasts[0]) > 1*time.Second {
 		// Shrink (l > r.max)
 		r.broadcasts = bcasts[l-r.max : l]
-		return r.bcaster.flood(ingress, packet)
+		return r.bcaster.flood(ingress, packetIn, packet)
 	}
-	// Deny! r.broadcast should not be updated!
+	// Deny! r.broadcast should not be updated! The packet buffered in the device
+	// is dropped after the event listeners return.
 	if ingress != nil && packet != nil {
 		logger.Infof("too many broadcasts: broadcast is denied to avoid the broadcast storm: ingress=%v, packet=%v", ingress.ID(), spew.Sdump(packet))
 	} else {
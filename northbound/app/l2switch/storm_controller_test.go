@@ -35,14 +35,14 @@ func TestStorm(t *testing.T) {
 	storm := newStormController(max, dummy)
 	fmt.Printf("%v\n", time.Now())
 	for i := uint(0); i < max; i++ {
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(i+1) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", i+1, dummy.getCounter())
 		}
 	}
 	for i := 0; i < 10; i++ {
 		fmt.Printf("%v\n", time.Now())
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(max) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", max, dummy.getCounter())
 		}
@@ -50,7 +50,7 @@ func TestStorm(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	fmt.Printf("%v\n", time.Now())
 	for i := uint(0); i < max-1; i++ {
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(max+i+1) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", max+1, dummy.getCounter())
 		}
@@ -63,7 +63,7 @@ func TestPeriodicBroadcast(t *testing.T) {
 	storm := newStormController(max, dummy)
 	for i := 0; i < 10; i++ {
 		fmt.Printf("Count: %v, Timestamp: %v\n", i, time.Now())
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(i+1) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", i+1, dummy.getCounter())
 		}
@@ -77,11 +77,11 @@ func TestPeriodicStorm(t *testing.T) {
 	storm := newStormController(max, dummy)
 	for i := 0; i < 10; i++ {
 		fmt.Printf("Count: %v, Timestamp: %v\n", i, time.Now())
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(i+1) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", i+1, dummy.getCounter())
 		}
-		storm.broadcast(nil, nil)
+		storm.broadcast(nil, nil, nil)
 		if dummy.getCounter() != uint64(i+1) {
 			t.Fatalf("Unexpected flood counter: expected=%v, got=%v", i+1, dummy.getCounter())
 		}
@@ -93,7 +93,7 @@ type dummyFlooder struct {
 	counter uint64
 }
 
-func (r *dummyFlooder) flood(ingress *network.Port, packet []byte) error {
+func (r *dummyFlooder) flood(ingress *network.Port, packetIn *network.PacketIn, packet []byte) error {
 	r.counter++
 	return nil
 }
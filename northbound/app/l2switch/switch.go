@@ -23,6 +23,7 @@ package l2switch
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"sync"
@@ -47,6 +48,9 @@ type L2Switch struct {
 	stormCtrl *stormController
 	db        Database
 	once      sync.Once
+	flows     *network.Reconciler
+	// Requests for the flow manager to reprogram all the devices.
+	reprogramCh chan struct{}
 }
 
 type Database interface {
@@ -56,19 +60,21 @@ type Database interface {
 
 func New(db Database) *L2Switch {
 	return &L2Switch{
-		stormCtrl: newStormController(100, new(flooder)),
-		db:        db,
+		stormCtrl:   newStormController(100, new(flooder)),
+		db:          db,
+		reprogramCh: make(chan struct{}, 1),
 	}
 }
 
 type flooder struct{}
 
 // flood broadcasts packet to all ports on the ingress device, except the ingress port itself.
-func (r *flooder) flood(ingress *network.Port, packet []byte) error {
-	return ingress.Device().Flood(ingress, packet)
+func (r *flooder) flood(ingress *network.Port, packetIn *network.PacketIn, packet []byte) error {
+	return ingress.Device().FloodPacketIn(ingress, packetIn, packet)
 }
 
 func (r *L2Switch) Init() error {
+	r.flows = network.NewReconciler(r.CookieNamespace())
 	return nil
 }
 
@@ -90,21 +96,51 @@ func (r flowParam) String() string {
 	return fmt.Sprintf("Device=%v, DstMAC=%v, OutPort=%v", r.device.ID(), r.dstMAC, r.outPort)
 }
 
-func (r *L2Switch) setFlow(p flowParam) error {
-	f := p.device.Factory()
-	match, err := f.NewMatch()
+const (
+	// Priority of the L2 switching flows.
+	flowPriority = 10
+	// Hard timeout of the L2 switching flows in seconds. The flows are managed by
+	// the reconciler, so this is only a safety net that removes stale flows that
+	// the reconciler has failed to delete. An expired flow is installed again by
+	// the next PACKET_IN of its packets, or by the next reprogramming.
+	flowHardTimeout = 1800
+	// Overall deadline to reconcile the flows of all devices.
+	reprogramTimeout = 30 * time.Second
+)
+
+func (r flowParam) flow() (network.Flow, error) {
+	match, err := r.device.NewFlowMatch()
 	if err != nil {
-		return err
+		return network.Flow{}, err
 	}
-	match.SetDstMAC(p.dstMAC)
+	match.SetDstMAC(r.dstMAC)
 
 	outPort := openflow.NewOutPort()
-	outPort.SetValue(p.outPort)
+	outPort.SetValue(r.outPort)
+	action, err := r.device.Factory().NewAction()
+	if err != nil {
+		return network.Flow{}, err
+	}
+	action.SetOutPort(outPort)
+
+	return network.Flow{
+		TableID:      r.device.FlowTableID(),
+		Priority:     flowPriority,
+		HardTimeout:  flowHardTimeout,
+		Match:        match,
+		Instructions: []network.FlowInstruction{network.ApplyActions(action)},
+	}, nil
+}
 
-	if err := p.device.SetFlow(match, outPort); err != nil {
+func (r *L2Switch) setFlow(p flowParam) error {
+	flow, err := p.flow()
+	if err != nil {
 		return err
 	}
-	logger.Debugf("installed a new flow rule: %v", p)
+	if err := r.flows.Set(p.device, flow); err != nil {
+		return err
+	}
+	logger.Debugf("set a flow rule: %v", p)
 
 	return nil
 }
@@ -114,6 +150,7 @@ type switchParam struct {
 	ethernet  *protocol.Ethernet
 	ingress   *network.Port
 	egress    *network.Port
+	packetIn  *network.PacketIn
 	rawPacket []byte
 }
 
@@ -129,30 +166,28 @@ func (r *L2Switch) switching(p switchParam) error {
 
 	// Send this ethernet packet directly to the destination node
 	logger.Debugf("sending a packet (Src=%v, Dst=%v) to egress port %v..", p.ethernet.SrcMAC, p.ethernet.DstMAC, p.egress.ID())
-	return r.PacketOut(p.egress, p.rawPacket)
+	return r.ForwardPacketIn(p.ingress, p.egress, p.packetIn, p.rawPacket)
 }
 
-func (r *L2Switch) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet) error {
-	drop, err := r.processPacket(finder, ingress, eth)
+func (r *L2Switch) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn) error {
+	drop, err := r.processPacket(finder, ingress, eth, packetIn)
 	if drop || err != nil {
 		return err
 	}
 
-	return r.BaseProcessor.OnPacketIn(finder, ingress, eth)
+	return r.BaseProcessor.OnPacketIn(finder, ingress, eth, packetIn)
 }
 
-func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet) (drop bool, err error) {
+func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn) (drop bool, err error) {
 	logger.Debugf("PACKET_IN.. Ingress=%v, SrcMAC=%v, DstMAC=%v", ingress.ID(), eth.SrcMAC, eth.DstMAC)
 
-	packet, err := eth.MarshalBinary()
-	if err != nil {
-		return false, err
-	}
+	// Use the raw packet instead of marshaling eth again.
+	packet := packetIn.Data
 
 	// Broadcast?
 	if isBroadcast(eth) {
 		logger.Debugf("broadcasting.. Ingress=%v, SrcMAC=%v, DstMAC=%v, Packet=%v", ingress.ID(), eth.SrcMAC, eth.DstMAC, spew.Sdump(packet))
-		return true, r.stormCtrl.broadcast(ingress, packet)
+		return true, r.stormCtrl.broadcast(ingress, packetIn, packet)
 	}
 
 	logger.Debugf("finding node for %v...", eth.DstMAC)
@@ -164,7 +199,7 @@ func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, e
 		if status == network.LocationUndiscovered {
 			// Broadcast!
 			logger.Debugf("undiscovered node! broadcasting.. SrcMAC=%v, DstMAC=%v", eth.SrcMAC, eth.DstMAC)
-			return true, ingress.Device().Flood(ingress, packet)
+			return true, ingress.Device().FloodPacketIn(ingress, packetIn, packet)
 		} else if status == network.LocationUnregistered {
 			// Drop!
 			logger.Debugf("unknown node! dropping.. SrcMAC=%v, DstMAC=%v", eth.SrcMAC, eth.DstMAC)
@@ -190,6 +225,7 @@ func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, e
 			ethernet:  eth,
 			ingress:   ingress,
 			egress:    dstNode.Port(),
+			packetIn:  packetIn,
 			rawPacket: packet,
 		}
 	} else {
@@ -210,6 +246,7 @@ func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, e
 			ethernet:  eth,
 			ingress:   ingress,
 			egress:    egress,
+			packetIn:  packetIn,
 			rawPacket: packet,
 		}
 	}
@@ -220,31 +257,21 @@ func (r *L2Switch) processPacket(finder network.Finder, ingress *network.Port, e
 func (r *L2Switch) OnTopologyChange(finder network.Finder) error {
 	logger.Debug("OnTopologyChange..")
 
-	// We should remove all edges from all switch devices when the network topology is changed.
-	// Otherwise, installed flow rules in switches may result in incorrect packet routing based on the previous topology.
-	if err := r.removeAllFlows(finder.Devices()); err != nil {
-		return err
+	// We should reprogram all switch devices when the network topology is changed. Otherwise,
+	// installed flow rules in switches may result in incorrect packet routing based on the
+	// previous topology. The flows are replaced atomically on the devices supporting bundles.
+	// Reprogramming queries all the devices, so it is done by the flow manager in background
+	// not to block the event handlers.
+	r.runFlowManager(finder)
+	select {
+	case r.reprogramCh <- struct{}{}:
+	default:
+		// Already requested and not yet started.
 	}
 
 	return r.BaseProcessor.OnTopologyChange(finder)
 }
 
-func (r *L2Switch) removeAllFlows(devices []*network.Device) error {
-	logger.Debug("removing all flows from all devices..")
-
-	for _, d := range devices {
-		if d.IsClosed() {
-			continue
-		}
-		if err := d.RemoveFlows(); err != nil {
-			return err
-		}
-		logger.Debugf("removed all flows from DPID %v", d.ID())
-	}
-
-	return nil
-}
-
 func (r *L2Switch) String() string {
 	return fmt.Sprintf("%v", r.Name())
 }
@@ -262,7 +289,8 @@ func (r *L2Switch) OnPortDown(finder network.Finder, port *network.Port) error {
 	outPort := openflow.NewOutPort()
 	outPort.SetValue(port.Number())
 
-	if err := device.RemoveFlow(match, outPort); err != nil {
+	r.flows.Forget(device, func(flow network.Flow) bool { return headsTo(flow, port.Number()) })
+	if err := r.Flows(device).RemoveFlow(match, outPort); err != nil {
 		return errors.Wrap(err, fmt.Sprintf("removing flows heading to port %v", port.ID()))
 	}
 	logger.Debugf("removed all flows heading to the port %v", port.ID())
@@ -270,57 +298,161 @@ func (r *L2Switch) OnPortDown(finder network.Finder, port *network.Port) error {
 	return r.BaseProcessor.OnPortDown(finder, port)
 }
 
+// headsTo returns whether the flow outputs packets to the port whose number is num.
+func headsTo(flow network.Flow, num uint32) bool {
+	for _, v := range flow.Instructions {
+		if v.Action == nil || !v.Action.HasOutPort() {
+			continue
+		}
+		if port := v.Action.OutPort(); !port.IsNone() && port.Value() == num {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *L2Switch) OnDeviceDown(finder network.Finder, device *network.Device) error {
+	// The intended flows will be rebuilt by the flow manager when the device is up again.
+	r.flows.Flush(device)
+
+	return r.BaseProcessor.OnDeviceDown(finder, device)
+}
+
 func (r *L2Switch) OnDeviceUp(finder network.Finder, device *network.Device) error {
+	r.runFlowManager(finder)
+
+	return r.BaseProcessor.OnDeviceUp(finder, device)
+}
+
+func (r *L2Switch) runFlowManager(finder network.Finder) {
 	// Make sure that there is only one flow manager in this application.
 	r.once.Do(func() {
 		// Run the background flow manager.
 		go r.flowManager(finder)
 	})
-
-	return r.BaseProcessor.OnDeviceUp(finder, device)
 }
 
 func (r *L2Switch) flowManager(finder network.Finder) {
 	logger.Debug("executed flow manager")
 
-	// This interval should be shorter than the flow hard-timeout specified by network.Device.SetFlow().
-	ticker := time.Tick(35 * time.Second)
+	ticker := time.NewTicker(35 * time.Second)
+	defer ticker.Stop()
 	// Infinite loop.
-	for range ticker {
-		mac, err := r.db.MACAddrs()
-		if err != nil {
-			logger.Errorf("failed to get MAC addresses: %v", err)
-			continue
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.reprogramCh:
+		}
+		// Stale flows are left as they are if it fails. They will be fixed by the
+		// next reprogramming, or removed by their hard timeout.
+		if err := r.reprogram(finder); err != nil {
+			logger.Errorf("failed to reprogram the devices: %v", err)
 		}
-		logger.Debugf("got %v MAC addresses", len(mac))
+	}
+}
 
-		for _, addr := range mac {
-			logger.Debugf("modifying the flow for %v...", addr)
-			r.modifyFlows(finder, addr)
+// reprogram makes the intended flows of all devices for the known MAC addresses,
+// and then reconciles the devices with them concurrently within reprogramTimeout.
+func (r *L2Switch) reprogram(finder network.Finder) error {
+	mac, err := r.db.MACAddrs()
+	if err != nil {
+		return errors.Wrap(err, "failed to get MAC addresses")
+	}
+	logger.Debugf("got %v MAC addresses", len(mac))
+
+	// Intended flows of each device. Devices without any flow are also included
+	// to remove their stale flows.
+	flows := make(map[*network.Device][]network.Flow)
+	for _, device := range finder.Devices() {
+		if device.IsClosed() {
+			continue
 		}
+		flows[device] = []network.Flow{}
+	}
+	for _, addr := range mac {
+		for _, p := range r.flowParams(finder, addr) {
+			if _, ok := flows[p.device]; !ok {
+				continue
+			}
+			flow, err := p.flow()
+			if err != nil {
+				logger.Errorf("failed to make the flow for %v on %v: %v", addr, p.device.ID(), err)
+				continue
+			}
+			flows[p.device] = append(flows[p.device], flow)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reprogramTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := 0
+	for device, v := range flows {
+		wg.Add(1)
+		go func(device *network.Device, flows []network.Flow) {
+			defer wg.Done()
+			if err := r.reconcile(ctx, device, flows); err != nil {
+				logger.Errorf("failed to reconcile the flows on %v: %v", device.ID(), err)
+				mutex.Lock()
+				failed++
+				mutex.Unlock()
+			}
+		}(device, v)
 	}
+	wg.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to reconcile %v of %v devices", failed, len(flows))
+	}
+
+	return nil
 }
 
-func (r *L2Switch) modifyFlows(finder network.Finder, mac net.HardwareAddr) {
+// reconcile replaces the intended flows of the device with flows, and then applies
+// the differences between the intended and actual flows to the device.
+func (r *L2Switch) reconcile(ctx context.Context, device *network.Device, flows []network.Flow) error {
+	if err := r.flows.Replace(device, flows); err != nil {
+		return errors.Wrap(err, "replacing the intended flows")
+	}
+
+	diff, err := r.flows.Reconcile(ctx, device)
+	if err != nil {
+		return err
+	}
+	if !diff.Empty() {
+		logger.Infof("reconciled the flows on %v: %v", device.ID(), diff)
+	}
+	for _, v := range diff.Failed {
+		logger.Errorf("failed to reconcile the flow on %v: match=%v, err=%v", device.ID(), v.Flow.Match, v.Err)
+	}
+
+	return nil
+}
+
+// flowParams returns the parameters of the flows for mac on all devices.
+func (r *L2Switch) flowParams(finder network.Finder, mac net.HardwareAddr) []flowParam {
 	// Locate the destination node for the address.
 	node, status, err := finder.Node(mac)
 	if err != nil {
 		logger.Errorf("failed to locate the node %v: %v", mac, err)
-		return
+		return nil
 	}
 	if status != network.LocationDiscovered {
 		logger.Debugf("skip flow management for %v: undiscovered location", mac)
-		return
+		return nil
 	}
 
 	// Disconnected node?
 	port := node.Port().Value()
 	if port.IsPortDown() || port.IsLinkDown() {
 		logger.Debugf("skip flow management for %v: link down", mac)
-		return
+		return nil
 	}
 
-	// Update the flows on all devices.
+	result := make([]flowParam, 0)
 	for _, device := range finder.Devices() {
 		var egress *network.Port
 
@@ -339,14 +471,12 @@ func (r *L2Switch) modifyFlows(finder network.Finder, mac net.HardwareAddr) {
 			egress = path[0][0]
 		}
 
-		flow := flowParam{
+		result = append(result, flowParam{
 			device:  device,
 			dstMAC:  mac,
 			outPort: egress.Number(),
-		}
-		if err := r.setFlow(flow); err != nil {
-			logger.Errorf("failed to modify the flows for %v on %v: %v", mac, device.ID(), err)
-			continue
-		}
+		})
 	}
+
+	return result
 }
@@ -30,7 +30,12 @@ import (
 )
 
 // Processor should prepare to be executed by multiple goroutines simultaneously.
+// The events of a device are passed in order one by one, but the events of
+// different devices, topology changes and flow removals are passed concurrently.
 type Processor interface {
+	// CookieNamespace returns the cookie namespace that is stamped on the flows
+	// installed by this application.
+	CookieNamespace() network.CookieNamespace
 	Dependencies() []string
 	fmt.Stringer
 	Init() error
@@ -38,11 +43,13 @@ type Processor interface {
 	Name() string
 	network.EventListener
 	Next() (next Processor, ok bool)
+	SetCookieNamespace(network.CookieNamespace)
 	SetNext(Processor)
 }
 
 type BaseProcessor struct {
-	next Processor
+	next      Processor
+	namespace network.CookieNamespace
 }
 
 func (r *BaseProcessor) Init() error {
@@ -57,13 +64,13 @@ func (r *BaseProcessor) Dependencies() []string {
 	return []string{}
 }
 
-func (r *BaseProcessor) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet) error {
+func (r *BaseProcessor) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn) error {
 	// Do nothging and execute the next processor if it exists
 	next, ok := r.Next()
 	if !ok {
 		return nil
 	}
-	return next.OnPacketIn(finder, ingress, eth)
+	return next.OnPacketIn(finder, ingress, eth, packetIn)
 }
 
 func (r *BaseProcessor) OnDeviceUp(finder network.Finder, device *network.Device) error {
@@ -120,6 +127,15 @@ func (r *BaseProcessor) OnFlowRemoved(finder network.Finder, flow openflow.FlowR
 	return next.OnFlowRemoved(finder, flow)
 }
 
+func (r *BaseProcessor) OnExperimenter(finder network.Finder, device *network.Device, msg openflow.Header) error {
+	// Do nothging and execute the next processor if it exists
+	next, ok := r.Next()
+	if !ok {
+		return nil
+	}
+	return next.OnExperimenter(finder, device, msg)
+}
+
 func (r *BaseProcessor) Next() (next Processor, ok bool) {
 	if r.next != nil {
 		return r.next, true
@@ -132,15 +148,67 @@ func (r *BaseProcessor) SetNext(next Processor) {
 	r.next = next
 }
 
-func (r *BaseProcessor) PacketOut(egress *network.Port, packet []byte) error {
-	f := egress.Device().Factory()
+func (r *BaseProcessor) CookieNamespace() network.CookieNamespace {
+	return r.namespace
+}
+
+func (r *BaseProcessor) SetCookieNamespace(ns network.CookieNamespace) {
+	r.namespace = ns
+}
 
+// Flows returns the flows of the device that belong to this application. It is a
+// shorthand that passes CookieNamespace() to the flow operations of the device.
+func (r *BaseProcessor) Flows(device *network.Device) *network.AppFlows {
+	return device.AppFlows(r.namespace)
+}
+
+// PacketOut sends the packet to egress.
+func (r *BaseProcessor) PacketOut(egress *network.Port, packet []byte) error {
 	inPort := openflow.NewInPort()
 	inPort.SetController()
 
 	outPort := openflow.NewOutPort()
 	outPort.SetValue(egress.Number())
 
+	return packetOut(egress.Device(), inPort, outPort, openflow.NoBuffer, packet)
+}
+
+// ForwardPacketIn sends the packet received by ingress to egress. If the packet
+// is buffered in the ingress device and egress is on the same device, the device
+// sends the buffered packet and releases its buffer instead of receiving the
+// packet again from the controller. Otherwise, the packet is sent from the
+// controller, which fails if the packet is truncated, and its buffer is released
+// after the event listeners return.
+func (r *BaseProcessor) ForwardPacketIn(ingress, egress *network.Port, packetIn *network.PacketIn, packet []byte) error {
+	bufferID := uint32(openflow.NoBuffer)
+	if packetIn != nil && ingress.Device() == egress.Device() {
+		bufferID = packetIn.TakeBuffer()
+	}
+	if bufferID == openflow.NoBuffer {
+		if packetIn != nil && packetIn.IsTruncated() {
+			return fmt.Errorf("failed to forward the truncated packet: ingress=%v, egress=%v", ingress.ID(), egress.ID())
+		}
+		return r.PacketOut(egress, packet)
+	}
+
+	inPort := openflow.NewInPort()
+	inPort.SetValue(ingress.Number())
+
+	outPort := openflow.NewOutPort()
+	if ingress.Number() == egress.Number() {
+		// Devices do not send a packet to its ingress port unless the output
+		// port is IN_PORT.
+		outPort.SetInPort()
+	} else {
+		outPort.SetValue(egress.Number())
+	}
+
+	return packetOut(egress.Device(), inPort, outPort, bufferID, packet)
+}
+
+func packetOut(device *network.Device, inPort openflow.InPort, outPort openflow.OutPort, bufferID uint32, packet []byte) error {
+	f := device.Factory()
+
 	action, err := f.NewAction()
 	if err != nil {
 		return err
@@ -153,7 +221,8 @@ func (r *BaseProcessor) PacketOut(egress *network.Port, packet []byte) error {
 	}
 	out.SetInPort(inPort)
 	out.SetAction(action)
+	out.SetBufferID(bufferID)
 	out.SetData(packet)
 
-	return egress.Device().SendMessage(out)
+	return device.SendMessage(out)
 }
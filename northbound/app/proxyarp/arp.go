@@ -67,10 +67,10 @@ func (r *ProxyARP) Name() string {
 	return "ProxyARP"
 }
 
-func (r *ProxyARP) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet) error {
+func (r *ProxyARP) OnPacketIn(finder network.Finder, ingress *network.Port, eth *protocol.Ethernet, packetIn *network.PacketIn) error {
 	// ARP?
 	if eth.Type != 0x0806 {
-		return r.BaseProcessor.OnPacketIn(finder, ingress, eth)
+		return r.BaseProcessor.OnPacketIn(finder, ingress, eth, packetIn)
 	}
 
 	logger.Debugf("received ARP packet.. ingress=%v, srcEthMAC=%v, dstEthMAC=%v", ingress.ID(), eth.SrcMAC, eth.DstMAC)
@@ -78,6 +78,13 @@ func NewManager(db *database.MySQL) (*Manager, error) {
 }
 
 func (r *Manager) register(app app.Processor) {
+	ns := network.NewCookieNamespace(app.Name())
+	for _, v := range r.apps {
+		if v.instance.CookieNamespace() == ns {
+			panic(fmt.Sprintf("cookie namespace collision: %v and %v", v.instance.Name(), app.Name()))
+		}
+	}
+	app.SetCookieNamespace(ns)
 	r.apps[strings.ToUpper(app.Name())] = &application{
 		instance: app,
 		enabled:  false,
@@ -23,41 +23,141 @@ package openflow
 
 import (
 	"encoding"
+	"fmt"
 	"net"
 
 	"github.com/pkg/errors"
 )
 
+type ActionType uint8
+
+const (
+	ActionOutput ActionType = iota
+	ActionCopyTTLOut
+	ActionCopyTTLIn
+	ActionSetMPLSTTL
+	ActionDecMPLSTTL
+	ActionPushVLAN
+	ActionPopVLAN
+	ActionPushMPLS
+	ActionPopMPLS
+	ActionSetQueue
+	ActionGroup
+	ActionSetNwTTL
+	ActionDecNwTTL
+	ActionSetField
+	ActionPushPBB
+	ActionPopPBB
+)
+
+// Action is a set of the actions that are applied to a packet. The actions are
+// encoded in the order of the OpenFlow action set regardless of the order that
+// they are set: copy TTL inwards, pop, push MPLS, push VLAN, copy TTL outwards,
+// decrement TTL, set-field, set queue, group, experimenter actions in the order
+// that they are added, and then output.
 type Action interface {
+	// AddExperimenterAction appends an experimenter action, such as Nicira's resubmit.
+	AddExperimenterAction(action ExperimenterAction)
+	CopyTTLIn() bool
+	CopyTTLOut() bool
+	DecNwTTL() bool
+	DSCP() (ok bool, dscp uint8)
+	DstIP() (ok bool, ip net.IP)
 	DstMAC() (ok bool, mac net.HardwareAddr)
+	ECN() (ok bool, ecn uint8)
 	encoding.BinaryMarshaler
+	ExperimenterActions() []ExperimenterAction
 	encoding.BinaryUnmarshaler
-	Queue() (ok bool, queue uint32)
 	// Error() returns last error message
 	Error() error
+	Group() (ok bool, id uint32)
+	// HasOutPort returns whether the output port has been set.
+	HasOutPort() bool
 	OutPort() OutPort
+	// PopMPLS returns the Ethernet type of the packet after popping the outermost MPLS tag.
+	PopMPLS() (ok bool, etherType uint16)
+	PopVLAN() bool
+	PushMPLS() (ok bool, etherType uint16)
+	PushVLAN() (ok bool, etherType uint16)
+	Queue() (ok bool, queue uint32)
+	SetCopyTTLIn(copy bool)
+	SetCopyTTLOut(copy bool)
+	SetDecNwTTL(dec bool)
+	SetDSCP(dscp uint8)
+	SetDstIP(ip net.IP)
 	SetDstMAC(mac net.HardwareAddr)
-	SetQueue(queue uint32)
+	SetECN(ecn uint8)
+	SetGroup(id uint32)
 	SetOutPort(port OutPort)
+	SetPopMPLS(etherType uint16)
+	SetPopVLAN(pop bool)
+	SetPushMPLS(etherType uint16)
+	SetPushVLAN(etherType uint16)
+	SetQueue(queue uint32)
+	SetSrcIP(ip net.IP)
 	SetSrcMAC(mac net.HardwareAddr)
+	SetTCPDstPort(port uint16)
+	SetTCPSrcPort(port uint16)
+	SetTunnelID(id uint64)
+	SetUDPDstPort(port uint16)
+	SetUDPSrcPort(port uint16)
 	SetVLANID(vid uint16)
+	SetVLANPriority(pcp uint8)
+	SrcIP() (ok bool, ip net.IP)
 	SrcMAC() (ok bool, mac net.HardwareAddr)
+	TCPDstPort() (ok bool, port uint16)
+	TCPSrcPort() (ok bool, port uint16)
+	TunnelID() (ok bool, id uint64)
+	UDPDstPort() (ok bool, port uint16)
+	UDPSrcPort() (ok bool, port uint16)
 	VLANID() (ok bool, vid uint16)
+	VLANPriority() (ok bool, pcp uint8)
 }
 
 type BaseAction struct {
-	err    error
-	output OutPort
-	srcMAC *net.HardwareAddr
-	dstMAC *net.HardwareAddr
-	queue  int64
-	vlanID int32
+	err          error
+	output       OutPort
+	hasOutput    bool
+	srcMAC       *net.HardwareAddr
+	dstMAC       *net.HardwareAddr
+	queue        int64
+	vlanID       int32
+	vlanPriority int16
+	srcIP        net.IP
+	dstIP        net.IP
+	dscp         int16
+	ecn          int16
+	tcpSrcPort   int32
+	tcpDstPort   int32
+	udpSrcPort   int32
+	udpDstPort   int32
+	tunnelID     *uint64
+	pushVLAN     int32
+	popVLAN      bool
+	pushMPLS     int32
+	popMPLS      int32
+	copyTTLIn    bool
+	copyTTLOut   bool
+	decNwTTL     bool
+	group        int64
+	experimenter []ExperimenterAction
 }
 
 func NewBaseAction() *BaseAction {
 	return &BaseAction{
-		queue:  -1,
-		vlanID: -1,
+		queue:        -1,
+		vlanID:       -1,
+		vlanPriority: -1,
+		dscp:         -1,
+		ecn:          -1,
+		tcpSrcPort:   -1,
+		tcpDstPort:   -1,
+		udpSrcPort:   -1,
+		udpDstPort:   -1,
+		pushVLAN:     -1,
+		pushMPLS:     -1,
+		popMPLS:      -1,
+		group:        -1,
 	}
 }
 
@@ -87,6 +187,11 @@ func (r *BaseAction) SetQueue(queue uint32) {
 
 func (r *BaseAction) SetOutPort(port OutPort) {
 	r.output = port
+	r.hasOutput = true
+}
+
+func (r *BaseAction) HasOutPort() bool {
+	return r.hasOutput
 }
 
 func (r *BaseAction) OutPort() OutPort {
@@ -130,3 +235,251 @@ func (r *BaseAction) DstMAC() (ok bool, mac net.HardwareAddr) {
 func (r *BaseAction) Error() error {
 	return r.err
 }
+
+func (r *BaseAction) VLANPriority() (ok bool, pcp uint8) {
+	if r.vlanPriority == -1 {
+		return false, 0
+	}
+
+	return true, uint8(r.vlanPriority)
+}
+
+func (r *BaseAction) SetVLANPriority(pcp uint8) {
+	if pcp > 7 {
+		r.err = fmt.Errorf("SetVLANPriority: invalid VLAN priority: %v", pcp)
+		return
+	}
+
+	r.vlanPriority = int16(pcp)
+}
+
+func (r *BaseAction) SrcIP() (ok bool, ip net.IP) {
+	if r.srcIP == nil {
+		return false, net.IPv4zero
+	}
+
+	return true, r.srcIP
+}
+
+func (r *BaseAction) SetSrcIP(ip net.IP) {
+	if ip == nil || ip.To4() == nil {
+		r.err = errors.Wrap(ErrInvalidIPAddress, "SetSrcIP")
+		return
+	}
+
+	r.srcIP = ip.To4()
+}
+
+func (r *BaseAction) DstIP() (ok bool, ip net.IP) {
+	if r.dstIP == nil {
+		return false, net.IPv4zero
+	}
+
+	return true, r.dstIP
+}
+
+func (r *BaseAction) SetDstIP(ip net.IP) {
+	if ip == nil || ip.To4() == nil {
+		r.err = errors.Wrap(ErrInvalidIPAddress, "SetDstIP")
+		return
+	}
+
+	r.dstIP = ip.To4()
+}
+
+func (r *BaseAction) DSCP() (ok bool, dscp uint8) {
+	if r.dscp == -1 {
+		return false, 0
+	}
+
+	return true, uint8(r.dscp)
+}
+
+func (r *BaseAction) SetDSCP(dscp uint8) {
+	if dscp > 63 {
+		r.err = fmt.Errorf("SetDSCP: invalid DSCP value: %v", dscp)
+		return
+	}
+
+	r.dscp = int16(dscp)
+}
+
+func (r *BaseAction) ECN() (ok bool, ecn uint8) {
+	if r.ecn == -1 {
+		return false, 0
+	}
+
+	return true, uint8(r.ecn)
+}
+
+func (r *BaseAction) SetECN(ecn uint8) {
+	if ecn > 3 {
+		r.err = fmt.Errorf("SetECN: invalid ECN value: %v", ecn)
+		return
+	}
+
+	r.ecn = int16(ecn)
+}
+
+func (r *BaseAction) TCPSrcPort() (ok bool, port uint16) {
+	if r.tcpSrcPort == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.tcpSrcPort)
+}
+
+func (r *BaseAction) SetTCPSrcPort(port uint16) {
+	r.tcpSrcPort = int32(port)
+}
+
+func (r *BaseAction) TCPDstPort() (ok bool, port uint16) {
+	if r.tcpDstPort == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.tcpDstPort)
+}
+
+func (r *BaseAction) SetTCPDstPort(port uint16) {
+	r.tcpDstPort = int32(port)
+}
+
+func (r *BaseAction) UDPSrcPort() (ok bool, port uint16) {
+	if r.udpSrcPort == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.udpSrcPort)
+}
+
+func (r *BaseAction) SetUDPSrcPort(port uint16) {
+	r.udpSrcPort = int32(port)
+}
+
+func (r *BaseAction) UDPDstPort() (ok bool, port uint16) {
+	if r.udpDstPort == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.udpDstPort)
+}
+
+func (r *BaseAction) SetUDPDstPort(port uint16) {
+	r.udpDstPort = int32(port)
+}
+
+func (r *BaseAction) TunnelID() (ok bool, id uint64) {
+	if r.tunnelID == nil {
+		return false, 0
+	}
+
+	return true, *r.tunnelID
+}
+
+func (r *BaseAction) SetTunnelID(id uint64) {
+	r.tunnelID = &id
+}
+
+func (r *BaseAction) PushVLAN() (ok bool, etherType uint16) {
+	if r.pushVLAN == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.pushVLAN)
+}
+
+func (r *BaseAction) SetPushVLAN(etherType uint16) {
+	// 802.1Q or 802.1ad
+	if etherType != 0x8100 && etherType != 0x88A8 {
+		r.err = fmt.Errorf("SetPushVLAN: invalid VLAN Ethernet type: %#x", etherType)
+		return
+	}
+
+	r.pushVLAN = int32(etherType)
+}
+
+func (r *BaseAction) PopVLAN() bool {
+	return r.popVLAN
+}
+
+func (r *BaseAction) SetPopVLAN(pop bool) {
+	r.popVLAN = pop
+}
+
+func (r *BaseAction) PushMPLS() (ok bool, etherType uint16) {
+	if r.pushMPLS == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.pushMPLS)
+}
+
+func (r *BaseAction) SetPushMPLS(etherType uint16) {
+	// MPLS unicast or multicast
+	if etherType != 0x8847 && etherType != 0x8848 {
+		r.err = fmt.Errorf("SetPushMPLS: invalid MPLS Ethernet type: %#x", etherType)
+		return
+	}
+
+	r.pushMPLS = int32(etherType)
+}
+
+func (r *BaseAction) PopMPLS() (ok bool, etherType uint16) {
+	if r.popMPLS == -1 {
+		return false, 0
+	}
+
+	return true, uint16(r.popMPLS)
+}
+
+func (r *BaseAction) SetPopMPLS(etherType uint16) {
+	r.popMPLS = int32(etherType)
+}
+
+func (r *BaseAction) CopyTTLIn() bool {
+	return r.copyTTLIn
+}
+
+func (r *BaseAction) SetCopyTTLIn(copy bool) {
+	r.copyTTLIn = copy
+}
+
+func (r *BaseAction) CopyTTLOut() bool {
+	return r.copyTTLOut
+}
+
+func (r *BaseAction) SetCopyTTLOut(copy bool) {
+	r.copyTTLOut = copy
+}
+
+func (r *BaseAction) DecNwTTL() bool {
+	return r.decNwTTL
+}
+
+func (r *BaseAction) SetDecNwTTL(dec bool) {
+	r.decNwTTL = dec
+}
+
+func (r *BaseAction) Group() (ok bool, id uint32) {
+	if r.group == -1 {
+		return false, 0
+	}
+
+	return true, uint32(r.group)
+}
+
+func (r *BaseAction) SetGroup(id uint32) {
+	r.group = int64(id)
+}
+
+func (r *BaseAction) AddExperimenterAction(action ExperimenterAction) {
+	if action == nil {
+		panic("action is nil")
+	}
+	r.experimenter = append(r.experimenter, action)
+}
+
+func (r *BaseAction) ExperimenterActions() []ExperimenterAction {
+	return r.experimenter
+}
@@ -0,0 +1,80 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type PacketInReason uint8
+
+const (
+	// No matching flow (table-miss flow entry).
+	PacketInNoMatch PacketInReason = iota
+	// Action explicitly output to controller.
+	PacketInAction
+	// Packet has invalid TTL.
+	PacketInInvalidTTL
+)
+
+type FlowRemovedReason uint8
+
+const (
+	// Flow idle time exceeded idle_timeout.
+	FlowRemovedIdleTimeout FlowRemovedReason = iota
+	// Time exceeded hard_timeout.
+	FlowRemovedHardTimeout
+	// Evicted by a DELETE flow mod.
+	FlowRemovedDelete
+	// Group was removed.
+	FlowRemovedGroupDelete
+)
+
+// AsyncConfig represents the asynchronous messages that a controller wants to
+// receive on its connection for each role. RoleMaster and RoleEqual share the
+// same configuration.
+type AsyncConfig interface {
+	Error() error
+	FlowRemovedReasons(role ControllerRole) []FlowRemovedReason
+	PacketInReasons(role ControllerRole) []PacketInReason
+	PortStatusReasons(role ControllerRole) []PortReason
+	SetFlowRemovedReasons(role ControllerRole, reasons ...FlowRemovedReason)
+	SetPacketInReasons(role ControllerRole, reasons ...PacketInReason)
+	SetPortStatusReasons(role ControllerRole, reasons ...PortReason)
+}
+
+type SetAsync interface {
+	Header
+	AsyncConfig
+	encoding.BinaryMarshaler
+}
+
+type GetAsyncRequest interface {
+	Header
+	encoding.BinaryMarshaler
+}
+
+type GetAsyncReply interface {
+	Header
+	AsyncConfig
+	encoding.BinaryUnmarshaler
+}
@@ -0,0 +1,95 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type BundleCtrlType uint8
+
+const (
+	BundleOpen BundleCtrlType = iota
+	BundleClose
+	BundleCommit
+	BundleDiscard
+)
+
+func (r BundleCtrlType) String() string {
+	switch r {
+	case BundleOpen:
+		return "OPEN"
+	case BundleClose:
+		return "CLOSE"
+	case BundleCommit:
+		return "COMMIT"
+	case BundleDiscard:
+		return "DISCARD"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type BundleFlag uint16
+
+const (
+	// Execute atomically: all the messages in the bundle are applied, or none of them.
+	BundleAtomic BundleFlag = 1 << iota
+	// Execute in the order in which the messages are added to the bundle.
+	BundleOrdered
+)
+
+// BundleControl opens, closes, commits or discards a bundle. The switch replies
+// to it with a BundleControl whose IsReply returns true.
+type BundleControl interface {
+	Header
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	BundleID() uint32
+	SetBundleID(id uint32)
+	ControlType() BundleCtrlType
+	SetControlType(t BundleCtrlType)
+	Flags() BundleFlag
+	SetFlags(flags BundleFlag)
+	IsReply() bool
+}
+
+// BundleMessage is a message that can be added to a bundle.
+type BundleMessage interface {
+	Header
+	encoding.BinaryMarshaler
+}
+
+// BundleAdd adds a message to a bundle. The transaction ID of the message is
+// replaced with the one of the BundleAdd so that the errors caused by the message
+// can be matched with the BundleAdd.
+type BundleAdd interface {
+	Header
+	encoding.BinaryMarshaler
+	Error() error
+	BundleID() uint32
+	SetBundleID(id uint32)
+	Flags() BundleFlag
+	SetFlags(flags BundleFlag)
+	BundledMessage() BundleMessage
+	SetBundledMessage(msg BundleMessage)
+}
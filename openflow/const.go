@@ -24,4 +24,5 @@ package openflow
 const (
 	OF10_VERSION = 0x01
 	OF13_VERSION = 0x04
+	OF14_VERSION = 0x05
 )
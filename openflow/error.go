@@ -24,6 +24,7 @@ package openflow
 import (
 	"encoding"
 	"encoding/binary"
+	"fmt"
 )
 
 type Error interface {
@@ -31,9 +32,123 @@ type Error interface {
 	Class() uint16 // Error type
 	Code() uint16
 	Data() []byte
+	// Cause returns the error type and code decoded into their names.
+	Cause() *ErrorCause
+	// Request parses the request message that caused this error from Data. The
+	// data may contain only the first 64 bytes of the request, so the returned
+	// message is a FlowMod or PacketOut only if the whole request is included.
+	// Otherwise, it is a Message that has the header and the truncated payload.
+	Request() (Header, error)
 	encoding.BinaryUnmarshaler
 }
 
+// ErrorCode is a name of an error code defined in the OpenFlow specification,
+// such as OFPBAC_BAD_OUT_PORT, and its description.
+type ErrorCode struct {
+	Name        string
+	Description string
+}
+
+// ErrorType is a name of an error type defined in the OpenFlow specification,
+// such as OFPET_BAD_ACTION, and the error codes of the type.
+type ErrorType struct {
+	Name        string
+	Description string
+	Codes       map[uint16]ErrorCode
+}
+
+// ErrorCause is an OpenFlow error decoded using the error types of a protocol
+// version. The names are empty if the type or code is unknown.
+type ErrorCause struct {
+	Class     uint16
+	Code      uint16
+	ClassName string
+	CodeName  string
+	// Description is the description of the code, or the type if the code is unknown.
+	Description string
+}
+
+// NewErrorCause decodes the error class and code using types.
+func NewErrorCause(types map[uint16]ErrorType, class, code uint16) *ErrorCause {
+	v := &ErrorCause{Class: class, Code: code}
+	t, ok := types[class]
+	if !ok {
+		return v
+	}
+	v.ClassName = t.Name
+	v.Description = t.Description
+	if c, ok := t.Codes[code]; ok {
+		v.CodeName = c.Name
+		v.Description = c.Description
+	}
+
+	return v
+}
+
+func (r *ErrorCause) Error() string {
+	class := r.ClassName
+	if class == "" {
+		class = fmt.Sprintf("UNKNOWN_TYPE(%v)", r.Class)
+	}
+	code := r.CodeName
+	if code == "" {
+		code = fmt.Sprintf("UNKNOWN_CODE(%v)", r.Code)
+	}
+	if r.Description == "" {
+		return fmt.Sprintf("%v: %v", class, code)
+	}
+
+	return fmt.Sprintf("%v: %v (%v)", class, code, r.Description)
+}
+
+// FindErrorCause returns the ErrorCause that err is or wraps, following the
+// chain of errors that have a Cause or Unwrap method.
+func FindErrorCause(err error) (cause *ErrorCause, ok bool) {
+	for err != nil {
+		switch v := err.(type) {
+		case *ErrorCause:
+			return v, true
+		case interface{ Cause() error }:
+			err = v.Cause()
+		case interface{ Unwrap() error }:
+			err = v.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// IsErrorCode returns whether err is or wraps an ErrorCause whose code name is
+// name, such as OFPFMFC_OVERLAP. The names of the same error are the same
+// across protocol versions even if their numbers are different.
+func IsErrorCode(err error, name string) bool {
+	cause, ok := FindErrorCause(err)
+	if !ok {
+		return false
+	}
+
+	return cause.CodeName == name
+}
+
+// ParseRequestHeader parses the header of a request message included in an error
+// message, which may be truncated. The returned message has the remaining data as
+// its payload.
+func ParseRequestHeader(data []byte) (*Message, error) {
+	if len(data) < 8 {
+		return nil, ErrInvalidPacketLength
+	}
+	if length := int(binary.BigEndian.Uint16(data[2:4])); length >= 8 && length < len(data) {
+		data = data[:length]
+	}
+
+	msg := NewMessage(data[0], data[1], binary.BigEndian.Uint32(data[4:8]))
+	msg.SetPayload(data[8:])
+
+	return &msg, nil
+}
+
 type BaseError struct {
 	Message
 	class uint16
@@ -0,0 +1,130 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+	"encoding/binary"
+)
+
+// Experimenter is an experimenter message (vendor message in OpenFlow 1.0). The
+// body of an OpenFlow 1.0 vendor message does not have the experimenter type,
+// but most vendors, including Nicira, begin the body with their 32-bit subtype
+// that is used as the experimenter type.
+type Experimenter interface {
+	Header
+	ExperimenterID() uint32
+	SetExperimenterID(id uint32)
+	ExperimenterType() uint32
+	SetExperimenterType(t uint32)
+	// Data returns the experimenter-defined data following the experimenter type.
+	Data() []byte
+	SetData(data []byte)
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+type BaseExperimenter struct {
+	Message
+	id      uint32
+	expType uint32
+	data    []byte
+}
+
+func NewExperimenter(version, msgType uint8, xid uint32) *BaseExperimenter {
+	return &BaseExperimenter{
+		Message: NewMessage(version, msgType, xid),
+	}
+}
+
+func (r *BaseExperimenter) ExperimenterID() uint32 {
+	return r.id
+}
+
+func (r *BaseExperimenter) SetExperimenterID(id uint32) {
+	r.id = id
+}
+
+func (r *BaseExperimenter) ExperimenterType() uint32 {
+	return r.expType
+}
+
+func (r *BaseExperimenter) SetExperimenterType(t uint32) {
+	r.expType = t
+}
+
+func (r *BaseExperimenter) Data() []byte {
+	return r.data
+}
+
+func (r *BaseExperimenter) SetData(data []byte) {
+	r.data = data
+}
+
+func (r *BaseExperimenter) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint32(v[0:4], r.id)
+	binary.BigEndian.PutUint32(v[4:8], r.expType)
+	v = append(v, r.data...)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+func (r *BaseExperimenter) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return ErrInvalidPacketLength
+	}
+	r.id = binary.BigEndian.Uint32(payload[0:4])
+	r.expType = binary.BigEndian.Uint32(payload[4:8])
+	r.data = payload[8:]
+
+	return nil
+}
+
+// ExperimenterAction is an experimenter action (vendor action in OpenFlow 1.0)
+// whose binary form is the whole action including the action header.
+type ExperimenterAction interface {
+	ExperimenterID() uint32
+	encoding.BinaryMarshaler
+}
+
+// RawExperimenterAction is an experimenter action that has been read from a
+// device as it is.
+type RawExperimenterAction []byte
+
+func (r RawExperimenterAction) ExperimenterID() uint32 {
+	if len(r) < 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(r[4:8])
+}
+
+func (r RawExperimenterAction) MarshalBinary() ([]byte, error) {
+	return r, nil
+}
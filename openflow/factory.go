@@ -43,31 +43,69 @@ var (
 type Factory interface {
 	ProtocolVersion() uint8
 	NewAction() (Action, error)
+	NewAggregateStatsRequest() (AggregateStatsRequest, error)
+	NewAggregateStatsReply() (AggregateStatsReply, error)
 	NewBarrierRequest() (BarrierRequest, error)
 	NewBarrierReply() (BarrierReply, error)
+	NewBucket() (Bucket, error)
+	NewBundleAdd() (BundleAdd, error)
+	NewBundleControl() (BundleControl, error)
 	NewDescRequest() (DescRequest, error)
 	NewDescReply() (DescReply, error)
 	NewEchoRequest() (EchoRequest, error)
 	NewEchoReply() (EchoReply, error)
 	NewError() (Error, error)
+	NewExperimenter() (Experimenter, error)
 	NewFeaturesRequest() (FeaturesRequest, error)
 	NewFeaturesReply() (FeaturesReply, error)
 	NewFlowMod(cmd FlowModCmd) (FlowMod, error)
+	NewFlowMonitorRequest() (FlowMonitorRequest, error)
+	NewFlowMonitorReply() (FlowMonitorReply, error)
 	NewFlowRemoved() (FlowRemoved, error)
 	NewFlowStatsRequest() (FlowStatsRequest, error)
-	// TODO: NewFlowStatsReply() (FlowStatsReply, error)
+	NewFlowStatsReply() (FlowStatsReply, error)
+	NewGetAsyncRequest() (GetAsyncRequest, error)
+	NewGetAsyncReply() (GetAsyncReply, error)
 	NewGetConfigRequest() (GetConfigRequest, error)
 	NewGetConfigReply() (GetConfigReply, error)
+	NewGroupDescRequest() (GroupDescRequest, error)
+	NewGroupDescReply() (GroupDescReply, error)
+	NewGroupFeaturesRequest() (GroupFeaturesRequest, error)
+	NewGroupFeaturesReply() (GroupFeaturesReply, error)
+	NewGroupMod(cmd GroupModCmd) (GroupMod, error)
+	NewGroupStatsRequest() (GroupStatsRequest, error)
+	NewGroupStatsReply() (GroupStatsReply, error)
 	NewHello() (Hello, error)
 	NewInstruction() (Instruction, error)
 	NewMatch() (Match, error)
+	NewMeterBand() (MeterBand, error)
+	NewMeterConfigRequest() (MeterConfigRequest, error)
+	NewMeterConfigReply() (MeterConfigReply, error)
+	NewMeterFeaturesRequest() (MeterFeaturesRequest, error)
+	NewMeterFeaturesReply() (MeterFeaturesReply, error)
+	NewMeterMod(cmd MeterModCmd) (MeterMod, error)
+	NewMeterStatsRequest() (MeterStatsRequest, error)
+	NewMeterStatsReply() (MeterStatsReply, error)
 	NewPacketIn() (PacketIn, error)
 	NewPacketOut() (PacketOut, error)
 	NewPortDescRequest() (PortDescRequest, error)
 	NewPortDescReply() (PortDescReply, error)
+	NewPortMod() (PortMod, error)
+	NewPortStatsRequest() (PortStatsRequest, error)
+	NewPortStatsReply() (PortStatsReply, error)
 	NewPortStatus() (PortStatus, error)
 	NewQueueGetConfigRequest() (QueueGetConfigRequest, error)
+	NewQueueStatsRequest() (QueueStatsRequest, error)
+	NewQueueStatsReply() (QueueStatsReply, error)
+	NewRoleRequest() (RoleRequest, error)
+	NewRoleReply() (RoleReply, error)
+	NewSetAsync() (SetAsync, error)
 	NewSetConfig() (SetConfig, error)
 	NewTableFeaturesRequest() (TableFeaturesRequest, error)
-	// TODO: NewTableFeaturesReply() (TableFeaturesReply, error)
+	NewTableFeaturesReply() (TableFeaturesReply, error)
+	NewTableStatsRequest() (TableStatsRequest, error)
+	NewTableStatsReply() (TableStatsReply, error)
+	// NewTransactionID returns a new transaction ID that has not been used by
+	// the messages made by this factory.
+	NewTransactionID() uint32
 }
@@ -31,6 +31,10 @@ const (
 	FlowAdd FlowModCmd = iota
 	FlowModify
 	FlowDelete
+	// FlowModifyStrict modifies the flow entry whose match and priority are exactly same.
+	FlowModifyStrict
+	// FlowDeleteStrict deletes the flow entry whose match and priority are exactly same.
+	FlowDeleteStrict
 )
 
 type FlowMod interface {
@@ -0,0 +1,123 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type FlowMonitorCmd uint8
+
+const (
+	// New flow monitor.
+	FlowMonitorAdd FlowMonitorCmd = iota
+	// Modify existing flow monitor.
+	FlowMonitorModify
+	// Delete/cancel existing flow monitor.
+	FlowMonitorDelete
+)
+
+type FlowMonitorFlag uint16
+
+const (
+	// Initially matching flows.
+	FlowMonitorInitial FlowMonitorFlag = 1 << iota
+	// New matching flows as they are added.
+	FlowMonitorAdded
+	// Old matching flows as they are removed.
+	FlowMonitorRemoved
+	// Matching flows as they are changed.
+	FlowMonitorModified
+	// If set, instructions are included.
+	FlowMonitorInstructions
+	// If set, include own changes in full.
+	FlowMonitorNoAbbrev
+	// If set, don't include other controllers.
+	FlowMonitorOnlyOwn
+)
+
+type FlowMonitorRequest interface {
+	Header
+	encoding.BinaryMarshaler
+	Error() error
+	MonitorID() uint32
+	SetMonitorID(id uint32)
+	Command() FlowMonitorCmd
+	SetCommand(cmd FlowMonitorCmd)
+	Flags() FlowMonitorFlag
+	SetFlags(flags FlowMonitorFlag)
+	// 0xFF means all table
+	TableID() uint8
+	SetTableID(id uint8)
+	// OutPort returns false if the request does not filter the flows by the output port.
+	OutPort() (ok bool, port uint32)
+	SetOutPort(port uint32)
+	Match() Match
+	SetMatch(match Match)
+}
+
+type FlowUpdateEvent uint16
+
+const (
+	// Flow present when the flow monitor is created.
+	FlowUpdateInitial FlowUpdateEvent = iota
+	// Flow was added.
+	FlowUpdateAdded
+	// Flow was removed.
+	FlowUpdateRemoved
+	// Flow instructions were changed.
+	FlowUpdateModified
+	// Abbreviated reply for the change made by this controller.
+	FlowUpdateAbbrev
+	// Monitoring paused because the switch is out of buffer space.
+	FlowUpdatePaused
+	// Monitoring resumed.
+	FlowUpdateResumed
+)
+
+// FlowUpdate describes a change of the flow entries monitored by a flow monitor.
+// The flow fields are only valid for the FlowUpdateInitial, FlowUpdateAdded,
+// FlowUpdateRemoved and FlowUpdateModified events.
+type FlowUpdate interface {
+	Event() FlowUpdateEvent
+	TableID() uint8
+	// Reason returns the OFPRR_* reason of the FlowUpdateRemoved event.
+	Reason() uint8
+	IdleTimeout() uint16
+	HardTimeout() uint16
+	Priority() uint16
+	Cookie() uint64
+	Match() Match
+	// Instruction returns nil if the update does not include the instructions.
+	Instruction() Instruction
+	// TransactionID returns the transaction ID of the message that caused the
+	// change. It is only valid for the FlowUpdateAbbrev event.
+	TransactionID() uint32
+}
+
+type FlowMonitorReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Updates() []FlowUpdate
+	encoding.BinaryUnmarshaler
+}
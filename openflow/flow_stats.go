@@ -40,4 +40,26 @@ type FlowStatsRequest interface {
 	TableID() uint8
 }
 
-// TODO: Implement FlowStatsReply
+type FlowStats interface {
+	ByteCount() uint64
+	Cookie() uint64
+	DurationNanoSec() uint32
+	DurationSec() uint32
+	HardTimeout() uint16
+	IdleTimeout() uint16
+	// Instruction returns nil if the flow entry has no instructions (or actions),
+	// which means the flow entry drops all the matched packets.
+	Instruction() Instruction
+	Match() Match
+	PacketCount() uint64
+	Priority() uint16
+	TableID() uint8
+}
+
+type FlowStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []FlowStats
+	encoding.BinaryUnmarshaler
+}
@@ -0,0 +1,152 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type GroupType uint8
+
+const (
+	// All buckets are executed (multicast and broadcast).
+	GroupTypeAll GroupType = iota
+	// One bucket is selected by a switch-computed algorithm (multipath).
+	GroupTypeSelect
+	// Single bucket group.
+	GroupTypeIndirect
+	// The first live bucket is executed (fast failover).
+	GroupTypeFastFailover
+)
+
+type GroupModCmd uint8
+
+const (
+	GroupAdd GroupModCmd = iota
+	GroupModify
+	GroupDelete
+)
+
+const (
+	// AllGroups represents all the groups in group stats requests and group delete commands.
+	AllGroups uint32 = 0xfffffffc
+)
+
+type Bucket interface {
+	Action() Action
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	// Error() returns last error message
+	Error() error
+	SetAction(action Action)
+	// SetWatchGroup sets the group whose liveness affects this bucket. It is only used by fast failover groups.
+	SetWatchGroup(group uint32)
+	// SetWatchPort sets the port whose liveness affects this bucket. It is only used by fast failover groups.
+	SetWatchPort(port uint32)
+	// SetWeight sets the relative weight of this bucket. It is only used by select groups.
+	SetWeight(weight uint16)
+	WatchGroup() (ok bool, group uint32)
+	WatchPort() (ok bool, port uint32)
+	Weight() uint16
+}
+
+type GroupMod interface {
+	AddBucket(bucket Bucket)
+	Buckets() []Bucket
+	encoding.BinaryMarshaler
+	// Error() returns last error message
+	Error() error
+	GroupID() uint32
+	GroupType() GroupType
+	Header
+	SetGroupID(id uint32)
+	SetGroupType(t GroupType)
+}
+
+type GroupStatsRequest interface {
+	encoding.BinaryMarshaler
+	GroupID() uint32
+	Header
+	// AllGroups means all groups
+	SetGroupID(id uint32)
+}
+
+type BucketCounter struct {
+	PacketCount uint64
+	ByteCount   uint64
+}
+
+type GroupStats interface {
+	GroupID() uint32
+	// RefCount returns the number of flows or groups that directly forward to this group
+	RefCount() uint32
+	PacketCount() uint64
+	ByteCount() uint64
+	DurationSec() uint32
+	DurationNanoSec() uint32
+	BucketCounters() []BucketCounter
+}
+
+type GroupStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []GroupStats
+	encoding.BinaryUnmarshaler
+}
+
+type GroupDescRequest interface {
+	Header
+	encoding.BinaryMarshaler
+}
+
+type GroupDesc interface {
+	GroupType() GroupType
+	GroupID() uint32
+	Buckets() []Bucket
+}
+
+type GroupDescReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Groups() []GroupDesc
+	encoding.BinaryUnmarshaler
+}
+
+type GroupFeaturesRequest interface {
+	Header
+	encoding.BinaryMarshaler
+}
+
+type GroupFeaturesReply interface {
+	Header
+	// IsSupported returns whether the switch supports the group type t
+	IsSupported(t GroupType) bool
+	// Capabilities returns bitmap of OFPGFC_* capability
+	Capabilities() uint32
+	// MaxGroups returns maximum number of groups for the group type t
+	MaxGroups(t GroupType) uint32
+	// Actions returns bitmap of actions that are supported by the group type t
+	Actions(t GroupType) uint32
+	encoding.BinaryUnmarshaler
+}
@@ -23,6 +23,12 @@ package openflow
 
 import (
 	"encoding"
+	"encoding/binary"
+)
+
+const (
+	// Hello element type of the bitmap of the supported versions.
+	helloElemVersionBitmap = 1
 )
 
 type Hello interface {
@@ -42,3 +48,66 @@ func (r *BaseHello) MarshalBinary() ([]byte, error) {
 func (r *BaseHello) UnmarshalBinary(data []byte) error {
 	return r.Message.UnmarshalBinary(data)
 }
+
+// MarshalVersionBitmap returns the version bitmap hello element that advertises
+// the versions.
+func MarshalVersionBitmap(versions ...uint8) []byte {
+	var max uint8
+	for _, v := range versions {
+		if v > max {
+			max = v
+		}
+	}
+	nBitmaps := int(max)/32 + 1
+	length := 4 + nBitmaps*4
+
+	// Hello elements are padded to make it 64-bit aligned.
+	v := make([]byte, (length+7)/8*8)
+	binary.BigEndian.PutUint16(v[0:2], helloElemVersionBitmap)
+	binary.BigEndian.PutUint16(v[2:4], uint16(length))
+	for _, version := range versions {
+		i := 4 + int(version)/32*4
+		bitmap := binary.BigEndian.Uint32(v[i:i+4]) | 1<<(version%32)
+		binary.BigEndian.PutUint32(v[i:i+4], bitmap)
+	}
+
+	return v
+}
+
+// ParseVersionBitmap returns the versions advertised by the version bitmap hello
+// element in the payload of a HELLO message. ok is false if the payload does
+// not have the version bitmap.
+func ParseVersionBitmap(payload []byte) (versions []uint8, ok bool) {
+	for len(payload) >= 4 {
+		elemType := binary.BigEndian.Uint16(payload[0:2])
+		length := int(binary.BigEndian.Uint16(payload[2:4]))
+		if length < 4 || length > len(payload) {
+			return nil, false
+		}
+
+		if elemType == helloElemVersionBitmap {
+			versions = make([]uint8, 0)
+			for i := 4; i+4 <= length; i += 4 {
+				bitmap := binary.BigEndian.Uint32(payload[i : i+4])
+				for bit := uint(0); bit < 32; bit++ {
+					if bitmap&(1<<bit) == 0 {
+						continue
+					}
+					if v := (i-4)/4*32 + int(bit); v <= 0xff {
+						versions = append(versions, uint8(v))
+					}
+				}
+			}
+			return versions, true
+		}
+
+		// Skip the padding to the next element.
+		next := (length + 7) / 8 * 8
+		if next > len(payload) {
+			break
+		}
+		payload = payload[next:]
+	}
+
+	return nil, false
+}
@@ -25,10 +25,32 @@ import (
 	"encoding"
 )
 
+type InstructionType uint8
+
+const (
+	InstructionGotoTable InstructionType = iota
+	InstructionWriteMetadata
+	InstructionWriteActions
+	InstructionApplyActions
+	InstructionClearActions
+	InstructionMeter
+)
+
+// Instruction is a set of instructions attached to a flow entry. Each setter
+// adds the instruction of its type to the set, replacing the previous one of the
+// same type, so that a flow can write actions, write metadata and go to the next
+// table at the same time.
 type Instruction interface {
 	ApplyAction(act Action)
+	// ClearActions clears all the actions in the action set immediately.
+	ClearActions()
 	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
 	Error() error
 	GotoTable(tableID uint8)
+	// Meter applies the meter (rate limiter) whose ID is meterID before other instructions
+	Meter(meterID uint32)
 	WriteAction(act Action)
+	// WriteMetadata updates the bits of the metadata selected by mask with metadata.
+	WriteMetadata(metadata, mask uint64)
 }
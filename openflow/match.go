@@ -26,47 +26,158 @@ import (
 	"net"
 )
 
+// MatchField is a header field that can be matched or set. The values are same
+// with the OpenFlow basic class OXM field numbers.
+type MatchField uint8
+
+const (
+	MatchFieldInPort MatchField = iota
+	MatchFieldInPhyPort
+	MatchFieldMetadata
+	MatchFieldEthDst
+	MatchFieldEthSrc
+	MatchFieldEthType
+	MatchFieldVLANID
+	MatchFieldVLANPriority
+	MatchFieldIPDSCP
+	MatchFieldIPECN
+	MatchFieldIPProtocol
+	MatchFieldIPv4Src
+	MatchFieldIPv4Dst
+	MatchFieldTCPSrc
+	MatchFieldTCPDst
+	MatchFieldUDPSrc
+	MatchFieldUDPDst
+	MatchFieldSCTPSrc
+	MatchFieldSCTPDst
+	MatchFieldICMPv4Type
+	MatchFieldICMPv4Code
+	MatchFieldARPOp
+	MatchFieldARPSPA
+	MatchFieldARPTPA
+	MatchFieldARPSHA
+	MatchFieldARPTHA
+	MatchFieldIPv6Src
+	MatchFieldIPv6Dst
+	MatchFieldIPv6FlowLabel
+	MatchFieldICMPv6Type
+	MatchFieldICMPv6Code
+	MatchFieldIPv6NDTarget
+	MatchFieldIPv6NDSLL
+	MatchFieldIPv6NDTLL
+	MatchFieldMPLSLabel
+	MatchFieldMPLSTC
+	MatchFieldMPLSBOS
+	MatchFieldPBBISID
+	MatchFieldTunnelID
+	MatchFieldIPv6ExtHeader
+)
+
+// Match represents a flow match. The IP address, port and ICMP fields are mapped
+// to the protocol specific fields according to the Ethernet type and the IP
+// protocol, so they should be set after setting the Ethernet type and the IP protocol.
 type Match interface {
+	ARPOp() (wildcard bool, op uint16)
+	ARPSHA() (wildcard bool, mac net.HardwareAddr)
+	ARPSPA() *net.IPNet
+	ARPTHA() (wildcard bool, mac net.HardwareAddr)
+	ARPTPA() *net.IPNet
+	// DstIP returns IPv4 or IPv6 destination address
 	DstIP() *net.IPNet
 	DstMAC() (wildcard bool, mac net.HardwareAddr)
-	// DstPort returns protocol (TCP or UDP) destination port number
+	// DstPort returns protocol (TCP, UDP or SCTP) destination port number
 	DstPort() (wildcard bool, port uint16)
 	encoding.BinaryMarshaler
 	encoding.BinaryUnmarshaler
 	Error() error
 	EtherType() (wildcard bool, etherType uint16)
+	// ICMPCode returns ICMPv4 or ICMPv6 code
+	ICMPCode() (wildcard bool, code uint8)
+	// ICMPType returns ICMPv4 or ICMPv6 type
+	ICMPType() (wildcard bool, t uint8)
 	// InPort returns switch port number
 	InPort() (wildcard bool, inport InPort)
+	IPDSCP() (wildcard bool, dscp uint8)
+	IPECN() (wildcard bool, ecn uint8)
 	IPProtocol() (wildcard bool, protocol uint8)
+	IPv6FlowLabel() (wildcard bool, label uint32)
+	IPv6NDSLL() (wildcard bool, mac net.HardwareAddr)
+	IPv6NDTarget() (wildcard bool, ip net.IP)
+	IPv6NDTLL() (wildcard bool, mac net.HardwareAddr)
+	Metadata() (wildcard bool, metadata uint64, mask uint64)
+	// Register returns the Nicira extension register reg0-reg7 of Open vSwitch
+	Register(index uint8) (wildcard bool, value uint32, mask uint32)
+	SetARPOp(op uint16)
+	SetARPSHA(mac net.HardwareAddr)
+	SetARPSPA(ip *net.IPNet)
+	SetARPTHA(mac net.HardwareAddr)
+	SetARPTPA(ip *net.IPNet)
+	// SetDstIP sets IPv4 or IPv6 destination address according to the Ethernet type
 	SetDstIP(ip *net.IPNet)
 	SetDstMAC(mac net.HardwareAddr)
-	// SetDstPort sets protocol (TCP or UDP) destination port number
+	// SetDstPort sets protocol (TCP, UDP or SCTP) destination port number
 	SetDstPort(p uint16)
 	SetEtherType(t uint16)
+	// SetICMPCode sets ICMPv4 or ICMPv6 code according to the Ethernet type
+	SetICMPCode(code uint8)
+	// SetICMPType sets ICMPv4 or ICMPv6 type according to the Ethernet type
+	SetICMPType(t uint8)
 	// SetInPort sets switch port number
 	SetInPort(port InPort)
+	SetIPDSCP(dscp uint8)
+	SetIPECN(ecn uint8)
 	SetIPProtocol(p uint8)
+	SetIPv6FlowLabel(label uint32)
+	// SetIPv6NDSLL sets the source link-layer address option in neighbor solicitations
+	SetIPv6NDSLL(mac net.HardwareAddr)
+	// SetIPv6NDTarget sets the target address in neighbor solicitations and advertisements
+	SetIPv6NDTarget(ip net.IP)
+	// SetIPv6NDTLL sets the target link-layer address option in neighbor advertisements
+	SetIPv6NDTLL(mac net.HardwareAddr)
+	// SetMetadata sets the metadata bits that are one in the mask
+	SetMetadata(metadata uint64, mask uint64)
+	// SetRegister sets the register bits that are one in the mask
+	SetRegister(index uint8, value uint32, mask uint32)
+	// SetSrcIP sets IPv4 or IPv6 source address according to the Ethernet type
 	SetSrcIP(ip *net.IPNet)
 	SetSrcMAC(mac net.HardwareAddr)
-	// SetSrcPort sets protocol (TCP or UDP) source port number
+	// SetSrcPort sets protocol (TCP, UDP or SCTP) source port number
 	SetSrcPort(p uint16)
 	SetVLANID(id uint16)
 	SetVLANPriority(p uint8)
+	SetWildcardARPOp()
+	SetWildcardARPSHA()
+	SetWildcardARPSPA()
+	SetWildcardARPTHA()
+	SetWildcardARPTPA()
+	SetWildcardDstIP()
 	SetWildcardEtherType()
 	SetWildcardDstMAC()
-	// SetWildcardDstPort sets protocol (TCP or UDP) destination port number as a wildcard
+	// SetWildcardDstPort sets protocol (TCP, UDP or SCTP) destination port number as a wildcard
 	SetWildcardDstPort()
+	SetWildcardICMPCode()
+	SetWildcardICMPType()
+	SetWildcardIPDSCP()
+	SetWildcardIPECN()
+	SetWildcardIPv6FlowLabel()
+	SetWildcardIPv6NDSLL()
+	SetWildcardIPv6NDTarget()
+	SetWildcardIPv6NDTLL()
+	SetWildcardMetadata()
+	SetWildcardRegister(index uint8)
+	SetWildcardSrcIP()
 	SetWildcardSrcMAC()
-	// SetWildcardSrcPort sets protocol (TCP or UDP) source port number as a wildcard
+	// SetWildcardSrcPort sets protocol (TCP, UDP or SCTP) source port number as a wildcard
 	SetWildcardSrcPort()
 	// SetWildcardInPort sets switch port number as a wildcard
 	SetWildcardInPort()
 	SetWildcardIPProtocol()
 	SetWildcardVLANID()
 	SetWildcardVLANPriority()
+	// SrcIP returns IPv4 or IPv6 source address
 	SrcIP() *net.IPNet
 	SrcMAC() (wildcard bool, mac net.HardwareAddr)
-	// SrcPort returns protocol (TCP or UDP) source port number
+	// SrcPort returns protocol (TCP, UDP or SCTP) source port number
 	SrcPort() (wildcard bool, port uint16)
 	VLANID() (wildcard bool, vlanID uint16)
 	VLANPriority() (wildcard bool, priority uint8)
@@ -53,6 +53,13 @@ func (r *Message) Version() uint8 {
 	return r.version
 }
 
+// SetVersion changes the protocol version of this message. It is used to reuse
+// the message implementations of an older protocol version whose wire format
+// has not been changed.
+func (r *Message) SetVersion(version uint8) {
+	r.version = version
+}
+
 func (r *Message) Type() uint8 {
 	return r.msgType
 }
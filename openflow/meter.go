@@ -0,0 +1,158 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type MeterModCmd uint8
+
+const (
+	MeterAdd MeterModCmd = iota
+	MeterModify
+	MeterDelete
+)
+
+type MeterFlag uint16
+
+const (
+	// Rate value in kb/s (kilo-bit per second).
+	MeterFlagKbps MeterFlag = 1 << iota
+	// Rate value in packet/sec.
+	MeterFlagPktps
+	// Do burst size.
+	MeterFlagBurst
+	// Collect statistics.
+	MeterFlagStats
+)
+
+const (
+	// AllMeters represents all the meters in meter stats requests and meter delete commands.
+	AllMeters uint32 = 0xffffffff
+)
+
+type MeterBandType uint8
+
+const (
+	// Drop packets exceeding the band rate.
+	MeterBandDrop MeterBandType = iota
+	// Remark DSCP in the IP header of packets exceeding the band rate.
+	MeterBandDSCPRemark
+)
+
+type MeterBand interface {
+	BandType() MeterBandType
+	BurstSize() uint32
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	// PrecLevel returns number of precedence levels to subtract. It is only used by DSCP remark bands.
+	PrecLevel() uint8
+	Rate() uint32
+	SetBandType(t MeterBandType)
+	SetBurstSize(size uint32)
+	SetPrecLevel(level uint8)
+	SetRate(rate uint32)
+}
+
+type Meter interface {
+	Bands() []MeterBand
+	Flags() MeterFlag
+	MeterID() uint32
+}
+
+type MeterMod interface {
+	Meter
+	AddBand(band MeterBand)
+	encoding.BinaryMarshaler
+	// Error() returns last error message
+	Error() error
+	Header
+	SetFlags(flags MeterFlag)
+	SetMeterID(id uint32)
+}
+
+type MeterStatsRequest interface {
+	encoding.BinaryMarshaler
+	Header
+	MeterID() uint32
+	// AllMeters means all meters
+	SetMeterID(id uint32)
+}
+
+type BandCounter struct {
+	PacketCount uint64
+	ByteCount   uint64
+}
+
+type MeterStats interface {
+	MeterID() uint32
+	// FlowCount returns the number of flows bound to this meter
+	FlowCount() uint32
+	PacketInCount() uint64
+	ByteInCount() uint64
+	DurationSec() uint32
+	DurationNanoSec() uint32
+	BandCounters() []BandCounter
+}
+
+type MeterStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []MeterStats
+	encoding.BinaryUnmarshaler
+}
+
+type MeterConfigRequest interface {
+	encoding.BinaryMarshaler
+	Header
+	MeterID() uint32
+	// AllMeters means all meters
+	SetMeterID(id uint32)
+}
+
+type MeterConfigReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Meters() []Meter
+	encoding.BinaryUnmarshaler
+}
+
+type MeterFeaturesRequest interface {
+	Header
+	encoding.BinaryMarshaler
+}
+
+type MeterFeaturesReply interface {
+	Header
+	MaxMeter() uint32
+	// IsSupported returns whether the switch supports the meter band type t
+	IsSupported(t MeterBandType) bool
+	Capabilities() MeterFlag
+	// MaxBands returns maximum number of bands per meter
+	MaxBands() uint8
+	// MaxColor returns maximum color value
+	MaxColor() uint8
+	encoding.BinaryUnmarshaler
+}
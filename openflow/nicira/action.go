@@ -0,0 +1,311 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package nicira
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// OFPAT_EXPERIMENTER (OFPAT_VENDOR in OpenFlow 1.0).
+const ofpatExperimenter = 0xffff
+
+// marshalHeader returns the Nicira action header followed by zeros whose total
+// length is length.
+func marshalHeader(subtype uint16, length int) []byte {
+	v := make([]byte, length)
+	binary.BigEndian.PutUint16(v[0:2], ofpatExperimenter)
+	binary.BigEndian.PutUint16(v[2:4], uint16(length))
+	binary.BigEndian.PutUint32(v[4:8], NX_VENDOR_ID)
+	binary.BigEndian.PutUint16(v[8:10], subtype)
+
+	return v
+}
+
+// pad appends zeros to v to make its length a multiple of 8, and then updates
+// the length of the action header.
+func pad(v []byte) []byte {
+	if rem := len(v) % 8; rem > 0 {
+		v = append(v, bytes.Repeat([]byte{0}, 8-rem)...)
+	}
+	binary.BigEndian.PutUint16(v[2:4], uint16(len(v)))
+
+	return v
+}
+
+// Resubmit searches the table for a flow entry as if the packet has been received
+// on the InPort, and then executes the actions of the flow entry (NXAST_RESUBMIT_TABLE).
+type Resubmit struct {
+	// InPort is OFPP_IN_PORT to use the ingress port of the packet.
+	InPort uint16
+	// Table is NX_RESUBMIT_CURRENT_TABLE to search the current table.
+	Table uint8
+}
+
+// NewResubmit returns a Resubmit that searches the table using the ingress port.
+func NewResubmit(table uint8) *Resubmit {
+	return &Resubmit{InPort: OFPP_IN_PORT, Table: table}
+}
+
+func (r *Resubmit) ExperimenterID() uint32 {
+	return NX_VENDOR_ID
+}
+
+func (r *Resubmit) MarshalBinary() ([]byte, error) {
+	v := marshalHeader(NXAST_RESUBMIT_TABLE, 16)
+	binary.BigEndian.PutUint16(v[10:12], r.InPort)
+	v[12] = r.Table
+	// v[13:16] is padding
+
+	return v, nil
+}
+
+// RegLoad loads the Value into the bits from Offset to Offset+NBits-1 of the Dst
+// field, e.g., a register returned by NXMRegister (NXAST_REG_LOAD).
+type RegLoad struct {
+	Dst    uint32
+	Offset uint16
+	NBits  uint16
+	Value  uint64
+}
+
+func (r *RegLoad) ExperimenterID() uint32 {
+	return NX_VENDOR_ID
+}
+
+func (r *RegLoad) MarshalBinary() ([]byte, error) {
+	if err := checkBits(r.Dst, r.Offset, r.NBits); err != nil {
+		return nil, err
+	}
+	if r.NBits < 64 && r.Value>>r.NBits != 0 {
+		return nil, fmt.Errorf("value %#x exceeds %v bits", r.Value, r.NBits)
+	}
+
+	v := marshalHeader(NXAST_REG_LOAD, 24)
+	binary.BigEndian.PutUint16(v[10:12], ofsNBits(r.Offset, r.NBits))
+	binary.BigEndian.PutUint32(v[12:16], r.Dst)
+	binary.BigEndian.PutUint64(v[16:24], r.Value)
+
+	return v, nil
+}
+
+// RegMove copies NBits bits from the SrcOffset of the Src field to the DstOffset
+// of the Dst field (NXAST_REG_MOVE).
+type RegMove struct {
+	Src       uint32
+	SrcOffset uint16
+	Dst       uint32
+	DstOffset uint16
+	NBits     uint16
+}
+
+func (r *RegMove) ExperimenterID() uint32 {
+	return NX_VENDOR_ID
+}
+
+func (r *RegMove) MarshalBinary() ([]byte, error) {
+	if err := checkBits(r.Src, r.SrcOffset, r.NBits); err != nil {
+		return nil, err
+	}
+	if err := checkBits(r.Dst, r.DstOffset, r.NBits); err != nil {
+		return nil, err
+	}
+
+	v := marshalHeader(NXAST_REG_MOVE, 24)
+	binary.BigEndian.PutUint16(v[10:12], r.NBits)
+	binary.BigEndian.PutUint16(v[12:14], r.SrcOffset)
+	binary.BigEndian.PutUint16(v[14:16], r.DstOffset)
+	binary.BigEndian.PutUint32(v[16:20], r.Src)
+	binary.BigEndian.PutUint32(v[20:24], r.Dst)
+
+	return v, nil
+}
+
+// Conntrack sends the packet through the connection tracker (NXAST_CT).
+type Conntrack struct {
+	// Flags is a bitwise OR of NX_CT_F_*.
+	Flags uint16
+	// Zone is the connection tracking zone.
+	Zone uint16
+	// RecircTable is the table that the packet is resubmitted to after the connection
+	// tracking, or NX_CT_RECIRC_NONE not to resubmit the packet.
+	RecircTable uint8
+	// Alg is the application layer gateway, e.g., 21 for FTP, or zero.
+	Alg uint16
+	// Exec is the actions that are applied to the connection if it is committed,
+	// e.g., a RegLoad to NXM_NX_CT_MARK.
+	Exec []openflow.ExperimenterAction
+}
+
+// NewConntrack returns a Conntrack of the zone that resubmits the packet to
+// the table after the connection tracking.
+func NewConntrack(zone uint16, table uint8) *Conntrack {
+	return &Conntrack{Zone: zone, RecircTable: table}
+}
+
+func (r *Conntrack) ExperimenterID() uint32 {
+	return NX_VENDOR_ID
+}
+
+func (r *Conntrack) MarshalBinary() ([]byte, error) {
+	if len(r.Exec) > 0 && r.Flags&NX_CT_F_COMMIT == 0 {
+		return nil, errors.New("conntrack actions are only allowed with the commit flag")
+	}
+
+	v := marshalHeader(NXAST_CT, 24)
+	binary.BigEndian.PutUint16(v[10:12], r.Flags)
+	// v[12:16] is zone_src that is zero to use the immediate zone value.
+	binary.BigEndian.PutUint16(v[16:18], r.Zone)
+	v[18] = r.RecircTable
+	// v[19:22] is padding
+	binary.BigEndian.PutUint16(v[22:24], r.Alg)
+	for _, a := range r.Exec {
+		action, err := a.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, action...)
+	}
+
+	return pad(v), nil
+}
+
+// LearnDst is the destination of a LearnSpec.
+type LearnDst uint16
+
+const (
+	// LearnMatch adds a match criterion to the learned flow.
+	LearnMatch LearnDst = NX_LEARN_DST_MATCH
+	// LearnLoad adds a RegLoad action to the learned flow.
+	LearnLoad LearnDst = NX_LEARN_DST_LOAD
+	// LearnOutput adds an output action to the learned flow.
+	LearnOutput LearnDst = NX_LEARN_DST_OUTPUT
+)
+
+// LearnSpec is a flow_mod_spec that copies NBits bits of a source field or an
+// immediate value of the packet into the match or actions of the learned flow.
+type LearnSpec struct {
+	NBits uint16
+	// SrcValue is the immediate value whose length should be 2*ceil(NBits/16)
+	// bytes. SrcField and SrcOffset are used if it is nil.
+	SrcValue  []byte
+	SrcField  uint32
+	SrcOffset uint16
+	Dst       LearnDst
+	// DstField and DstOffset are not used if Dst is LearnOutput.
+	DstField  uint32
+	DstOffset uint16
+}
+
+func (r *LearnSpec) MarshalBinary() ([]byte, error) {
+	if r.NBits == 0 || r.NBits > NX_LEARN_N_BITS_MASK {
+		return nil, fmt.Errorf("invalid number of bits of a learn spec: %v", r.NBits)
+	}
+
+	v := make([]byte, 2)
+	header := uint16(r.Dst) | r.NBits
+	if r.SrcValue != nil {
+		if len(r.SrcValue) != int(r.NBits+15)/16*2 {
+			return nil, fmt.Errorf("invalid length of the immediate value: %v", len(r.SrcValue))
+		}
+		header |= NX_LEARN_SRC_IMMEDIATE
+		v = append(v, r.SrcValue...)
+	} else {
+		if err := checkBits(r.SrcField, r.SrcOffset, r.NBits); err != nil {
+			return nil, err
+		}
+		v = append(v, marshalField(r.SrcField, r.SrcOffset)...)
+	}
+	binary.BigEndian.PutUint16(v[0:2], header)
+
+	switch r.Dst {
+	case LearnMatch, LearnLoad:
+		if err := checkBits(r.DstField, r.DstOffset, r.NBits); err != nil {
+			return nil, err
+		}
+		v = append(v, marshalField(r.DstField, r.DstOffset)...)
+	case LearnOutput:
+		// No destination field.
+	default:
+		return nil, fmt.Errorf("invalid destination of a learn spec: %v", r.Dst)
+	}
+
+	return v, nil
+}
+
+func marshalField(h uint32, ofs uint16) []byte {
+	v := make([]byte, 6)
+	binary.BigEndian.PutUint32(v[0:4], h)
+	binary.BigEndian.PutUint16(v[4:6], ofs)
+
+	return v
+}
+
+// Learn adds or modifies a flow in the Table whose match and actions are made
+// from the packet according to the Specs (NXAST_LEARN).
+type Learn struct {
+	IdleTimeout uint16
+	HardTimeout uint16
+	Priority    uint16
+	Cookie      uint64
+	// Flags is a bitwise OR of NX_LEARN_F_*.
+	Flags          uint16
+	Table          uint8
+	FinIdleTimeout uint16
+	FinHardTimeout uint16
+	Specs          []LearnSpec
+}
+
+func (r *Learn) ExperimenterID() uint32 {
+	return NX_VENDOR_ID
+}
+
+func (r *Learn) MarshalBinary() ([]byte, error) {
+	if len(r.Specs) == 0 {
+		return nil, errors.New("empty learn specs")
+	}
+
+	v := marshalHeader(NXAST_LEARN, 32)
+	binary.BigEndian.PutUint16(v[10:12], r.IdleTimeout)
+	binary.BigEndian.PutUint16(v[12:14], r.HardTimeout)
+	binary.BigEndian.PutUint16(v[14:16], r.Priority)
+	binary.BigEndian.PutUint64(v[16:24], r.Cookie)
+	binary.BigEndian.PutUint16(v[24:26], r.Flags)
+	v[26] = r.Table
+	// v[27] is padding
+	binary.BigEndian.PutUint16(v[28:30], r.FinIdleTimeout)
+	binary.BigEndian.PutUint16(v[30:32], r.FinHardTimeout)
+	for i := range r.Specs {
+		spec, err := r.Specs[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, spec...)
+	}
+
+	// The padding also terminates the specs with a zero header.
+	return pad(v), nil
+}
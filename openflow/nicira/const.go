@@ -0,0 +1,115 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+// Package nicira implements the Nicira extensions (NX) of Open vSwitch.
+package nicira
+
+const (
+	NX_VENDOR_ID = 0x00002320 /* Nicira. */
+)
+
+// Nicira extension message subtypes.
+const (
+	NXT_SET_PACKET_IN_FORMAT = 16 /* Set the format of PACKET_IN messages. */
+	NXT_PACKET_IN            = 17 /* Nicira Packet In. */
+	NXT_RESUME               = 28 /* Resume a paused pipeline. */
+	NXT_CT_FLUSH_ZONE        = 29 /* Flush the conntrack entries of a zone. */
+	NXT_PACKET_IN2           = 30 /* Extensible Packet In. */
+)
+
+// Formats of PACKET_IN messages for NXT_SET_PACKET_IN_FORMAT.
+const (
+	NXPIF_STANDARD       = 0 /* OFPT_PACKET_IN for this OpenFlow version. */
+	NXPIF_NXT_PACKET_IN  = 1 /* NXT_PACKET_IN (since OVS v1.1). */
+	NXPIF_NXT_PACKET_IN2 = 2 /* NXT_PACKET_IN2 (since OVS v2.6). */
+)
+
+// Property types of NXT_PACKET_IN2.
+const (
+	NXPINT_PACKET       = 0 /* Raw packet data. */
+	NXPINT_FULL_LEN     = 1 /* ovs_be32: Full packet len, if truncated. */
+	NXPINT_BUFFER_ID    = 2 /* ovs_be32: Buffer ID, if buffered. */
+	NXPINT_TABLE_ID     = 3 /* uint8_t: Table ID. */
+	NXPINT_COOKIE       = 4 /* ovs_be64: Flow cookie. */
+	NXPINT_REASON       = 5 /* uint8_t, one of OFPR_*. */
+	NXPINT_METADATA     = 6 /* NXM or OXM for metadata fields. */
+	NXPINT_USERDATA     = 7 /* From NXAST_CONTROLLER2 userdata. */
+	NXPINT_CONTINUATION = 8 /* Private data for continuing processing. */
+)
+
+// Nicira extension action subtypes.
+const (
+	NXAST_RESUBMIT       = 1  /* struct nx_action_resubmit */
+	NXAST_REG_MOVE       = 6  /* struct nx_action_reg_move */
+	NXAST_REG_LOAD       = 7  /* struct nx_action_reg_load */
+	NXAST_RESUBMIT_TABLE = 14 /* struct nx_action_resubmit */
+	NXAST_LEARN          = 16 /* struct nx_action_learn */
+	NXAST_CT             = 35 /* struct nx_action_conntrack */
+)
+
+const (
+	// OFPP_IN_PORT is the OpenFlow 1.0 port number that the Nicira actions use
+	// to represent the ingress port regardless of the protocol version.
+	OFPP_IN_PORT = 0xfff8
+	// NX_RESUBMIT_CURRENT_TABLE resubmits to the current table.
+	NX_RESUBMIT_CURRENT_TABLE = 0xff
+)
+
+// Flags and values of NXAST_CT.
+const (
+	NX_CT_F_COMMIT    = 1 << 0 /* Commit the connection to the connection tracking table. */
+	NX_CT_F_FORCE     = 1 << 1 /* Terminate the existing connection of the opposite direction before committing. */
+	NX_CT_RECIRC_NONE = 0xff   /* Do not recirculate the packet. */
+)
+
+// Flags of NXAST_LEARN.
+const (
+	NX_LEARN_F_SEND_FLOW_REM  = 1 << 0 /* Set OFPFF_SEND_FLOW_REM on the learned flow. */
+	NX_LEARN_F_DELETE_LEARNED = 1 << 1 /* Delete the learned flows when the learning flow is deleted. */
+	NX_LEARN_F_WRITE_RESULT   = 1 << 2 /* Write the result of learning to a field. */
+)
+
+// Header bits of the flow_mod_spec of NXAST_LEARN.
+const (
+	NX_LEARN_N_BITS_MASK   = 0x3ff
+	NX_LEARN_SRC_FIELD     = 0 << 13 /* Copy from field. */
+	NX_LEARN_SRC_IMMEDIATE = 1 << 13 /* Copy from immediate value. */
+	NX_LEARN_DST_MATCH     = 0 << 11 /* Add match criterion. */
+	NX_LEARN_DST_LOAD      = 1 << 11 /* Add NXAST_REG_LOAD action. */
+	NX_LEARN_DST_OUTPUT    = 2 << 11 /* Add OFPAT_OUTPUT action. */
+)
+
+// Headers of the NXM fields that are used by the Nicira actions. A header has
+// the class, field number, has-mask bit, and length of a field.
+const (
+	NXM_OF_IN_PORT  = 0x0000<<16 | 0<<9 | 2
+	NXM_OF_ETH_DST  = 0x0000<<16 | 1<<9 | 6
+	NXM_OF_ETH_SRC  = 0x0000<<16 | 2<<9 | 6
+	NXM_OF_ETH_TYPE = 0x0000<<16 | 3<<9 | 2
+	NXM_OF_VLAN_TCI = 0x0000<<16 | 4<<9 | 2
+	NXM_OF_IP_SRC   = 0x0000<<16 | 7<<9 | 4
+	NXM_OF_IP_DST   = 0x0000<<16 | 8<<9 | 4
+	NXM_NX_TUN_ID   = 0x0001<<16 | 16<<9 | 8
+	NXM_NX_CT_STATE = 0x0001<<16 | 105<<9 | 4
+	NXM_NX_CT_ZONE  = 0x0001<<16 | 106<<9 | 2
+	NXM_NX_CT_MARK  = 0x0001<<16 | 107<<9 | 4
+	NXM_NX_MAX_REGS = 8
+)
@@ -0,0 +1,328 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package nicira
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/transceiver"
+)
+
+// Manufacturer description of Open vSwitch in DESC_REPLY.
+const ovsManufacturer = "Nicira, Inc."
+
+// Codec decodes the Nicira extension messages. It should be registered using
+// transceiver.RegisterExperimenter with NX_VENDOR_ID.
+type Codec struct{}
+
+func NewCodec() *Codec {
+	return &Codec{}
+}
+
+// Decode decodes NXT_PACKET_IN2 into PacketIn2. The other messages are returned
+// as they are.
+func (r *Codec) Decode(f openflow.Factory, msg openflow.Experimenter) (openflow.Header, error) {
+	if msg.ExperimenterID() != NX_VENDOR_ID {
+		return nil, fmt.Errorf("unexpected experimenter ID: %#x", msg.ExperimenterID())
+	}
+
+	switch msg.ExperimenterType() {
+	case NXT_PACKET_IN2:
+		v := new(PacketIn2)
+		if err := v.decode(msg); err != nil {
+			return nil, err
+		}
+		if f != nil {
+			match, err := v.newMatch(f)
+			if err != nil {
+				return nil, err
+			}
+			v.match = match
+		}
+		return v, nil
+	default:
+		return msg, nil
+	}
+}
+
+// Init makes Open vSwitch send NXT_PACKET_IN2 instead of the standard PACKET_IN,
+// which OVS does not send until it is requested. The other devices are left as
+// they are. OVS before v2.6 rejects the request with an ERROR message, and keeps
+// sending the standard PACKET_IN.
+func (r *Codec) Init(f openflow.Factory, w transceiver.Writer, desc openflow.DescReply) error {
+	if desc.Manufacturer() != ovsManufacturer {
+		return nil
+	}
+
+	msg, err := NewSetPacketInFormat(f, NXPIF_NXT_PACKET_IN2)
+	if err != nil {
+		return err
+	}
+
+	return w.Write(msg)
+}
+
+// NewSetPacketInFormat returns a NXT_SET_PACKET_IN_FORMAT message that makes
+// the device send PACKET_IN messages in the format, one of NXPIF_*.
+func NewSetPacketInFormat(f openflow.Factory, format uint32) (openflow.Experimenter, error) {
+	msg, err := f.NewExperimenter()
+	if err != nil {
+		return nil, err
+	}
+	msg.SetExperimenterID(NX_VENDOR_ID)
+	msg.SetExperimenterType(NXT_SET_PACKET_IN_FORMAT)
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint32(v, format)
+	msg.SetData(v)
+
+	return msg, nil
+}
+
+// NewCTFlushZone returns a NXT_CT_FLUSH_ZONE message that flushes all the
+// connection tracking entries of the zone.
+func NewCTFlushZone(f openflow.Factory, zone uint16) (openflow.Experimenter, error) {
+	msg, err := f.NewExperimenter()
+	if err != nil {
+		return nil, err
+	}
+	msg.SetExperimenterID(NX_VENDOR_ID)
+	msg.SetExperimenterType(NXT_CT_FLUSH_ZONE)
+	// 6 bytes of padding follow the zone.
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], zone)
+	msg.SetData(v)
+
+	return msg, nil
+}
+
+// PacketIn2 is an extensible PACKET_IN message (NXT_PACKET_IN2) of Open vSwitch.
+// It implements openflow.PacketIn so that it can be handled as a standard one.
+type PacketIn2 struct {
+	openflow.Message
+	packet    []byte
+	fullLen   uint32
+	bufferID  uint32
+	tableID   uint8
+	cookie    uint64
+	reason    uint8
+	inPort    uint32
+	registers [NXM_NX_MAX_REGS]uint32
+	// Bitmap of the registers that are reported by the device.
+	hasRegisters uint8
+	match        openflow.Match
+	userData     []byte
+	continuation []byte
+}
+
+func (r *PacketIn2) BufferID() uint32 {
+	return r.bufferID
+}
+
+// Length returns the full length of the packet that may be truncated.
+func (r *PacketIn2) Length() uint16 {
+	return uint16(r.fullLen)
+}
+
+func (r *PacketIn2) InPort() uint32 {
+	return r.inPort
+}
+
+func (r *PacketIn2) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *PacketIn2) Reason() uint8 {
+	return r.reason
+}
+
+func (r *PacketIn2) Cookie() uint64 {
+	return r.cookie
+}
+
+func (r *PacketIn2) Data() []byte {
+	return r.packet
+}
+
+// Register returns the value of the register reg0-reg7 when the packet is sent
+// to the controller.
+func (r *PacketIn2) Register(index uint8) uint32 {
+	if index >= NXM_NX_MAX_REGS {
+		return 0
+	}
+
+	return r.registers[index]
+}
+
+// Match returns the ingress port and the registers of the packet. It returns nil
+// if the message is not decoded by Codec with a factory.
+func (r *PacketIn2) Match() openflow.Match {
+	return r.match
+}
+
+func (r *PacketIn2) newMatch(f openflow.Factory) (openflow.Match, error) {
+	match, err := f.NewMatch()
+	if err != nil {
+		return nil, err
+	}
+	port := openflow.NewInPort()
+	port.SetValue(r.inPort)
+	match.SetInPort(port)
+	// OpenFlow 1.0 does not support the register match.
+	if f.ProtocolVersion() == openflow.OF10_VERSION {
+		return match, nil
+	}
+	for i := uint8(0); i < NXM_NX_MAX_REGS; i++ {
+		if r.hasRegisters&(1<<i) == 0 {
+			continue
+		}
+		match.SetRegister(i, r.registers[i], 0xFFFFFFFF)
+	}
+
+	return match, nil
+}
+
+// UserData returns the userdata of the controller action that has sent the packet.
+func (r *PacketIn2) UserData() []byte {
+	return r.userData
+}
+
+// Continuation returns the private data that is used to resume the pipeline
+// using NXT_RESUME if the packet is paused.
+func (r *PacketIn2) Continuation() []byte {
+	return r.continuation
+}
+
+func (r *PacketIn2) UnmarshalBinary(data []byte) error {
+	msg := new(openflow.BaseExperimenter)
+	if err := msg.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if msg.ExperimenterID() != NX_VENDOR_ID || msg.ExperimenterType() != NXT_PACKET_IN2 {
+		return fmt.Errorf("unexpected experimenter message: experimenter=%#x, type=%v", msg.ExperimenterID(), msg.ExperimenterType())
+	}
+
+	return r.decode(msg)
+}
+
+func (r *PacketIn2) decode(msg openflow.Experimenter) error {
+	*r = PacketIn2{
+		Message:  openflow.NewMessage(msg.Version(), msg.Type(), msg.TransactionID()),
+		bufferID: 0xFFFFFFFF,
+	}
+	hasFullLen := false
+
+	buf := msg.Data()
+	for len(buf) >= 4 {
+		t := binary.BigEndian.Uint16(buf[0:2])
+		length := int(binary.BigEndian.Uint16(buf[2:4]))
+		if length < 4 || len(buf) < length {
+			return openflow.ErrInvalidPacketLength
+		}
+		v := buf[4:length]
+
+		switch t {
+		case NXPINT_PACKET:
+			r.packet = v
+		case NXPINT_FULL_LEN, NXPINT_BUFFER_ID:
+			if len(v) < 4 {
+				return openflow.ErrInvalidPacketLength
+			}
+			if t == NXPINT_FULL_LEN {
+				r.fullLen = binary.BigEndian.Uint32(v[0:4])
+				hasFullLen = true
+			} else {
+				r.bufferID = binary.BigEndian.Uint32(v[0:4])
+			}
+		case NXPINT_TABLE_ID, NXPINT_REASON:
+			if len(v) < 1 {
+				return openflow.ErrInvalidPacketLength
+			}
+			if t == NXPINT_TABLE_ID {
+				r.tableID = v[0]
+			} else {
+				r.reason = v[0]
+			}
+		case NXPINT_COOKIE:
+			// 64-bit values are preceded by 4 bytes of padding to be 64-bit aligned.
+			if len(v) < 12 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.cookie = binary.BigEndian.Uint64(v[4:12])
+		case NXPINT_METADATA:
+			if err := r.decodeMetadata(v); err != nil {
+				return err
+			}
+		case NXPINT_USERDATA:
+			r.userData = v
+		case NXPINT_CONTINUATION:
+			r.continuation = v
+		default:
+			// Ignore unknown properties.
+		}
+
+		// Properties are padded to make them 64-bit aligned.
+		next := (length + 7) / 8 * 8
+		if next > len(buf) {
+			next = len(buf)
+		}
+		buf = buf[next:]
+	}
+	if !hasFullLen {
+		r.fullLen = uint32(len(r.packet))
+	}
+
+	return nil
+}
+
+// decodeMetadata decodes the ingress port and registers from the NXM or OXM
+// fields of the pipeline metadata.
+func (r *PacketIn2) decodeMetadata(data []byte) error {
+	buf := data
+	for len(buf) >= 4 {
+		header := binary.BigEndian.Uint32(buf[0:4])
+		length := int(header & 0xFF)
+		if len(buf) < 4+length {
+			return openflow.ErrInvalidPacketLength
+		}
+		v := buf[4 : 4+length]
+
+		class := header >> 16
+		field := header >> 9 & 0x7F
+		switch {
+		// NXM_OF_IN_PORT is used in OpenFlow 1.0.
+		case class == 0x0000 && field == 0 && length == 2:
+			r.inPort = uint32(binary.BigEndian.Uint16(v))
+		// OXM_OF_IN_PORT.
+		case class == 0x8000 && field == 0 && length == 4:
+			r.inPort = binary.BigEndian.Uint32(v)
+		case class == 0x0001 && field < NXM_NX_MAX_REGS && length >= 4:
+			r.registers[field] = binary.BigEndian.Uint32(v[0:4])
+			r.hasRegisters |= 1 << field
+		}
+
+		buf = buf[4+length:]
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package nicira
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+func appendProperty(buf []byte, t uint16, value []byte) []byte {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], uint16(4+len(value)))
+	v = append(v, value...)
+	if rem := len(v) % 8; rem > 0 {
+		v = append(v, make([]byte, 8-rem)...)
+	}
+
+	return append(buf, v...)
+}
+
+func TestPacketIn2(t *testing.T) {
+	packet := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x08, 0x06}
+	cookie := make([]byte, 12)
+	binary.BigEndian.PutUint64(cookie[4:12], 0x1234)
+	// OXM_OF_IN_PORT=7 and NXM_NX_REG1=0xAB.
+	metadata := []byte{0x80, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x07, 0x00, 0x01, 0x02, 0x04, 0x00, 0x00, 0x00, 0xAB}
+
+	props := appendProperty(nil, NXPINT_PACKET, packet)
+	props = appendProperty(props, NXPINT_TABLE_ID, []byte{3})
+	props = appendProperty(props, NXPINT_COOKIE, cookie)
+	props = appendProperty(props, NXPINT_REASON, []byte{1})
+	props = appendProperty(props, NXPINT_METADATA, metadata)
+
+	msg := openflow.NewExperimenter(openflow.OF13_VERSION, 4, 10)
+	msg.SetExperimenterID(NX_VENDOR_ID)
+	msg.SetExperimenterType(NXT_PACKET_IN2)
+	msg.SetData(props)
+
+	v, err := NewCodec().Decode(nil, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := v.(openflow.PacketIn)
+	if !ok {
+		t.Fatalf("unexpected message type: %T", v)
+	}
+	if !bytes.Equal(p.Data(), packet) || p.Length() != uint16(len(packet)) {
+		t.Fatalf("unexpected packet: %v", p.Data())
+	}
+	if p.InPort() != 7 || p.TableID() != 3 || p.Cookie() != 0x1234 || p.Reason() != 1 || p.BufferID() != 0xFFFFFFFF {
+		t.Fatalf("unexpected PACKET_IN2: inport=%v, table=%v, cookie=%v, reason=%v, buffer=%v",
+			p.InPort(), p.TableID(), p.Cookie(), p.Reason(), p.BufferID())
+	}
+	if reg := v.(*PacketIn2).Register(1); reg != 0xAB {
+		t.Fatalf("unexpected reg1: %v", reg)
+	}
+}
+
+func TestLearn(t *testing.T) {
+	learn := &Learn{
+		HardTimeout: 60,
+		Priority:    100,
+		Table:       1,
+		Specs: []LearnSpec{
+			// NXM_OF_ETH_DST[]=NXM_OF_ETH_SRC[]
+			{NBits: 48, SrcField: NXM_OF_ETH_SRC, Dst: LearnMatch, DstField: NXM_OF_ETH_DST},
+			// output:NXM_OF_IN_PORT[]
+			{NBits: 16, SrcField: NXM_OF_IN_PORT, Dst: LearnOutput},
+		},
+	}
+	v, err := learn.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 32 bytes of header, 14 and 8 bytes of the specs, and 2 bytes of padding.
+	if len(v) != 56 || binary.BigEndian.Uint16(v[2:4]) != 56 {
+		t.Fatalf("unexpected length: %v", len(v))
+	}
+	if h := binary.BigEndian.Uint16(v[32:34]); h != NX_LEARN_SRC_FIELD|NX_LEARN_DST_MATCH|48 {
+		t.Fatalf("unexpected spec header: %#x", h)
+	}
+	if h := binary.BigEndian.Uint16(v[46:48]); h != NX_LEARN_SRC_FIELD|NX_LEARN_DST_OUTPUT|16 {
+		t.Fatalf("unexpected spec header: %#x", h)
+	}
+
+	load := &RegLoad{Dst: NXMRegister(0), NBits: 8, Value: 0x100}
+	if _, err := load.MarshalBinary(); err == nil {
+		t.Fatal("expected an error for the value that exceeds the bits")
+	}
+}
+
+type testDesc struct {
+	openflow.DescReply
+	manufacturer string
+}
+
+func (r testDesc) Manufacturer() string {
+	return r.manufacturer
+}
+
+type testWriter []encoding.BinaryMarshaler
+
+func (r *testWriter) Write(msg encoding.BinaryMarshaler) error {
+	*r = append(*r, msg)
+	return nil
+}
+
+func TestCodecInit(t *testing.T) {
+	// Other devices should not receive the Nicira extension messages.
+	w := new(testWriter)
+	if err := NewCodec().Init(of13.NewFactory(), w, testDesc{manufacturer: "Unknown"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*w) != 0 {
+		t.Fatalf("unexpected messages: %v", *w)
+	}
+
+	if err := NewCodec().Init(of13.NewFactory(), w, testDesc{manufacturer: ovsManufacturer}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*w) != 1 {
+		t.Fatalf("unexpected number of messages: %v", len(*w))
+	}
+	msg, ok := (*w)[0].(openflow.Experimenter)
+	if !ok {
+		t.Fatalf("unexpected message type: %T", (*w)[0])
+	}
+	if msg.ExperimenterID() != NX_VENDOR_ID || msg.ExperimenterType() != NXT_SET_PACKET_IN_FORMAT {
+		t.Fatalf("unexpected message: experimenter=%#x, type=%v", msg.ExperimenterID(), msg.ExperimenterType())
+	}
+	if format := binary.BigEndian.Uint32(msg.Data()); format != NXPIF_NXT_PACKET_IN2 {
+		t.Fatalf("unexpected format: %v", format)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package nicira
+
+import (
+	"fmt"
+)
+
+// NXMRegister returns the NXM header of the register reg0-reg7.
+func NXMRegister(index uint8) uint32 {
+	if index >= NXM_NX_MAX_REGS {
+		panic(fmt.Sprintf("invalid register index: %v", index))
+	}
+
+	return 0x0001<<16 | uint32(index)<<9 | 4
+}
+
+// nxmBits returns the number of bits of the NXM field whose header is h.
+func nxmBits(h uint32) uint16 {
+	length := h & 0xFF
+	// The length of a masked field includes the mask.
+	if h>>8&0x1 == 1 {
+		length /= 2
+	}
+
+	return uint16(length * 8)
+}
+
+// ofsNBits encodes the offset and the number of bits of a field.
+func ofsNBits(ofs, nbits uint16) uint16 {
+	return ofs<<6 | (nbits - 1)
+}
+
+func checkBits(h uint32, ofs, nbits uint16) error {
+	if nbits == 0 || ofs+nbits > nxmBits(h) {
+		return fmt.Errorf("invalid bits of the NXM field %#x: offset=%v, nbits=%v", h, ofs, nbits)
+	}
+
+	return nil
+}
@@ -23,6 +23,7 @@ package of10
 
 import (
 	"encoding/binary"
+	"errors"
 	"net"
 
 	"github.com/superkkt/cherry/openflow"
@@ -61,8 +62,8 @@ func marshalOutPort(p openflow.OutPort) ([]byte, error) {
 		port = uint16(p.Value())
 	}
 	binary.BigEndian.PutUint16(v[4:6], port)
-	// We don't support buffer ID and partial PACKET_IN
-	binary.BigEndian.PutUint16(v[6:8], 0xFFFF)
+	// Maximum length of the packets sent to the controller
+	binary.BigEndian.PutUint16(v[6:8], p.MaxLength())
 
 	return v, nil
 }
@@ -117,12 +118,120 @@ func marshalVLANID(vid uint16) ([]byte, error) {
 	return v, nil
 }
 
+func marshalVLANPriority(pcp uint8) ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], uint16(OFPAT_SET_VLAN_PCP))
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	v[4] = pcp
+	// v[5:8] is padding
+
+	return v, nil
+}
+
+func marshalStripVLAN() ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], uint16(OFPAT_STRIP_VLAN))
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	// v[4:8] is padding
+
+	return v, nil
+}
+
+func marshalIP(t uint16, ip net.IP) ([]byte, error) {
+	ipv4 := ip.To4()
+	if ipv4 == nil {
+		return nil, openflow.ErrInvalidIPAddress
+	}
+
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	copy(v[4:8], ipv4)
+
+	return v, nil
+}
+
+func marshalTOS(dscp uint8) ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], uint16(OFPAT_SET_NW_TOS))
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	// DSCP is the upper 6 bits of the ToS field.
+	v[4] = dscp << 2
+	// v[5:8] is padding
+
+	return v, nil
+}
+
+func marshalTransportPort(t uint16, port uint16) ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	binary.BigEndian.PutUint16(v[4:6], port)
+	// v[6:8] is padding
+
+	return v, nil
+}
+
+// checkUnsupported returns an error if the action has any action that OF10 cannot express.
+func (r *Action) checkUnsupported() error {
+	if r.CopyTTLIn() || r.CopyTTLOut() {
+		return errors.New("of10 does not support the copy TTL action")
+	}
+	if r.DecNwTTL() {
+		return errors.New("of10 does not support the decrement TTL action")
+	}
+	if ok, _ := r.PushMPLS(); ok {
+		return errors.New("of10 does not support the push MPLS action")
+	}
+	if ok, _ := r.PopMPLS(); ok {
+		return errors.New("of10 does not support the pop MPLS action")
+	}
+	if ok, _ := r.ECN(); ok {
+		return errors.New("of10 does not support the set ECN action")
+	}
+	if ok, _ := r.TunnelID(); ok {
+		return errors.New("of10 does not support the set tunnel ID action")
+	}
+	if ok, _ := r.Group(); ok {
+		return errors.New("of10 does not support the group action")
+	}
+	if ok, etherType := r.PushVLAN(); ok {
+		// OF10 adds a new 802.1Q header when it sets the VLAN ID of an untagged packet.
+		if ok, _ := r.VLANID(); !ok || etherType != 0x8100 {
+			return errors.New("of10 only supports the push VLAN action of 802.1Q with the set VLAN ID action")
+		}
+	}
+	tcp, _ := r.TCPSrcPort()
+	if ok, _ := r.TCPDstPort(); ok {
+		tcp = true
+	}
+	udp, _ := r.UDPSrcPort()
+	if ok, _ := r.UDPDstPort(); ok {
+		udp = true
+	}
+	if tcp && udp {
+		return errors.New("of10 cannot set both of TCP and UDP ports")
+	}
+
+	return nil
+}
+
 func (r *Action) MarshalBinary() ([]byte, error) {
 	if err := r.Error(); err != nil {
 		return nil, err
 	}
+	if err := r.checkUnsupported(); err != nil {
+		return nil, err
+	}
 
 	result := make([]byte, 0)
+	if r.PopVLAN() {
+		v, err := marshalStripVLAN()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
 	if ok, srcMAC := r.SrcMAC(); ok {
 		v, err := marshalMAC(OFPAT_SET_DL_SRC, srcMAC)
 		if err != nil {
@@ -146,6 +255,65 @@ func (r *Action) MarshalBinary() ([]byte, error) {
 		}
 		result = append(result, v...)
 	}
+	if ok, pcp := r.VLANPriority(); ok {
+		v, err := marshalVLANPriority(pcp)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
+	if ok, srcIP := r.SrcIP(); ok {
+		v, err := marshalIP(OFPAT_SET_NW_SRC, srcIP)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
+	if ok, dstIP := r.DstIP(); ok {
+		v, err := marshalIP(OFPAT_SET_NW_DST, dstIP)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
+	if ok, dscp := r.DSCP(); ok {
+		v, err := marshalTOS(dscp)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
+	// OF10 uses the same actions for TCP and UDP ports.
+	for _, f := range []func() (bool, uint16){r.TCPSrcPort, r.UDPSrcPort} {
+		if ok, port := f(); ok {
+			v, err := marshalTransportPort(OFPAT_SET_TP_SRC, port)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v...)
+		}
+	}
+	for _, f := range []func() (bool, uint16){r.TCPDstPort, r.UDPDstPort} {
+		if ok, port := f(); ok {
+			v, err := marshalTransportPort(OFPAT_SET_TP_DST, port)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v...)
+		}
+	}
+
+	for _, v := range r.ExperimenterActions() {
+		a, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a...)
+	}
+	// Output port can be omitted if the packets are handled by the vendor actions, e.g., resubmit.
+	if len(r.ExperimenterActions()) > 0 && !r.HasOutPort() {
+		return result, nil
+	}
 
 	// XXX: Output action should be specified as a last element of this action command.
 	var buf []byte
@@ -170,7 +338,7 @@ func (r *Action) UnmarshalBinary(data []byte) error {
 	for len(buf) >= 4 {
 		t := binary.BigEndian.Uint16(buf[0:2])
 		length := binary.BigEndian.Uint16(buf[2:4])
-		if len(buf) < int(length) {
+		if length < 4 || len(buf) < int(length) {
 			return openflow.ErrInvalidPacketLength
 		}
 
@@ -181,26 +349,18 @@ func (r *Action) UnmarshalBinary(data []byte) error {
 			}
 			outPort := openflow.NewOutPort()
 			outPort.SetValue(uint32(binary.BigEndian.Uint16(buf[4:6])))
+			outPort.SetMaxLength(binary.BigEndian.Uint16(buf[6:8]))
 			r.SetOutPort(outPort)
-			if err := r.Error(); err != nil {
-				return err
-			}
 		case OFPAT_SET_DL_SRC:
 			if len(buf) < 16 {
 				return openflow.ErrInvalidPacketLength
 			}
 			r.SetSrcMAC(buf[4:10])
-			if err := r.Error(); err != nil {
-				return err
-			}
 		case OFPAT_SET_DL_DST:
 			if len(buf) < 16 {
 				return openflow.ErrInvalidPacketLength
 			}
 			r.SetDstMAC(buf[4:10])
-			if err := r.Error(); err != nil {
-				return err
-			}
 		case OFPAT_ENQUEUE:
 			if len(buf) < 16 {
 				return openflow.ErrInvalidPacketLength
@@ -209,20 +369,55 @@ func (r *Action) UnmarshalBinary(data []byte) error {
 			outPort.SetValue(uint32(binary.BigEndian.Uint16(buf[4:6])))
 			r.SetOutPort(outPort)
 			r.SetQueue(binary.BigEndian.Uint32(buf[12:16]))
-			if err := r.Error(); err != nil {
-				return err
-			}
 		case OFPAT_SET_VLAN_VID:
 			if len(buf) < 8 {
 				return openflow.ErrInvalidPacketLength
 			}
 			r.SetVLANID(binary.BigEndian.Uint16(buf[4:6]))
-			if err := r.Error(); err != nil {
-				return err
+		case OFPAT_SET_VLAN_PCP:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
 			}
+			r.SetVLANPriority(buf[4])
+		case OFPAT_STRIP_VLAN:
+			r.SetPopVLAN(true)
+		case OFPAT_SET_NW_SRC:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.SetSrcIP(net.IPv4(buf[4], buf[5], buf[6], buf[7]))
+		case OFPAT_SET_NW_DST:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.SetDstIP(net.IPv4(buf[4], buf[5], buf[6], buf[7]))
+		case OFPAT_SET_NW_TOS:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.SetDSCP(buf[4] >> 2)
+		case OFPAT_SET_TP_SRC:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			// OF10 does not distinguish TCP and UDP ports. We regard them as TCP ports.
+			r.SetTCPSrcPort(binary.BigEndian.Uint16(buf[4:6]))
+		case OFPAT_SET_TP_DST:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.SetTCPDstPort(binary.BigEndian.Uint16(buf[4:6]))
+		case OFPAT_VENDOR:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.AddExperimenterAction(openflow.RawExperimenterAction(append([]byte(nil), buf[:length]...)))
 		default:
 			// Do nothing
 		}
+		if err := r.Error(); err != nil {
+			return err
+		}
 
 		buf = buf[length:]
 	}
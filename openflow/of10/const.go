@@ -169,6 +169,10 @@ const (
 	OFPST_VENDOR = 0xffff
 )
 
+const (
+	OFPSF_REPLY_MORE = 1 << 0 /* More replies to follow. */
+)
+
 const (
 	OFPC_FRAG_NORMAL = iota /* No special handling for fragments. */
 	OFPC_FRAG_DROP          /* Drop fragments. */
@@ -181,3 +185,65 @@ const (
 	OFPPR_DELETE = 1
 	OFPPR_MODIFY = 2
 )
+
+const (
+	OFPQ_ALL = 0xffffffff /* All ones is used to indicate all queues in a port (for stats retrieval). */
+)
+
+const (
+	OFPET_HELLO_FAILED    = iota /* Hello protocol failed. */
+	OFPET_BAD_REQUEST            /* Request was not understood. */
+	OFPET_BAD_ACTION             /* Error in action description. */
+	OFPET_FLOW_MOD_FAILED        /* Problem modifying flow entry. */
+	OFPET_PORT_MOD_FAILED        /* Port mod request failed. */
+	OFPET_QUEUE_OP_FAILED        /* Queue operation failed. */
+)
+
+const (
+	OFPHFC_INCOMPATIBLE = iota /* No compatible version. */
+	OFPHFC_EPERM               /* Permissions error. */
+)
+
+const (
+	OFPBRC_BAD_VERSION    = iota /* ofp_header.version not supported. */
+	OFPBRC_BAD_TYPE              /* ofp_header.type not supported. */
+	OFPBRC_BAD_STAT              /* ofp_stats_request.type not supported. */
+	OFPBRC_BAD_VENDOR            /* Vendor not supported (in ofp_vendor_header or ofp_stats_request or ofp_stats_reply). */
+	OFPBRC_BAD_SUBTYPE           /* Vendor subtype not supported. */
+	OFPBRC_EPERM                 /* Permissions error. */
+	OFPBRC_BAD_LEN               /* Wrong request length for type. */
+	OFPBRC_BUFFER_EMPTY          /* Specified buffer has already been used. */
+	OFPBRC_BUFFER_UNKNOWN        /* Specified buffer does not exist. */
+)
+
+const (
+	OFPBAC_BAD_TYPE        = iota /* Unknown action type. */
+	OFPBAC_BAD_LEN                /* Length problem in actions. */
+	OFPBAC_BAD_VENDOR             /* Unknown vendor id specified. */
+	OFPBAC_BAD_VENDOR_TYPE        /* Unknown action type for vendor id. */
+	OFPBAC_BAD_OUT_PORT           /* Problem validating output action. */
+	OFPBAC_BAD_ARGUMENT           /* Bad action argument. */
+	OFPBAC_EPERM                  /* Permissions error. */
+	OFPBAC_TOO_MANY               /* Can't handle this many actions. */
+	OFPBAC_BAD_QUEUE              /* Problem validating output queue. */
+)
+
+const (
+	OFPFMFC_ALL_TABLES_FULL   = iota /* Flow not added because of full tables. */
+	OFPFMFC_OVERLAP                  /* Attempted to add overlapping flow with CHECK_OVERLAP flag set. */
+	OFPFMFC_EPERM                    /* Permissions error. */
+	OFPFMFC_BAD_EMERG_TIMEOUT        /* Flow not added because of non-zero idle/hard timeout. */
+	OFPFMFC_BAD_COMMAND              /* Unknown command. */
+	OFPFMFC_UNSUPPORTED              /* Unsupported action list - cannot process in the order specified. */
+)
+
+const (
+	OFPPMFC_BAD_PORT    = iota /* Specified port does not exist. */
+	OFPPMFC_BAD_HW_ADDR        /* Specified hardware address is wrong. */
+)
+
+const (
+	OFPQOFC_BAD_PORT  = iota /* Invalid port (or port does not exist). */
+	OFPQOFC_BAD_QUEUE        /* Queue does not exist. */
+	OFPQOFC_EPERM            /* Permissions error. */
+)
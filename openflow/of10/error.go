@@ -0,0 +1,139 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of10
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type Error struct {
+	openflow.BaseError
+}
+
+func NewError() openflow.Error {
+	return new(Error)
+}
+
+func (r *Error) Cause() *openflow.ErrorCause {
+	return openflow.NewErrorCause(errorTypes, r.Class(), r.Code())
+}
+
+func (r *Error) Request() (openflow.Header, error) {
+	data := r.Data()
+	if len(data) < 8 {
+		return nil, openflow.ErrInvalidPacketLength
+	}
+	// Truncated request?
+	if len(data) < int(binary.BigEndian.Uint16(data[2:4])) {
+		return openflow.ParseRequestHeader(data)
+	}
+
+	var msg interface {
+		openflow.Header
+		UnmarshalBinary(data []byte) error
+	}
+	switch data[1] {
+	case OFPT_FLOW_MOD:
+		msg = new(FlowMod)
+	case OFPT_PACKET_OUT:
+		msg = new(PacketOut)
+	default:
+		return openflow.ParseRequestHeader(data)
+	}
+	if err := msg.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+var errorTypes = map[uint16]openflow.ErrorType{
+	OFPET_HELLO_FAILED: {
+		Name:        "OFPET_HELLO_FAILED",
+		Description: "hello protocol failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPHFC_INCOMPATIBLE: {Name: "OFPHFC_INCOMPATIBLE", Description: "no compatible version"},
+			OFPHFC_EPERM:        {Name: "OFPHFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_BAD_REQUEST: {
+		Name:        "OFPET_BAD_REQUEST",
+		Description: "request was not understood",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBRC_BAD_VERSION:    {Name: "OFPBRC_BAD_VERSION", Description: "ofp_header.version not supported"},
+			OFPBRC_BAD_TYPE:       {Name: "OFPBRC_BAD_TYPE", Description: "ofp_header.type not supported"},
+			OFPBRC_BAD_STAT:       {Name: "OFPBRC_BAD_STAT", Description: "ofp_stats_request.type not supported"},
+			OFPBRC_BAD_VENDOR:     {Name: "OFPBRC_BAD_VENDOR", Description: "vendor not supported (in ofp_vendor_header or ofp_stats_request or ofp_stats_reply)"},
+			OFPBRC_BAD_SUBTYPE:    {Name: "OFPBRC_BAD_SUBTYPE", Description: "vendor subtype not supported"},
+			OFPBRC_EPERM:          {Name: "OFPBRC_EPERM", Description: "permissions error"},
+			OFPBRC_BAD_LEN:        {Name: "OFPBRC_BAD_LEN", Description: "wrong request length for type"},
+			OFPBRC_BUFFER_EMPTY:   {Name: "OFPBRC_BUFFER_EMPTY", Description: "specified buffer has already been used"},
+			OFPBRC_BUFFER_UNKNOWN: {Name: "OFPBRC_BUFFER_UNKNOWN", Description: "specified buffer does not exist"},
+		},
+	},
+	OFPET_BAD_ACTION: {
+		Name:        "OFPET_BAD_ACTION",
+		Description: "error in action description",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBAC_BAD_TYPE:        {Name: "OFPBAC_BAD_TYPE", Description: "unknown action type"},
+			OFPBAC_BAD_LEN:         {Name: "OFPBAC_BAD_LEN", Description: "length problem in actions"},
+			OFPBAC_BAD_VENDOR:      {Name: "OFPBAC_BAD_VENDOR", Description: "unknown vendor id specified"},
+			OFPBAC_BAD_VENDOR_TYPE: {Name: "OFPBAC_BAD_VENDOR_TYPE", Description: "unknown action type for vendor id"},
+			OFPBAC_BAD_OUT_PORT:    {Name: "OFPBAC_BAD_OUT_PORT", Description: "problem validating output action"},
+			OFPBAC_BAD_ARGUMENT:    {Name: "OFPBAC_BAD_ARGUMENT", Description: "bad action argument"},
+			OFPBAC_EPERM:           {Name: "OFPBAC_EPERM", Description: "permissions error"},
+			OFPBAC_TOO_MANY:        {Name: "OFPBAC_TOO_MANY", Description: "can't handle this many actions"},
+			OFPBAC_BAD_QUEUE:       {Name: "OFPBAC_BAD_QUEUE", Description: "problem validating output queue"},
+		},
+	},
+	OFPET_FLOW_MOD_FAILED: {
+		Name:        "OFPET_FLOW_MOD_FAILED",
+		Description: "problem modifying flow entry",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPFMFC_ALL_TABLES_FULL:   {Name: "OFPFMFC_ALL_TABLES_FULL", Description: "flow not added because of full tables"},
+			OFPFMFC_OVERLAP:           {Name: "OFPFMFC_OVERLAP", Description: "attempted to add overlapping flow with CHECK_OVERLAP flag set"},
+			OFPFMFC_EPERM:             {Name: "OFPFMFC_EPERM", Description: "permissions error"},
+			OFPFMFC_BAD_EMERG_TIMEOUT: {Name: "OFPFMFC_BAD_EMERG_TIMEOUT", Description: "flow not added because of non-zero idle/hard timeout"},
+			OFPFMFC_BAD_COMMAND:       {Name: "OFPFMFC_BAD_COMMAND", Description: "unknown command"},
+			OFPFMFC_UNSUPPORTED:       {Name: "OFPFMFC_UNSUPPORTED", Description: "unsupported action list - cannot process in the order specified"},
+		},
+	},
+	OFPET_PORT_MOD_FAILED: {
+		Name:        "OFPET_PORT_MOD_FAILED",
+		Description: "port mod request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPPMFC_BAD_PORT:    {Name: "OFPPMFC_BAD_PORT", Description: "specified port does not exist"},
+			OFPPMFC_BAD_HW_ADDR: {Name: "OFPPMFC_BAD_HW_ADDR", Description: "specified hardware address is wrong"},
+		},
+	},
+	OFPET_QUEUE_OP_FAILED: {
+		Name:        "OFPET_QUEUE_OP_FAILED",
+		Description: "queue operation failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPQOFC_BAD_PORT:  {Name: "OFPQOFC_BAD_PORT", Description: "invalid port (or port does not exist)"},
+			OFPQOFC_BAD_QUEUE: {Name: "OFPQOFC_BAD_QUEUE", Description: "queue does not exist"},
+			OFPQOFC_EPERM:     {Name: "OFPQOFC_EPERM", Description: "permissions error"},
+		},
+	},
+}
@@ -104,6 +104,10 @@ func getFlowModCmd(cmd openflow.FlowModCmd) uint16 {
 		c = OFPFC_MODIFY
 	case openflow.FlowDelete:
 		c = OFPFC_DELETE
+	case openflow.FlowModifyStrict:
+		c = OFPFC_MODIFY_STRICT
+	case openflow.FlowDeleteStrict:
+		c = OFPFC_DELETE_STRICT
 	default:
 		panic(fmt.Sprintf("unexpected FlowModCmd: %v", cmd))
 	}
@@ -127,6 +131,10 @@ func (r *Factory) NewPacketOut() (openflow.PacketOut, error) {
 	return NewPacketOut(r.getTransactionID()), nil
 }
 
+func (r *Factory) NewPortMod() (openflow.PortMod, error) {
+	return NewPortMod(r.getTransactionID()), nil
+}
+
 func (r *Factory) NewPortStatus() (openflow.PortStatus, error) {
 	return new(PortStatus), nil
 }
@@ -143,7 +151,9 @@ func (r *Factory) NewFlowStatsRequest() (openflow.FlowStatsRequest, error) {
 	return NewFlowStatsRequest(r.getTransactionID()), nil
 }
 
-// TODO: NewFlowStatsReply() (openflow.FlowStatsReply, error) {
+func (r *Factory) NewFlowStatsReply() (openflow.FlowStatsReply, error) {
+	return new(FlowStatsReply), nil
+}
 
 func (r *Factory) NewPortDescRequest() (openflow.PortDescRequest, error) {
 	return nil, errors.New("of10 does not support PortDescRequest")
@@ -158,10 +168,16 @@ func (r *Factory) NewTableFeaturesRequest() (openflow.TableFeaturesRequest, erro
 }
 
 func (r *Factory) NewError() (openflow.Error, error) {
-	return new(openflow.BaseError), nil
+	return NewError(), nil
+}
+
+func (r *Factory) NewExperimenter() (openflow.Experimenter, error) {
+	return openflow.NewExperimenter(openflow.OF10_VERSION, OFPT_VENDOR, r.getTransactionID()), nil
 }
 
-// TODO: NewTableFeaturesReply() (TableFeaturesReply, error)
+func (r *Factory) NewTableFeaturesReply() (openflow.TableFeaturesReply, error) {
+	return nil, errors.New("of10 does not support TableFeaturesReply")
+}
 
 func (r *Factory) NewInstruction() (openflow.Instruction, error) {
 	return new(Instruction), nil
@@ -170,3 +186,139 @@ func (r *Factory) NewInstruction() (openflow.Instruction, error) {
 func (r *Factory) NewQueueGetConfigRequest() (openflow.QueueGetConfigRequest, error) {
 	return NewQueueGetConfigRequest(r.getTransactionID()), nil
 }
+
+func (r *Factory) NewBucket() (openflow.Bucket, error) {
+	return nil, errors.New("of10 does not support Bucket")
+}
+
+func (r *Factory) NewGroupMod(cmd openflow.GroupModCmd) (openflow.GroupMod, error) {
+	return nil, errors.New("of10 does not support GroupMod")
+}
+
+func (r *Factory) NewGroupStatsRequest() (openflow.GroupStatsRequest, error) {
+	return nil, errors.New("of10 does not support GroupStatsRequest")
+}
+
+func (r *Factory) NewGroupStatsReply() (openflow.GroupStatsReply, error) {
+	return nil, errors.New("of10 does not support GroupStatsReply")
+}
+
+func (r *Factory) NewGroupDescRequest() (openflow.GroupDescRequest, error) {
+	return nil, errors.New("of10 does not support GroupDescRequest")
+}
+
+func (r *Factory) NewGroupDescReply() (openflow.GroupDescReply, error) {
+	return nil, errors.New("of10 does not support GroupDescReply")
+}
+
+func (r *Factory) NewGroupFeaturesRequest() (openflow.GroupFeaturesRequest, error) {
+	return nil, errors.New("of10 does not support GroupFeaturesRequest")
+}
+
+func (r *Factory) NewGroupFeaturesReply() (openflow.GroupFeaturesReply, error) {
+	return nil, errors.New("of10 does not support GroupFeaturesReply")
+}
+
+func (r *Factory) NewMeterBand() (openflow.MeterBand, error) {
+	return nil, errors.New("of10 does not support MeterBand")
+}
+
+func (r *Factory) NewMeterMod(cmd openflow.MeterModCmd) (openflow.MeterMod, error) {
+	return nil, errors.New("of10 does not support MeterMod")
+}
+
+func (r *Factory) NewMeterStatsRequest() (openflow.MeterStatsRequest, error) {
+	return nil, errors.New("of10 does not support MeterStatsRequest")
+}
+
+func (r *Factory) NewMeterStatsReply() (openflow.MeterStatsReply, error) {
+	return nil, errors.New("of10 does not support MeterStatsReply")
+}
+
+func (r *Factory) NewMeterConfigRequest() (openflow.MeterConfigRequest, error) {
+	return nil, errors.New("of10 does not support MeterConfigRequest")
+}
+
+func (r *Factory) NewMeterConfigReply() (openflow.MeterConfigReply, error) {
+	return nil, errors.New("of10 does not support MeterConfigReply")
+}
+
+func (r *Factory) NewMeterFeaturesRequest() (openflow.MeterFeaturesRequest, error) {
+	return nil, errors.New("of10 does not support MeterFeaturesRequest")
+}
+
+func (r *Factory) NewMeterFeaturesReply() (openflow.MeterFeaturesReply, error) {
+	return nil, errors.New("of10 does not support MeterFeaturesReply")
+}
+
+func (r *Factory) NewPortStatsRequest() (openflow.PortStatsRequest, error) {
+	return NewPortStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewPortStatsReply() (openflow.PortStatsReply, error) {
+	return new(PortStatsReply), nil
+}
+
+func (r *Factory) NewTableStatsRequest() (openflow.TableStatsRequest, error) {
+	return NewTableStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewTableStatsReply() (openflow.TableStatsReply, error) {
+	return new(TableStatsReply), nil
+}
+
+func (r *Factory) NewQueueStatsRequest() (openflow.QueueStatsRequest, error) {
+	return NewQueueStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewQueueStatsReply() (openflow.QueueStatsReply, error) {
+	return new(QueueStatsReply), nil
+}
+
+func (r *Factory) NewAggregateStatsRequest() (openflow.AggregateStatsRequest, error) {
+	return NewAggregateStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewAggregateStatsReply() (openflow.AggregateStatsReply, error) {
+	return new(AggregateStatsReply), nil
+}
+
+func (r *Factory) NewRoleRequest() (openflow.RoleRequest, error) {
+	return nil, errors.New("of10 does not support RoleRequest")
+}
+
+func (r *Factory) NewRoleReply() (openflow.RoleReply, error) {
+	return nil, errors.New("of10 does not support RoleReply")
+}
+
+func (r *Factory) NewSetAsync() (openflow.SetAsync, error) {
+	return nil, errors.New("of10 does not support SetAsync")
+}
+
+func (r *Factory) NewGetAsyncRequest() (openflow.GetAsyncRequest, error) {
+	return nil, errors.New("of10 does not support GetAsyncRequest")
+}
+
+func (r *Factory) NewGetAsyncReply() (openflow.GetAsyncReply, error) {
+	return nil, errors.New("of10 does not support GetAsyncReply")
+}
+
+func (r *Factory) NewBundleControl() (openflow.BundleControl, error) {
+	return nil, errors.New("of10 does not support BundleControl")
+}
+
+func (r *Factory) NewBundleAdd() (openflow.BundleAdd, error) {
+	return nil, errors.New("of10 does not support BundleAdd")
+}
+
+func (r *Factory) NewFlowMonitorRequest() (openflow.FlowMonitorRequest, error) {
+	return nil, errors.New("of10 does not support FlowMonitorRequest")
+}
+
+func (r *Factory) NewFlowMonitorReply() (openflow.FlowMonitorReply, error) {
+	return nil, errors.New("of10 does not support FlowMonitorReply")
+}
+
+func (r *Factory) NewTransactionID() uint32 {
+	return r.getTransactionID()
+}
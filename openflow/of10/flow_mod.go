@@ -176,3 +176,42 @@ func (r *FlowMod) MarshalBinary() ([]byte, error) {
 	r.SetPayload(result)
 	return r.Message.MarshalBinary()
 }
+
+func (r *FlowMod) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 64 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.match = NewMatch()
+	if err := r.match.UnmarshalBinary(payload[0:40]); err != nil {
+		return err
+	}
+	r.cookie = binary.BigEndian.Uint64(payload[40:48])
+	r.command = binary.BigEndian.Uint16(payload[48:50])
+	r.idleTimeout = binary.BigEndian.Uint16(payload[50:52])
+	r.hardTimeout = binary.BigEndian.Uint16(payload[52:54])
+	r.priority = binary.BigEndian.Uint16(payload[54:56])
+	// payload[56:60] is buffer ID
+	r.outPort = openflow.NewOutPort()
+	if port := binary.BigEndian.Uint16(payload[60:62]); port == OFPP_NONE {
+		r.outPort.SetNone()
+	} else {
+		r.outPort.SetValue(uint32(port))
+	}
+	// payload[62:64] is flags
+
+	r.instruction = nil
+	if len(payload) > 64 {
+		inst := new(Instruction)
+		if err := inst.UnmarshalBinary(payload[64:]); err != nil {
+			return err
+		}
+		r.instruction = inst
+	}
+
+	return nil
+}
@@ -85,12 +85,18 @@ func (r *FlowStatsRequest) SetTableID(id uint8) {
 
 // TODO: Need testing
 func (r *FlowStatsRequest) MarshalBinary() ([]byte, error) {
+	return r.marshal(OFPST_FLOW)
+}
+
+// marshal encodes the request whose stats type is t. Individual and aggregate
+// flow stats requests have the same body.
+func (r *FlowStatsRequest) marshal(t uint16) ([]byte, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
 
 	v := make([]byte, 48)
-	binary.BigEndian.PutUint16(v[0:2], OFPST_FLOW)
+	binary.BigEndian.PutUint16(v[0:2], t)
 	// v[2:4] is flags, but not yet defined
 
 	if r.match == nil {
@@ -109,4 +115,137 @@ func (r *FlowStatsRequest) MarshalBinary() ([]byte, error) {
 	return r.Message.MarshalBinary()
 }
 
-// TODO: Implement FlowStatsReply
+type FlowStats struct {
+	length          uint16
+	tableID         uint8
+	match           openflow.Match
+	durationSec     uint32
+	durationNanoSec uint32
+	priority        uint16
+	idleTimeout     uint16
+	hardTimeout     uint16
+	cookie          uint64
+	packetCount     uint64
+	byteCount       uint64
+	instruction     openflow.Instruction
+}
+
+func (r *FlowStats) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *FlowStats) Match() openflow.Match {
+	return r.match
+}
+
+func (r *FlowStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *FlowStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *FlowStats) Priority() uint16 {
+	return r.priority
+}
+
+func (r *FlowStats) IdleTimeout() uint16 {
+	return r.idleTimeout
+}
+
+func (r *FlowStats) HardTimeout() uint16 {
+	return r.hardTimeout
+}
+
+func (r *FlowStats) Cookie() uint64 {
+	return r.cookie
+}
+
+func (r *FlowStats) PacketCount() uint64 {
+	return r.packetCount
+}
+
+func (r *FlowStats) ByteCount() uint64 {
+	return r.byteCount
+}
+
+func (r *FlowStats) Instruction() openflow.Instruction {
+	return r.instruction
+}
+
+func (r *FlowStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 88 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 88 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.tableID = data[2]
+	// data[3] is padding
+	r.match = NewMatch()
+	if err := r.match.UnmarshalBinary(data[4:44]); err != nil {
+		return err
+	}
+	r.durationSec = binary.BigEndian.Uint32(data[44:48])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[48:52])
+	r.priority = binary.BigEndian.Uint16(data[52:54])
+	r.idleTimeout = binary.BigEndian.Uint16(data[54:56])
+	r.hardTimeout = binary.BigEndian.Uint16(data[56:58])
+	// data[58:64] is padding
+	r.cookie = binary.BigEndian.Uint64(data[64:72])
+	r.packetCount = binary.BigEndian.Uint64(data[72:80])
+	r.byteCount = binary.BigEndian.Uint64(data[80:88])
+
+	// A flow entry without actions drops all the matched packets.
+	r.instruction = nil
+	if r.length > 88 {
+		inst := new(Instruction)
+		if err := inst.UnmarshalBinary(data[88:r.length]); err != nil {
+			return err
+		}
+		r.instruction = inst
+	}
+
+	return nil
+}
+
+type FlowStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.FlowStats
+}
+
+func (r *FlowStatsReply) More() bool {
+	return r.flags&OFPSF_REPLY_MORE != 0
+}
+
+func (r *FlowStatsReply) Stats() []openflow.FlowStats {
+	return r.stats
+}
+
+func (r *FlowStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 4 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:2] is the stats type
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.FlowStats, 0)
+	for i := 4; i < len(payload); {
+		v := new(FlowStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
@@ -54,6 +54,28 @@ func (r *Instruction) ApplyAction(act openflow.Action) {
 	r.action = act
 }
 
+func (r *Instruction) Meter(meterID uint32) {
+	r.err = errors.New("of10 does not support meter")
+}
+
+func (r *Instruction) WriteMetadata(metadata, mask uint64) {
+	r.err = errors.New("of10 does not support write metadata")
+}
+
+func (r *Instruction) ClearActions() {
+	r.err = errors.New("of10 does not support clear actions")
+}
+
+func (r *Instruction) UnmarshalBinary(data []byte) error {
+	action := NewAction()
+	if err := action.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	r.action = action
+
+	return nil
+}
+
 func (r *Instruction) MarshalBinary() ([]byte, error) {
 	if r.err != nil {
 		return nil, r.err
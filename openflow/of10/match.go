@@ -45,6 +45,7 @@ type Wildcard struct {
 	SrcIP        uint8
 	DstIP        uint8
 	VLANPriority bool /* VLAN priority. */
+	TOS          bool /* IP ToS (DSCP field, 6 bits). */
 }
 
 func newWildcardAll() *Wildcard {
@@ -60,6 +61,7 @@ func newWildcardAll() *Wildcard {
 		SrcIP:        32,
 		DstIP:        32,
 		VLANPriority: true,
+		TOS:          true,
 	}
 }
 
@@ -104,8 +106,9 @@ func (r *Wildcard) MarshalBinary() ([]byte, error) {
 	if r.VLANPriority {
 		v = v | OFPFW_DL_VLAN_PCP
 	}
-	// ToS is always wildcarded
-	v = v | OFPFW_NW_TOS
+	if r.TOS {
+		v = v | OFPFW_NW_TOS
+	}
 
 	data := make([]byte, 4)
 	binary.BigEndian.PutUint32(data[0:4], v)
@@ -148,6 +151,9 @@ func (r *Wildcard) UnmarshalBinary(data []byte) error {
 	if w&OFPFW_DL_VLAN_PCP != 0 {
 		r.VLANPriority = true
 	}
+	if w&OFPFW_NW_TOS != 0 {
+		r.TOS = true
+	}
 
 	return nil
 }
@@ -161,6 +167,7 @@ type Match struct {
 	vlanID       uint16
 	vlanPriority uint8
 	etherType    uint16
+	tos          uint8
 	protocol     uint8
 	srcIP        net.IP
 	dstIP        net.IP
@@ -349,15 +356,12 @@ func (r *Match) SetSrcIP(ip *net.IPNet) {
 		return
 	}
 
-	r.srcIP = make([]byte, len(ip.IP))
-	copy(r.srcIP, ip.IP)
+	r.srcIP, r.wildcards.SrcIP = getIPv4Wildcard(ip)
+}
 
-	netmaskBits, _ := ip.Mask.Size()
-	if netmaskBits >= 32 {
-		r.wildcards.SrcIP = 0
-	} else {
-		r.wildcards.SrcIP = uint8(32 - netmaskBits)
-	}
+func (r *Match) SetWildcardSrcIP() {
+	r.srcIP = net.IPv4zero
+	r.wildcards.SrcIP = 32
 }
 
 func (r *Match) SrcIP() *net.IPNet {
@@ -384,15 +388,12 @@ func (r *Match) SetDstIP(ip *net.IPNet) {
 		return
 	}
 
-	r.dstIP = make([]byte, len(ip.IP))
-	copy(r.dstIP, ip.IP)
+	r.dstIP, r.wildcards.DstIP = getIPv4Wildcard(ip)
+}
 
-	netmaskBits, _ := ip.Mask.Size()
-	if netmaskBits >= 32 {
-		r.wildcards.DstIP = 0
-	} else {
-		r.wildcards.DstIP = uint8(32 - netmaskBits)
-	}
+func (r *Match) SetWildcardDstIP() {
+	r.dstIP = net.IPv4zero
+	r.wildcards.DstIP = 32
 }
 
 func (r *Match) DstIP() *net.IPNet {
@@ -419,6 +420,281 @@ func (r *Match) EtherType() (wildcard bool, etherType uint16) {
 	return r.wildcards.EtherType, r.etherType
 }
 
+// getIPv4Wildcard returns a copy of the IP address and its wildcard bit count.
+func getIPv4Wildcard(ip *net.IPNet) (net.IP, uint8) {
+	addr := make([]byte, len(ip.IP))
+	copy(addr, ip.IP)
+
+	netmaskBits, _ := ip.Mask.Size()
+	if netmaskBits >= 32 {
+		return addr, 0
+	}
+
+	return addr, uint8(32 - netmaskBits)
+}
+
+func (r *Match) isICMPv4() bool {
+	return r.etherType == 0x0800 && r.protocol == 0x01
+}
+
+func (r *Match) isARP() bool {
+	return r.etherType == 0x0806
+}
+
+func (r *Match) SetWildcardICMPType() {
+	r.SetWildcardSrcPort()
+}
+
+// SetICMPType sets the ICMP type. OF10 uses the transport source port field as the ICMP type.
+func (r *Match) SetICMPType(t uint8) {
+	if r.etherType == 0x86DD {
+		r.err = errors.New("of10 does not support ICMPv6 match")
+		return
+	}
+	if !r.isICMPv4() {
+		r.err = errors.Wrap(openflow.ErrUnsupportedIPProtocol, "SetICMPType")
+		return
+	}
+
+	r.srcPort = uint16(t)
+	r.wildcards.SrcPort = false
+}
+
+func (r *Match) ICMPType() (wildcard bool, t uint8) {
+	if !r.isICMPv4() {
+		return true, 0
+	}
+
+	return r.wildcards.SrcPort, uint8(r.srcPort)
+}
+
+func (r *Match) SetWildcardICMPCode() {
+	r.SetWildcardDstPort()
+}
+
+// SetICMPCode sets the ICMP code. OF10 uses the transport destination port field as the ICMP code.
+func (r *Match) SetICMPCode(code uint8) {
+	if r.etherType == 0x86DD {
+		r.err = errors.New("of10 does not support ICMPv6 match")
+		return
+	}
+	if !r.isICMPv4() {
+		r.err = errors.Wrap(openflow.ErrUnsupportedIPProtocol, "SetICMPCode")
+		return
+	}
+
+	r.dstPort = uint16(code)
+	r.wildcards.DstPort = false
+}
+
+func (r *Match) ICMPCode() (wildcard bool, code uint8) {
+	if !r.isICMPv4() {
+		return true, 0
+	}
+
+	return r.wildcards.DstPort, uint8(r.dstPort)
+}
+
+func (r *Match) SetWildcardIPDSCP() {
+	r.tos = 0
+	r.wildcards.TOS = true
+}
+
+func (r *Match) SetIPDSCP(dscp uint8) {
+	// IPv4?
+	if r.etherType != 0x0800 {
+		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetIPDSCP")
+		return
+	}
+	if dscp > 63 {
+		r.err = errors.New("SetIPDSCP: invalid DSCP value")
+		return
+	}
+
+	// DSCP is the upper 6 bits of the ToS field.
+	r.tos = dscp << 2
+	r.wildcards.TOS = false
+}
+
+func (r *Match) IPDSCP() (wildcard bool, dscp uint8) {
+	return r.wildcards.TOS, r.tos >> 2
+}
+
+func (r *Match) SetWildcardIPECN() {}
+
+func (r *Match) SetIPECN(ecn uint8) {
+	r.err = errors.New("of10 does not support IP ECN match")
+}
+
+func (r *Match) IPECN() (wildcard bool, ecn uint8) {
+	return true, 0
+}
+
+func (r *Match) SetWildcardIPv6FlowLabel() {}
+
+func (r *Match) SetIPv6FlowLabel(label uint32) {
+	r.err = errors.New("of10 does not support IPv6 flow label match")
+}
+
+func (r *Match) IPv6FlowLabel() (wildcard bool, label uint32) {
+	return true, 0
+}
+
+func (r *Match) SetWildcardIPv6NDTarget() {}
+
+func (r *Match) SetIPv6NDTarget(ip net.IP) {
+	r.err = errors.New("of10 does not support IPv6 ND target match")
+}
+
+func (r *Match) IPv6NDTarget() (wildcard bool, ip net.IP) {
+	return true, net.IPv6zero
+}
+
+func (r *Match) SetWildcardIPv6NDSLL() {}
+
+func (r *Match) SetIPv6NDSLL(mac net.HardwareAddr) {
+	r.err = errors.New("of10 does not support IPv6 ND SLL match")
+}
+
+func (r *Match) IPv6NDSLL() (wildcard bool, mac net.HardwareAddr) {
+	return true, net.HardwareAddr([]byte{0, 0, 0, 0, 0, 0})
+}
+
+func (r *Match) SetWildcardIPv6NDTLL() {}
+
+func (r *Match) SetIPv6NDTLL(mac net.HardwareAddr) {
+	r.err = errors.New("of10 does not support IPv6 ND TLL match")
+}
+
+func (r *Match) IPv6NDTLL() (wildcard bool, mac net.HardwareAddr) {
+	return true, net.HardwareAddr([]byte{0, 0, 0, 0, 0, 0})
+}
+
+func (r *Match) SetWildcardARPOp() {
+	r.SetWildcardIPProtocol()
+}
+
+// SetARPOp sets the ARP opcode. OF10 uses the lower 8 bits of the opcode in the IP protocol field.
+func (r *Match) SetARPOp(op uint16) {
+	if !r.isARP() {
+		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetARPOp")
+		return
+	}
+	if op > 0xFF {
+		r.err = errors.New("of10 only supports the ARP opcode less than 256")
+		return
+	}
+
+	r.protocol = uint8(op)
+	r.wildcards.Protocol = false
+}
+
+func (r *Match) ARPOp() (wildcard bool, op uint16) {
+	if !r.isARP() {
+		return true, 0
+	}
+
+	return r.wildcards.Protocol, uint16(r.protocol)
+}
+
+func (r *Match) SetWildcardARPSPA() {
+	r.SetWildcardSrcIP()
+}
+
+// SetARPSPA sets the ARP sender protocol address. OF10 uses the IP source address field for it.
+func (r *Match) SetARPSPA(ip *net.IPNet) {
+	if ip == nil {
+		panic("ip is nil")
+	}
+	if !r.isARP() {
+		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetARPSPA")
+		return
+	}
+	if ip.IP.To4() == nil {
+		r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetARPSPA")
+		return
+	}
+
+	r.srcIP, r.wildcards.SrcIP = getIPv4Wildcard(ip)
+}
+
+func (r *Match) ARPSPA() *net.IPNet {
+	if !r.isARP() {
+		return &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+	}
+
+	return r.SrcIP()
+}
+
+func (r *Match) SetWildcardARPTPA() {
+	r.SetWildcardDstIP()
+}
+
+// SetARPTPA sets the ARP target protocol address. OF10 uses the IP destination address field for it.
+func (r *Match) SetARPTPA(ip *net.IPNet) {
+	if ip == nil {
+		panic("ip is nil")
+	}
+	if !r.isARP() {
+		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetARPTPA")
+		return
+	}
+	if ip.IP.To4() == nil {
+		r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetARPTPA")
+		return
+	}
+
+	r.dstIP, r.wildcards.DstIP = getIPv4Wildcard(ip)
+}
+
+func (r *Match) ARPTPA() *net.IPNet {
+	if !r.isARP() {
+		return &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+	}
+
+	return r.DstIP()
+}
+
+func (r *Match) SetWildcardARPSHA() {}
+
+func (r *Match) SetARPSHA(mac net.HardwareAddr) {
+	r.err = errors.New("of10 does not support ARP SHA match")
+}
+
+func (r *Match) ARPSHA() (wildcard bool, mac net.HardwareAddr) {
+	return true, net.HardwareAddr([]byte{0, 0, 0, 0, 0, 0})
+}
+
+func (r *Match) SetWildcardARPTHA() {}
+
+func (r *Match) SetARPTHA(mac net.HardwareAddr) {
+	r.err = errors.New("of10 does not support ARP THA match")
+}
+
+func (r *Match) ARPTHA() (wildcard bool, mac net.HardwareAddr) {
+	return true, net.HardwareAddr([]byte{0, 0, 0, 0, 0, 0})
+}
+
+func (r *Match) SetWildcardMetadata() {}
+
+func (r *Match) SetMetadata(metadata uint64, mask uint64) {
+	r.err = errors.New("of10 does not support metadata match")
+}
+
+func (r *Match) Metadata() (wildcard bool, metadata uint64, mask uint64) {
+	return true, 0, 0
+}
+
+func (r *Match) SetWildcardRegister(index uint8) {}
+
+func (r *Match) SetRegister(index uint8, value uint32, mask uint32) {
+	r.err = errors.New("of10 does not support register match")
+}
+
+func (r *Match) Register(index uint8) (wildcard bool, value uint32, mask uint32) {
+	return true, 0, 0
+}
+
 func (r *Match) MarshalBinary() ([]byte, error) {
 	if r.err != nil {
 		return nil, r.err
@@ -451,6 +727,7 @@ func (r *Match) MarshalBinary() ([]byte, error) {
 	data[20] = r.vlanPriority
 	// data[21] = padding
 	binary.BigEndian.PutUint16(data[22:24], r.etherType)
+	data[24] = r.tos
 	data[25] = r.protocol
 	// data[26:28] = padding
 	srcIP := r.srcIP.To4()
@@ -487,6 +764,7 @@ func (r *Match) UnmarshalBinary(data []byte) error {
 	r.vlanPriority = data[20]
 	// data[21] = padding
 	r.etherType = binary.BigEndian.Uint16(data[22:24])
+	r.tos = data[24]
 	r.protocol = data[25]
 	// data[26:28] = padding
 	r.srcIP = net.IPv4(data[28], data[29], data[30], data[31])
@@ -66,6 +66,15 @@ func (r PacketIn) Cookie() uint64 {
 	return 0
 }
 
+func (r PacketIn) Match() openflow.Match {
+	port := openflow.NewInPort()
+	port.SetValue(uint32(r.inPort))
+	match := NewMatch()
+	match.SetInPort(port)
+
+	return match
+}
+
 func (r *PacketIn) UnmarshalBinary(data []byte) error {
 	if err := r.Message.UnmarshalBinary(data); err != nil {
 		return err
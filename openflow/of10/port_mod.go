@@ -0,0 +1,59 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of10
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortMod struct {
+	openflow.BasePortMod
+}
+
+func NewPortMod(xid uint32) openflow.PortMod {
+	return &PortMod{
+		BasePortMod: openflow.NewBasePortMod(openflow.OF10_VERSION, OFPT_PORT_MOD, xid),
+	}
+}
+
+func (r *PortMod) MarshalBinary() ([]byte, error) {
+	if r.PortNumber() > OFPP_MAX {
+		return nil, fmt.Errorf("invalid port number: %v", r.PortNumber())
+	}
+	if len(r.MAC()) != 6 {
+		return nil, openflow.ErrInvalidMACAddress
+	}
+
+	v := make([]byte, 24)
+	binary.BigEndian.PutUint16(v[0:2], uint16(r.PortNumber()))
+	copy(v[2:8], r.MAC())
+	binary.BigEndian.PutUint32(v[8:12], uint32(r.Config()))
+	binary.BigEndian.PutUint32(v[12:16], uint32(r.Mask()))
+	// Zero advertise means that the advertised features are not changed.
+	// v[20:24] is padding.
+	r.SetPayload(v)
+
+	return r.BasePortMod.MarshalBinary()
+}
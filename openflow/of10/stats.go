@@ -0,0 +1,471 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of10
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortStatsRequest struct {
+	openflow.Message
+	portNum uint16
+}
+
+func NewPortStatsRequest(xid uint32) openflow.PortStatsRequest {
+	return &PortStatsRequest{
+		Message: openflow.NewMessage(openflow.OF10_VERSION, OFPT_STATS_REQUEST, xid),
+		// OFPP_NONE means all ports
+		portNum: OFPP_NONE,
+	}
+}
+
+func (r *PortStatsRequest) PortNumber() (ok bool, num uint32) {
+	if r.portNum == OFPP_NONE {
+		return false, 0
+	}
+
+	return true, uint32(r.portNum)
+}
+
+func (r *PortStatsRequest) SetPortNumber(num uint32) {
+	r.portNum = uint16(num)
+}
+
+func (r *PortStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 12)
+	binary.BigEndian.PutUint16(v[0:2], OFPST_PORT)
+	// v[2:4] is flags, but not yet defined
+	binary.BigEndian.PutUint16(v[4:6], r.portNum)
+	// v[6:12] is padding
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type PortStats struct {
+	portNum  uint16
+	counters [12]uint64
+}
+
+func (r *PortStats) PortNumber() uint32 {
+	return uint32(r.portNum)
+}
+
+func (r *PortStats) RxPackets() uint64 {
+	return r.counters[0]
+}
+
+func (r *PortStats) TxPackets() uint64 {
+	return r.counters[1]
+}
+
+func (r *PortStats) RxBytes() uint64 {
+	return r.counters[2]
+}
+
+func (r *PortStats) TxBytes() uint64 {
+	return r.counters[3]
+}
+
+func (r *PortStats) RxDropped() uint64 {
+	return r.counters[4]
+}
+
+func (r *PortStats) TxDropped() uint64 {
+	return r.counters[5]
+}
+
+func (r *PortStats) RxErrors() uint64 {
+	return r.counters[6]
+}
+
+func (r *PortStats) TxErrors() uint64 {
+	return r.counters[7]
+}
+
+func (r *PortStats) RxFrameErrors() uint64 {
+	return r.counters[8]
+}
+
+func (r *PortStats) RxOverErrors() uint64 {
+	return r.counters[9]
+}
+
+func (r *PortStats) RxCRCErrors() uint64 {
+	return r.counters[10]
+}
+
+func (r *PortStats) Collisions() uint64 {
+	return r.counters[11]
+}
+
+func (r *PortStats) DurationSec() uint32 {
+	// OpenFlow 1.0 does not have duration
+	return 0
+}
+
+func (r *PortStats) DurationNanoSec() uint32 {
+	// OpenFlow 1.0 does not have duration
+	return 0
+}
+
+func (r *PortStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 104 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.portNum = binary.BigEndian.Uint16(data[0:2])
+	// data[2:8] is padding
+	for i := range r.counters {
+		r.counters[i] = binary.BigEndian.Uint64(data[8+i*8 : 16+i*8])
+	}
+
+	return nil
+}
+
+type PortStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.PortStats
+}
+
+func (r *PortStatsReply) More() bool {
+	return r.flags&OFPSF_REPLY_MORE != 0
+}
+
+func (r *PortStatsReply) Stats() []openflow.PortStats {
+	return r.stats
+}
+
+func (r *PortStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 4 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:2] is the stats type
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.PortStats, 0)
+	for i := 4; i+104 <= len(payload); i += 104 {
+		v := new(PortStats)
+		if err := v.UnmarshalBinary(payload[i : i+104]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type TableStatsRequest struct {
+	openflow.Message
+}
+
+func NewTableStatsRequest(xid uint32) openflow.TableStatsRequest {
+	return &TableStatsRequest{
+		Message: openflow.NewMessage(openflow.OF10_VERSION, OFPT_STATS_REQUEST, xid),
+	}
+}
+
+func (r *TableStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint16(v[0:2], OFPST_TABLE)
+	// v[2:4] is flags, but not yet defined
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type TableStats struct {
+	tableID      uint8
+	activeCount  uint32
+	lookupCount  uint64
+	matchedCount uint64
+}
+
+func (r *TableStats) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *TableStats) ActiveCount() uint32 {
+	return r.activeCount
+}
+
+func (r *TableStats) LookupCount() uint64 {
+	return r.lookupCount
+}
+
+func (r *TableStats) MatchedCount() uint64 {
+	return r.matchedCount
+}
+
+func (r *TableStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 64 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.tableID = data[0]
+	// data[1:4] is padding
+	// data[4:36] is the table name, data[36:40] is wildcards, and data[40:44] is max. entries
+	r.activeCount = binary.BigEndian.Uint32(data[44:48])
+	r.lookupCount = binary.BigEndian.Uint64(data[48:56])
+	r.matchedCount = binary.BigEndian.Uint64(data[56:64])
+
+	return nil
+}
+
+type TableStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.TableStats
+}
+
+func (r *TableStatsReply) More() bool {
+	return r.flags&OFPSF_REPLY_MORE != 0
+}
+
+func (r *TableStatsReply) Stats() []openflow.TableStats {
+	return r.stats
+}
+
+func (r *TableStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 4 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:2] is the stats type
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.TableStats, 0)
+	for i := 4; i+64 <= len(payload); i += 64 {
+		v := new(TableStats)
+		if err := v.UnmarshalBinary(payload[i : i+64]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type QueueStatsRequest struct {
+	openflow.Message
+	portNum uint16
+	queueID uint32
+}
+
+func NewQueueStatsRequest(xid uint32) openflow.QueueStatsRequest {
+	return &QueueStatsRequest{
+		Message: openflow.NewMessage(openflow.OF10_VERSION, OFPT_STATS_REQUEST, xid),
+		// OFPP_ALL means all ports
+		portNum: OFPP_ALL,
+		queueID: OFPQ_ALL,
+	}
+}
+
+func (r *QueueStatsRequest) PortNumber() (ok bool, num uint32) {
+	if r.portNum == OFPP_ALL {
+		return false, 0
+	}
+
+	return true, uint32(r.portNum)
+}
+
+func (r *QueueStatsRequest) SetPortNumber(num uint32) {
+	r.portNum = uint16(num)
+}
+
+func (r *QueueStatsRequest) QueueID() (ok bool, id uint32) {
+	if r.queueID == OFPQ_ALL {
+		return false, 0
+	}
+
+	return true, r.queueID
+}
+
+func (r *QueueStatsRequest) SetQueueID(id uint32) {
+	r.queueID = id
+}
+
+func (r *QueueStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 12)
+	binary.BigEndian.PutUint16(v[0:2], OFPST_QUEUE)
+	// v[2:4] is flags, but not yet defined
+	binary.BigEndian.PutUint16(v[4:6], r.portNum)
+	// v[6:8] is padding
+	binary.BigEndian.PutUint32(v[8:12], r.queueID)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type QueueStats struct {
+	portNum   uint16
+	queueID   uint32
+	txBytes   uint64
+	txPackets uint64
+	txErrors  uint64
+}
+
+func (r *QueueStats) PortNumber() uint32 {
+	return uint32(r.portNum)
+}
+
+func (r *QueueStats) QueueID() uint32 {
+	return r.queueID
+}
+
+func (r *QueueStats) TxBytes() uint64 {
+	return r.txBytes
+}
+
+func (r *QueueStats) TxPackets() uint64 {
+	return r.txPackets
+}
+
+func (r *QueueStats) TxErrors() uint64 {
+	return r.txErrors
+}
+
+func (r *QueueStats) DurationSec() uint32 {
+	// OpenFlow 1.0 does not have duration
+	return 0
+}
+
+func (r *QueueStats) DurationNanoSec() uint32 {
+	// OpenFlow 1.0 does not have duration
+	return 0
+}
+
+func (r *QueueStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 32 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.portNum = binary.BigEndian.Uint16(data[0:2])
+	// data[2:4] is padding
+	r.queueID = binary.BigEndian.Uint32(data[4:8])
+	r.txBytes = binary.BigEndian.Uint64(data[8:16])
+	r.txPackets = binary.BigEndian.Uint64(data[16:24])
+	r.txErrors = binary.BigEndian.Uint64(data[24:32])
+
+	return nil
+}
+
+type QueueStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.QueueStats
+}
+
+func (r *QueueStatsReply) More() bool {
+	return r.flags&OFPSF_REPLY_MORE != 0
+}
+
+func (r *QueueStatsReply) Stats() []openflow.QueueStats {
+	return r.stats
+}
+
+func (r *QueueStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 4 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:2] is the stats type
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.QueueStats, 0)
+	for i := 4; i+32 <= len(payload); i += 32 {
+		v := new(QueueStats)
+		if err := v.UnmarshalBinary(payload[i : i+32]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type AggregateStatsRequest struct {
+	*FlowStatsRequest
+}
+
+func NewAggregateStatsRequest(xid uint32) openflow.AggregateStatsRequest {
+	return &AggregateStatsRequest{
+		FlowStatsRequest: NewFlowStatsRequest(xid).(*FlowStatsRequest),
+	}
+}
+
+func (r *AggregateStatsRequest) MarshalBinary() ([]byte, error) {
+	return r.marshal(OFPST_AGGREGATE)
+}
+
+type AggregateStatsReply struct {
+	openflow.Message
+	packetCount uint64
+	byteCount   uint64
+	flowCount   uint32
+}
+
+func (r *AggregateStatsReply) PacketCount() uint64 {
+	return r.packetCount
+}
+
+func (r *AggregateStatsReply) ByteCount() uint64 {
+	return r.byteCount
+}
+
+func (r *AggregateStatsReply) FlowCount() uint32 {
+	return r.flowCount
+}
+
+func (r *AggregateStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 28 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:4] is the stats type and flags
+	r.packetCount = binary.BigEndian.Uint64(payload[4:12])
+	r.byteCount = binary.BigEndian.Uint64(payload[12:20])
+	r.flowCount = binary.BigEndian.Uint32(payload[20:24])
+	// payload[24:28] is padding
+
+	return nil
+}
@@ -63,18 +63,44 @@ func marshalOutput(p openflow.OutPort) ([]byte, error) {
 		port = p.Value()
 	}
 	binary.BigEndian.PutUint32(v[4:8], port)
-	// We don't support buffer ID and partial PACKET_IN
-	binary.BigEndian.PutUint16(v[8:10], 0xFFFF)
+	// Maximum length of the packets sent to the controller
+	binary.BigEndian.PutUint16(v[8:10], p.MaxLength())
 
 	return v, nil
 }
 
-func marshalMAC(t uint8, mac net.HardwareAddr) ([]byte, error) {
-	if mac == nil || len(mac) < 6 {
-		return nil, openflow.ErrInvalidMACAddress
-	}
+// marshalHeaderOnly encodes the actions that only have the type and length fields.
+func marshalHeaderOnly(t uint16) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	// v[4:8] is padding
+
+	return v
+}
+
+// marshalEtherType encodes the push and pop actions that have an Ethernet type.
+func marshalEtherType(t uint16, etherType uint16) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	binary.BigEndian.PutUint16(v[4:6], etherType)
+	// v[6:8] is padding
+
+	return v
+}
 
-	tlv, err := marshalHardwareAddrTLV(t, mac)
+// marshalUint32 encodes the actions that have a 32-bit value such as set queue and group.
+func marshalUint32(t uint16, value uint32) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], t)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	binary.BigEndian.PutUint32(v[4:8], value)
+
+	return v
+}
+
+func marshalSetField(tlv []byte, err error) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -92,50 +118,253 @@ func marshalMAC(t uint8, mac net.HardwareAddr) ([]byte, error) {
 	return v, nil
 }
 
-// TODO: Marshal Enqueue
+func marshalMAC(t uint8, mac net.HardwareAddr) ([]byte, error) {
+	if mac == nil || len(mac) < 6 {
+		return nil, openflow.ErrInvalidMACAddress
+	}
 
-// TODO: Marshal SetVLANVID
+	return marshalSetField(marshalHardwareAddrTLV(t, mac))
+}
 
-func (r *Action) MarshalBinary() ([]byte, error) {
-	if err := r.Error(); err != nil {
-		return nil, err
+func marshalIPv4(t uint8, ip net.IP) ([]byte, error) {
+	ipv4 := ip.To4()
+	if ipv4 == nil {
+		return nil, openflow.ErrInvalidIPAddress
 	}
 
+	return marshalSetField(marshalUint32TLV(t, binary.BigEndian.Uint32(ipv4)))
+}
+
+func (r *Action) marshalSetFields() ([]byte, error) {
 	result := make([]byte, 0)
-	if ok, srcMAC := r.SrcMAC(); ok {
-		v, err := marshalMAC(OFPXMT_OFB_ETH_SRC, srcMAC)
+	add := func(v []byte, err error) error {
 		if err != nil {
-			return nil, err
+			return err
 		}
 		result = append(result, v...)
+		return nil
+	}
+
+	if ok, srcMAC := r.SrcMAC(); ok {
+		if err := add(marshalMAC(OFPXMT_OFB_ETH_SRC, srcMAC)); err != nil {
+			return nil, err
+		}
 	}
 	if ok, dstMAC := r.DstMAC(); ok {
-		v, err := marshalMAC(OFPXMT_OFB_ETH_DST, dstMAC)
-		if err != nil {
+		if err := add(marshalMAC(OFPXMT_OFB_ETH_DST, dstMAC)); err != nil {
+			return nil, err
+		}
+	}
+	if ok, vid := r.VLANID(); ok {
+		if err := add(marshalSetField(marshalUint16TLV(OFPXMT_OFB_VLAN_VID, vid|OFPVID_PRESENT))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, pcp := r.VLANPriority(); ok {
+		if err := add(marshalSetField(marshalUint8TLV(OFPXMT_OFB_VLAN_PCP, pcp))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, srcIP := r.SrcIP(); ok {
+		if err := add(marshalIPv4(OFPXMT_OFB_IPV4_SRC, srcIP)); err != nil {
+			return nil, err
+		}
+	}
+	if ok, dstIP := r.DstIP(); ok {
+		if err := add(marshalIPv4(OFPXMT_OFB_IPV4_DST, dstIP)); err != nil {
+			return nil, err
+		}
+	}
+	if ok, dscp := r.DSCP(); ok {
+		if err := add(marshalSetField(marshalUint8TLV(OFPXMT_OFB_IP_DSCP, dscp))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, ecn := r.ECN(); ok {
+		if err := add(marshalSetField(marshalUint8TLV(OFPXMT_OFB_IP_ECN, ecn))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, port := r.TCPSrcPort(); ok {
+		if err := add(marshalSetField(marshalUint16TLV(OFPXMT_OFB_TCP_SRC, port))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, port := r.TCPDstPort(); ok {
+		if err := add(marshalSetField(marshalUint16TLV(OFPXMT_OFB_TCP_DST, port))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, port := r.UDPSrcPort(); ok {
+		if err := add(marshalSetField(marshalUint16TLV(OFPXMT_OFB_UDP_SRC, port))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, port := r.UDPDstPort(); ok {
+		if err := add(marshalSetField(marshalUint16TLV(OFPXMT_OFB_UDP_DST, port))); err != nil {
+			return nil, err
+		}
+	}
+	if ok, id := r.TunnelID(); ok {
+		if err := add(marshalSetField(marshalUint64TLV(OFPXMT_OFB_TUNNEL_ID, id))); err != nil {
 			return nil, err
 		}
-		result = append(result, v...)
 	}
 
-	v, err := marshalOutput(r.OutPort())
+	return result, nil
+}
+
+func (r *Action) MarshalBinary() ([]byte, error) {
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+
+	// The actions are encoded in the order of the action set.
+	result := make([]byte, 0)
+	if r.CopyTTLIn() {
+		result = append(result, marshalHeaderOnly(OFPAT_COPY_TTL_IN)...)
+	}
+	if r.PopVLAN() {
+		result = append(result, marshalHeaderOnly(OFPAT_POP_VLAN)...)
+	}
+	if ok, etherType := r.PopMPLS(); ok {
+		result = append(result, marshalEtherType(OFPAT_POP_MPLS, etherType)...)
+	}
+	if ok, etherType := r.PushMPLS(); ok {
+		result = append(result, marshalEtherType(OFPAT_PUSH_MPLS, etherType)...)
+	}
+	if ok, etherType := r.PushVLAN(); ok {
+		result = append(result, marshalEtherType(OFPAT_PUSH_VLAN, etherType)...)
+	}
+	if r.CopyTTLOut() {
+		result = append(result, marshalHeaderOnly(OFPAT_COPY_TTL_OUT)...)
+	}
+	if r.DecNwTTL() {
+		result = append(result, marshalHeaderOnly(OFPAT_DEC_NW_TTL)...)
+	}
+
+	v, err := r.marshalSetFields()
 	if err != nil {
 		return nil, err
 	}
 	result = append(result, v...)
 
+	if ok, queue := r.Queue(); ok {
+		result = append(result, marshalUint32(OFPAT_SET_QUEUE, queue)...)
+	}
+	if ok, group := r.Group(); ok {
+		result = append(result, marshalUint32(OFPAT_GROUP, group)...)
+	}
+	for _, v := range r.ExperimenterActions() {
+		a, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a...)
+	}
+	// Output port can be omitted if the packets are sent to a group or handled
+	// by the experimenter actions, e.g., resubmit.
+	if ok, _ := r.Group(); (!ok && len(r.ExperimenterActions()) == 0) || r.HasOutPort() {
+		v, err := marshalOutput(r.OutPort())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v...)
+	}
+
 	return result, nil
 }
 
-// TODO: Unmarshal Enqueue
+func (r *Action) unmarshalSetField(buf []byte) error {
+	if len(buf) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	header := binary.BigEndian.Uint32(buf[4:8])
+	class := header >> 16 & 0xFFFF
+	if class != 0x8000 {
+		return errors.New("unsupported TLV class")
+	}
+	field := header >> 9 & 0x7F
+	length := int(header & 0xFF)
+	if len(buf) < 8+length {
+		return openflow.ErrInvalidPacketLength
+	}
+	value := buf[8 : 8+length]
 
-// TODO: Unmarshal SetVLANVID
+	switch field {
+	case OFPXMT_OFB_ETH_DST:
+		if length < 6 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetDstMAC(value[0:6])
+	case OFPXMT_OFB_ETH_SRC:
+		if length < 6 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetSrcMAC(value[0:6])
+	case OFPXMT_OFB_VLAN_VID:
+		if length < 2 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetVLANID(binary.BigEndian.Uint16(value[0:2]) &^ OFPVID_PRESENT)
+	case OFPXMT_OFB_VLAN_PCP:
+		if length < 1 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetVLANPriority(value[0])
+	case OFPXMT_OFB_IPV4_SRC:
+		if length < 4 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetSrcIP(net.IPv4(value[0], value[1], value[2], value[3]))
+	case OFPXMT_OFB_IPV4_DST:
+		if length < 4 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetDstIP(net.IPv4(value[0], value[1], value[2], value[3]))
+	case OFPXMT_OFB_IP_DSCP:
+		if length < 1 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetDSCP(value[0])
+	case OFPXMT_OFB_IP_ECN:
+		if length < 1 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetECN(value[0])
+	case OFPXMT_OFB_TCP_SRC, OFPXMT_OFB_TCP_DST, OFPXMT_OFB_UDP_SRC, OFPXMT_OFB_UDP_DST:
+		if length < 2 {
+			return openflow.ErrInvalidPacketLength
+		}
+		port := binary.BigEndian.Uint16(value[0:2])
+		switch field {
+		case OFPXMT_OFB_TCP_SRC:
+			r.SetTCPSrcPort(port)
+		case OFPXMT_OFB_TCP_DST:
+			r.SetTCPDstPort(port)
+		case OFPXMT_OFB_UDP_SRC:
+			r.SetUDPSrcPort(port)
+		case OFPXMT_OFB_UDP_DST:
+			r.SetUDPDstPort(port)
+		}
+	case OFPXMT_OFB_TUNNEL_ID:
+		if length < 8 {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.SetTunnelID(binary.BigEndian.Uint64(value[0:8]))
+	default:
+		// Do nothing
+	}
+
+	return r.Error()
+}
 
 func (r *Action) UnmarshalBinary(data []byte) error {
 	buf := data
 	for len(buf) >= 4 {
 		t := binary.BigEndian.Uint16(buf[0:2])
 		length := binary.BigEndian.Uint16(buf[2:4])
-		if len(buf) < int(length) {
+		if length < 4 || len(buf) < int(length) {
 			return openflow.ErrInvalidPacketLength
 		}
 
@@ -146,44 +375,56 @@ func (r *Action) UnmarshalBinary(data []byte) error {
 			}
 			outPort := openflow.NewOutPort()
 			outPort.SetValue(binary.BigEndian.Uint32(buf[4:8]))
+			if len(buf) >= 10 {
+				outPort.SetMaxLength(binary.BigEndian.Uint16(buf[8:10]))
+			}
 			r.SetOutPort(outPort)
-			if err := r.Error(); err != nil {
-				return err
+		case OFPAT_COPY_TTL_IN:
+			r.SetCopyTTLIn(true)
+		case OFPAT_COPY_TTL_OUT:
+			r.SetCopyTTLOut(true)
+		case OFPAT_DEC_NW_TTL:
+			r.SetDecNwTTL(true)
+		case OFPAT_POP_VLAN:
+			r.SetPopVLAN(true)
+		case OFPAT_PUSH_VLAN, OFPAT_PUSH_MPLS, OFPAT_POP_MPLS:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
 			}
-		case OFPAT_SET_FIELD:
+			etherType := binary.BigEndian.Uint16(buf[4:6])
+			switch t {
+			case OFPAT_PUSH_VLAN:
+				r.SetPushVLAN(etherType)
+			case OFPAT_PUSH_MPLS:
+				r.SetPushMPLS(etherType)
+			case OFPAT_POP_MPLS:
+				r.SetPopMPLS(etherType)
+			}
+		case OFPAT_SET_QUEUE:
 			if len(buf) < 8 {
 				return openflow.ErrInvalidPacketLength
 			}
-			header := binary.BigEndian.Uint32(buf[4:8])
-			class := header >> 16 & 0xFFFF
-			if class != 0x8000 {
-				return errors.New("unsupported TLV class")
+			r.SetQueue(binary.BigEndian.Uint32(buf[4:8]))
+		case OFPAT_GROUP:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
+			}
+			r.SetGroup(binary.BigEndian.Uint32(buf[4:8]))
+		case OFPAT_SET_FIELD:
+			if err := r.unmarshalSetField(buf[:length]); err != nil {
+				return err
 			}
-			field := header >> 9 & 0x7F
-
-			switch field {
-			case OFPXMT_OFB_ETH_DST:
-				if len(buf) < 14 {
-					return openflow.ErrInvalidPacketLength
-				}
-				r.SetDstMAC(buf[8:14])
-				if err := r.Error(); err != nil {
-					return err
-				}
-			case OFPXMT_OFB_ETH_SRC:
-				if len(buf) < 14 {
-					return openflow.ErrInvalidPacketLength
-				}
-				r.SetSrcMAC(buf[8:14])
-				if err := r.Error(); err != nil {
-					return err
-				}
-			default:
-				// Do nothing
+		case OFPAT_EXPERIMENTER:
+			if len(buf) < 8 {
+				return openflow.ErrInvalidPacketLength
 			}
+			r.AddExperimenterAction(openflow.RawExperimenterAction(append([]byte(nil), buf[:length]...)))
 		default:
 			// Do nothing
 		}
+		if err := r.Error(); err != nil {
+			return err
+		}
 
 		buf = buf[length:]
 	}
@@ -0,0 +1,73 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func TestOutputMaxLength(t *testing.T) {
+	tests := []struct {
+		maxLength uint16
+		set       bool
+		expected  uint16
+	}{
+		// The whole packet without buffering by default.
+		{0, false, openflow.NoBufferLength},
+		{128, true, 128},
+		{0, true, 0},
+	}
+
+	for i, test := range tests {
+		outPort := openflow.NewOutPort()
+		outPort.SetController()
+		if test.set {
+			outPort.SetMaxLength(test.maxLength)
+		}
+		action := NewAction()
+		action.SetOutPort(outPort)
+		v, err := action.MarshalBinary()
+		if err != nil {
+			t.Fatalf("#%v: unexpected error: %v", i, err)
+		}
+		if len(v) != 16 || binary.BigEndian.Uint16(v[0:2]) != OFPAT_OUTPUT {
+			t.Fatalf("#%v: unexpected output action: %v", i, v)
+		}
+		if port := binary.BigEndian.Uint32(v[4:8]); port != OFPP_CONTROLLER {
+			t.Fatalf("#%v: unexpected port: %#x", i, port)
+		}
+		if l := binary.BigEndian.Uint16(v[8:10]); l != test.expected {
+			t.Fatalf("#%v: unexpected max_len: expected=%v, got=%v", i, test.expected, l)
+		}
+
+		decoded := NewAction()
+		if err := decoded.UnmarshalBinary(v); err != nil {
+			t.Fatalf("#%v: unexpected error: %v", i, err)
+		}
+		if p := decoded.OutPort(); p.MaxLength() != test.expected {
+			t.Fatalf("#%v: unexpected decoded max_len: expected=%v, got=%v", i, test.expected, p.MaxLength())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+var (
+	packetInReasons = map[openflow.PacketInReason]uint8{
+		openflow.PacketInNoMatch:    OFPR_NO_MATCH,
+		openflow.PacketInAction:     OFPR_ACTION,
+		openflow.PacketInInvalidTTL: OFPR_INVALID_TTL,
+	}
+	portStatusReasons = map[openflow.PortReason]uint8{
+		openflow.PortAdded:    OFPPR_ADD,
+		openflow.PortDeleted:  OFPPR_DELETE,
+		openflow.PortModified: OFPPR_MODIFY,
+	}
+	flowRemovedReasons = map[openflow.FlowRemovedReason]uint8{
+		openflow.FlowRemovedIdleTimeout: OFPRR_IDLE_TIMEOUT,
+		openflow.FlowRemovedHardTimeout: OFPRR_HARD_TIMEOUT,
+		openflow.FlowRemovedDelete:      OFPRR_DELETE,
+		openflow.FlowRemovedGroupDelete: OFPRR_GROUP_DELETE,
+	}
+)
+
+// AsyncConfig keeps the reason bitmasks of the asynchronous messages. Index 0 of
+// each mask is for the master and equal roles, and index 1 is for the slave role.
+type AsyncConfig struct {
+	err             error
+	packetInMask    [2]uint32
+	portStatusMask  [2]uint32
+	flowRemovedMask [2]uint32
+}
+
+func getRoleIndex(role openflow.ControllerRole) (int, error) {
+	switch role {
+	case openflow.RoleMaster, openflow.RoleEqual:
+		return 0, nil
+	case openflow.RoleSlave:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unexpected controller role for the async config: %v", role)
+	}
+}
+
+func (r *AsyncConfig) Error() error {
+	return r.err
+}
+
+func (r *AsyncConfig) PacketInReasons(role openflow.ControllerRole) []openflow.PacketInReason {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		return nil
+	}
+
+	result := make([]openflow.PacketInReason, 0)
+	for reason, bit := range packetInReasons {
+		if r.packetInMask[idx]&(1<<bit) != 0 {
+			result = append(result, reason)
+		}
+	}
+
+	return result
+}
+
+func (r *AsyncConfig) SetPacketInReasons(role openflow.ControllerRole, reasons ...openflow.PacketInReason) {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	var mask uint32
+	for _, v := range reasons {
+		bit, ok := packetInReasons[v]
+		if !ok {
+			r.err = fmt.Errorf("SetPacketInReasons: unexpected packet-in reason: %v", v)
+			return
+		}
+		mask |= 1 << bit
+	}
+	r.packetInMask[idx] = mask
+}
+
+func (r *AsyncConfig) PortStatusReasons(role openflow.ControllerRole) []openflow.PortReason {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		return nil
+	}
+
+	result := make([]openflow.PortReason, 0)
+	for reason, bit := range portStatusReasons {
+		if r.portStatusMask[idx]&(1<<bit) != 0 {
+			result = append(result, reason)
+		}
+	}
+
+	return result
+}
+
+func (r *AsyncConfig) SetPortStatusReasons(role openflow.ControllerRole, reasons ...openflow.PortReason) {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	var mask uint32
+	for _, v := range reasons {
+		bit, ok := portStatusReasons[v]
+		if !ok {
+			r.err = fmt.Errorf("SetPortStatusReasons: unexpected port status reason: %v", v)
+			return
+		}
+		mask |= 1 << bit
+	}
+	r.portStatusMask[idx] = mask
+}
+
+func (r *AsyncConfig) FlowRemovedReasons(role openflow.ControllerRole) []openflow.FlowRemovedReason {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		return nil
+	}
+
+	result := make([]openflow.FlowRemovedReason, 0)
+	for reason, bit := range flowRemovedReasons {
+		if r.flowRemovedMask[idx]&(1<<bit) != 0 {
+			result = append(result, reason)
+		}
+	}
+
+	return result
+}
+
+func (r *AsyncConfig) SetFlowRemovedReasons(role openflow.ControllerRole, reasons ...openflow.FlowRemovedReason) {
+	idx, err := getRoleIndex(role)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	var mask uint32
+	for _, v := range reasons {
+		bit, ok := flowRemovedReasons[v]
+		if !ok {
+			r.err = fmt.Errorf("SetFlowRemovedReasons: unexpected flow removed reason: %v", v)
+			return
+		}
+		mask |= 1 << bit
+	}
+	r.flowRemovedMask[idx] = mask
+}
+
+func (r *AsyncConfig) marshal() []byte {
+	v := make([]byte, 24)
+	for i := 0; i < 2; i++ {
+		binary.BigEndian.PutUint32(v[i*4:i*4+4], r.packetInMask[i])
+		binary.BigEndian.PutUint32(v[8+i*4:8+i*4+4], r.portStatusMask[i])
+		binary.BigEndian.PutUint32(v[16+i*4:16+i*4+4], r.flowRemovedMask[i])
+	}
+
+	return v
+}
+
+func (r *AsyncConfig) unmarshal(data []byte) error {
+	if len(data) < 24 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	for i := 0; i < 2; i++ {
+		r.packetInMask[i] = binary.BigEndian.Uint32(data[i*4 : i*4+4])
+		r.portStatusMask[i] = binary.BigEndian.Uint32(data[8+i*4 : 8+i*4+4])
+		r.flowRemovedMask[i] = binary.BigEndian.Uint32(data[16+i*4 : 16+i*4+4])
+	}
+
+	return nil
+}
+
+type SetAsync struct {
+	openflow.Message
+	AsyncConfig
+}
+
+func NewSetAsync(xid uint32) openflow.SetAsync {
+	return &SetAsync{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_SET_ASYNC, xid),
+	}
+}
+
+func (r *SetAsync) MarshalBinary() ([]byte, error) {
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+
+	r.SetPayload(r.marshal())
+	return r.Message.MarshalBinary()
+}
+
+type GetAsyncRequest struct {
+	openflow.Message
+}
+
+func NewGetAsyncRequest(xid uint32) openflow.GetAsyncRequest {
+	return &GetAsyncRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_GET_ASYNC_REQUEST, xid),
+	}
+}
+
+func (r *GetAsyncRequest) MarshalBinary() ([]byte, error) {
+	return r.Message.MarshalBinary()
+}
+
+type GetAsyncReply struct {
+	openflow.Message
+	AsyncConfig
+}
+
+func (r *GetAsyncReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	return r.unmarshal(r.Payload())
+}
@@ -0,0 +1,261 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// OpenFlow 1.3 does not have the bundles, but some switches implement them as the
+// ONF bundle extension whose messages are the experimenter messages having the
+// same bodies with the OpenFlow 1.4 bundle messages.
+
+var (
+	bundleCtrlRequests = map[openflow.BundleCtrlType]uint16{
+		openflow.BundleOpen:    ONFBCT_OPEN_REQUEST,
+		openflow.BundleClose:   ONFBCT_CLOSE_REQUEST,
+		openflow.BundleCommit:  ONFBCT_COMMIT_REQUEST,
+		openflow.BundleDiscard: ONFBCT_DISCARD_REQUEST,
+	}
+	bundleCtrlReplies = map[openflow.BundleCtrlType]uint16{
+		openflow.BundleOpen:    ONFBCT_OPEN_REPLY,
+		openflow.BundleClose:   ONFBCT_CLOSE_REPLY,
+		openflow.BundleCommit:  ONFBCT_COMMIT_REPLY,
+		openflow.BundleDiscard: ONFBCT_DISCARD_REPLY,
+	}
+)
+
+func getBundleFlags(flags openflow.BundleFlag) uint16 {
+	var v uint16
+	if flags&openflow.BundleAtomic != 0 {
+		v |= ONFBF_ATOMIC
+	}
+	if flags&openflow.BundleOrdered != 0 {
+		v |= ONFBF_ORDERED
+	}
+
+	return v
+}
+
+func toBundleFlags(flags uint16) openflow.BundleFlag {
+	var v openflow.BundleFlag
+	if flags&ONFBF_ATOMIC != 0 {
+		v |= openflow.BundleAtomic
+	}
+	if flags&ONFBF_ORDERED != 0 {
+		v |= openflow.BundleOrdered
+	}
+
+	return v
+}
+
+func marshalONFHeader(expType uint32) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint32(v[0:4], ONF_EXPERIMENTER_ID)
+	binary.BigEndian.PutUint32(v[4:8], expType)
+
+	return v
+}
+
+// IsBundleControl returns whether the packet is the ONF bundle control message.
+func IsBundleControl(packet []byte) bool {
+	if len(packet) < 16 || packet[1] != OFPT_EXPERIMENTER {
+		return false
+	}
+
+	return binary.BigEndian.Uint32(packet[8:12]) == ONF_EXPERIMENTER_ID &&
+		binary.BigEndian.Uint32(packet[12:16]) == ONFT_BUNDLE_CONTROL
+}
+
+type BundleControl struct {
+	openflow.Message
+	bundleID uint32
+	ctrlType uint16
+	flags    uint16
+}
+
+func NewBundleControl(xid uint32) openflow.BundleControl {
+	return &BundleControl{
+		Message:  openflow.NewMessage(openflow.OF13_VERSION, OFPT_EXPERIMENTER, xid),
+		ctrlType: ONFBCT_OPEN_REQUEST,
+	}
+}
+
+func (r *BundleControl) BundleID() uint32 {
+	return r.bundleID
+}
+
+func (r *BundleControl) SetBundleID(id uint32) {
+	r.bundleID = id
+}
+
+func (r *BundleControl) ControlType() openflow.BundleCtrlType {
+	for t, v := range bundleCtrlRequests {
+		if v == r.ctrlType {
+			return t
+		}
+	}
+	for t, v := range bundleCtrlReplies {
+		if v == r.ctrlType {
+			return t
+		}
+	}
+
+	return openflow.BundleCtrlType(r.ctrlType)
+}
+
+func (r *BundleControl) SetControlType(t openflow.BundleCtrlType) {
+	v, ok := bundleCtrlRequests[t]
+	if !ok {
+		panic(fmt.Sprintf("unexpected bundle control type: %v", t))
+	}
+	r.ctrlType = v
+}
+
+func (r *BundleControl) Flags() openflow.BundleFlag {
+	return toBundleFlags(r.flags)
+}
+
+func (r *BundleControl) SetFlags(flags openflow.BundleFlag) {
+	r.flags = getBundleFlags(flags)
+}
+
+func (r *BundleControl) IsReply() bool {
+	// Reply types are odd numbers.
+	return r.ctrlType%2 == 1
+}
+
+func (r *BundleControl) MarshalBinary() ([]byte, error) {
+	v := marshalONFHeader(ONFT_BUNDLE_CONTROL)
+	body := make([]byte, 8)
+	binary.BigEndian.PutUint32(body[0:4], r.bundleID)
+	binary.BigEndian.PutUint16(body[4:6], r.ctrlType)
+	binary.BigEndian.PutUint16(body[6:8], r.flags)
+	// No properties
+	r.SetPayload(append(v, body...))
+
+	return r.Message.MarshalBinary()
+}
+
+func (r *BundleControl) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if !IsBundleControl(data) {
+		return errors.New("not a bundle control message")
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 16 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:8] is the experimenter header
+	r.bundleID = binary.BigEndian.Uint32(payload[8:12])
+	r.ctrlType = binary.BigEndian.Uint16(payload[12:14])
+	r.flags = binary.BigEndian.Uint16(payload[14:16])
+	// Ignore the properties.
+
+	return nil
+}
+
+type BundleAdd struct {
+	openflow.Message
+	err      error
+	bundleID uint32
+	flags    uint16
+	msg      openflow.BundleMessage
+}
+
+func NewBundleAdd(xid uint32) openflow.BundleAdd {
+	return &BundleAdd{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_EXPERIMENTER, xid),
+	}
+}
+
+func (r *BundleAdd) Error() error {
+	return r.err
+}
+
+func (r *BundleAdd) BundleID() uint32 {
+	return r.bundleID
+}
+
+func (r *BundleAdd) SetBundleID(id uint32) {
+	r.bundleID = id
+}
+
+func (r *BundleAdd) Flags() openflow.BundleFlag {
+	return toBundleFlags(r.flags)
+}
+
+func (r *BundleAdd) SetFlags(flags openflow.BundleFlag) {
+	r.flags = getBundleFlags(flags)
+}
+
+func (r *BundleAdd) BundledMessage() openflow.BundleMessage {
+	return r.msg
+}
+
+func (r *BundleAdd) SetBundledMessage(msg openflow.BundleMessage) {
+	if msg == nil {
+		r.err = errors.New("SetBundledMessage: nil message")
+		return
+	}
+	if msg.Version() != openflow.OF13_VERSION {
+		r.err = fmt.Errorf("SetBundledMessage: mis-matched OpenFlow version: %v", msg.Version())
+		return
+	}
+	r.msg = msg
+}
+
+func (r *BundleAdd) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.msg == nil {
+		return nil, errors.New("empty bundle message")
+	}
+
+	// Use the same transaction ID with the message so that the errors caused by
+	// the message can be matched with this BundleAdd.
+	r.msg.SetTransactionID(r.TransactionID())
+	msg, err := r.msg.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	v := marshalONFHeader(ONFT_BUNDLE_ADD_MESSAGE)
+	body := make([]byte, 8)
+	binary.BigEndian.PutUint32(body[0:4], r.bundleID)
+	// body[4:6] is padding
+	binary.BigEndian.PutUint16(body[6:8], r.flags)
+	// No properties, so that the message does not need the padding.
+	v = append(v, body...)
+	v = append(v, msg...)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
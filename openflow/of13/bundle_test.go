@@ -0,0 +1,150 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func TestBundleControl(t *testing.T) {
+	req := NewBundleControl(3)
+	req.SetBundleID(7)
+	req.SetControlType(openflow.BundleCommit)
+	req.SetFlags(openflow.BundleAtomic | openflow.BundleOrdered)
+	if req.IsReply() {
+		t.Fatal("unexpected reply")
+	}
+	packet, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ONF bundle extension is an experimenter message.
+	if len(packet) != 24 || packet[1] != OFPT_EXPERIMENTER {
+		t.Fatalf("unexpected message: length=%v, type=%v", len(packet), packet[1])
+	}
+	if v := binary.BigEndian.Uint32(packet[8:12]); v != ONF_EXPERIMENTER_ID {
+		t.Fatalf("unexpected experimenter ID: 0x%x", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[12:16]); v != ONFT_BUNDLE_CONTROL {
+		t.Fatalf("unexpected experimenter type: %v", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[16:20]); v != 7 {
+		t.Fatalf("unexpected bundle ID: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[20:22]); v != ONFBCT_COMMIT_REQUEST {
+		t.Fatalf("unexpected control type: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[22:24]); v != ONFBF_ATOMIC|ONFBF_ORDERED {
+		t.Fatalf("unexpected flags: %v", v)
+	}
+	if !IsBundleControl(packet) {
+		t.Fatal("expected a bundle control message")
+	}
+
+	// Decode the reply to the request.
+	binary.BigEndian.PutUint16(packet[20:22], ONFBCT_COMMIT_REPLY)
+	reply := new(BundleControl)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.IsReply() || reply.ControlType() != openflow.BundleCommit {
+		t.Fatalf("unexpected reply: isReply=%v, type=%v", reply.IsReply(), reply.ControlType())
+	}
+	if reply.TransactionID() != 3 || reply.BundleID() != 7 || reply.Flags() != openflow.BundleAtomic|openflow.BundleOrdered {
+		t.Fatalf("unexpected reply: xid=%v, id=%v, flags=%v", reply.TransactionID(), reply.BundleID(), reply.Flags())
+	}
+
+	// Other experimenter messages are not the bundle control.
+	other := append([]byte{}, packet...)
+	binary.BigEndian.PutUint32(other[8:12], 0x2320)
+	if IsBundleControl(other) {
+		t.Fatal("unexpected bundle control of the other experimenter")
+	}
+	if err := new(BundleControl).UnmarshalBinary(other); err == nil {
+		t.Fatal("expected an error for the other experimenter")
+	}
+	if IsBundleControl(packet[:12]) {
+		t.Fatal("unexpected bundle control of the truncated message")
+	}
+}
+
+func TestBundleAdd(t *testing.T) {
+	flow := NewFlowMod(0, OFPFC_ADD)
+	flow.SetPriority(100)
+	flow.SetFlowMatch(NewMatch())
+
+	add := NewBundleAdd(9)
+	add.SetBundleID(7)
+	add.SetFlags(openflow.BundleAtomic)
+	add.SetBundledMessage(flow)
+	if add.Error() != nil {
+		t.Fatal(add.Error())
+	}
+	packet, err := add.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet[1] != OFPT_EXPERIMENTER || IsBundleControl(packet) {
+		t.Fatalf("unexpected message type: %v", packet[1])
+	}
+	if v := binary.BigEndian.Uint32(packet[8:12]); v != ONF_EXPERIMENTER_ID {
+		t.Fatalf("unexpected experimenter ID: 0x%x", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[12:16]); v != ONFT_BUNDLE_ADD_MESSAGE {
+		t.Fatalf("unexpected experimenter type: %v", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[16:20]); v != 7 {
+		t.Fatalf("unexpected bundle ID: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[22:24]); v != ONFBF_ATOMIC {
+		t.Fatalf("unexpected flags: %v", v)
+	}
+
+	// The bundled message has the same transaction ID with the BundleAdd.
+	msg, err := flow.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow.TransactionID() != 9 || !bytes.Equal(packet[24:], msg) {
+		t.Fatalf("unexpected bundled message: xid=%v, message=%v", flow.TransactionID(), packet[24:])
+	}
+
+	invalid := NewBundleAdd(10)
+	invalid.SetBundledMessage(nil)
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the nil message")
+	}
+	invalid = NewBundleAdd(11)
+	other := openflow.NewMessage(openflow.OF14_VERSION, OFPT_FLOW_MOD, 0)
+	invalid.SetBundledMessage(&other)
+	if _, err := invalid.MarshalBinary(); err == nil {
+		t.Fatal("expected an error for the mis-matched version")
+	}
+	if _, err := NewBundleAdd(12).MarshalBinary(); err == nil {
+		t.Fatal("expected an error for the empty bundle")
+	}
+}
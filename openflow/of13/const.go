@@ -65,8 +65,23 @@ const (
 )
 
 const (
-	OFPAT_OUTPUT    = 0
-	OFPAT_SET_FIELD = 25
+	OFPAT_OUTPUT       = 0  /* Output to switch port. */
+	OFPAT_COPY_TTL_OUT = 11 /* Copy TTL "outwards" -- from next-to-outermost to outermost */
+	OFPAT_COPY_TTL_IN  = 12 /* Copy TTL "inwards" -- from outermost to next-to-outermost */
+	OFPAT_SET_MPLS_TTL = 15 /* MPLS TTL */
+	OFPAT_DEC_MPLS_TTL = 16 /* Decrement MPLS TTL */
+	OFPAT_PUSH_VLAN    = 17 /* Push a new VLAN tag */
+	OFPAT_POP_VLAN     = 18 /* Pop the outer VLAN tag */
+	OFPAT_PUSH_MPLS    = 19 /* Push a new MPLS tag */
+	OFPAT_POP_MPLS     = 20 /* Pop the outer MPLS tag */
+	OFPAT_SET_QUEUE    = 21 /* Set queue id when outputting to a port */
+	OFPAT_GROUP        = 22 /* Apply group. */
+	OFPAT_SET_NW_TTL   = 23 /* IP TTL. */
+	OFPAT_DEC_NW_TTL   = 24 /* Decrement IP TTL. */
+	OFPAT_SET_FIELD    = 25 /* Set a header field using OXM TLV format. */
+	OFPAT_PUSH_PBB     = 26 /* Push a new PBB service tag (I-TAG) */
+	OFPAT_POP_PBB      = 27 /* Pop the outer PBB service tag (I-TAG) */
+	OFPAT_EXPERIMENTER = 0xffff
 )
 
 const (
@@ -84,6 +99,17 @@ const (
 	OFPP_ANY        = 0xffffffff /* Wildcard */
 )
 
+const (
+	OFPXMC_NXM_0          = 0x0000 /* Backward compatibility with NXM */
+	OFPXMC_NXM_1          = 0x0001 /* Backward compatibility with NXM */
+	OFPXMC_OPENFLOW_BASIC = 0x8000 /* Basic class for OpenFlow */
+	OFPXMC_EXPERIMENTER   = 0xFFFF /* Experimenter class */
+)
+
+// NXM_NX_MAX_REGS is the number of the Nicira extension registers, reg0-reg7,
+// whose OXM class is OFPXMC_NXM_1 and field numbers are their indexes.
+const NXM_NX_MAX_REGS = 8
+
 const (
 	OFPXMT_OFB_IN_PORT = iota
 	OFPXMT_OFB_IN_PHY_PORT
@@ -252,7 +278,36 @@ const (
 )
 
 const (
-	OFPG_ANY = 0xffffffff
+	OFPMPF_REQ_MORE   = 1 << 0 /* More requests to follow. */
+	OFPMPF_REPLY_MORE = 1 << 0 /* More replies to follow. */
+)
+
+const (
+	/* Last usable group number. */
+	OFPG_MAX = 0xffffff00
+	/* Fake groups. */
+	OFPG_ALL = 0xfffffffc /* Represents all groups for group delete commands. */
+	OFPG_ANY = 0xffffffff /* Wildcard group used only for flow stats requests. Selects all flows regardless of group (including flows with no group). */
+)
+
+const (
+	OFPGC_ADD    = 0 /* New group. */
+	OFPGC_MODIFY = 1 /* Modify all matching groups. */
+	OFPGC_DELETE = 2 /* Delete all matching groups. */
+)
+
+const (
+	OFPGT_ALL      = 0 /* All (multicast/broadcast) group. */
+	OFPGT_SELECT   = 1 /* Select group. */
+	OFPGT_INDIRECT = 2 /* Indirect group. */
+	OFPGT_FF       = 3 /* Fast failover group. */
+)
+
+const (
+	OFPGFC_SELECT_WEIGHT   = 1 << 0 /* Support weight for select groups */
+	OFPGFC_SELECT_LIVENESS = 1 << 1 /* Support liveness for select groups */
+	OFPGFC_CHAINING        = 1 << 2 /* Support chaining groups */
+	OFPGFC_CHAINING_CHECKS = 1 << 3 /* Check chaining for loops and delete */
 )
 
 const (
@@ -277,3 +332,281 @@ const (
 	OFPIT_METER          = 6      /* Apply meter (rate limiter) */
 	OFPIT_EXPERIMENTER   = 0xFFFF /* Experimenter instruction */
 )
+
+const (
+	OFPMC_ADD    = 0 /* New meter. */
+	OFPMC_MODIFY = 1 /* Modify specified meter. */
+	OFPMC_DELETE = 2 /* Delete specified meter. */
+)
+
+const (
+	OFPMF_KBPS  = 1 << 0 /* Rate value in kb/s (kilo-bit per second). */
+	OFPMF_PKTPS = 1 << 1 /* Rate value in packet/sec. */
+	OFPMF_BURST = 1 << 2 /* Do burst size. */
+	OFPMF_STATS = 1 << 3 /* Collect statistics. */
+)
+
+const (
+	/* Last usable table number. */
+	OFPTT_MAX = 0xfe
+	/* Wildcard table used for table config, flow stats and flow deletes. */
+	OFPTT_ALL = 0xff
+)
+
+const (
+	/* Last usable meter. */
+	OFPM_MAX = 0xffff0000
+	/* Virtual meters. */
+	OFPM_SLOWPATH   = 0xfffffffd /* Meter for slow datapath. */
+	OFPM_CONTROLLER = 0xfffffffe /* Meter for controller connection. */
+	OFPM_ALL        = 0xffffffff /* Represents all meters for stat requests commands. */
+)
+
+const (
+	OFPMBT_DROP         = 1      /* Drop packet. */
+	OFPMBT_DSCP_REMARK  = 2      /* Remark DSCP in the IP header. */
+	OFPMBT_EXPERIMENTER = 0xFFFF /* Experimenter meter band. */
+)
+
+const (
+	OFPQ_ALL = 0xffffffff /* All ones is used to indicate all queues in a port (for stats retrieval). */
+)
+
+const (
+	OFPTFPT_INSTRUCTIONS        = 0      /* Instructions property. */
+	OFPTFPT_INSTRUCTIONS_MISS   = 1      /* Instructions for table-miss. */
+	OFPTFPT_NEXT_TABLES         = 2      /* Next Table property. */
+	OFPTFPT_NEXT_TABLES_MISS    = 3      /* Next Table for table-miss. */
+	OFPTFPT_WRITE_ACTIONS       = 4      /* Write Actions property. */
+	OFPTFPT_WRITE_ACTIONS_MISS  = 5      /* Write Actions for table-miss. */
+	OFPTFPT_APPLY_ACTIONS       = 6      /* Apply Actions property. */
+	OFPTFPT_APPLY_ACTIONS_MISS  = 7      /* Apply Actions for table-miss. */
+	OFPTFPT_MATCH               = 8      /* Match property. */
+	OFPTFPT_WILDCARDS           = 10     /* Wildcards property. */
+	OFPTFPT_WRITE_SETFIELD      = 12     /* Write Set-Field property. */
+	OFPTFPT_WRITE_SETFIELD_MISS = 13     /* Write Set-Field for table-miss. */
+	OFPTFPT_APPLY_SETFIELD      = 14     /* Apply Set-Field property. */
+	OFPTFPT_APPLY_SETFIELD_MISS = 15     /* Apply Set-Field for table-miss. */
+	OFPTFPT_EXPERIMENTER        = 0xFFFE /* Experimenter property. */
+	OFPTFPT_EXPERIMENTER_MISS   = 0xFFFF /* Experimenter for table-miss. */
+)
+
+const (
+	OFPCR_ROLE_NOCHANGE = 0 /* Don't change current role. */
+	OFPCR_ROLE_EQUAL    = 1 /* Default role, full access. */
+	OFPCR_ROLE_MASTER   = 2 /* Full access, at most one master. */
+	OFPCR_ROLE_SLAVE    = 3 /* Read-only access. */
+)
+
+const (
+	OFPET_HELLO_FAILED          = 0      /* Hello protocol failed. */
+	OFPET_BAD_REQUEST           = 1      /* Request was not understood. */
+	OFPET_BAD_ACTION            = 2      /* Error in action description. */
+	OFPET_BAD_INSTRUCTION       = 3      /* Error in instruction list. */
+	OFPET_BAD_MATCH             = 4      /* Error in match. */
+	OFPET_FLOW_MOD_FAILED       = 5      /* Problem modifying flow entry. */
+	OFPET_GROUP_MOD_FAILED      = 6      /* Problem modifying group entry. */
+	OFPET_PORT_MOD_FAILED       = 7      /* Port mod request failed. */
+	OFPET_TABLE_MOD_FAILED      = 8      /* Table mod request failed. */
+	OFPET_QUEUE_OP_FAILED       = 9      /* Queue operation failed. */
+	OFPET_SWITCH_CONFIG_FAILED  = 10     /* Switch config request failed. */
+	OFPET_ROLE_REQUEST_FAILED   = 11     /* Controller Role request failed. */
+	OFPET_METER_MOD_FAILED      = 12     /* Error in meter. */
+	OFPET_TABLE_FEATURES_FAILED = 13     /* Setting table features failed. */
+	OFPET_EXPERIMENTER          = 0xffff /* Experimenter error messages. */
+)
+
+const (
+	OFPRRFC_STALE    = 0 /* Stale Message: old generation_id. */
+	OFPRRFC_UNSUP    = 1 /* Controller role change unsupported. */
+	OFPRRFC_BAD_ROLE = 2 /* Invalid role. */
+)
+
+const (
+	OFPHFC_INCOMPATIBLE = 0 /* No compatible version. */
+	OFPHFC_EPERM        = 1 /* Permissions error. */
+)
+
+const (
+	OFPBRC_BAD_VERSION               = 0  /* ofp_header.version not supported. */
+	OFPBRC_BAD_TYPE                  = 1  /* ofp_header.type not supported. */
+	OFPBRC_BAD_MULTIPART             = 2  /* ofp_multipart_request.type not supported. */
+	OFPBRC_BAD_EXPERIMENTER          = 3  /* Experimenter id not supported. */
+	OFPBRC_BAD_EXP_TYPE              = 4  /* Experimenter type not supported. */
+	OFPBRC_EPERM                     = 5  /* Permissions error. */
+	OFPBRC_BAD_LEN                   = 6  /* Wrong request length for type. */
+	OFPBRC_BUFFER_EMPTY              = 7  /* Specified buffer has already been used. */
+	OFPBRC_BUFFER_UNKNOWN            = 8  /* Specified buffer does not exist. */
+	OFPBRC_BAD_TABLE_ID              = 9  /* Specified table-id invalid or does not exist. */
+	OFPBRC_IS_SLAVE                  = 10 /* Denied because controller is slave. */
+	OFPBRC_BAD_PORT                  = 11 /* Invalid port. */
+	OFPBRC_BAD_PACKET                = 12 /* Invalid packet in packet-out. */
+	OFPBRC_MULTIPART_BUFFER_OVERFLOW = 13 /* ofp_multipart_request overflowed the assigned buffer. */
+)
+
+const (
+	OFPBAC_BAD_TYPE           = 0  /* Unknown action type. */
+	OFPBAC_BAD_LEN            = 1  /* Length problem in actions. */
+	OFPBAC_BAD_EXPERIMENTER   = 2  /* Unknown experimenter id specified. */
+	OFPBAC_BAD_EXP_TYPE       = 3  /* Unknown action for experimenter id. */
+	OFPBAC_BAD_OUT_PORT       = 4  /* Problem validating output port. */
+	OFPBAC_BAD_ARGUMENT       = 5  /* Bad action argument. */
+	OFPBAC_EPERM              = 6  /* Permissions error. */
+	OFPBAC_TOO_MANY           = 7  /* Can't handle this many actions. */
+	OFPBAC_BAD_QUEUE          = 8  /* Problem validating output queue. */
+	OFPBAC_BAD_OUT_GROUP      = 9  /* Invalid group id in forward action. */
+	OFPBAC_MATCH_INCONSISTENT = 10 /* Action can't apply for this match, or Set-Field missing prerequisite. */
+	OFPBAC_UNSUPPORTED_ORDER  = 11 /* Action order is unsupported for the action list in an Apply-Actions instruction */
+	OFPBAC_BAD_TAG            = 12 /* Actions uses an unsupported tag/encap. */
+	OFPBAC_BAD_SET_TYPE       = 13 /* Unsupported type in SET_FIELD action. */
+	OFPBAC_BAD_SET_LEN        = 14 /* Length problem in SET_FIELD action. */
+	OFPBAC_BAD_SET_ARGUMENT   = 15 /* Bad argument in SET_FIELD action. */
+)
+
+const (
+	OFPBIC_UNKNOWN_INST        = 0 /* Unknown instruction. */
+	OFPBIC_UNSUP_INST          = 1 /* Switch or table does not support the instruction. */
+	OFPBIC_BAD_TABLE_ID        = 2 /* Invalid Table-ID specified. */
+	OFPBIC_UNSUP_METADATA      = 3 /* Metadata value unsupported by datapath. */
+	OFPBIC_UNSUP_METADATA_MASK = 4 /* Metadata mask value unsupported by datapath. */
+	OFPBIC_BAD_EXPERIMENTER    = 5 /* Unknown experimenter id specified. */
+	OFPBIC_BAD_EXP_TYPE        = 6 /* Unknown instruction for experimenter id. */
+	OFPBIC_BAD_LEN             = 7 /* Length problem in instructions. */
+	OFPBIC_EPERM               = 8 /* Permissions error. */
+)
+
+const (
+	OFPBMC_BAD_TYPE         = 0  /* Unsupported match type specified by the match */
+	OFPBMC_BAD_LEN          = 1  /* Length problem in match. */
+	OFPBMC_BAD_TAG          = 2  /* Match uses an unsupported tag/encap. */
+	OFPBMC_BAD_DL_ADDR_MASK = 3  /* Unsupported datalink addr mask - switch does not support arbitrary datalink address mask. */
+	OFPBMC_BAD_NW_ADDR_MASK = 4  /* Unsupported network addr mask - switch does not support arbitrary network address mask. */
+	OFPBMC_BAD_WILDCARDS    = 5  /* Unsupported combination of fields masked or omitted in the match. */
+	OFPBMC_BAD_FIELD        = 6  /* Unsupported field type in the match. */
+	OFPBMC_BAD_VALUE        = 7  /* Unsupported value in a match field. */
+	OFPBMC_BAD_MASK         = 8  /* Unsupported mask specified in the match, field is not dl-address or nw-address. */
+	OFPBMC_BAD_PREREQ       = 9  /* A prerequisite was not met. */
+	OFPBMC_DUP_FIELD        = 10 /* A field type was duplicated. */
+	OFPBMC_EPERM            = 11 /* Permissions error. */
+)
+
+const (
+	OFPFMFC_UNKNOWN      = 0 /* Unspecified error. */
+	OFPFMFC_TABLE_FULL   = 1 /* Flow not added because table was full. */
+	OFPFMFC_BAD_TABLE_ID = 2 /* Table does not exist */
+	OFPFMFC_OVERLAP      = 3 /* Attempted to add overlapping flow with CHECK_OVERLAP flag set. */
+	OFPFMFC_EPERM        = 4 /* Permissions error. */
+	OFPFMFC_BAD_TIMEOUT  = 5 /* Flow not added because of unsupported idle/hard timeout. */
+	OFPFMFC_BAD_COMMAND  = 6 /* Unsupported or unknown command. */
+	OFPFMFC_BAD_FLAGS    = 7 /* Unsupported or unknown flags. */
+)
+
+const (
+	OFPGMFC_GROUP_EXISTS         = 0  /* Group not added because a group ADD attempted to replace an already-present group. */
+	OFPGMFC_INVALID_GROUP        = 1  /* Group not added because Group specified is invalid. */
+	OFPGMFC_WEIGHT_UNSUPPORTED   = 2  /* Switch does not support unequal load sharing with select groups. */
+	OFPGMFC_OUT_OF_GROUPS        = 3  /* The group table is full. */
+	OFPGMFC_OUT_OF_BUCKETS       = 4  /* The maximum number of action buckets for a group has been exceeded. */
+	OFPGMFC_CHAINING_UNSUPPORTED = 5  /* Switch does not support groups that forward to groups. */
+	OFPGMFC_WATCH_UNSUPPORTED    = 6  /* This group cannot watch the watch_port or watch_group specified. */
+	OFPGMFC_LOOP                 = 7  /* Group entry would cause a loop. */
+	OFPGMFC_UNKNOWN_GROUP        = 8  /* Group not modified because a group MODIFY attempted to modify a non-existent group. */
+	OFPGMFC_CHAINED_GROUP        = 9  /* Group not deleted because another group is forwarding to it. */
+	OFPGMFC_BAD_TYPE             = 10 /* Unsupported or unknown group type. */
+	OFPGMFC_BAD_COMMAND          = 11 /* Unsupported or unknown command. */
+	OFPGMFC_BAD_BUCKET           = 12 /* Error in bucket. */
+	OFPGMFC_BAD_WATCH            = 13 /* Error in watch port/group. */
+	OFPGMFC_EPERM                = 14 /* Permissions error. */
+)
+
+const (
+	OFPPMFC_BAD_PORT      = 0 /* Specified port number does not exist. */
+	OFPPMFC_BAD_HW_ADDR   = 1 /* Specified hardware address does not match the port number. */
+	OFPPMFC_BAD_CONFIG    = 2 /* Specified config is invalid. */
+	OFPPMFC_BAD_ADVERTISE = 3 /* Specified advertise is invalid. */
+	OFPPMFC_EPERM         = 4 /* Permissions error. */
+)
+
+const (
+	OFPTMFC_BAD_TABLE  = 0 /* Specified table does not exist. */
+	OFPTMFC_BAD_CONFIG = 1 /* Specified config is invalid. */
+	OFPTMFC_EPERM      = 2 /* Permissions error. */
+)
+
+const (
+	OFPQOFC_BAD_PORT  = 0 /* Invalid port (or port does not exist). */
+	OFPQOFC_BAD_QUEUE = 1 /* Queue does not exist. */
+	OFPQOFC_EPERM     = 2 /* Permissions error. */
+)
+
+const (
+	OFPSCFC_BAD_FLAGS = 0 /* Specified flags is invalid. */
+	OFPSCFC_BAD_LEN   = 1 /* Specified len is invalid. */
+	OFPSCFC_EPERM     = 2 /* Permissions error. */
+)
+
+const (
+	OFPMMFC_UNKNOWN        = 0  /* Unspecified error. */
+	OFPMMFC_METER_EXISTS   = 1  /* Meter not added because a Meter ADD attempted to replace an existing Meter. */
+	OFPMMFC_INVALID_METER  = 2  /* Meter not added because Meter specified is invalid. */
+	OFPMMFC_UNKNOWN_METER  = 3  /* Meter not modified because a Meter MODIFY attempted to modify a non-existent Meter. */
+	OFPMMFC_BAD_COMMAND    = 4  /* Unsupported or unknown command. */
+	OFPMMFC_BAD_FLAGS      = 5  /* Flag configuration unsupported. */
+	OFPMMFC_BAD_RATE       = 6  /* Rate unsupported. */
+	OFPMMFC_BAD_BURST      = 7  /* Burst size unsupported. */
+	OFPMMFC_BAD_BAND       = 8  /* Band unsupported. */
+	OFPMMFC_BAD_BAND_VALUE = 9  /* Band value unsupported. */
+	OFPMMFC_OUT_OF_METERS  = 10 /* No more meters available. */
+	OFPMMFC_OUT_OF_BANDS   = 11 /* The maximum number of properties for a meter has been exceeded. */
+)
+
+const (
+	OFPTFFC_BAD_TABLE    = 0 /* Specified table does not exist. */
+	OFPTFFC_BAD_METADATA = 1 /* Invalid metadata mask. */
+	OFPTFFC_BAD_TYPE     = 2 /* Unknown property type. */
+	OFPTFFC_BAD_LEN      = 3 /* Length problem in properties. */
+	OFPTFFC_BAD_ARGUMENT = 4 /* Unsupported property value. */
+	OFPTFFC_EPERM        = 5 /* Permissions error. */
+)
+
+const (
+	OFPR_NO_MATCH    = 0 /* No matching flow (table-miss flow entry). */
+	OFPR_ACTION      = 1 /* Action explicitly output to controller. */
+	OFPR_INVALID_TTL = 2 /* Packet has invalid TTL. */
+)
+
+const (
+	OFPRR_IDLE_TIMEOUT = 0 /* Flow idle time exceeded idle_timeout. */
+	OFPRR_HARD_TIMEOUT = 1 /* Time exceeded hard_timeout. */
+	OFPRR_DELETE       = 2 /* Evicted by a DELETE flow mod. */
+	OFPRR_GROUP_DELETE = 3 /* Group was removed. */
+)
+
+const (
+	OFPVID_PRESENT = 0x1000 /* Bit that indicate that a VLAN id is set */
+	OFPVID_NONE    = 0x0000 /* No VLAN id was set. */
+)
+
+const (
+	/* ONF experimenter ID of the ONF extensions for OpenFlow 1.3. */
+	ONF_EXPERIMENTER_ID = 0x4F4E4600
+	/* Bundle extension (EXT-230) that backports the OpenFlow 1.4 bundles. */
+	ONFT_BUNDLE_CONTROL     = 2300
+	ONFT_BUNDLE_ADD_MESSAGE = 2301
+)
+
+const (
+	ONFBCT_OPEN_REQUEST    = 0
+	ONFBCT_OPEN_REPLY      = 1
+	ONFBCT_CLOSE_REQUEST   = 2
+	ONFBCT_CLOSE_REPLY     = 3
+	ONFBCT_COMMIT_REQUEST  = 4
+	ONFBCT_COMMIT_REPLY    = 5
+	ONFBCT_DISCARD_REQUEST = 6
+	ONFBCT_DISCARD_REPLY   = 7
+)
+
+const (
+	ONFBF_ATOMIC  = 1 << 0 /* Execute atomically. */
+	ONFBF_ORDERED = 1 << 1 /* Execute in specified order. */
+)
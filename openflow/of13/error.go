@@ -0,0 +1,271 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type Error struct {
+	openflow.BaseError
+}
+
+func NewError() openflow.Error {
+	return new(Error)
+}
+
+func (r *Error) Cause() *openflow.ErrorCause {
+	return openflow.NewErrorCause(errorTypes, r.Class(), r.Code())
+}
+
+func (r *Error) Request() (openflow.Header, error) {
+	data := r.Data()
+	if len(data) < 8 {
+		return nil, openflow.ErrInvalidPacketLength
+	}
+	// Truncated request?
+	if len(data) < int(binary.BigEndian.Uint16(data[2:4])) {
+		return openflow.ParseRequestHeader(data)
+	}
+
+	var msg interface {
+		openflow.Header
+		UnmarshalBinary(data []byte) error
+	}
+	switch data[1] {
+	case OFPT_FLOW_MOD:
+		msg = new(FlowMod)
+	case OFPT_PACKET_OUT:
+		msg = new(PacketOut)
+	default:
+		return openflow.ParseRequestHeader(data)
+	}
+	if err := msg.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+var errorTypes = map[uint16]openflow.ErrorType{
+	OFPET_HELLO_FAILED: {
+		Name:        "OFPET_HELLO_FAILED",
+		Description: "hello protocol failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPHFC_INCOMPATIBLE: {Name: "OFPHFC_INCOMPATIBLE", Description: "no compatible version"},
+			OFPHFC_EPERM:        {Name: "OFPHFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_BAD_REQUEST: {
+		Name:        "OFPET_BAD_REQUEST",
+		Description: "request was not understood",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBRC_BAD_VERSION:               {Name: "OFPBRC_BAD_VERSION", Description: "ofp_header.version not supported"},
+			OFPBRC_BAD_TYPE:                  {Name: "OFPBRC_BAD_TYPE", Description: "ofp_header.type not supported"},
+			OFPBRC_BAD_MULTIPART:             {Name: "OFPBRC_BAD_MULTIPART", Description: "ofp_multipart_request.type not supported"},
+			OFPBRC_BAD_EXPERIMENTER:          {Name: "OFPBRC_BAD_EXPERIMENTER", Description: "experimenter id not supported"},
+			OFPBRC_BAD_EXP_TYPE:              {Name: "OFPBRC_BAD_EXP_TYPE", Description: "experimenter type not supported"},
+			OFPBRC_EPERM:                     {Name: "OFPBRC_EPERM", Description: "permissions error"},
+			OFPBRC_BAD_LEN:                   {Name: "OFPBRC_BAD_LEN", Description: "wrong request length for type"},
+			OFPBRC_BUFFER_EMPTY:              {Name: "OFPBRC_BUFFER_EMPTY", Description: "specified buffer has already been used"},
+			OFPBRC_BUFFER_UNKNOWN:            {Name: "OFPBRC_BUFFER_UNKNOWN", Description: "specified buffer does not exist"},
+			OFPBRC_BAD_TABLE_ID:              {Name: "OFPBRC_BAD_TABLE_ID", Description: "specified table-id invalid or does not exist"},
+			OFPBRC_IS_SLAVE:                  {Name: "OFPBRC_IS_SLAVE", Description: "denied because controller is slave"},
+			OFPBRC_BAD_PORT:                  {Name: "OFPBRC_BAD_PORT", Description: "invalid port"},
+			OFPBRC_BAD_PACKET:                {Name: "OFPBRC_BAD_PACKET", Description: "invalid packet in packet-out"},
+			OFPBRC_MULTIPART_BUFFER_OVERFLOW: {Name: "OFPBRC_MULTIPART_BUFFER_OVERFLOW", Description: "ofp_multipart_request overflowed the assigned buffer"},
+		},
+	},
+	OFPET_BAD_ACTION: {
+		Name:        "OFPET_BAD_ACTION",
+		Description: "error in action description",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBAC_BAD_TYPE:           {Name: "OFPBAC_BAD_TYPE", Description: "unknown action type"},
+			OFPBAC_BAD_LEN:            {Name: "OFPBAC_BAD_LEN", Description: "length problem in actions"},
+			OFPBAC_BAD_EXPERIMENTER:   {Name: "OFPBAC_BAD_EXPERIMENTER", Description: "unknown experimenter id specified"},
+			OFPBAC_BAD_EXP_TYPE:       {Name: "OFPBAC_BAD_EXP_TYPE", Description: "unknown action for experimenter id"},
+			OFPBAC_BAD_OUT_PORT:       {Name: "OFPBAC_BAD_OUT_PORT", Description: "problem validating output port"},
+			OFPBAC_BAD_ARGUMENT:       {Name: "OFPBAC_BAD_ARGUMENT", Description: "bad action argument"},
+			OFPBAC_EPERM:              {Name: "OFPBAC_EPERM", Description: "permissions error"},
+			OFPBAC_TOO_MANY:           {Name: "OFPBAC_TOO_MANY", Description: "can't handle this many actions"},
+			OFPBAC_BAD_QUEUE:          {Name: "OFPBAC_BAD_QUEUE", Description: "problem validating output queue"},
+			OFPBAC_BAD_OUT_GROUP:      {Name: "OFPBAC_BAD_OUT_GROUP", Description: "invalid group id in forward action"},
+			OFPBAC_MATCH_INCONSISTENT: {Name: "OFPBAC_MATCH_INCONSISTENT", Description: "action can't apply for this match, or Set-Field missing prerequisite"},
+			OFPBAC_UNSUPPORTED_ORDER:  {Name: "OFPBAC_UNSUPPORTED_ORDER", Description: "action order is unsupported for the action list in an Apply-Actions instruction"},
+			OFPBAC_BAD_TAG:            {Name: "OFPBAC_BAD_TAG", Description: "actions uses an unsupported tag/encap"},
+			OFPBAC_BAD_SET_TYPE:       {Name: "OFPBAC_BAD_SET_TYPE", Description: "unsupported type in SET_FIELD action"},
+			OFPBAC_BAD_SET_LEN:        {Name: "OFPBAC_BAD_SET_LEN", Description: "length problem in SET_FIELD action"},
+			OFPBAC_BAD_SET_ARGUMENT:   {Name: "OFPBAC_BAD_SET_ARGUMENT", Description: "bad argument in SET_FIELD action"},
+		},
+	},
+	OFPET_BAD_INSTRUCTION: {
+		Name:        "OFPET_BAD_INSTRUCTION",
+		Description: "error in instruction list",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBIC_UNKNOWN_INST:        {Name: "OFPBIC_UNKNOWN_INST", Description: "unknown instruction"},
+			OFPBIC_UNSUP_INST:          {Name: "OFPBIC_UNSUP_INST", Description: "switch or table does not support the instruction"},
+			OFPBIC_BAD_TABLE_ID:        {Name: "OFPBIC_BAD_TABLE_ID", Description: "invalid Table-ID specified"},
+			OFPBIC_UNSUP_METADATA:      {Name: "OFPBIC_UNSUP_METADATA", Description: "metadata value unsupported by datapath"},
+			OFPBIC_UNSUP_METADATA_MASK: {Name: "OFPBIC_UNSUP_METADATA_MASK", Description: "metadata mask value unsupported by datapath"},
+			OFPBIC_BAD_EXPERIMENTER:    {Name: "OFPBIC_BAD_EXPERIMENTER", Description: "unknown experimenter id specified"},
+			OFPBIC_BAD_EXP_TYPE:        {Name: "OFPBIC_BAD_EXP_TYPE", Description: "unknown instruction for experimenter id"},
+			OFPBIC_BAD_LEN:             {Name: "OFPBIC_BAD_LEN", Description: "length problem in instructions"},
+			OFPBIC_EPERM:               {Name: "OFPBIC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_BAD_MATCH: {
+		Name:        "OFPET_BAD_MATCH",
+		Description: "error in match",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBMC_BAD_TYPE:         {Name: "OFPBMC_BAD_TYPE", Description: "unsupported match type specified by the match"},
+			OFPBMC_BAD_LEN:          {Name: "OFPBMC_BAD_LEN", Description: "length problem in match"},
+			OFPBMC_BAD_TAG:          {Name: "OFPBMC_BAD_TAG", Description: "match uses an unsupported tag/encap"},
+			OFPBMC_BAD_DL_ADDR_MASK: {Name: "OFPBMC_BAD_DL_ADDR_MASK", Description: "unsupported datalink addr mask - switch does not support arbitrary datalink address mask"},
+			OFPBMC_BAD_NW_ADDR_MASK: {Name: "OFPBMC_BAD_NW_ADDR_MASK", Description: "unsupported network addr mask - switch does not support arbitrary network address mask"},
+			OFPBMC_BAD_WILDCARDS:    {Name: "OFPBMC_BAD_WILDCARDS", Description: "unsupported combination of fields masked or omitted in the match"},
+			OFPBMC_BAD_FIELD:        {Name: "OFPBMC_BAD_FIELD", Description: "unsupported field type in the match"},
+			OFPBMC_BAD_VALUE:        {Name: "OFPBMC_BAD_VALUE", Description: "unsupported value in a match field"},
+			OFPBMC_BAD_MASK:         {Name: "OFPBMC_BAD_MASK", Description: "unsupported mask specified in the match, field is not dl-address or nw-address"},
+			OFPBMC_BAD_PREREQ:       {Name: "OFPBMC_BAD_PREREQ", Description: "A prerequisite was not met"},
+			OFPBMC_DUP_FIELD:        {Name: "OFPBMC_DUP_FIELD", Description: "A field type was duplicated"},
+			OFPBMC_EPERM:            {Name: "OFPBMC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_FLOW_MOD_FAILED: {
+		Name:        "OFPET_FLOW_MOD_FAILED",
+		Description: "problem modifying flow entry",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPFMFC_UNKNOWN:      {Name: "OFPFMFC_UNKNOWN", Description: "unspecified error"},
+			OFPFMFC_TABLE_FULL:   {Name: "OFPFMFC_TABLE_FULL", Description: "flow not added because table was full"},
+			OFPFMFC_BAD_TABLE_ID: {Name: "OFPFMFC_BAD_TABLE_ID", Description: "table does not exist"},
+			OFPFMFC_OVERLAP:      {Name: "OFPFMFC_OVERLAP", Description: "attempted to add overlapping flow with CHECK_OVERLAP flag set"},
+			OFPFMFC_EPERM:        {Name: "OFPFMFC_EPERM", Description: "permissions error"},
+			OFPFMFC_BAD_TIMEOUT:  {Name: "OFPFMFC_BAD_TIMEOUT", Description: "flow not added because of unsupported idle/hard timeout"},
+			OFPFMFC_BAD_COMMAND:  {Name: "OFPFMFC_BAD_COMMAND", Description: "unsupported or unknown command"},
+			OFPFMFC_BAD_FLAGS:    {Name: "OFPFMFC_BAD_FLAGS", Description: "unsupported or unknown flags"},
+		},
+	},
+	OFPET_GROUP_MOD_FAILED: {
+		Name:        "OFPET_GROUP_MOD_FAILED",
+		Description: "problem modifying group entry",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPGMFC_GROUP_EXISTS:         {Name: "OFPGMFC_GROUP_EXISTS", Description: "group not added because a group ADD attempted to replace an already-present group"},
+			OFPGMFC_INVALID_GROUP:        {Name: "OFPGMFC_INVALID_GROUP", Description: "group not added because Group specified is invalid"},
+			OFPGMFC_WEIGHT_UNSUPPORTED:   {Name: "OFPGMFC_WEIGHT_UNSUPPORTED", Description: "switch does not support unequal load sharing with select groups"},
+			OFPGMFC_OUT_OF_GROUPS:        {Name: "OFPGMFC_OUT_OF_GROUPS", Description: "the group table is full"},
+			OFPGMFC_OUT_OF_BUCKETS:       {Name: "OFPGMFC_OUT_OF_BUCKETS", Description: "the maximum number of action buckets for a group has been exceeded"},
+			OFPGMFC_CHAINING_UNSUPPORTED: {Name: "OFPGMFC_CHAINING_UNSUPPORTED", Description: "switch does not support groups that forward to groups"},
+			OFPGMFC_WATCH_UNSUPPORTED:    {Name: "OFPGMFC_WATCH_UNSUPPORTED", Description: "this group cannot watch the watch_port or watch_group specified"},
+			OFPGMFC_LOOP:                 {Name: "OFPGMFC_LOOP", Description: "group entry would cause a loop"},
+			OFPGMFC_UNKNOWN_GROUP:        {Name: "OFPGMFC_UNKNOWN_GROUP", Description: "group not modified because a group MODIFY attempted to modify a non-existent group"},
+			OFPGMFC_CHAINED_GROUP:        {Name: "OFPGMFC_CHAINED_GROUP", Description: "group not deleted because another group is forwarding to it"},
+			OFPGMFC_BAD_TYPE:             {Name: "OFPGMFC_BAD_TYPE", Description: "unsupported or unknown group type"},
+			OFPGMFC_BAD_COMMAND:          {Name: "OFPGMFC_BAD_COMMAND", Description: "unsupported or unknown command"},
+			OFPGMFC_BAD_BUCKET:           {Name: "OFPGMFC_BAD_BUCKET", Description: "error in bucket"},
+			OFPGMFC_BAD_WATCH:            {Name: "OFPGMFC_BAD_WATCH", Description: "error in watch port/group"},
+			OFPGMFC_EPERM:                {Name: "OFPGMFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_PORT_MOD_FAILED: {
+		Name:        "OFPET_PORT_MOD_FAILED",
+		Description: "port mod request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPPMFC_BAD_PORT:      {Name: "OFPPMFC_BAD_PORT", Description: "specified port number does not exist"},
+			OFPPMFC_BAD_HW_ADDR:   {Name: "OFPPMFC_BAD_HW_ADDR", Description: "specified hardware address does not match the port number"},
+			OFPPMFC_BAD_CONFIG:    {Name: "OFPPMFC_BAD_CONFIG", Description: "specified config is invalid"},
+			OFPPMFC_BAD_ADVERTISE: {Name: "OFPPMFC_BAD_ADVERTISE", Description: "specified advertise is invalid"},
+			OFPPMFC_EPERM:         {Name: "OFPPMFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_TABLE_MOD_FAILED: {
+		Name:        "OFPET_TABLE_MOD_FAILED",
+		Description: "table mod request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPTMFC_BAD_TABLE:  {Name: "OFPTMFC_BAD_TABLE", Description: "specified table does not exist"},
+			OFPTMFC_BAD_CONFIG: {Name: "OFPTMFC_BAD_CONFIG", Description: "specified config is invalid"},
+			OFPTMFC_EPERM:      {Name: "OFPTMFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_QUEUE_OP_FAILED: {
+		Name:        "OFPET_QUEUE_OP_FAILED",
+		Description: "queue operation failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPQOFC_BAD_PORT:  {Name: "OFPQOFC_BAD_PORT", Description: "invalid port (or port does not exist)"},
+			OFPQOFC_BAD_QUEUE: {Name: "OFPQOFC_BAD_QUEUE", Description: "queue does not exist"},
+			OFPQOFC_EPERM:     {Name: "OFPQOFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_SWITCH_CONFIG_FAILED: {
+		Name:        "OFPET_SWITCH_CONFIG_FAILED",
+		Description: "switch config request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPSCFC_BAD_FLAGS: {Name: "OFPSCFC_BAD_FLAGS", Description: "specified flags is invalid"},
+			OFPSCFC_BAD_LEN:   {Name: "OFPSCFC_BAD_LEN", Description: "specified len is invalid"},
+			OFPSCFC_EPERM:     {Name: "OFPSCFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_ROLE_REQUEST_FAILED: {
+		Name:        "OFPET_ROLE_REQUEST_FAILED",
+		Description: "controller Role request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPRRFC_STALE:    {Name: "OFPRRFC_STALE", Description: "stale Message: old generation_id"},
+			OFPRRFC_UNSUP:    {Name: "OFPRRFC_UNSUP", Description: "controller role change unsupported"},
+			OFPRRFC_BAD_ROLE: {Name: "OFPRRFC_BAD_ROLE", Description: "invalid role"},
+		},
+	},
+	OFPET_METER_MOD_FAILED: {
+		Name:        "OFPET_METER_MOD_FAILED",
+		Description: "error in meter",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPMMFC_UNKNOWN:        {Name: "OFPMMFC_UNKNOWN", Description: "unspecified error"},
+			OFPMMFC_METER_EXISTS:   {Name: "OFPMMFC_METER_EXISTS", Description: "meter not added because a Meter ADD attempted to replace an existing Meter"},
+			OFPMMFC_INVALID_METER:  {Name: "OFPMMFC_INVALID_METER", Description: "meter not added because Meter specified is invalid"},
+			OFPMMFC_UNKNOWN_METER:  {Name: "OFPMMFC_UNKNOWN_METER", Description: "meter not modified because a Meter MODIFY attempted to modify a non-existent Meter"},
+			OFPMMFC_BAD_COMMAND:    {Name: "OFPMMFC_BAD_COMMAND", Description: "unsupported or unknown command"},
+			OFPMMFC_BAD_FLAGS:      {Name: "OFPMMFC_BAD_FLAGS", Description: "flag configuration unsupported"},
+			OFPMMFC_BAD_RATE:       {Name: "OFPMMFC_BAD_RATE", Description: "rate unsupported"},
+			OFPMMFC_BAD_BURST:      {Name: "OFPMMFC_BAD_BURST", Description: "burst size unsupported"},
+			OFPMMFC_BAD_BAND:       {Name: "OFPMMFC_BAD_BAND", Description: "band unsupported"},
+			OFPMMFC_BAD_BAND_VALUE: {Name: "OFPMMFC_BAD_BAND_VALUE", Description: "band value unsupported"},
+			OFPMMFC_OUT_OF_METERS:  {Name: "OFPMMFC_OUT_OF_METERS", Description: "no more meters available"},
+			OFPMMFC_OUT_OF_BANDS:   {Name: "OFPMMFC_OUT_OF_BANDS", Description: "the maximum number of properties for a meter has been exceeded"},
+		},
+	},
+	OFPET_TABLE_FEATURES_FAILED: {
+		Name:        "OFPET_TABLE_FEATURES_FAILED",
+		Description: "setting table features failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPTFFC_BAD_TABLE:    {Name: "OFPTFFC_BAD_TABLE", Description: "specified table does not exist"},
+			OFPTFFC_BAD_METADATA: {Name: "OFPTFFC_BAD_METADATA", Description: "invalid metadata mask"},
+			OFPTFFC_BAD_TYPE:     {Name: "OFPTFFC_BAD_TYPE", Description: "unknown property type"},
+			OFPTFFC_BAD_LEN:      {Name: "OFPTFFC_BAD_LEN", Description: "length problem in properties"},
+			OFPTFFC_BAD_ARGUMENT: {Name: "OFPTFFC_BAD_ARGUMENT", Description: "unsupported property value"},
+			OFPTFFC_EPERM:        {Name: "OFPTFFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_EXPERIMENTER: {
+		Name:        "OFPET_EXPERIMENTER",
+		Description: "experimenter error messages",
+	},
+}
@@ -0,0 +1,107 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newErrorPacket(class, code uint16, data []byte) []byte {
+	v := make([]byte, 12)
+	v[0] = openflow.OF13_VERSION
+	v[1] = OFPT_ERROR
+	binary.BigEndian.PutUint16(v[2:4], uint16(12+len(data)))
+	binary.BigEndian.PutUint32(v[4:8], 1)
+	binary.BigEndian.PutUint16(v[8:10], class)
+	binary.BigEndian.PutUint16(v[10:12], code)
+
+	return append(v, data...)
+}
+
+func TestErrorRequest(t *testing.T) {
+	inPort := openflow.NewInPort()
+	inPort.SetValue(3)
+	match := NewMatch()
+	match.SetInPort(inPort)
+	outPort := openflow.NewOutPort()
+	outPort.SetValue(5)
+	action := NewAction()
+	action.SetOutPort(outPort)
+	inst := new(Instruction)
+	inst.ApplyAction(action)
+
+	flow := NewFlowMod(0x1234, OFPFC_ADD)
+	flow.SetCookie(0xABCD)
+	flow.SetPriority(100)
+	flow.SetFlowMatch(match)
+	flow.SetFlowInstruction(inst)
+	request, err := flow.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewError()
+	if err := e.UnmarshalBinary(newErrorPacket(OFPET_BAD_ACTION, OFPBAC_BAD_OUT_PORT, request)); err != nil {
+		t.Fatal(err)
+	}
+	cause := e.Cause()
+	if cause.ClassName != "OFPET_BAD_ACTION" || cause.CodeName != "OFPBAC_BAD_OUT_PORT" {
+		t.Fatalf("unexpected cause: %v", cause)
+	}
+	if !openflow.IsErrorCode(cause, "OFPBAC_BAD_OUT_PORT") {
+		t.Fatalf("expected OFPBAC_BAD_OUT_PORT: %v", cause)
+	}
+
+	v, err := e.Request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, ok := v.(openflow.FlowMod)
+	if !ok {
+		t.Fatalf("unexpected request type: %T", v)
+	}
+	if decoded.TransactionID() != 0x1234 || decoded.Cookie() != 0xABCD || decoded.Priority() != 100 {
+		t.Fatalf("unexpected FLOW_MOD: xid=%v, cookie=%v, priority=%v", decoded.TransactionID(), decoded.Cookie(), decoded.Priority())
+	}
+	if wildcard, port := decoded.FlowMatch().InPort(); wildcard || port.Value() != 3 {
+		t.Fatalf("unexpected in_port of the match: wildcard=%v, port=%v", wildcard, port.Value())
+	}
+
+	// The switch may include only the first 64 bytes of the request.
+	truncated := NewError()
+	if err := truncated.UnmarshalBinary(newErrorPacket(OFPET_BAD_ACTION, OFPBAC_BAD_OUT_PORT, request[:64])); err != nil {
+		t.Fatal(err)
+	}
+	v, err = truncated.Request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(openflow.FlowMod); ok {
+		t.Fatal("truncated request is parsed as FLOW_MOD")
+	}
+	if v.Type() != OFPT_FLOW_MOD || v.TransactionID() != 0x1234 {
+		t.Fatalf("unexpected request header: type=%v, xid=%v", v.Type(), v.TransactionID())
+	}
+}
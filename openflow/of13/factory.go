@@ -22,6 +22,7 @@
 package of13
 
 import (
+	"errors"
 	"fmt"
 	"sync/atomic"
 
@@ -103,6 +104,10 @@ func getFlowModCmd(cmd openflow.FlowModCmd) uint8 {
 		c = OFPFC_MODIFY
 	case openflow.FlowDelete:
 		c = OFPFC_DELETE
+	case openflow.FlowModifyStrict:
+		c = OFPFC_MODIFY_STRICT
+	case openflow.FlowDeleteStrict:
+		c = OFPFC_DELETE_STRICT
 	default:
 		panic(fmt.Sprintf("unexpected FlowModCmd: %v", cmd))
 	}
@@ -126,6 +131,10 @@ func (r *Factory) NewPacketOut() (openflow.PacketOut, error) {
 	return NewPacketOut(r.getTransactionID()), nil
 }
 
+func (r *Factory) NewPortMod() (openflow.PortMod, error) {
+	return NewPortMod(r.getTransactionID()), nil
+}
+
 func (r *Factory) NewPortStatus() (openflow.PortStatus, error) {
 	return new(PortStatus), nil
 }
@@ -142,7 +151,9 @@ func (r *Factory) NewFlowStatsRequest() (openflow.FlowStatsRequest, error) {
 	return NewFlowStatsRequest(r.getTransactionID()), nil
 }
 
-// TODO: NewFlowStatsReply() (openflow.FlowStatsReply, error) {
+func (r *Factory) NewFlowStatsReply() (openflow.FlowStatsReply, error) {
+	return new(FlowStatsReply), nil
+}
 
 func (r *Factory) NewPortDescRequest() (openflow.PortDescRequest, error) {
 	return NewPortDescRequest(r.getTransactionID()), nil
@@ -157,10 +168,16 @@ func (r *Factory) NewTableFeaturesRequest() (openflow.TableFeaturesRequest, erro
 }
 
 func (r *Factory) NewError() (openflow.Error, error) {
-	return new(openflow.BaseError), nil
+	return NewError(), nil
 }
 
-// TODO: NewTableFeaturesReply() (TableFeaturesReply, error)
+func (r *Factory) NewExperimenter() (openflow.Experimenter, error) {
+	return openflow.NewExperimenter(openflow.OF13_VERSION, OFPT_EXPERIMENTER, r.getTransactionID()), nil
+}
+
+func (r *Factory) NewTableFeaturesReply() (openflow.TableFeaturesReply, error) {
+	return new(TableFeaturesReply), nil
+}
 
 func (r *Factory) NewInstruction() (openflow.Instruction, error) {
 	return new(Instruction), nil
@@ -169,3 +186,173 @@ func (r *Factory) NewInstruction() (openflow.Instruction, error) {
 func (r *Factory) NewQueueGetConfigRequest() (openflow.QueueGetConfigRequest, error) {
 	return NewQueueGetConfigRequest(r.getTransactionID()), nil
 }
+
+func (r *Factory) NewBucket() (openflow.Bucket, error) {
+	return NewBucket(), nil
+}
+
+func getGroupModCmd(cmd openflow.GroupModCmd) uint16 {
+	var c uint16
+	switch cmd {
+	case openflow.GroupAdd:
+		c = OFPGC_ADD
+	case openflow.GroupModify:
+		c = OFPGC_MODIFY
+	case openflow.GroupDelete:
+		c = OFPGC_DELETE
+	default:
+		panic(fmt.Sprintf("unexpected GroupModCmd: %v", cmd))
+	}
+
+	return c
+}
+
+func (r *Factory) NewGroupMod(cmd openflow.GroupModCmd) (openflow.GroupMod, error) {
+	return NewGroupMod(r.getTransactionID(), getGroupModCmd(cmd)), nil
+}
+
+func (r *Factory) NewGroupStatsRequest() (openflow.GroupStatsRequest, error) {
+	return NewGroupStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGroupStatsReply() (openflow.GroupStatsReply, error) {
+	return new(GroupStatsReply), nil
+}
+
+func (r *Factory) NewGroupDescRequest() (openflow.GroupDescRequest, error) {
+	return NewGroupDescRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGroupDescReply() (openflow.GroupDescReply, error) {
+	return new(GroupDescReply), nil
+}
+
+func (r *Factory) NewGroupFeaturesRequest() (openflow.GroupFeaturesRequest, error) {
+	return NewGroupFeaturesRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGroupFeaturesReply() (openflow.GroupFeaturesReply, error) {
+	return new(GroupFeaturesReply), nil
+}
+
+func (r *Factory) NewMeterBand() (openflow.MeterBand, error) {
+	return NewMeterBand(), nil
+}
+
+func getMeterModCmd(cmd openflow.MeterModCmd) uint16 {
+	var c uint16
+	switch cmd {
+	case openflow.MeterAdd:
+		c = OFPMC_ADD
+	case openflow.MeterModify:
+		c = OFPMC_MODIFY
+	case openflow.MeterDelete:
+		c = OFPMC_DELETE
+	default:
+		panic(fmt.Sprintf("unexpected MeterModCmd: %v", cmd))
+	}
+
+	return c
+}
+
+func (r *Factory) NewMeterMod(cmd openflow.MeterModCmd) (openflow.MeterMod, error) {
+	return NewMeterMod(r.getTransactionID(), getMeterModCmd(cmd)), nil
+}
+
+func (r *Factory) NewMeterStatsRequest() (openflow.MeterStatsRequest, error) {
+	return NewMeterStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewMeterStatsReply() (openflow.MeterStatsReply, error) {
+	return new(MeterStatsReply), nil
+}
+
+func (r *Factory) NewMeterConfigRequest() (openflow.MeterConfigRequest, error) {
+	return NewMeterConfigRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewMeterConfigReply() (openflow.MeterConfigReply, error) {
+	return new(MeterConfigReply), nil
+}
+
+func (r *Factory) NewMeterFeaturesRequest() (openflow.MeterFeaturesRequest, error) {
+	return NewMeterFeaturesRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewMeterFeaturesReply() (openflow.MeterFeaturesReply, error) {
+	return new(MeterFeaturesReply), nil
+}
+
+func (r *Factory) NewPortStatsRequest() (openflow.PortStatsRequest, error) {
+	return NewPortStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewPortStatsReply() (openflow.PortStatsReply, error) {
+	return new(PortStatsReply), nil
+}
+
+func (r *Factory) NewTableStatsRequest() (openflow.TableStatsRequest, error) {
+	return NewTableStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewTableStatsReply() (openflow.TableStatsReply, error) {
+	return new(TableStatsReply), nil
+}
+
+func (r *Factory) NewQueueStatsRequest() (openflow.QueueStatsRequest, error) {
+	return NewQueueStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewQueueStatsReply() (openflow.QueueStatsReply, error) {
+	return new(QueueStatsReply), nil
+}
+
+func (r *Factory) NewAggregateStatsRequest() (openflow.AggregateStatsRequest, error) {
+	return NewAggregateStatsRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewAggregateStatsReply() (openflow.AggregateStatsReply, error) {
+	return new(AggregateStatsReply), nil
+}
+
+func (r *Factory) NewRoleRequest() (openflow.RoleRequest, error) {
+	return NewRoleRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewRoleReply() (openflow.RoleReply, error) {
+	return new(RoleReply), nil
+}
+
+func (r *Factory) NewSetAsync() (openflow.SetAsync, error) {
+	return NewSetAsync(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGetAsyncRequest() (openflow.GetAsyncRequest, error) {
+	return NewGetAsyncRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGetAsyncReply() (openflow.GetAsyncReply, error) {
+	return new(GetAsyncReply), nil
+}
+
+// NewBundleControl returns the bundle control message of the ONF bundle extension.
+func (r *Factory) NewBundleControl() (openflow.BundleControl, error) {
+	return NewBundleControl(r.getTransactionID()), nil
+}
+
+// NewBundleAdd returns the bundle add message of the ONF bundle extension.
+func (r *Factory) NewBundleAdd() (openflow.BundleAdd, error) {
+	return NewBundleAdd(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewFlowMonitorRequest() (openflow.FlowMonitorRequest, error) {
+	return nil, errors.New("of13 does not support FlowMonitorRequest")
+}
+
+func (r *Factory) NewFlowMonitorReply() (openflow.FlowMonitorReply, error) {
+	return nil, errors.New("of13 does not support FlowMonitorReply")
+}
+
+func (r *Factory) NewTransactionID() uint32 {
+	return r.getTransactionID()
+}
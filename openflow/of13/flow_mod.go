@@ -179,3 +179,52 @@ func (r *FlowMod) MarshalBinary() ([]byte, error) {
 	r.SetPayload(v)
 	return r.Message.MarshalBinary()
 }
+
+func (r *FlowMod) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 44 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.cookie = binary.BigEndian.Uint64(payload[0:8])
+	r.cookieMask = binary.BigEndian.Uint64(payload[8:16])
+	r.tableID = payload[16]
+	r.command = payload[17]
+	r.idleTimeout = binary.BigEndian.Uint16(payload[18:20])
+	r.hardTimeout = binary.BigEndian.Uint16(payload[20:22])
+	r.priority = binary.BigEndian.Uint16(payload[22:24])
+	// payload[24:28] is buffer ID
+	r.outPort = openflow.NewOutPort()
+	if port := binary.BigEndian.Uint32(payload[28:32]); port == OFPP_ANY {
+		r.outPort.SetNone()
+	} else {
+		r.outPort.SetValue(port)
+	}
+	// payload[32:36] is out group, payload[36:38] is flags, and payload[38:40] is padding
+
+	r.match = NewMatch()
+	if err := r.match.UnmarshalBinary(payload[40:]); err != nil {
+		return err
+	}
+	// ofp_match is padded to make it 64-bit aligned.
+	matchLength := (int(binary.BigEndian.Uint16(payload[42:44])) + 7) / 8 * 8
+	if 40+matchLength > len(payload) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.instruction = nil
+	if 40+matchLength < len(payload) {
+		inst := new(Instruction)
+		if err := inst.UnmarshalBinary(payload[40+matchLength:]); err != nil {
+			return err
+		}
+		if !inst.isEmpty() {
+			r.instruction = inst
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func getGroupType(t openflow.GroupType) (uint8, error) {
+	switch t {
+	case openflow.GroupTypeAll:
+		return OFPGT_ALL, nil
+	case openflow.GroupTypeSelect:
+		return OFPGT_SELECT, nil
+	case openflow.GroupTypeIndirect:
+		return OFPGT_INDIRECT, nil
+	case openflow.GroupTypeFastFailover:
+		return OFPGT_FF, nil
+	default:
+		return 0, fmt.Errorf("unexpected group type: %v", t)
+	}
+}
+
+func parseGroupType(t uint8) (openflow.GroupType, error) {
+	switch t {
+	case OFPGT_ALL:
+		return openflow.GroupTypeAll, nil
+	case OFPGT_SELECT:
+		return openflow.GroupTypeSelect, nil
+	case OFPGT_INDIRECT:
+		return openflow.GroupTypeIndirect, nil
+	case OFPGT_FF:
+		return openflow.GroupTypeFastFailover, nil
+	default:
+		return 0, fmt.Errorf("unknown group type: %v", t)
+	}
+}
+
+type Bucket struct {
+	err        error
+	weight     uint16
+	watchPort  int64
+	watchGroup int64
+	action     openflow.Action
+}
+
+func NewBucket() openflow.Bucket {
+	return &Bucket{
+		watchPort:  -1,
+		watchGroup: -1,
+	}
+}
+
+func (r *Bucket) Error() error {
+	return r.err
+}
+
+func (r *Bucket) Weight() uint16 {
+	return r.weight
+}
+
+func (r *Bucket) SetWeight(weight uint16) {
+	r.weight = weight
+}
+
+func (r *Bucket) WatchPort() (ok bool, port uint32) {
+	if r.watchPort == -1 {
+		return false, 0
+	}
+
+	return true, uint32(r.watchPort)
+}
+
+func (r *Bucket) SetWatchPort(port uint32) {
+	r.watchPort = int64(port)
+}
+
+func (r *Bucket) WatchGroup() (ok bool, group uint32) {
+	if r.watchGroup == -1 {
+		return false, 0
+	}
+
+	return true, uint32(r.watchGroup)
+}
+
+func (r *Bucket) SetWatchGroup(group uint32) {
+	r.watchGroup = int64(group)
+}
+
+func (r *Bucket) Action() openflow.Action {
+	return r.action
+}
+
+func (r *Bucket) SetAction(action openflow.Action) {
+	if action == nil {
+		panic("action is nil")
+	}
+	r.action = action
+}
+
+func (r *Bucket) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint16(v[2:4], r.weight)
+	if ok, port := r.WatchPort(); ok {
+		binary.BigEndian.PutUint32(v[4:8], port)
+	} else {
+		binary.BigEndian.PutUint32(v[4:8], OFPP_ANY)
+	}
+	if ok, group := r.WatchGroup(); ok {
+		binary.BigEndian.PutUint32(v[8:12], group)
+	} else {
+		binary.BigEndian.PutUint32(v[8:12], OFPG_ANY)
+	}
+	// v[12:16] is padding
+
+	// A bucket without any action drops the packets.
+	if r.action != nil {
+		action, err := r.action.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, action...)
+	}
+	binary.BigEndian.PutUint16(v[0:2], uint16(len(v)))
+
+	return v, nil
+}
+
+func (r *Bucket) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return openflow.ErrInvalidPacketLength
+	}
+	length := binary.BigEndian.Uint16(data[0:2])
+	if length < 16 || len(data) < int(length) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.weight = binary.BigEndian.Uint16(data[2:4])
+	r.watchPort = -1
+	if port := binary.BigEndian.Uint32(data[4:8]); port != OFPP_ANY {
+		r.watchPort = int64(port)
+	}
+	r.watchGroup = -1
+	if group := binary.BigEndian.Uint32(data[8:12]); group != OFPG_ANY {
+		r.watchGroup = int64(group)
+	}
+	// data[12:16] is padding
+
+	if length > 16 {
+		action := NewAction()
+		if err := action.UnmarshalBinary(data[16:length]); err != nil {
+			return err
+		}
+		r.action = action
+	}
+
+	return nil
+}
+
+type GroupMod struct {
+	err error
+	openflow.Message
+	command   uint16
+	groupType openflow.GroupType
+	groupID   uint32
+	buckets   []openflow.Bucket
+}
+
+func NewGroupMod(xid uint32, cmd uint16) openflow.GroupMod {
+	return &GroupMod{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_GROUP_MOD, xid),
+		command: cmd,
+	}
+}
+
+func (r *GroupMod) Error() error {
+	return r.err
+}
+
+func (r *GroupMod) GroupType() openflow.GroupType {
+	return r.groupType
+}
+
+func (r *GroupMod) SetGroupType(t openflow.GroupType) {
+	if _, err := getGroupType(t); err != nil {
+		r.err = err
+		return
+	}
+	r.groupType = t
+}
+
+func (r *GroupMod) GroupID() uint32 {
+	return r.groupID
+}
+
+func (r *GroupMod) SetGroupID(id uint32) {
+	if id > OFPG_MAX && id != OFPG_ALL {
+		r.err = fmt.Errorf("invalid group ID: %v", id)
+		return
+	}
+	r.groupID = id
+}
+
+func (r *GroupMod) Buckets() []openflow.Bucket {
+	return r.buckets
+}
+
+func (r *GroupMod) AddBucket(bucket openflow.Bucket) {
+	if bucket == nil {
+		panic("bucket is nil")
+	}
+	r.buckets = append(r.buckets, bucket)
+}
+
+func (r *GroupMod) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	groupType, err := getGroupType(r.groupType)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], r.command)
+	v[2] = groupType
+	// v[3] is padding
+	binary.BigEndian.PutUint32(v[4:8], r.groupID)
+
+	// The bucket list should be empty for the delete command.
+	if r.command != OFPGC_DELETE {
+		for _, b := range r.buckets {
+			bucket, err := b.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, bucket...)
+		}
+	}
+
+	r.SetPayload(v)
+	return r.Message.MarshalBinary()
+}
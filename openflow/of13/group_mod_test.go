@@ -0,0 +1,129 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestBucket(weight uint16, port uint32) openflow.Bucket {
+	outPort := openflow.NewOutPort()
+	outPort.SetValue(port)
+	action := NewAction()
+	action.SetOutPort(outPort)
+
+	bucket := NewBucket()
+	bucket.SetWeight(weight)
+	bucket.SetWatchPort(port)
+	bucket.SetAction(action)
+
+	return bucket
+}
+
+func TestGroupMod(t *testing.T) {
+	mod := NewGroupMod(9, OFPGC_ADD)
+	mod.SetGroupType(openflow.GroupTypeSelect)
+	mod.SetGroupID(100)
+	mod.AddBucket(newTestBucket(10, 1))
+	mod.AddBucket(newTestBucket(20, 2))
+	packet, err := mod.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := new(openflow.Message)
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type() != OFPT_GROUP_MOD || msg.TransactionID() != 9 {
+		t.Fatalf("unexpected header: type=%v, xid=%v", msg.Type(), msg.TransactionID())
+	}
+	payload := msg.Payload()
+	if len(payload) < 8 {
+		t.Fatalf("unexpected payload length: %v", len(payload))
+	}
+	if cmd := binary.BigEndian.Uint16(payload[0:2]); cmd != OFPGC_ADD {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+	if payload[2] != OFPGT_SELECT {
+		t.Fatalf("unexpected group type: %v", payload[2])
+	}
+	if id := binary.BigEndian.Uint32(payload[4:8]); id != 100 {
+		t.Fatalf("unexpected group ID: %v", id)
+	}
+
+	// The bucket list should be decoded back into the same buckets.
+	weights := []uint16{10, 20}
+	for i, buf := 0, payload[8:]; len(buf) > 0; i++ {
+		if i >= len(weights) {
+			t.Fatalf("too many buckets: %v", payload[8:])
+		}
+		bucket := NewBucket()
+		if err := bucket.UnmarshalBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+		if bucket.Weight() != weights[i] {
+			t.Fatalf("unexpected bucket weight: expected=%v, got=%v", weights[i], bucket.Weight())
+		}
+		ok, port := bucket.WatchPort()
+		if !ok || port != uint32(i+1) {
+			t.Fatalf("unexpected watch port: ok=%v, port=%v", ok, port)
+		}
+		if ok, _ := bucket.WatchGroup(); ok {
+			t.Fatal("unexpected watch group")
+		}
+		length := binary.BigEndian.Uint16(buf[0:2])
+		v, err := bucket.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(v, buf[:length]) {
+			t.Fatalf("mismatched bucket: expected=%v, got=%v", buf[:length], v)
+		}
+		buf = buf[length:]
+	}
+
+	// The delete command should not carry any bucket.
+	del := NewGroupMod(10, OFPGC_DELETE)
+	del.SetGroupID(OFPG_ALL)
+	del.AddBucket(newTestBucket(10, 1))
+	packet, err = del.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 16 {
+		t.Fatalf("unexpected delete length: %v", len(packet))
+	}
+
+	invalid := NewGroupMod(11, OFPGC_ADD)
+	invalid.SetGroupType(openflow.GroupType(0xFF))
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the invalid group type")
+	}
+	if _, err := invalid.MarshalBinary(); err == nil {
+		t.Fatal("expected an error marshalling the invalid group type")
+	}
+}
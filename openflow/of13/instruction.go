@@ -29,9 +29,33 @@ import (
 	"github.com/superkkt/cherry/openflow"
 )
 
+// Instruction is a set of OpenFlow 1.3 instructions. The set contains at most one
+// instruction of each type, and they are encoded in the order the switch executes
+// them: meter, apply-actions, clear-actions, write-actions, write-metadata, and
+// goto-table.
 type Instruction struct {
-	err   error
-	value encoding.BinaryMarshaler
+	err          error
+	meter        *meter
+	applyAction  *applyAction
+	clearActions *clearActions
+	writeAction  *writeAction
+	metadata     *writeMetadata
+	gotoTable    *gotoTable
+	// raw keeps the unsupported instruction types that we cannot represent.
+	raw []byte
+}
+
+type meter struct {
+	meterID uint32
+}
+
+func (r *meter) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], OFPIT_METER)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	binary.BigEndian.PutUint32(v[4:8], r.meterID)
+
+	return v, nil
 }
 
 type gotoTable struct {
@@ -48,6 +72,33 @@ func (r *gotoTable) MarshalBinary() ([]byte, error) {
 	return v, nil
 }
 
+type writeMetadata struct {
+	metadata uint64
+	mask     uint64
+}
+
+func (r *writeMetadata) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 24)
+	binary.BigEndian.PutUint16(v[0:2], OFPIT_WRITE_METADATA)
+	binary.BigEndian.PutUint16(v[2:4], 24)
+	// v[4:8] is padding
+	binary.BigEndian.PutUint64(v[8:16], r.metadata)
+	binary.BigEndian.PutUint64(v[16:24], r.mask)
+
+	return v, nil
+}
+
+type clearActions struct{}
+
+func (r *clearActions) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], OFPIT_CLEAR_ACTIONS)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	// v[4:8] is padding
+
+	return v, nil
+}
+
 type writeAction struct {
 	action openflow.Action
 }
@@ -97,31 +148,134 @@ func (r *Instruction) Error() error {
 }
 
 func (r *Instruction) GotoTable(tableID uint8) {
-	r.value = &gotoTable{tableID: tableID}
+	if tableID > OFPTT_MAX {
+		r.err = errors.New("invalid table ID")
+		return
+	}
+	r.gotoTable = &gotoTable{tableID: tableID}
+}
+
+func (r *Instruction) WriteMetadata(metadata, mask uint64) {
+	r.metadata = &writeMetadata{metadata: metadata, mask: mask}
 }
 
 func (r *Instruction) WriteAction(act openflow.Action) {
 	if act == nil {
 		panic("act is nil")
 	}
-	r.value = &writeAction{action: act}
+	r.writeAction = &writeAction{action: act}
 }
 
 func (r *Instruction) ApplyAction(act openflow.Action) {
 	if act == nil {
 		panic("act is nil")
 	}
-	r.value = &applyAction{action: act}
+	r.applyAction = &applyAction{action: act}
+}
+
+func (r *Instruction) ClearActions() {
+	r.clearActions = &clearActions{}
+}
+
+func (r *Instruction) Meter(meterID uint32) {
+	if meterID == 0 || (meterID > OFPM_MAX && meterID != OFPM_SLOWPATH && meterID != OFPM_CONTROLLER) {
+		r.err = errors.New("invalid meter ID")
+		return
+	}
+	r.meter = &meter{meterID: meterID}
+}
+
+func (r *Instruction) isEmpty() bool {
+	return r.meter == nil && r.applyAction == nil && r.clearActions == nil &&
+		r.writeAction == nil && r.metadata == nil && r.gotoTable == nil && len(r.raw) == 0
+}
+
+func (r *Instruction) UnmarshalBinary(data []byte) error {
+	*r = Instruction{}
+
+	buf := data
+	for len(buf) >= 4 {
+		t := binary.BigEndian.Uint16(buf[0:2])
+		length := binary.BigEndian.Uint16(buf[2:4])
+		if length < 8 || len(buf) < int(length) {
+			return openflow.ErrInvalidPacketLength
+		}
+
+		switch t {
+		case OFPIT_METER:
+			r.meter = &meter{meterID: binary.BigEndian.Uint32(buf[4:8])}
+		case OFPIT_GOTO_TABLE:
+			r.gotoTable = &gotoTable{tableID: buf[4]}
+		case OFPIT_WRITE_METADATA:
+			if length < 24 {
+				return openflow.ErrInvalidPacketLength
+			}
+			// buf[4:8] is padding
+			r.metadata = &writeMetadata{
+				metadata: binary.BigEndian.Uint64(buf[8:16]),
+				mask:     binary.BigEndian.Uint64(buf[16:24]),
+			}
+		case OFPIT_CLEAR_ACTIONS:
+			r.clearActions = &clearActions{}
+		case OFPIT_WRITE_ACTIONS, OFPIT_APPLY_ACTIONS:
+			action := NewAction()
+			// buf[4:8] is padding
+			if err := action.UnmarshalBinary(buf[8:length]); err != nil {
+				return err
+			}
+			if t == OFPIT_WRITE_ACTIONS {
+				r.writeAction = &writeAction{action: action}
+			} else {
+				r.applyAction = &applyAction{action: action}
+			}
+		default:
+			// Unsupported instruction type. Keep it as a raw instruction.
+			r.raw = append(r.raw, buf[:length]...)
+		}
+
+		buf = buf[length:]
+	}
+
+	return nil
 }
 
 func (r *Instruction) MarshalBinary() ([]byte, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
-
-	if r.value == nil {
+	if r.isEmpty() {
 		return nil, errors.New("empty action of an instruction")
 	}
 
-	return r.value.MarshalBinary()
+	values := make([]encoding.BinaryMarshaler, 0)
+	// The meter instruction should be applied before other instructions.
+	if r.meter != nil {
+		values = append(values, r.meter)
+	}
+	if r.applyAction != nil {
+		values = append(values, r.applyAction)
+	}
+	if r.clearActions != nil {
+		values = append(values, r.clearActions)
+	}
+	if r.writeAction != nil {
+		values = append(values, r.writeAction)
+	}
+	if r.metadata != nil {
+		values = append(values, r.metadata)
+	}
+	if r.gotoTable != nil {
+		values = append(values, r.gotoTable)
+	}
+
+	v := make([]byte, 0)
+	for _, inst := range values {
+		b, err := inst.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, b...)
+	}
+
+	return append(v, r.raw...), nil
 }
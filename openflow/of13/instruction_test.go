@@ -0,0 +1,83 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func TestInstructionSet(t *testing.T) {
+	outPort := openflow.NewOutPort()
+	outPort.SetValue(3)
+	action := NewAction()
+	action.SetOutPort(outPort)
+
+	inst := new(Instruction)
+	inst.GotoTable(2)
+	inst.WriteMetadata(0x10, 0xF0)
+	inst.WriteAction(action)
+	inst.ClearActions()
+	v, err := inst.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Expected order: clear-actions, write-actions, write-metadata, goto-table.
+	types := make([]uint16, 0)
+	for buf := v; len(buf) >= 4; buf = buf[binary.BigEndian.Uint16(buf[2:4]):] {
+		types = append(types, binary.BigEndian.Uint16(buf[0:2]))
+	}
+	expected := []uint16{OFPIT_CLEAR_ACTIONS, OFPIT_WRITE_ACTIONS, OFPIT_WRITE_METADATA, OFPIT_GOTO_TABLE}
+	if len(types) != len(expected) {
+		t.Fatalf("unexpected instructions: expected=%v, got=%v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("unexpected instructions: expected=%v, got=%v", expected, types)
+		}
+	}
+
+	decoded := new(Instruction)
+	if err := decoded.UnmarshalBinary(v); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.metadata == nil || decoded.metadata.metadata != 0x10 || decoded.metadata.mask != 0xF0 {
+		t.Fatalf("unexpected write metadata: %+v", decoded.metadata)
+	}
+	w, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v, w) {
+		t.Fatalf("mismatched instruction: expected=%v, got=%v", v, w)
+	}
+
+	invalid := new(Instruction)
+	invalid.GotoTable(OFPTT_ALL)
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the invalid table ID")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"sort"
 	"sync"
 
 	"github.com/superkkt/cherry/openflow"
@@ -54,22 +55,21 @@ func (r *Match) SetWildcardSrcPort() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	r.deletePortsSrc()
+}
+
+func (r *Match) deletePortsSrc() {
 	delete(r.m, OFPXMT_OFB_TCP_SRC)
 	delete(r.m, OFPXMT_OFB_UDP_SRC)
+	delete(r.m, OFPXMT_OFB_SCTP_SRC)
 }
 
 func (r *Match) SetSrcPort(p uint16) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
-	if !ok {
-		r.err = errors.Wrap(openflow.ErrMissingEtherType, "SetSrcPort")
-		return
-	}
-	// IPv4?
-	if etherType.(uint16) != 0x0800 {
-		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetSrcPort")
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetSrcPort")
 		return
 	}
 
@@ -79,15 +79,17 @@ func (r *Match) SetSrcPort(p uint16) {
 		return
 	}
 
+	r.deletePortsSrc()
 	switch proto.(uint8) {
 	// TCP
 	case 0x06:
 		r.m[OFPXMT_OFB_TCP_SRC] = p
-		delete(r.m, OFPXMT_OFB_UDP_SRC)
 	// UDP
 	case 0x11:
 		r.m[OFPXMT_OFB_UDP_SRC] = p
-		delete(r.m, OFPXMT_OFB_TCP_SRC)
+	// SCTP
+	case 0x84:
+		r.m[OFPXMT_OFB_SCTP_SRC] = p
 	default:
 		r.err = errors.Wrap(openflow.ErrUnsupportedIPProtocol, "SetSrcPort")
 		return
@@ -108,6 +110,11 @@ func (r *Match) SrcPort() (wildcard bool, port uint16) {
 		return false, v.(uint16)
 	}
 
+	v, ok = r.m[OFPXMT_OFB_SCTP_SRC]
+	if ok {
+		return false, v.(uint16)
+	}
+
 	return true, 0
 }
 
@@ -115,22 +122,21 @@ func (r *Match) SetWildcardDstPort() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	r.deletePortsDst()
+}
+
+func (r *Match) deletePortsDst() {
 	delete(r.m, OFPXMT_OFB_TCP_DST)
 	delete(r.m, OFPXMT_OFB_UDP_DST)
+	delete(r.m, OFPXMT_OFB_SCTP_DST)
 }
 
 func (r *Match) SetDstPort(p uint16) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
-	if !ok {
-		r.err = errors.Wrap(openflow.ErrMissingEtherType, "SetDstPort")
-		return
-	}
-	// IPv4?
-	if etherType.(uint16) != 0x0800 {
-		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetDstPort")
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetDstPort")
 		return
 	}
 
@@ -140,15 +146,17 @@ func (r *Match) SetDstPort(p uint16) {
 		return
 	}
 
+	r.deletePortsDst()
 	switch proto.(uint8) {
 	// TCP
 	case 0x06:
 		r.m[OFPXMT_OFB_TCP_DST] = p
-		delete(r.m, OFPXMT_OFB_UDP_DST)
 	// UDP
 	case 0x11:
 		r.m[OFPXMT_OFB_UDP_DST] = p
-		delete(r.m, OFPXMT_OFB_TCP_DST)
+	// SCTP
+	case 0x84:
+		r.m[OFPXMT_OFB_SCTP_DST] = p
 	default:
 		r.err = errors.Wrap(openflow.ErrUnsupportedIPProtocol, "SetDstPort")
 		return
@@ -169,6 +177,11 @@ func (r *Match) DstPort() (wildcard bool, port uint16) {
 		return false, v.(uint16)
 	}
 
+	v, ok = r.m[OFPXMT_OFB_SCTP_DST]
+	if ok {
+		return false, v.(uint16)
+	}
+
 	return true, 0
 }
 
@@ -235,14 +248,8 @@ func (r *Match) SetIPProtocol(p uint8) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
-	if !ok {
-		r.err = errors.Wrap(openflow.ErrMissingEtherType, "SetIPProtocol")
-		return
-	}
-	// IPv4?
-	if etherType.(uint16) != 0x0800 {
-		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetIPProtocol")
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetIPProtocol")
 		return
 	}
 
@@ -367,18 +374,34 @@ func (r *Match) SetSrcIP(ip *net.IPNet) {
 		return
 	}
 
-	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
-	if !ok {
-		r.err = errors.Wrap(openflow.ErrMissingEtherType, "SetSrcIP")
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetSrcIP")
 		return
 	}
-	// IPv4?
-	if etherType.(uint16) != 0x0800 {
-		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetSrcIP")
-		return
+
+	delete(r.m, OFPXMT_OFB_IPV4_SRC)
+	delete(r.m, OFPXMT_OFB_IPV6_SRC)
+	if r.m[OFPXMT_OFB_ETH_TYPE].(uint16) == 0x0800 {
+		if ip.IP.To4() == nil {
+			r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetSrcIP")
+			return
+		}
+		r.m[OFPXMT_OFB_IPV4_SRC] = ip
+	} else {
+		if ip.IP.To4() != nil || ip.IP.To16() == nil {
+			r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetSrcIP")
+			return
+		}
+		r.m[OFPXMT_OFB_IPV6_SRC] = ip
 	}
+}
 
-	r.m[OFPXMT_OFB_IPV4_SRC] = ip
+func (r *Match) SetWildcardSrcIP() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.m, OFPXMT_OFB_IPV4_SRC)
+	delete(r.m, OFPXMT_OFB_IPV6_SRC)
 }
 
 func (r *Match) SrcIP() *net.IPNet {
@@ -389,6 +412,10 @@ func (r *Match) SrcIP() *net.IPNet {
 	if ok {
 		return v.(*net.IPNet)
 	}
+	v, ok = r.m[OFPXMT_OFB_IPV6_SRC]
+	if ok {
+		return v.(*net.IPNet)
+	}
 
 	return &net.IPNet{
 		IP:   net.IPv4zero,
@@ -408,18 +435,34 @@ func (r *Match) SetDstIP(ip *net.IPNet) {
 		return
 	}
 
-	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
-	if !ok {
-		r.err = errors.Wrap(openflow.ErrMissingEtherType, "SetDstIP")
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetDstIP")
 		return
 	}
-	// IPv4?
-	if etherType.(uint16) != 0x0800 {
-		r.err = errors.Wrap(openflow.ErrUnsupportedEtherType, "SetDstIP")
-		return
+
+	delete(r.m, OFPXMT_OFB_IPV4_DST)
+	delete(r.m, OFPXMT_OFB_IPV6_DST)
+	if r.m[OFPXMT_OFB_ETH_TYPE].(uint16) == 0x0800 {
+		if ip.IP.To4() == nil {
+			r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetDstIP")
+			return
+		}
+		r.m[OFPXMT_OFB_IPV4_DST] = ip
+	} else {
+		if ip.IP.To4() != nil || ip.IP.To16() == nil {
+			r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetDstIP")
+			return
+		}
+		r.m[OFPXMT_OFB_IPV6_DST] = ip
 	}
+}
+
+func (r *Match) SetWildcardDstIP() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	r.m[OFPXMT_OFB_IPV4_DST] = ip
+	delete(r.m, OFPXMT_OFB_IPV4_DST)
+	delete(r.m, OFPXMT_OFB_IPV6_DST)
 }
 
 func (r *Match) DstIP() *net.IPNet {
@@ -430,6 +473,10 @@ func (r *Match) DstIP() *net.IPNet {
 	if ok {
 		return v.(*net.IPNet)
 	}
+	v, ok = r.m[OFPXMT_OFB_IPV6_DST]
+	if ok {
+		return v.(*net.IPNet)
+	}
 
 	return &net.IPNet{
 		IP:   net.IPv4zero,
@@ -463,6 +510,541 @@ func (r *Match) EtherType() (wildcard bool, etherType uint16) {
 	return true, 0
 }
 
+// checkEtherType returns an error if the Ethernet type is missing or is not one
+// of types. The caller should hold the lock.
+func (r *Match) checkEtherType(types ...uint16) error {
+	etherType, ok := r.m[OFPXMT_OFB_ETH_TYPE]
+	if !ok {
+		return openflow.ErrMissingEtherType
+	}
+	for _, v := range types {
+		if etherType.(uint16) == v {
+			return nil
+		}
+	}
+
+	return openflow.ErrUnsupportedEtherType
+}
+
+// checkIPProtocol returns an error if the IP protocol is missing or is not one of
+// protocols. The caller should hold the lock.
+func (r *Match) checkIPProtocol(protocols ...uint8) error {
+	proto, ok := r.m[OFPXMT_OFB_IP_PROTO]
+	if !ok {
+		return openflow.ErrMissingIPProtocol
+	}
+	for _, v := range protocols {
+		if proto.(uint8) == v {
+			return nil
+		}
+	}
+
+	return openflow.ErrUnsupportedIPProtocol
+}
+
+// checkICMPv6Type returns an error if the ICMPv6 type is missing or is not one of
+// types. The caller should hold the lock.
+func (r *Match) checkICMPv6Type(types ...uint8) error {
+	if err := r.checkEtherType(0x86DD); err != nil {
+		return err
+	}
+	if err := r.checkIPProtocol(0x3A); err != nil {
+		return err
+	}
+	t, ok := r.m[OFPXMT_OFB_ICMPV6_TYPE]
+	if !ok {
+		return errors.New("missing ICMPv6 type")
+	}
+	for _, v := range types {
+		if t.(uint8) == v {
+			return nil
+		}
+	}
+
+	return errors.New("unsupported ICMPv6 type")
+}
+
+func (r *Match) getUint8(field uint) (wildcard bool, v uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	value, ok := r.m[field]
+	if ok {
+		return false, value.(uint8)
+	}
+
+	return true, 0
+}
+
+func (r *Match) getHardwareAddr(field uint) (wildcard bool, mac net.HardwareAddr) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	value, ok := r.m[field]
+	if ok {
+		return false, value.(net.HardwareAddr)
+	}
+
+	return true, net.HardwareAddr([]byte{0, 0, 0, 0, 0, 0})
+}
+
+func (r *Match) getIPv4Net(field uint) *net.IPNet {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	value, ok := r.m[field]
+	if ok {
+		return value.(*net.IPNet)
+	}
+
+	return &net.IPNet{
+		IP:   net.IPv4zero,
+		Mask: net.CIDRMask(0, 32),
+	}
+}
+
+func (r *Match) deleteField(fields ...uint) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, v := range fields {
+		delete(r.m, v)
+	}
+}
+
+func (r *Match) SetWildcardICMPType() {
+	r.deleteField(OFPXMT_OFB_ICMPV4_TYPE, OFPXMT_OFB_ICMPV6_TYPE)
+}
+
+func (r *Match) SetICMPType(t uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetICMPType")
+		return
+	}
+
+	if r.m[OFPXMT_OFB_ETH_TYPE].(uint16) == 0x0800 {
+		if err := r.checkIPProtocol(0x01); err != nil {
+			r.err = errors.Wrap(err, "SetICMPType")
+			return
+		}
+		r.m[OFPXMT_OFB_ICMPV4_TYPE] = t
+	} else {
+		if err := r.checkIPProtocol(0x3A); err != nil {
+			r.err = errors.Wrap(err, "SetICMPType")
+			return
+		}
+		r.m[OFPXMT_OFB_ICMPV6_TYPE] = t
+	}
+}
+
+func (r *Match) ICMPType() (wildcard bool, t uint8) {
+	if wildcard, t := r.getUint8(OFPXMT_OFB_ICMPV4_TYPE); !wildcard {
+		return false, t
+	}
+
+	return r.getUint8(OFPXMT_OFB_ICMPV6_TYPE)
+}
+
+func (r *Match) SetWildcardICMPCode() {
+	r.deleteField(OFPXMT_OFB_ICMPV4_CODE, OFPXMT_OFB_ICMPV6_CODE)
+}
+
+func (r *Match) SetICMPCode(code uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetICMPCode")
+		return
+	}
+
+	if r.m[OFPXMT_OFB_ETH_TYPE].(uint16) == 0x0800 {
+		if err := r.checkIPProtocol(0x01); err != nil {
+			r.err = errors.Wrap(err, "SetICMPCode")
+			return
+		}
+		r.m[OFPXMT_OFB_ICMPV4_CODE] = code
+	} else {
+		if err := r.checkIPProtocol(0x3A); err != nil {
+			r.err = errors.Wrap(err, "SetICMPCode")
+			return
+		}
+		r.m[OFPXMT_OFB_ICMPV6_CODE] = code
+	}
+}
+
+func (r *Match) ICMPCode() (wildcard bool, code uint8) {
+	if wildcard, code := r.getUint8(OFPXMT_OFB_ICMPV4_CODE); !wildcard {
+		return false, code
+	}
+
+	return r.getUint8(OFPXMT_OFB_ICMPV6_CODE)
+}
+
+func (r *Match) SetWildcardIPDSCP() {
+	r.deleteField(OFPXMT_OFB_IP_DSCP)
+}
+
+func (r *Match) SetIPDSCP(dscp uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetIPDSCP")
+		return
+	}
+	if dscp > 63 {
+		r.err = fmt.Errorf("SetIPDSCP: invalid DSCP value: %v", dscp)
+		return
+	}
+
+	r.m[OFPXMT_OFB_IP_DSCP] = dscp
+}
+
+func (r *Match) IPDSCP() (wildcard bool, dscp uint8) {
+	return r.getUint8(OFPXMT_OFB_IP_DSCP)
+}
+
+func (r *Match) SetWildcardIPECN() {
+	r.deleteField(OFPXMT_OFB_IP_ECN)
+}
+
+func (r *Match) SetIPECN(ecn uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0800, 0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetIPECN")
+		return
+	}
+	if ecn > 3 {
+		r.err = fmt.Errorf("SetIPECN: invalid ECN value: %v", ecn)
+		return
+	}
+
+	r.m[OFPXMT_OFB_IP_ECN] = ecn
+}
+
+func (r *Match) IPECN() (wildcard bool, ecn uint8) {
+	return r.getUint8(OFPXMT_OFB_IP_ECN)
+}
+
+func (r *Match) SetWildcardIPv6FlowLabel() {
+	r.deleteField(OFPXMT_OFB_IPV6_FLABEL)
+}
+
+func (r *Match) SetIPv6FlowLabel(label uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x86DD); err != nil {
+		r.err = errors.Wrap(err, "SetIPv6FlowLabel")
+		return
+	}
+	// Flow label is a 20-bit value.
+	if label > 0xFFFFF {
+		r.err = fmt.Errorf("SetIPv6FlowLabel: invalid flow label: %v", label)
+		return
+	}
+
+	r.m[OFPXMT_OFB_IPV6_FLABEL] = label
+}
+
+func (r *Match) IPv6FlowLabel() (wildcard bool, label uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.m[OFPXMT_OFB_IPV6_FLABEL]
+	if ok {
+		return false, v.(uint32)
+	}
+
+	return true, 0
+}
+
+func (r *Match) SetWildcardIPv6NDTarget() {
+	r.deleteField(OFPXMT_OFB_IPV6_ND_TARGET)
+}
+
+func (r *Match) SetIPv6NDTarget(ip net.IP) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Neighbor solicitation or advertisement?
+	if err := r.checkICMPv6Type(135, 136); err != nil {
+		r.err = errors.Wrap(err, "SetIPv6NDTarget")
+		return
+	}
+	if ip == nil || ip.To4() != nil || ip.To16() == nil {
+		r.err = errors.Wrap(openflow.ErrInvalidIPAddress, "SetIPv6NDTarget")
+		return
+	}
+
+	r.m[OFPXMT_OFB_IPV6_ND_TARGET] = ip.To16()
+}
+
+func (r *Match) IPv6NDTarget() (wildcard bool, ip net.IP) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.m[OFPXMT_OFB_IPV6_ND_TARGET]
+	if ok {
+		return false, v.(net.IP)
+	}
+
+	return true, net.IPv6zero
+}
+
+func (r *Match) SetWildcardIPv6NDSLL() {
+	r.deleteField(OFPXMT_OFB_IPV6_ND_SLL)
+}
+
+func (r *Match) SetIPv6NDSLL(mac net.HardwareAddr) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Neighbor solicitation?
+	if err := r.checkICMPv6Type(135); err != nil {
+		r.err = errors.Wrap(err, "SetIPv6NDSLL")
+		return
+	}
+	if mac == nil || len(mac) < 6 {
+		r.err = errors.Wrap(openflow.ErrInvalidMACAddress, "SetIPv6NDSLL")
+		return
+	}
+
+	r.m[OFPXMT_OFB_IPV6_ND_SLL] = mac
+}
+
+func (r *Match) IPv6NDSLL() (wildcard bool, mac net.HardwareAddr) {
+	return r.getHardwareAddr(OFPXMT_OFB_IPV6_ND_SLL)
+}
+
+func (r *Match) SetWildcardIPv6NDTLL() {
+	r.deleteField(OFPXMT_OFB_IPV6_ND_TLL)
+}
+
+func (r *Match) SetIPv6NDTLL(mac net.HardwareAddr) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Neighbor advertisement?
+	if err := r.checkICMPv6Type(136); err != nil {
+		r.err = errors.Wrap(err, "SetIPv6NDTLL")
+		return
+	}
+	if mac == nil || len(mac) < 6 {
+		r.err = errors.Wrap(openflow.ErrInvalidMACAddress, "SetIPv6NDTLL")
+		return
+	}
+
+	r.m[OFPXMT_OFB_IPV6_ND_TLL] = mac
+}
+
+func (r *Match) IPv6NDTLL() (wildcard bool, mac net.HardwareAddr) {
+	return r.getHardwareAddr(OFPXMT_OFB_IPV6_ND_TLL)
+}
+
+func (r *Match) SetWildcardARPOp() {
+	r.deleteField(OFPXMT_OFB_ARP_OP)
+}
+
+func (r *Match) SetARPOp(op uint16) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0806); err != nil {
+		r.err = errors.Wrap(err, "SetARPOp")
+		return
+	}
+
+	r.m[OFPXMT_OFB_ARP_OP] = op
+}
+
+func (r *Match) ARPOp() (wildcard bool, op uint16) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.m[OFPXMT_OFB_ARP_OP]
+	if ok {
+		return false, v.(uint16)
+	}
+
+	return true, 0
+}
+
+func (r *Match) setARPAddr(field uint, ip *net.IPNet, caller string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if ip == nil {
+		panic("ip is nil")
+	}
+	if err := r.checkEtherType(0x0806); err != nil {
+		r.err = errors.Wrap(err, caller)
+		return
+	}
+	if ip.IP.To4() == nil {
+		r.err = errors.Wrap(openflow.ErrInvalidIPAddress, caller)
+		return
+	}
+
+	r.m[field] = ip
+}
+
+func (r *Match) SetWildcardARPSPA() {
+	r.deleteField(OFPXMT_OFB_ARP_SPA)
+}
+
+func (r *Match) SetARPSPA(ip *net.IPNet) {
+	r.setARPAddr(OFPXMT_OFB_ARP_SPA, ip, "SetARPSPA")
+}
+
+func (r *Match) ARPSPA() *net.IPNet {
+	return r.getIPv4Net(OFPXMT_OFB_ARP_SPA)
+}
+
+func (r *Match) SetWildcardARPTPA() {
+	r.deleteField(OFPXMT_OFB_ARP_TPA)
+}
+
+func (r *Match) SetARPTPA(ip *net.IPNet) {
+	r.setARPAddr(OFPXMT_OFB_ARP_TPA, ip, "SetARPTPA")
+}
+
+func (r *Match) ARPTPA() *net.IPNet {
+	return r.getIPv4Net(OFPXMT_OFB_ARP_TPA)
+}
+
+func (r *Match) setARPHardwareAddr(field uint, mac net.HardwareAddr, caller string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkEtherType(0x0806); err != nil {
+		r.err = errors.Wrap(err, caller)
+		return
+	}
+	if mac == nil || len(mac) < 6 {
+		r.err = errors.Wrap(openflow.ErrInvalidMACAddress, caller)
+		return
+	}
+
+	r.m[field] = mac
+}
+
+func (r *Match) SetWildcardARPSHA() {
+	r.deleteField(OFPXMT_OFB_ARP_SHA)
+}
+
+func (r *Match) SetARPSHA(mac net.HardwareAddr) {
+	r.setARPHardwareAddr(OFPXMT_OFB_ARP_SHA, mac, "SetARPSHA")
+}
+
+func (r *Match) ARPSHA() (wildcard bool, mac net.HardwareAddr) {
+	return r.getHardwareAddr(OFPXMT_OFB_ARP_SHA)
+}
+
+func (r *Match) SetWildcardARPTHA() {
+	r.deleteField(OFPXMT_OFB_ARP_THA)
+}
+
+func (r *Match) SetARPTHA(mac net.HardwareAddr) {
+	r.setARPHardwareAddr(OFPXMT_OFB_ARP_THA, mac, "SetARPTHA")
+}
+
+func (r *Match) ARPTHA() (wildcard bool, mac net.HardwareAddr) {
+	return r.getHardwareAddr(OFPXMT_OFB_ARP_THA)
+}
+
+type metadata struct {
+	value uint64
+	mask  uint64
+}
+
+func (r *Match) SetWildcardMetadata() {
+	r.deleteField(OFPXMT_OFB_METADATA)
+}
+
+func (r *Match) SetMetadata(value uint64, mask uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.m[OFPXMT_OFB_METADATA] = metadata{value: value & mask, mask: mask}
+}
+
+func (r *Match) Metadata() (wildcard bool, value uint64, mask uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.m[OFPXMT_OFB_METADATA]
+	if ok {
+		m := v.(metadata)
+		return false, m.value, m.mask
+	}
+
+	return true, 0, 0
+}
+
+// nxmRegister is the key of the Nicira extension register reg0 in the match
+// fields. The keys of the registers follow the OXM field numbers so that they
+// are encoded after the OpenFlow basic fields.
+const nxmRegister = 0x10000
+
+type register struct {
+	value uint32
+	mask  uint32
+}
+
+func (r *Match) SetWildcardRegister(index uint8) {
+	r.deleteField(nxmRegister + uint(index))
+}
+
+func (r *Match) SetRegister(index uint8, value uint32, mask uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if index >= NXM_NX_MAX_REGS {
+		r.err = errors.New("invalid register index")
+		return
+	}
+	r.m[nxmRegister+uint(index)] = register{value: value & mask, mask: mask}
+}
+
+func (r *Match) Register(index uint8) (wildcard bool, value uint32, mask uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.m[nxmRegister+uint(index)]
+	if ok {
+		reg := v.(register)
+		return false, reg.value, reg.mask
+	}
+
+	return true, 0, 0
+}
+
+func marshalRegisterTLV(index uint8, reg register) ([]byte, error) {
+	if reg.mask == 0xFFFFFFFF {
+		data := make([]byte, 8)
+		// TLV header
+		var header uint32 = OFPXMC_NXM_1<<16 | uint32(index)<<9 | 0x0<<8 | 4
+		binary.BigEndian.PutUint32(data[0:4], header)
+		binary.BigEndian.PutUint32(data[4:8], reg.value)
+		return data, nil
+	}
+
+	data := make([]byte, 12)
+	// TLV header
+	var header uint32 = OFPXMC_NXM_1<<16 | uint32(index)<<9 | 0x1<<8 | 8
+	binary.BigEndian.PutUint32(data[0:4], header)
+	binary.BigEndian.PutUint32(data[4:8], reg.value)
+	binary.BigEndian.PutUint32(data[8:12], reg.mask)
+	return data, nil
+}
+
 func marshalIPNetTLV(field uint8, ip *net.IPNet) ([]byte, error) {
 	data := make([]byte, 12)
 	// TLV header
@@ -477,6 +1059,55 @@ func marshalIPNetTLV(field uint8, ip *net.IPNet) ([]byte, error) {
 	return data, nil
 }
 
+func marshalIPv6NetTLV(field uint8, ip *net.IPNet) ([]byte, error) {
+	ipv6 := ip.IP.To16()
+	if ipv6 == nil || ip.IP.To4() != nil {
+		return nil, openflow.ErrInvalidIPAddress
+	}
+
+	// Exact match?
+	ones, bits := ip.Mask.Size()
+	if ones == 128 && bits == 128 {
+		return marshalIPv6TLV(field, ipv6)
+	}
+	mask := ip.Mask
+	if len(mask) != 16 {
+		return nil, errors.New("invalid IPv6 netmask")
+	}
+
+	data := make([]byte, 36)
+	// TLV header
+	var header uint32 = 0x8000<<16 | uint32(field)<<9 | 0x1<<8 | 32
+	binary.BigEndian.PutUint32(data[0:4], header)
+	copy(data[4:20], ipv6)
+	copy(data[20:36], mask)
+	return data, nil
+}
+
+func marshalIPv6TLV(field uint8, ip net.IP) ([]byte, error) {
+	ipv6 := ip.To16()
+	if ipv6 == nil {
+		return nil, openflow.ErrInvalidIPAddress
+	}
+
+	data := make([]byte, 20)
+	// TLV header
+	var header uint32 = 0x8000<<16 | uint32(field)<<9 | 0x0<<8 | 16
+	binary.BigEndian.PutUint32(data[0:4], header)
+	copy(data[4:20], ipv6)
+	return data, nil
+}
+
+func marshalMetadataTLV(m metadata) ([]byte, error) {
+	data := make([]byte, 20)
+	// TLV header
+	var header uint32 = 0x8000<<16 | uint32(OFPXMT_OFB_METADATA)<<9 | 0x1<<8 | 16
+	binary.BigEndian.PutUint32(data[0:4], header)
+	binary.BigEndian.PutUint64(data[4:12], m.value)
+	binary.BigEndian.PutUint64(data[12:20], m.mask)
+	return data, nil
+}
+
 func marshalHardwareAddrTLV(field uint8, mac net.HardwareAddr) ([]byte, error) {
 	data := make([]byte, 10)
 	// TLV header
@@ -491,7 +1122,7 @@ func marshalUint8TLV(field uint8, v uint8) ([]byte, error) {
 	// TLV header
 	var header uint32 = 0x8000<<16 | uint32(field)<<9 | 0x0<<8 | 1
 	binary.BigEndian.PutUint32(data[0:4], header)
-	data[5] = v
+	data[4] = v
 	return data, nil
 }
 
@@ -513,6 +1144,15 @@ func marshalUint32TLV(field uint8, v uint32) ([]byte, error) {
 	return data, nil
 }
 
+func marshalUint64TLV(field uint8, v uint64) ([]byte, error) {
+	data := make([]byte, 12)
+	// TLV header
+	var header uint32 = 0x8000<<16 | uint32(field)<<9 | 0x0<<8 | 8
+	binary.BigEndian.PutUint32(data[0:4], header)
+	binary.BigEndian.PutUint64(data[4:12], v)
+	return data, nil
+}
+
 func marshalTLV(id uint, v interface{}) ([]byte, error) {
 	switch id {
 	case OFPXMT_OFB_IN_PORT:
@@ -554,6 +1194,24 @@ func marshalTLV(id uint, v interface{}) ([]byte, error) {
 	case OFPXMT_OFB_UDP_DST:
 		port := v.(uint16)
 		return marshalUint16TLV(OFPXMT_OFB_UDP_DST, port)
+	case OFPXMT_OFB_SCTP_SRC, OFPXMT_OFB_SCTP_DST, OFPXMT_OFB_ARP_OP:
+		return marshalUint16TLV(uint8(id), v.(uint16))
+	case OFPXMT_OFB_IP_DSCP, OFPXMT_OFB_IP_ECN, OFPXMT_OFB_ICMPV4_TYPE, OFPXMT_OFB_ICMPV4_CODE, OFPXMT_OFB_ICMPV6_TYPE, OFPXMT_OFB_ICMPV6_CODE:
+		return marshalUint8TLV(uint8(id), v.(uint8))
+	case OFPXMT_OFB_ARP_SPA, OFPXMT_OFB_ARP_TPA:
+		return marshalIPNetTLV(uint8(id), v.(*net.IPNet))
+	case OFPXMT_OFB_ARP_SHA, OFPXMT_OFB_ARP_THA, OFPXMT_OFB_IPV6_ND_SLL, OFPXMT_OFB_IPV6_ND_TLL:
+		return marshalHardwareAddrTLV(uint8(id), v.(net.HardwareAddr))
+	case OFPXMT_OFB_IPV6_SRC, OFPXMT_OFB_IPV6_DST:
+		return marshalIPv6NetTLV(uint8(id), v.(*net.IPNet))
+	case OFPXMT_OFB_IPV6_FLABEL:
+		return marshalUint32TLV(uint8(id), v.(uint32))
+	case OFPXMT_OFB_IPV6_ND_TARGET:
+		return marshalIPv6TLV(uint8(id), v.(net.IP))
+	case OFPXMT_OFB_METADATA:
+		return marshalMetadataTLV(v.(metadata))
+	case nxmRegister, nxmRegister + 1, nxmRegister + 2, nxmRegister + 3, nxmRegister + 4, nxmRegister + 5, nxmRegister + 6, nxmRegister + 7:
+		return marshalRegisterTLV(uint8(id-nxmRegister), v.(register))
 	default:
 		panic(fmt.Sprintf("unexpected TLV type: %v", id))
 	}
@@ -569,8 +1227,16 @@ func (r *Match) MarshalBinary() ([]byte, error) {
 
 	data := make([]byte, 4)
 	binary.BigEndian.PutUint16(data[0:2], OFPMT_OXM)
-	for k, v := range r.m {
-		tlv, err := marshalTLV(k, v)
+	// Encode the fields in ascending order of the OXM field number so that the
+	// prerequisite fields, e.g., ETH_TYPE and IP_PROTO, always precede the fields
+	// that depend on them, and the encoded match is deterministic.
+	fields := make([]uint, 0, len(r.m))
+	for k := range r.m {
+		fields = append(fields, k)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i] < fields[j] })
+	for _, k := range fields {
+		tlv, err := marshalTLV(k, r.m[k])
 		if err != nil {
 			return nil, err
 		}
@@ -651,13 +1317,90 @@ func (r *Match) unmarshalIPNetTLV(field uint8, hasmask uint8, data []byte) error
 	return nil
 }
 
+func (r *Match) unmarshalIPv6NetTLV(field uint8, hasmask uint8, data []byte) error {
+	length := 20
+	if hasmask == 1 {
+		length = 36
+	}
+	if len(data) < length {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	ip := make(net.IP, 16)
+	copy(ip, data[4:20])
+	mask := net.CIDRMask(128, 128)
+	if hasmask == 1 {
+		copy(mask, data[20:36])
+	}
+
+	r.m[uint(field)] = &net.IPNet{
+		IP:   ip,
+		Mask: mask,
+	}
+
+	return nil
+}
+
+func (r *Match) unmarshalIPv6TLV(field uint8, data []byte) error {
+	if len(data) < 20 {
+		return openflow.ErrInvalidPacketLength
+	}
+	ip := make(net.IP, 16)
+	copy(ip, data[4:20])
+	r.m[uint(field)] = ip
+
+	return nil
+}
+
+func (r *Match) unmarshalMetadataTLV(hasmask uint8, data []byte) error {
+	length := 12
+	if hasmask == 1 {
+		length = 20
+	}
+	if len(data) < length {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	m := metadata{
+		value: binary.BigEndian.Uint64(data[4:12]),
+		mask:  0xFFFFFFFFFFFFFFFF,
+	}
+	if hasmask == 1 {
+		m.mask = binary.BigEndian.Uint64(data[12:20])
+	}
+	r.m[OFPXMT_OFB_METADATA] = m
+
+	return nil
+}
+
+func (r *Match) unmarshalRegisterTLV(index uint8, hasmask uint8, data []byte) error {
+	length := 8
+	if hasmask == 1 {
+		length = 12
+	}
+	if len(data) < length {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	reg := register{
+		value: binary.BigEndian.Uint32(data[4:8]),
+		mask:  0xFFFFFFFF,
+	}
+	if hasmask == 1 {
+		reg.mask = binary.BigEndian.Uint32(data[8:12])
+	}
+	r.m[nxmRegister+uint(index)] = reg
+
+	return nil
+}
+
 func (r *Match) unmarshalTLV(data []byte) error {
 	buf := data
 	// TLV header length is 4 bytes
 	for len(buf) >= 4 {
 		header := binary.BigEndian.Uint32(buf[0:4])
 		class := header >> 16 & 0xFFFF
-		if class != 0x8000 {
+		if class != 0x8000 && class != OFPXMC_NXM_1 {
 			return errors.New("unsupported TLV class")
 		}
 		field := header >> 9 & 0x7F
@@ -667,6 +1410,16 @@ func (r *Match) unmarshalTLV(data []byte) error {
 		if len(buf) < int(4+length) {
 			return openflow.ErrInvalidPacketLength
 		}
+		// Nicira extension fields of Open vSwitch.
+		if class == OFPXMC_NXM_1 {
+			if field < NXM_NX_MAX_REGS {
+				if err := r.unmarshalRegisterTLV(uint8(field), uint8(hasmask), buf); err != nil {
+					return err
+				}
+			}
+			buf = buf[4+length:]
+			continue
+		}
 
 		switch field {
 		case OFPXMT_OFB_IN_PORT:
@@ -721,6 +1474,38 @@ func (r *Match) unmarshalTLV(data []byte) error {
 			if err := r.unmarshalUint16TLV(OFPXMT_OFB_UDP_DST, buf); err != nil {
 				return err
 			}
+		case OFPXMT_OFB_SCTP_SRC, OFPXMT_OFB_SCTP_DST, OFPXMT_OFB_ARP_OP:
+			if err := r.unmarshalUint16TLV(uint8(field), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_IP_DSCP, OFPXMT_OFB_IP_ECN, OFPXMT_OFB_ICMPV4_TYPE, OFPXMT_OFB_ICMPV4_CODE, OFPXMT_OFB_ICMPV6_TYPE, OFPXMT_OFB_ICMPV6_CODE:
+			if err := r.unmarshalUint8TLV(uint8(field), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_ARP_SPA, OFPXMT_OFB_ARP_TPA:
+			if err := r.unmarshalIPNetTLV(uint8(field), uint8(hasmask), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_ARP_SHA, OFPXMT_OFB_ARP_THA, OFPXMT_OFB_IPV6_ND_SLL, OFPXMT_OFB_IPV6_ND_TLL:
+			if err := r.unmarshalHardwareAddrTLV(uint8(field), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_IPV6_SRC, OFPXMT_OFB_IPV6_DST:
+			if err := r.unmarshalIPv6NetTLV(uint8(field), uint8(hasmask), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_IPV6_FLABEL:
+			if err := r.unmarshalUint32TLV(uint8(field), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_IPV6_ND_TARGET:
+			if err := r.unmarshalIPv6TLV(uint8(field), buf); err != nil {
+				return err
+			}
+		case OFPXMT_OFB_METADATA:
+			if err := r.unmarshalMetadataTLV(uint8(hasmask), buf); err != nil {
+				return err
+			}
 		default:
 			// Do nothing
 		}
@@ -0,0 +1,126 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestMatchIPv6(t *testing.T) {
+	_, src, err := net.ParseCIDR("2001:db8::/64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := net.ParseIP("2001:db8::1")
+
+	match := NewMatch()
+	match.SetEtherType(0x86DD)
+	match.SetSrcIP(src)
+	match.SetIPProtocol(0x3A)
+	match.SetICMPType(135)
+	match.SetIPv6NDTarget(target)
+	match.SetMetadata(0x1234, 0xFFFF)
+	data, err := match.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewMatch()
+	if err := v.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if ip := v.SrcIP(); !ip.IP.Equal(src.IP) || !bytes.Equal(ip.Mask, src.Mask) {
+		t.Fatalf("unexpected source IP: %v", ip)
+	}
+	if wildcard, icmpType := v.ICMPType(); wildcard || icmpType != 135 {
+		t.Fatalf("unexpected ICMPv6 type: wildcard=%v, type=%v", wildcard, icmpType)
+	}
+	if wildcard, ip := v.IPv6NDTarget(); wildcard || !ip.Equal(target) {
+		t.Fatalf("unexpected ND target: wildcard=%v, ip=%v", wildcard, ip)
+	}
+	if wildcard, value, mask := v.Metadata(); wildcard || value != 0x1234 || mask != 0xFFFF {
+		t.Fatalf("unexpected metadata: wildcard=%v, value=%v, mask=%v", wildcard, value, mask)
+	}
+}
+
+func TestMatchARP(t *testing.T) {
+	sha := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	spa := &net.IPNet{IP: net.IPv4(10, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)}
+
+	match := NewMatch()
+	match.SetEtherType(0x0806)
+	match.SetARPOp(2)
+	match.SetARPSHA(sha)
+	match.SetARPSPA(spa)
+	data, err := match.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewMatch()
+	if err := v.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if wildcard, op := v.ARPOp(); wildcard || op != 2 {
+		t.Fatalf("unexpected ARP opcode: wildcard=%v, op=%v", wildcard, op)
+	}
+	if wildcard, mac := v.ARPSHA(); wildcard || !bytes.Equal(mac, sha) {
+		t.Fatalf("unexpected ARP SHA: wildcard=%v, mac=%v", wildcard, mac)
+	}
+	if ip := v.ARPSPA(); !ip.IP.Equal(spa.IP) {
+		t.Fatalf("unexpected ARP SPA: %v", ip)
+	}
+
+	// ARP fields require the ARP Ethernet type.
+	invalid := NewMatch()
+	invalid.SetEtherType(0x0800)
+	invalid.SetARPOp(1)
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the ARP opcode in an IPv4 match")
+	}
+}
+
+func TestMatchRegister(t *testing.T) {
+	match := NewMatch()
+	match.SetRegister(0, 0x12, 0xFFFFFFFF)
+	match.SetRegister(5, 0x1234, 0xFF00)
+	v, err := match.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewMatch()
+	if err := decoded.UnmarshalBinary(v); err != nil {
+		t.Fatal(err)
+	}
+	if wildcard, value, mask := decoded.Register(0); wildcard || value != 0x12 || mask != 0xFFFFFFFF {
+		t.Fatalf("unexpected reg0: wildcard=%v, value=%#x, mask=%#x", wildcard, value, mask)
+	}
+	if wildcard, value, mask := decoded.Register(5); wildcard || value != 0x1200 || mask != 0xFF00 {
+		t.Fatalf("unexpected reg5: wildcard=%v, value=%#x, mask=%#x", wildcard, value, mask)
+	}
+	if wildcard, _, _ := decoded.Register(1); !wildcard {
+		t.Fatal("reg1 should be a wildcard")
+	}
+}
@@ -0,0 +1,270 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func getMeterFlags(flags openflow.MeterFlag) uint16 {
+	var v uint16
+	if flags&openflow.MeterFlagKbps != 0 {
+		v |= OFPMF_KBPS
+	}
+	if flags&openflow.MeterFlagPktps != 0 {
+		v |= OFPMF_PKTPS
+	}
+	if flags&openflow.MeterFlagBurst != 0 {
+		v |= OFPMF_BURST
+	}
+	if flags&openflow.MeterFlagStats != 0 {
+		v |= OFPMF_STATS
+	}
+
+	return v
+}
+
+func parseMeterFlags(flags uint16) openflow.MeterFlag {
+	var v openflow.MeterFlag
+	if flags&OFPMF_KBPS != 0 {
+		v |= openflow.MeterFlagKbps
+	}
+	if flags&OFPMF_PKTPS != 0 {
+		v |= openflow.MeterFlagPktps
+	}
+	if flags&OFPMF_BURST != 0 {
+		v |= openflow.MeterFlagBurst
+	}
+	if flags&OFPMF_STATS != 0 {
+		v |= openflow.MeterFlagStats
+	}
+
+	return v
+}
+
+func getMeterBandType(t openflow.MeterBandType) (uint16, error) {
+	switch t {
+	case openflow.MeterBandDrop:
+		return OFPMBT_DROP, nil
+	case openflow.MeterBandDSCPRemark:
+		return OFPMBT_DSCP_REMARK, nil
+	default:
+		return 0, fmt.Errorf("unexpected meter band type: %v", t)
+	}
+}
+
+type MeterBand struct {
+	err       error
+	bandType  openflow.MeterBandType
+	rate      uint32
+	burstSize uint32
+	precLevel uint8
+}
+
+func NewMeterBand() openflow.MeterBand {
+	return &MeterBand{
+		bandType: openflow.MeterBandDrop,
+	}
+}
+
+func (r *MeterBand) BandType() openflow.MeterBandType {
+	return r.bandType
+}
+
+func (r *MeterBand) SetBandType(t openflow.MeterBandType) {
+	if _, err := getMeterBandType(t); err != nil {
+		r.err = err
+		return
+	}
+	r.bandType = t
+}
+
+func (r *MeterBand) Rate() uint32 {
+	return r.rate
+}
+
+func (r *MeterBand) SetRate(rate uint32) {
+	r.rate = rate
+}
+
+func (r *MeterBand) BurstSize() uint32 {
+	return r.burstSize
+}
+
+func (r *MeterBand) SetBurstSize(size uint32) {
+	r.burstSize = size
+}
+
+func (r *MeterBand) PrecLevel() uint8 {
+	return r.precLevel
+}
+
+func (r *MeterBand) SetPrecLevel(level uint8) {
+	r.precLevel = level
+}
+
+func (r *MeterBand) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	bandType, err := getMeterBandType(r.bandType)
+	if err != nil {
+		return nil, err
+	}
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint16(v[0:2], bandType)
+	binary.BigEndian.PutUint16(v[2:4], 16)
+	binary.BigEndian.PutUint32(v[4:8], r.rate)
+	binary.BigEndian.PutUint32(v[8:12], r.burstSize)
+	if r.bandType == openflow.MeterBandDSCPRemark {
+		v[12] = r.precLevel
+	}
+	// v[13:16] is padding
+
+	return v, nil
+}
+
+func (r *MeterBand) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	switch binary.BigEndian.Uint16(data[0:2]) {
+	case OFPMBT_DROP:
+		r.bandType = openflow.MeterBandDrop
+	case OFPMBT_DSCP_REMARK:
+		r.bandType = openflow.MeterBandDSCPRemark
+		r.precLevel = data[12]
+	default:
+		return fmt.Errorf("unsupported meter band type: %v", binary.BigEndian.Uint16(data[0:2]))
+	}
+	r.rate = binary.BigEndian.Uint32(data[4:8])
+	r.burstSize = binary.BigEndian.Uint32(data[8:12])
+
+	return nil
+}
+
+// unmarshalMeterBands decodes a list of ofp_meter_band_header. Unsupported
+// band types, such as experimenter bands, are skipped.
+func unmarshalMeterBands(data []byte) ([]openflow.MeterBand, error) {
+	bands := make([]openflow.MeterBand, 0)
+
+	buf := data
+	for len(buf) >= 4 {
+		t := binary.BigEndian.Uint16(buf[0:2])
+		length := binary.BigEndian.Uint16(buf[2:4])
+		if length < 4 || len(buf) < int(length) {
+			return nil, openflow.ErrInvalidPacketLength
+		}
+		if t == OFPMBT_DROP || t == OFPMBT_DSCP_REMARK {
+			band := NewMeterBand()
+			if err := band.UnmarshalBinary(buf[:length]); err != nil {
+				return nil, err
+			}
+			bands = append(bands, band)
+		}
+		buf = buf[length:]
+	}
+
+	return bands, nil
+}
+
+type MeterMod struct {
+	err error
+	openflow.Message
+	command uint16
+	flags   openflow.MeterFlag
+	meterID uint32
+	bands   []openflow.MeterBand
+}
+
+func NewMeterMod(xid uint32, cmd uint16) openflow.MeterMod {
+	return &MeterMod{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_METER_MOD, xid),
+		command: cmd,
+		flags:   openflow.MeterFlagKbps,
+	}
+}
+
+func (r *MeterMod) Error() error {
+	return r.err
+}
+
+func (r *MeterMod) Flags() openflow.MeterFlag {
+	return r.flags
+}
+
+func (r *MeterMod) SetFlags(flags openflow.MeterFlag) {
+	r.flags = flags
+}
+
+func (r *MeterMod) MeterID() uint32 {
+	return r.meterID
+}
+
+func (r *MeterMod) SetMeterID(id uint32) {
+	if id == 0 || (id > OFPM_MAX && id != OFPM_ALL) {
+		r.err = fmt.Errorf("invalid meter ID: %v", id)
+		return
+	}
+	r.meterID = id
+}
+
+func (r *MeterMod) Bands() []openflow.MeterBand {
+	return r.bands
+}
+
+func (r *MeterMod) AddBand(band openflow.MeterBand) {
+	if band == nil {
+		panic("band is nil")
+	}
+	r.bands = append(r.bands, band)
+}
+
+func (r *MeterMod) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], r.command)
+	binary.BigEndian.PutUint16(v[2:4], getMeterFlags(r.flags))
+	binary.BigEndian.PutUint32(v[4:8], r.meterID)
+
+	// The band list should be empty for the delete command.
+	if r.command != OFPMC_DELETE {
+		for _, b := range r.bands {
+			band, err := b.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, band...)
+		}
+	}
+
+	r.SetPayload(v)
+	return r.Message.MarshalBinary()
+}
@@ -0,0 +1,165 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestMeterBand(t openflow.MeterBandType, rate, burst uint32, precLevel uint8) openflow.MeterBand {
+	band := NewMeterBand()
+	band.SetBandType(t)
+	band.SetRate(rate)
+	band.SetBurstSize(burst)
+	band.SetPrecLevel(precLevel)
+
+	return band
+}
+
+func TestMeterMod(t *testing.T) {
+	mod := NewMeterMod(9, OFPMC_ADD)
+	mod.SetFlags(openflow.MeterFlagKbps | openflow.MeterFlagBurst | openflow.MeterFlagStats)
+	mod.SetMeterID(5)
+	mod.AddBand(newTestMeterBand(openflow.MeterBandDrop, 1000, 100, 0))
+	mod.AddBand(newTestMeterBand(openflow.MeterBandDSCPRemark, 500, 50, 2))
+	packet, err := mod.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := new(openflow.Message)
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type() != OFPT_METER_MOD || msg.TransactionID() != 9 {
+		t.Fatalf("unexpected header: type=%v, xid=%v", msg.Type(), msg.TransactionID())
+	}
+	payload := msg.Payload()
+	if len(payload) != 8+2*16 {
+		t.Fatalf("unexpected payload length: %v", len(payload))
+	}
+	if cmd := binary.BigEndian.Uint16(payload[0:2]); cmd != OFPMC_ADD {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+	if flags := binary.BigEndian.Uint16(payload[2:4]); flags != OFPMF_KBPS|OFPMF_BURST|OFPMF_STATS {
+		t.Fatalf("unexpected flags: %v", flags)
+	}
+	if id := binary.BigEndian.Uint32(payload[4:8]); id != 5 {
+		t.Fatalf("unexpected meter ID: %v", id)
+	}
+	if v := binary.BigEndian.Uint16(payload[8:10]); v != OFPMBT_DROP {
+		t.Fatalf("unexpected band type: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(payload[24:26]); v != OFPMBT_DSCP_REMARK {
+		t.Fatalf("unexpected band type: %v", v)
+	}
+
+	// The band list should be decoded back into the same bands.
+	bands, err := unmarshalMeterBands(payload[8:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bands) != 2 {
+		t.Fatalf("unexpected number of bands: %v", len(bands))
+	}
+	drop, remark := bands[0], bands[1]
+	if drop.BandType() != openflow.MeterBandDrop || drop.Rate() != 1000 || drop.BurstSize() != 100 || drop.PrecLevel() != 0 {
+		t.Fatalf("unexpected drop band: %+v", drop)
+	}
+	if remark.BandType() != openflow.MeterBandDSCPRemark || remark.Rate() != 500 || remark.BurstSize() != 50 || remark.PrecLevel() != 2 {
+		t.Fatalf("unexpected DSCP remark band: %+v", remark)
+	}
+	v, err := remark.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v, payload[24:40]) {
+		t.Fatalf("mismatched band: expected=%v, got=%v", payload[24:40], v)
+	}
+
+	// The delete command should not carry any band.
+	del := NewMeterMod(10, OFPMC_DELETE)
+	del.SetMeterID(OFPM_ALL)
+	del.AddBand(newTestMeterBand(openflow.MeterBandDrop, 1000, 100, 0))
+	packet, err = del.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 16 {
+		t.Fatalf("unexpected delete length: %v", len(packet))
+	}
+
+	invalid := NewMeterMod(11, OFPMC_ADD)
+	invalid.SetMeterID(0)
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the invalid meter ID")
+	}
+	if _, err := invalid.MarshalBinary(); err == nil {
+		t.Fatal("expected an error marshalling the invalid meter ID")
+	}
+
+	// An unknown band type should be reported instead of panicking.
+	unknown := NewMeterMod(12, OFPMC_ADD)
+	unknown.SetMeterID(1)
+	unknown.AddBand(newTestMeterBand(openflow.MeterBandType(0xFF), 1000, 100, 0))
+	if _, err := unknown.MarshalBinary(); err == nil {
+		t.Fatal("expected an error marshalling the unknown band type")
+	}
+}
+
+func TestMeterInstruction(t *testing.T) {
+	inst := new(Instruction)
+	inst.Meter(5)
+	v, err := inst.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 8 {
+		t.Fatalf("unexpected length: %v", len(v))
+	}
+	if typ := binary.BigEndian.Uint16(v[0:2]); typ != OFPIT_METER {
+		t.Fatalf("unexpected instruction type: %v", typ)
+	}
+	if id := binary.BigEndian.Uint32(v[4:8]); id != 5 {
+		t.Fatalf("unexpected meter ID: %v", id)
+	}
+
+	decoded := new(Instruction)
+	if err := decoded.UnmarshalBinary(v); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.meter == nil || decoded.meter.meterID != 5 {
+		t.Fatalf("unexpected meter instruction: %+v", decoded.meter)
+	}
+
+	for _, id := range []uint32{0, OFPM_MAX + 1, OFPM_ALL} {
+		invalid := new(Instruction)
+		invalid.Meter(id)
+		if invalid.Error() == nil {
+			t.Fatalf("expected an error for the invalid meter ID: %v", id)
+		}
+	}
+}
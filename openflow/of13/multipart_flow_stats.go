@@ -83,13 +83,19 @@ func (r *FlowStatsRequest) SetTableID(id uint8) {
 }
 
 func (r *FlowStatsRequest) MarshalBinary() ([]byte, error) {
+	// Flow stats request
+	return r.marshal(OFPMP_FLOW)
+}
+
+// marshal encodes the request whose multipart type is t. Individual and aggregate
+// flow stats requests have the same body.
+func (r *FlowStatsRequest) marshal(t uint16) ([]byte, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
 
 	v := make([]byte, 40)
-	// Flow stats request
-	binary.BigEndian.PutUint16(v[0:2], OFPMP_FLOW)
+	binary.BigEndian.PutUint16(v[0:2], t)
 	v[8] = r.tableID
 	// v[9:12] is padding
 	binary.BigEndian.PutUint32(v[12:16], OFPP_ANY)
@@ -111,4 +117,146 @@ func (r *FlowStatsRequest) MarshalBinary() ([]byte, error) {
 	return r.Message.MarshalBinary()
 }
 
-// TODO: Implement FlowStatsReply
+type FlowStats struct {
+	length          uint16
+	tableID         uint8
+	durationSec     uint32
+	durationNanoSec uint32
+	priority        uint16
+	idleTimeout     uint16
+	hardTimeout     uint16
+	flags           uint16
+	cookie          uint64
+	packetCount     uint64
+	byteCount       uint64
+	match           openflow.Match
+	instruction     openflow.Instruction
+}
+
+func (r *FlowStats) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *FlowStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *FlowStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *FlowStats) Priority() uint16 {
+	return r.priority
+}
+
+func (r *FlowStats) IdleTimeout() uint16 {
+	return r.idleTimeout
+}
+
+func (r *FlowStats) HardTimeout() uint16 {
+	return r.hardTimeout
+}
+
+func (r *FlowStats) Cookie() uint64 {
+	return r.cookie
+}
+
+func (r *FlowStats) PacketCount() uint64 {
+	return r.packetCount
+}
+
+func (r *FlowStats) ByteCount() uint64 {
+	return r.byteCount
+}
+
+func (r *FlowStats) Match() openflow.Match {
+	return r.match
+}
+
+func (r *FlowStats) Instruction() openflow.Instruction {
+	return r.instruction
+}
+
+func (r *FlowStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 56 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 56 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.tableID = data[2]
+	// data[3] is padding
+	r.durationSec = binary.BigEndian.Uint32(data[4:8])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[8:12])
+	r.priority = binary.BigEndian.Uint16(data[12:14])
+	r.idleTimeout = binary.BigEndian.Uint16(data[14:16])
+	r.hardTimeout = binary.BigEndian.Uint16(data[16:18])
+	r.flags = binary.BigEndian.Uint16(data[18:20])
+	// data[20:24] is padding
+	r.cookie = binary.BigEndian.Uint64(data[24:32])
+	r.packetCount = binary.BigEndian.Uint64(data[32:40])
+	r.byteCount = binary.BigEndian.Uint64(data[40:48])
+
+	r.match = NewMatch()
+	if err := r.match.UnmarshalBinary(data[48:r.length]); err != nil {
+		return err
+	}
+	// ofp_match is padded to make it 64-bit aligned.
+	matchLength := (int(binary.BigEndian.Uint16(data[50:52])) + 7) / 8 * 8
+	if 48+matchLength > int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	// A flow entry without instructions drops all the matched packets.
+	r.instruction = nil
+	if 48+matchLength < int(r.length) {
+		inst := new(Instruction)
+		if err := inst.UnmarshalBinary(data[48+matchLength : r.length]); err != nil {
+			return err
+		}
+		if !inst.isEmpty() {
+			r.instruction = inst
+		}
+	}
+
+	return nil
+}
+
+type FlowStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.FlowStats
+}
+
+func (r *FlowStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *FlowStatsReply) Stats() []openflow.FlowStats {
+	return r.stats
+}
+
+func (r *FlowStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.FlowStats, 0)
+	for i := 8; i < len(payload); {
+		v := new(FlowStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
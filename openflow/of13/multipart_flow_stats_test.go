@@ -0,0 +1,126 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestFlowStats(cookie uint64, inst []byte) []byte {
+	v := make([]byte, 56)
+	binary.BigEndian.PutUint16(v[0:2], uint16(56+len(inst)))
+	v[2] = 1 // Table ID
+	binary.BigEndian.PutUint32(v[4:8], 30)
+	binary.BigEndian.PutUint16(v[12:14], 10)
+	binary.BigEndian.PutUint16(v[16:18], 180)
+	binary.BigEndian.PutUint64(v[24:32], cookie)
+	binary.BigEndian.PutUint64(v[32:40], 100)
+	binary.BigEndian.PutUint64(v[40:48], 6400)
+	// Empty OXM match padded to 8 bytes
+	binary.BigEndian.PutUint16(v[48:50], OFPMT_OXM)
+	binary.BigEndian.PutUint16(v[50:52], 4)
+
+	return append(v, inst...)
+}
+
+func newTestFlowStatsReply(more bool, stats ...[]byte) []byte {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint16(payload[0:2], OFPMP_FLOW)
+	if more {
+		binary.BigEndian.PutUint16(payload[2:4], OFPMPF_REPLY_MORE)
+	}
+	for _, v := range stats {
+		payload = append(payload, v...)
+	}
+
+	msg := openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REPLY, 7)
+	msg.SetPayload(payload)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	return packet
+}
+
+func TestFlowStatsReply(t *testing.T) {
+	outPort := openflow.NewOutPort()
+	outPort.SetValue(3)
+	action := NewAction()
+	action.SetOutPort(outPort)
+	inst := new(Instruction)
+	inst.Meter(5)
+	inst.ApplyAction(action)
+	instBytes, err := inst.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := newTestFlowStatsReply(true, newTestFlowStats(1, instBytes), newTestFlowStats(2, nil))
+	reply := new(FlowStatsReply)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if reply.TransactionID() != 7 {
+		t.Fatalf("unexpected transaction ID: %v", reply.TransactionID())
+	}
+	if !reply.More() {
+		t.Fatal("expected the MORE flag")
+	}
+
+	stats := reply.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("unexpected number of flow stats: %v", len(stats))
+	}
+	first := stats[0]
+	if first.Cookie() != 1 || first.TableID() != 1 || first.Priority() != 10 || first.HardTimeout() != 180 {
+		t.Fatalf("unexpected flow stats: %+v", first)
+	}
+	if first.PacketCount() != 100 || first.ByteCount() != 6400 || first.DurationSec() != 30 {
+		t.Fatalf("unexpected flow counters: %+v", first)
+	}
+	if first.Instruction() == nil {
+		t.Fatal("expected an instruction")
+	}
+	v, err := first.Instruction().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v, instBytes) {
+		t.Fatalf("mismatched instruction: expected=%v, got=%v", instBytes, v)
+	}
+	if stats[1].Cookie() != 2 || stats[1].Instruction() != nil {
+		t.Fatalf("unexpected flow stats: %+v", stats[1])
+	}
+
+	packet = newTestFlowStatsReply(false)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if reply.More() || len(reply.Stats()) != 0 {
+		t.Fatal("unexpected last part of the reply")
+	}
+}
@@ -0,0 +1,350 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type GroupStatsRequest struct {
+	openflow.Message
+	groupID uint32
+}
+
+func NewGroupStatsRequest(xid uint32) openflow.GroupStatsRequest {
+	return &GroupStatsRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		groupID: OFPG_ALL,
+	}
+}
+
+func (r *GroupStatsRequest) GroupID() uint32 {
+	return r.groupID
+}
+
+func (r *GroupStatsRequest) SetGroupID(id uint32) {
+	r.groupID = id
+}
+
+func (r *GroupStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 16)
+	// Group stats request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_GROUP)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.groupID)
+	// v[12:16] is padding
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type GroupStats struct {
+	groupID         uint32
+	refCount        uint32
+	packetCount     uint64
+	byteCount       uint64
+	durationSec     uint32
+	durationNanoSec uint32
+	counters        []openflow.BucketCounter
+	length          uint16
+}
+
+func (r *GroupStats) GroupID() uint32 {
+	return r.groupID
+}
+
+func (r *GroupStats) RefCount() uint32 {
+	return r.refCount
+}
+
+func (r *GroupStats) PacketCount() uint64 {
+	return r.packetCount
+}
+
+func (r *GroupStats) ByteCount() uint64 {
+	return r.byteCount
+}
+
+func (r *GroupStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *GroupStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *GroupStats) BucketCounters() []openflow.BucketCounter {
+	return r.counters
+}
+
+func (r *GroupStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 40 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 40 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	// data[2:4] is padding
+	r.groupID = binary.BigEndian.Uint32(data[4:8])
+	r.refCount = binary.BigEndian.Uint32(data[8:12])
+	// data[12:16] is padding
+	r.packetCount = binary.BigEndian.Uint64(data[16:24])
+	r.byteCount = binary.BigEndian.Uint64(data[24:32])
+	r.durationSec = binary.BigEndian.Uint32(data[32:36])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[36:40])
+
+	r.counters = make([]openflow.BucketCounter, 0)
+	for i := 40; i+16 <= int(r.length); i += 16 {
+		r.counters = append(r.counters, openflow.BucketCounter{
+			PacketCount: binary.BigEndian.Uint64(data[i : i+8]),
+			ByteCount:   binary.BigEndian.Uint64(data[i+8 : i+16]),
+		})
+	}
+
+	return nil
+}
+
+type GroupStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.GroupStats
+}
+
+func (r *GroupStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *GroupStatsReply) Stats() []openflow.GroupStats {
+	return r.stats
+}
+
+func (r *GroupStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.GroupStats, 0)
+	for i := 8; i < len(payload); {
+		v := new(GroupStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
+
+type GroupDescRequest struct {
+	openflow.Message
+}
+
+func NewGroupDescRequest(xid uint32) openflow.GroupDescRequest {
+	return &GroupDescRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+	}
+}
+
+func (r *GroupDescRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	// Group description request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_GROUP_DESC)
+	// No flags and body
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type GroupDesc struct {
+	groupType openflow.GroupType
+	groupID   uint32
+	buckets   []openflow.Bucket
+	length    uint16
+}
+
+func (r *GroupDesc) GroupType() openflow.GroupType {
+	return r.groupType
+}
+
+func (r *GroupDesc) GroupID() uint32 {
+	return r.groupID
+}
+
+func (r *GroupDesc) Buckets() []openflow.Bucket {
+	return r.buckets
+}
+
+func (r *GroupDesc) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 8 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	t, err := parseGroupType(data[2])
+	if err != nil {
+		return err
+	}
+	r.groupType = t
+	// data[3] is padding
+	r.groupID = binary.BigEndian.Uint32(data[4:8])
+
+	r.buckets = make([]openflow.Bucket, 0)
+	for i := 8; i < int(r.length); {
+		b := NewBucket()
+		if err := b.UnmarshalBinary(data[i:r.length]); err != nil {
+			return err
+		}
+		r.buckets = append(r.buckets, b)
+		i += int(binary.BigEndian.Uint16(data[i : i+2]))
+	}
+
+	return nil
+}
+
+type GroupDescReply struct {
+	openflow.Message
+	flags  uint16
+	groups []openflow.GroupDesc
+}
+
+func (r *GroupDescReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *GroupDescReply) Groups() []openflow.GroupDesc {
+	return r.groups
+}
+
+func (r *GroupDescReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.groups = make([]openflow.GroupDesc, 0)
+	for i := 8; i < len(payload); {
+		v := new(GroupDesc)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.groups = append(r.groups, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
+
+type GroupFeaturesRequest struct {
+	openflow.Message
+}
+
+func NewGroupFeaturesRequest(xid uint32) openflow.GroupFeaturesRequest {
+	return &GroupFeaturesRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+	}
+}
+
+func (r *GroupFeaturesRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	// Group features request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_GROUP_FEATURES)
+	// No flags and body
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type GroupFeaturesReply struct {
+	openflow.Message
+	types        uint32
+	capabilities uint32
+	maxGroups    [4]uint32
+	actions      [4]uint32
+}
+
+func (r *GroupFeaturesReply) IsSupported(t openflow.GroupType) bool {
+	v, err := getGroupType(t)
+	if err != nil {
+		return false
+	}
+
+	return r.types&(1<<v) != 0
+}
+
+func (r *GroupFeaturesReply) Capabilities() uint32 {
+	return r.capabilities
+}
+
+func (r *GroupFeaturesReply) MaxGroups(t openflow.GroupType) uint32 {
+	v, err := getGroupType(t)
+	if err != nil {
+		return 0
+	}
+
+	return r.maxGroups[v]
+}
+
+func (r *GroupFeaturesReply) Actions(t openflow.GroupType) uint32 {
+	v, err := getGroupType(t)
+	if err != nil {
+		return 0
+	}
+
+	return r.actions[v]
+}
+
+func (r *GroupFeaturesReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 48 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:8] is type, flags and padding of ofp_multipart_reply
+	r.types = binary.BigEndian.Uint32(payload[8:12])
+	r.capabilities = binary.BigEndian.Uint32(payload[12:16])
+	for i := 0; i < 4; i++ {
+		r.maxGroups[i] = binary.BigEndian.Uint32(payload[16+i*4 : 20+i*4])
+		r.actions[i] = binary.BigEndian.Uint32(payload[32+i*4 : 36+i*4])
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestMultipartReply(mpType uint16, more bool, body []byte) []byte {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint16(payload[0:2], mpType)
+	if more {
+		binary.BigEndian.PutUint16(payload[2:4], OFPMPF_REPLY_MORE)
+	}
+	payload = append(payload, body...)
+
+	msg := openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REPLY, 7)
+	msg.SetPayload(payload)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	return packet
+}
+
+func newTestGroupStats(groupID uint32, counters ...openflow.BucketCounter) []byte {
+	v := make([]byte, 40)
+	binary.BigEndian.PutUint16(v[0:2], uint16(40+len(counters)*16))
+	binary.BigEndian.PutUint32(v[4:8], groupID)
+	binary.BigEndian.PutUint32(v[8:12], 2)
+	binary.BigEndian.PutUint64(v[16:24], 100)
+	binary.BigEndian.PutUint64(v[24:32], 6400)
+	binary.BigEndian.PutUint32(v[32:36], 30)
+	binary.BigEndian.PutUint32(v[36:40], 500)
+	for _, c := range counters {
+		b := make([]byte, 16)
+		binary.BigEndian.PutUint64(b[0:8], c.PacketCount)
+		binary.BigEndian.PutUint64(b[8:16], c.ByteCount)
+		v = append(v, b...)
+	}
+
+	return v
+}
+
+func TestGroupStatsRequest(t *testing.T) {
+	req := NewGroupStatsRequest(3)
+	if req.GroupID() != OFPG_ALL {
+		t.Fatalf("unexpected default group ID: %v", req.GroupID())
+	}
+	req.SetGroupID(100)
+	packet, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 24 {
+		t.Fatalf("unexpected length: %v", len(packet))
+	}
+	if v := binary.BigEndian.Uint16(packet[8:10]); v != OFPMP_GROUP {
+		t.Fatalf("unexpected multipart type: %v", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[16:20]); v != 100 {
+		t.Fatalf("unexpected group ID: %v", v)
+	}
+}
+
+func TestGroupStatsReply(t *testing.T) {
+	counters := []openflow.BucketCounter{
+		{PacketCount: 60, ByteCount: 3840},
+		{PacketCount: 40, ByteCount: 2560},
+	}
+	packet := newTestMultipartReply(OFPMP_GROUP, true, append(newTestGroupStats(1, counters...), newTestGroupStats(2)...))
+
+	reply := new(GroupStatsReply)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.More() {
+		t.Fatal("expected the more flag")
+	}
+	stats := reply.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("unexpected number of stats: %v", len(stats))
+	}
+	s := stats[0]
+	if s.GroupID() != 1 || s.RefCount() != 2 || s.PacketCount() != 100 || s.ByteCount() != 6400 {
+		t.Fatalf("unexpected group stats: %+v", s)
+	}
+	if s.DurationSec() != 30 || s.DurationNanoSec() != 500 {
+		t.Fatalf("unexpected duration: %v.%v", s.DurationSec(), s.DurationNanoSec())
+	}
+	if len(s.BucketCounters()) != len(counters) {
+		t.Fatalf("unexpected bucket counters: %v", s.BucketCounters())
+	}
+	for i, c := range s.BucketCounters() {
+		if c != counters[i] {
+			t.Fatalf("unexpected bucket counter: expected=%v, got=%v", counters[i], c)
+		}
+	}
+	if stats[1].GroupID() != 2 || len(stats[1].BucketCounters()) != 0 {
+		t.Fatalf("unexpected group stats: %+v", stats[1])
+	}
+
+	// A truncated entry should be rejected instead of being read out of range.
+	truncated := newTestMultipartReply(OFPMP_GROUP, false, newTestGroupStats(1, counters...)[:48])
+	if err := new(GroupStatsReply).UnmarshalBinary(truncated); err == nil {
+		t.Fatal("expected an error for the truncated group stats")
+	}
+}
+
+func TestGroupDescReply(t *testing.T) {
+	buckets := make([]byte, 0)
+	for i := uint32(1); i <= 2; i++ {
+		v, err := newTestBucket(uint16(i*10), i).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, v...)
+	}
+	desc := make([]byte, 8)
+	binary.BigEndian.PutUint16(desc[0:2], uint16(8+len(buckets)))
+	desc[2] = OFPGT_FF
+	binary.BigEndian.PutUint32(desc[4:8], 100)
+	desc = append(desc, buckets...)
+	empty := make([]byte, 8)
+	binary.BigEndian.PutUint16(empty[0:2], 8)
+	empty[2] = OFPGT_INDIRECT
+	binary.BigEndian.PutUint32(empty[4:8], 200)
+
+	reply := new(GroupDescReply)
+	if err := reply.UnmarshalBinary(newTestMultipartReply(OFPMP_GROUP_DESC, false, append(desc, empty...))); err != nil {
+		t.Fatal(err)
+	}
+	if reply.More() {
+		t.Fatal("unexpected more flag")
+	}
+	groups := reply.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("unexpected number of groups: %v", len(groups))
+	}
+	if groups[0].GroupType() != openflow.GroupTypeFastFailover || groups[0].GroupID() != 100 {
+		t.Fatalf("unexpected group: type=%v, id=%v", groups[0].GroupType(), groups[0].GroupID())
+	}
+	if len(groups[0].Buckets()) != 2 {
+		t.Fatalf("unexpected number of buckets: %v", len(groups[0].Buckets()))
+	}
+	encoded := make([]byte, 0)
+	for _, b := range groups[0].Buckets() {
+		v, err := b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, v...)
+	}
+	if !bytes.Equal(encoded, buckets) {
+		t.Fatalf("mismatched buckets: expected=%v, got=%v", buckets, encoded)
+	}
+	if groups[1].GroupType() != openflow.GroupTypeIndirect || groups[1].GroupID() != 200 || len(groups[1].Buckets()) != 0 {
+		t.Fatalf("unexpected group: type=%v, id=%v", groups[1].GroupType(), groups[1].GroupID())
+	}
+
+	// An unknown group type should be rejected.
+	empty[2] = 0xFF
+	if err := new(GroupDescReply).UnmarshalBinary(newTestMultipartReply(OFPMP_GROUP_DESC, false, empty)); err == nil {
+		t.Fatal("expected an error for the unknown group type")
+	}
+}
+
+func TestGroupFeaturesReply(t *testing.T) {
+	body := make([]byte, 40)
+	binary.BigEndian.PutUint32(body[0:4], 1<<OFPGT_ALL|1<<OFPGT_SELECT)
+	binary.BigEndian.PutUint32(body[4:8], 0x3)
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint32(body[8+i*4:12+i*4], uint32(i+1)*100)
+		binary.BigEndian.PutUint32(body[24+i*4:28+i*4], uint32(i+1))
+	}
+
+	reply := new(GroupFeaturesReply)
+	if err := reply.UnmarshalBinary(newTestMultipartReply(OFPMP_GROUP_FEATURES, false, body)); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.IsSupported(openflow.GroupTypeAll) || !reply.IsSupported(openflow.GroupTypeSelect) {
+		t.Fatal("expected the all and select group types to be supported")
+	}
+	if reply.IsSupported(openflow.GroupTypeIndirect) || reply.IsSupported(openflow.GroupTypeFastFailover) {
+		t.Fatal("unexpected supported group type")
+	}
+	if reply.Capabilities() != 0x3 {
+		t.Fatalf("unexpected capabilities: %v", reply.Capabilities())
+	}
+	if reply.MaxGroups(openflow.GroupTypeIndirect) != 300 || reply.Actions(openflow.GroupTypeFastFailover) != 4 {
+		t.Fatalf("unexpected features: maxGroups=%v, actions=%v", reply.maxGroups, reply.actions)
+	}
+
+	// An unknown group type should not panic.
+	unknown := openflow.GroupType(0xFF)
+	if reply.IsSupported(unknown) || reply.MaxGroups(unknown) != 0 || reply.Actions(unknown) != 0 {
+		t.Fatal("unexpected features for the unknown group type")
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type MeterStatsRequest struct {
+	openflow.Message
+	meterID uint32
+}
+
+func NewMeterStatsRequest(xid uint32) openflow.MeterStatsRequest {
+	return &MeterStatsRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		meterID: OFPM_ALL,
+	}
+}
+
+func (r *MeterStatsRequest) MeterID() uint32 {
+	return r.meterID
+}
+
+func (r *MeterStatsRequest) SetMeterID(id uint32) {
+	r.meterID = id
+}
+
+func (r *MeterStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 16)
+	// Meter stats request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_METER)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.meterID)
+	// v[12:16] is padding
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type MeterStats struct {
+	meterID         uint32
+	flowCount       uint32
+	packetInCount   uint64
+	byteInCount     uint64
+	durationSec     uint32
+	durationNanoSec uint32
+	counters        []openflow.BandCounter
+	length          uint16
+}
+
+func (r *MeterStats) MeterID() uint32 {
+	return r.meterID
+}
+
+func (r *MeterStats) FlowCount() uint32 {
+	return r.flowCount
+}
+
+func (r *MeterStats) PacketInCount() uint64 {
+	return r.packetInCount
+}
+
+func (r *MeterStats) ByteInCount() uint64 {
+	return r.byteInCount
+}
+
+func (r *MeterStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *MeterStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *MeterStats) BandCounters() []openflow.BandCounter {
+	return r.counters
+}
+
+func (r *MeterStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 40 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.meterID = binary.BigEndian.Uint32(data[0:4])
+	r.length = binary.BigEndian.Uint16(data[4:6])
+	if r.length < 40 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	// data[6:12] is padding
+	r.flowCount = binary.BigEndian.Uint32(data[12:16])
+	r.packetInCount = binary.BigEndian.Uint64(data[16:24])
+	r.byteInCount = binary.BigEndian.Uint64(data[24:32])
+	r.durationSec = binary.BigEndian.Uint32(data[32:36])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[36:40])
+
+	r.counters = make([]openflow.BandCounter, 0)
+	for i := 40; i+16 <= int(r.length); i += 16 {
+		r.counters = append(r.counters, openflow.BandCounter{
+			PacketCount: binary.BigEndian.Uint64(data[i : i+8]),
+			ByteCount:   binary.BigEndian.Uint64(data[i+8 : i+16]),
+		})
+	}
+
+	return nil
+}
+
+type MeterStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.MeterStats
+}
+
+func (r *MeterStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *MeterStatsReply) Stats() []openflow.MeterStats {
+	return r.stats
+}
+
+func (r *MeterStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.MeterStats, 0)
+	for i := 8; i < len(payload); {
+		v := new(MeterStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
+
+type MeterConfigRequest struct {
+	openflow.Message
+	meterID uint32
+}
+
+func NewMeterConfigRequest(xid uint32) openflow.MeterConfigRequest {
+	return &MeterConfigRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		meterID: OFPM_ALL,
+	}
+}
+
+func (r *MeterConfigRequest) MeterID() uint32 {
+	return r.meterID
+}
+
+func (r *MeterConfigRequest) SetMeterID(id uint32) {
+	r.meterID = id
+}
+
+func (r *MeterConfigRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 16)
+	// Meter configuration request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_METER_CONFIG)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.meterID)
+	// v[12:16] is padding
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type MeterConfig struct {
+	flags   openflow.MeterFlag
+	meterID uint32
+	bands   []openflow.MeterBand
+	length  uint16
+}
+
+func (r *MeterConfig) Flags() openflow.MeterFlag {
+	return r.flags
+}
+
+func (r *MeterConfig) MeterID() uint32 {
+	return r.meterID
+}
+
+func (r *MeterConfig) Bands() []openflow.MeterBand {
+	return r.bands
+}
+
+func (r *MeterConfig) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 8 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = parseMeterFlags(binary.BigEndian.Uint16(data[2:4]))
+	r.meterID = binary.BigEndian.Uint32(data[4:8])
+
+	bands, err := unmarshalMeterBands(data[8:r.length])
+	if err != nil {
+		return err
+	}
+	r.bands = bands
+
+	return nil
+}
+
+type MeterConfigReply struct {
+	openflow.Message
+	flags  uint16
+	meters []openflow.Meter
+}
+
+func (r *MeterConfigReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *MeterConfigReply) Meters() []openflow.Meter {
+	return r.meters
+}
+
+func (r *MeterConfigReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.meters = make([]openflow.Meter, 0)
+	for i := 8; i < len(payload); {
+		v := new(MeterConfig)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.meters = append(r.meters, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
+
+type MeterFeaturesRequest struct {
+	openflow.Message
+}
+
+func NewMeterFeaturesRequest(xid uint32) openflow.MeterFeaturesRequest {
+	return &MeterFeaturesRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+	}
+}
+
+func (r *MeterFeaturesRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	// Meter features request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_METER_FEATURES)
+	// No flags and body
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type MeterFeaturesReply struct {
+	openflow.Message
+	maxMeter     uint32
+	bandTypes    uint32
+	capabilities uint32
+	maxBands     uint8
+	maxColor     uint8
+}
+
+func (r *MeterFeaturesReply) MaxMeter() uint32 {
+	return r.maxMeter
+}
+
+func (r *MeterFeaturesReply) IsSupported(t openflow.MeterBandType) bool {
+	v, err := getMeterBandType(t)
+	if err != nil {
+		return false
+	}
+
+	return r.bandTypes&(1<<v) != 0
+}
+
+func (r *MeterFeaturesReply) Capabilities() openflow.MeterFlag {
+	return parseMeterFlags(uint16(r.capabilities))
+}
+
+func (r *MeterFeaturesReply) MaxBands() uint8 {
+	return r.maxBands
+}
+
+func (r *MeterFeaturesReply) MaxColor() uint8 {
+	return r.maxColor
+}
+
+func (r *MeterFeaturesReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 24 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:8] is type, flags and padding of ofp_multipart_reply
+	r.maxMeter = binary.BigEndian.Uint32(payload[8:12])
+	r.bandTypes = binary.BigEndian.Uint32(payload[12:16])
+	r.capabilities = binary.BigEndian.Uint32(payload[16:20])
+	r.maxBands = payload[20]
+	r.maxColor = payload[21]
+	// payload[22:24] is padding
+
+	return nil
+}
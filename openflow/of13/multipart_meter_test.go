@@ -0,0 +1,207 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestMeterStats(meterID uint32, counters ...openflow.BandCounter) []byte {
+	v := make([]byte, 40)
+	binary.BigEndian.PutUint32(v[0:4], meterID)
+	binary.BigEndian.PutUint16(v[4:6], uint16(40+len(counters)*16))
+	binary.BigEndian.PutUint32(v[12:16], 2)
+	binary.BigEndian.PutUint64(v[16:24], 100)
+	binary.BigEndian.PutUint64(v[24:32], 6400)
+	binary.BigEndian.PutUint32(v[32:36], 30)
+	binary.BigEndian.PutUint32(v[36:40], 500)
+	for _, c := range counters {
+		b := make([]byte, 16)
+		binary.BigEndian.PutUint64(b[0:8], c.PacketCount)
+		binary.BigEndian.PutUint64(b[8:16], c.ByteCount)
+		v = append(v, b...)
+	}
+
+	return v
+}
+
+func newTestMeterConfig(meterID uint32, bands ...[]byte) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[2:4], OFPMF_PKTPS|OFPMF_BURST)
+	binary.BigEndian.PutUint32(v[4:8], meterID)
+	for _, b := range bands {
+		v = append(v, b...)
+	}
+	binary.BigEndian.PutUint16(v[0:2], uint16(len(v)))
+
+	return v
+}
+
+func TestMeterStatsRequest(t *testing.T) {
+	req := NewMeterStatsRequest(3)
+	if req.MeterID() != OFPM_ALL {
+		t.Fatalf("unexpected default meter ID: %v", req.MeterID())
+	}
+	req.SetMeterID(5)
+	packet, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 24 {
+		t.Fatalf("unexpected length: %v", len(packet))
+	}
+	if v := binary.BigEndian.Uint16(packet[8:10]); v != OFPMP_METER {
+		t.Fatalf("unexpected multipart type: %v", v)
+	}
+	if v := binary.BigEndian.Uint32(packet[16:20]); v != 5 {
+		t.Fatalf("unexpected meter ID: %v", v)
+	}
+}
+
+func TestMeterStatsReply(t *testing.T) {
+	counters := []openflow.BandCounter{
+		{PacketCount: 60, ByteCount: 3840},
+		{PacketCount: 40, ByteCount: 2560},
+	}
+	packet := newTestMultipartReply(OFPMP_METER, true, append(newTestMeterStats(1, counters...), newTestMeterStats(2)...))
+
+	reply := new(MeterStatsReply)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.More() {
+		t.Fatal("expected the more flag")
+	}
+	stats := reply.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("unexpected number of stats: %v", len(stats))
+	}
+	s := stats[0]
+	if s.MeterID() != 1 || s.FlowCount() != 2 || s.PacketInCount() != 100 || s.ByteInCount() != 6400 {
+		t.Fatalf("unexpected meter stats: %+v", s)
+	}
+	if s.DurationSec() != 30 || s.DurationNanoSec() != 500 {
+		t.Fatalf("unexpected duration: %v.%v", s.DurationSec(), s.DurationNanoSec())
+	}
+	if len(s.BandCounters()) != len(counters) {
+		t.Fatalf("unexpected band counters: %v", s.BandCounters())
+	}
+	for i, c := range s.BandCounters() {
+		if c != counters[i] {
+			t.Fatalf("unexpected band counter: expected=%v, got=%v", counters[i], c)
+		}
+	}
+	if stats[1].MeterID() != 2 || len(stats[1].BandCounters()) != 0 {
+		t.Fatalf("unexpected meter stats: %+v", stats[1])
+	}
+
+	// A truncated entry should be rejected instead of being read out of range.
+	truncated := newTestMultipartReply(OFPMP_METER, false, newTestMeterStats(1, counters...)[:48])
+	if err := new(MeterStatsReply).UnmarshalBinary(truncated); err == nil {
+		t.Fatal("expected an error for the truncated meter stats")
+	}
+}
+
+func TestMeterConfigReply(t *testing.T) {
+	drop, err := newTestMeterBand(openflow.MeterBandDrop, 1000, 100, 0).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remark, err := newTestMeterBand(openflow.MeterBandDSCPRemark, 500, 50, 2).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An experimenter band should be skipped.
+	experimenter := make([]byte, 16)
+	binary.BigEndian.PutUint16(experimenter[0:2], OFPMBT_EXPERIMENTER)
+	binary.BigEndian.PutUint16(experimenter[2:4], 16)
+
+	body := append(newTestMeterConfig(1, drop, experimenter, remark), newTestMeterConfig(2)...)
+	reply := new(MeterConfigReply)
+	if err := reply.UnmarshalBinary(newTestMultipartReply(OFPMP_METER_CONFIG, false, body)); err != nil {
+		t.Fatal(err)
+	}
+	if reply.More() {
+		t.Fatal("unexpected more flag")
+	}
+	meters := reply.Meters()
+	if len(meters) != 2 {
+		t.Fatalf("unexpected number of meters: %v", len(meters))
+	}
+	m := meters[0]
+	if m.MeterID() != 1 || m.Flags() != openflow.MeterFlagPktps|openflow.MeterFlagBurst {
+		t.Fatalf("unexpected meter: id=%v, flags=%v", m.MeterID(), m.Flags())
+	}
+	bands := m.Bands()
+	if len(bands) != 2 {
+		t.Fatalf("unexpected number of bands: %v", len(bands))
+	}
+	if bands[0].BandType() != openflow.MeterBandDrop || bands[0].Rate() != 1000 || bands[0].BurstSize() != 100 {
+		t.Fatalf("unexpected drop band: %+v", bands[0])
+	}
+	if bands[1].BandType() != openflow.MeterBandDSCPRemark || bands[1].Rate() != 500 || bands[1].PrecLevel() != 2 {
+		t.Fatalf("unexpected DSCP remark band: %+v", bands[1])
+	}
+	if meters[1].MeterID() != 2 || len(meters[1].Bands()) != 0 {
+		t.Fatalf("unexpected meter: id=%v, bands=%v", meters[1].MeterID(), meters[1].Bands())
+	}
+
+	// A band longer than its meter should be rejected.
+	invalid := newTestMeterConfig(1, drop)
+	binary.BigEndian.PutUint16(invalid[10:12], 32)
+	if err := new(MeterConfigReply).UnmarshalBinary(newTestMultipartReply(OFPMP_METER_CONFIG, false, invalid)); err == nil {
+		t.Fatal("expected an error for the invalid band length")
+	}
+}
+
+func TestMeterFeaturesReply(t *testing.T) {
+	body := make([]byte, 16)
+	binary.BigEndian.PutUint32(body[0:4], 1000)
+	binary.BigEndian.PutUint32(body[4:8], 1<<OFPMBT_DROP)
+	binary.BigEndian.PutUint32(body[8:12], OFPMF_KBPS|OFPMF_STATS)
+	body[12] = 4
+	body[13] = 2
+
+	reply := new(MeterFeaturesReply)
+	if err := reply.UnmarshalBinary(newTestMultipartReply(OFPMP_METER_FEATURES, false, body)); err != nil {
+		t.Fatal(err)
+	}
+	if reply.MaxMeter() != 1000 || reply.MaxBands() != 4 || reply.MaxColor() != 2 {
+		t.Fatalf("unexpected features: maxMeter=%v, maxBands=%v, maxColor=%v", reply.MaxMeter(), reply.MaxBands(), reply.MaxColor())
+	}
+	if reply.Capabilities() != openflow.MeterFlagKbps|openflow.MeterFlagStats {
+		t.Fatalf("unexpected capabilities: %v", reply.Capabilities())
+	}
+	if !reply.IsSupported(openflow.MeterBandDrop) {
+		t.Fatal("expected the drop band to be supported")
+	}
+	if reply.IsSupported(openflow.MeterBandDSCPRemark) {
+		t.Fatal("unexpected supported band type")
+	}
+	// An unknown band type should not panic.
+	if reply.IsSupported(openflow.MeterBandType(0xFF)) {
+		t.Fatal("unexpected supported unknown band type")
+	}
+}
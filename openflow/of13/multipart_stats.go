@@ -0,0 +1,471 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortStatsRequest struct {
+	openflow.Message
+	portNum uint32
+}
+
+func NewPortStatsRequest(xid uint32) openflow.PortStatsRequest {
+	return &PortStatsRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		portNum: OFPP_ANY,
+	}
+}
+
+func (r *PortStatsRequest) PortNumber() (ok bool, num uint32) {
+	if r.portNum == OFPP_ANY {
+		return false, 0
+	}
+
+	return true, r.portNum
+}
+
+func (r *PortStatsRequest) SetPortNumber(num uint32) {
+	r.portNum = num
+}
+
+func (r *PortStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 16)
+	// Port stats request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_PORT_STATS)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.portNum)
+	// v[12:16] is padding
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type PortStats struct {
+	portNum         uint32
+	counters        [12]uint64
+	durationSec     uint32
+	durationNanoSec uint32
+}
+
+func (r *PortStats) PortNumber() uint32 {
+	return r.portNum
+}
+
+func (r *PortStats) RxPackets() uint64 {
+	return r.counters[0]
+}
+
+func (r *PortStats) TxPackets() uint64 {
+	return r.counters[1]
+}
+
+func (r *PortStats) RxBytes() uint64 {
+	return r.counters[2]
+}
+
+func (r *PortStats) TxBytes() uint64 {
+	return r.counters[3]
+}
+
+func (r *PortStats) RxDropped() uint64 {
+	return r.counters[4]
+}
+
+func (r *PortStats) TxDropped() uint64 {
+	return r.counters[5]
+}
+
+func (r *PortStats) RxErrors() uint64 {
+	return r.counters[6]
+}
+
+func (r *PortStats) TxErrors() uint64 {
+	return r.counters[7]
+}
+
+func (r *PortStats) RxFrameErrors() uint64 {
+	return r.counters[8]
+}
+
+func (r *PortStats) RxOverErrors() uint64 {
+	return r.counters[9]
+}
+
+func (r *PortStats) RxCRCErrors() uint64 {
+	return r.counters[10]
+}
+
+func (r *PortStats) Collisions() uint64 {
+	return r.counters[11]
+}
+
+func (r *PortStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *PortStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *PortStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 112 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.portNum = binary.BigEndian.Uint32(data[0:4])
+	// data[4:8] is padding
+	for i := range r.counters {
+		r.counters[i] = binary.BigEndian.Uint64(data[8+i*8 : 16+i*8])
+	}
+	r.durationSec = binary.BigEndian.Uint32(data[104:108])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[108:112])
+
+	return nil
+}
+
+type PortStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.PortStats
+}
+
+func (r *PortStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *PortStatsReply) Stats() []openflow.PortStats {
+	return r.stats
+}
+
+func (r *PortStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.PortStats, 0)
+	for i := 8; i+112 <= len(payload); i += 112 {
+		v := new(PortStats)
+		if err := v.UnmarshalBinary(payload[i : i+112]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type TableStatsRequest struct {
+	openflow.Message
+}
+
+func NewTableStatsRequest(xid uint32) openflow.TableStatsRequest {
+	return &TableStatsRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+	}
+}
+
+func (r *TableStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	// Table stats request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_TABLE)
+	// No flags and body
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type TableStats struct {
+	tableID      uint8
+	activeCount  uint32
+	lookupCount  uint64
+	matchedCount uint64
+}
+
+func (r *TableStats) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *TableStats) ActiveCount() uint32 {
+	return r.activeCount
+}
+
+func (r *TableStats) LookupCount() uint64 {
+	return r.lookupCount
+}
+
+func (r *TableStats) MatchedCount() uint64 {
+	return r.matchedCount
+}
+
+func (r *TableStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 24 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.tableID = data[0]
+	// data[1:4] is padding
+	r.activeCount = binary.BigEndian.Uint32(data[4:8])
+	r.lookupCount = binary.BigEndian.Uint64(data[8:16])
+	r.matchedCount = binary.BigEndian.Uint64(data[16:24])
+
+	return nil
+}
+
+type TableStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.TableStats
+}
+
+func (r *TableStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *TableStatsReply) Stats() []openflow.TableStats {
+	return r.stats
+}
+
+func (r *TableStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.TableStats, 0)
+	for i := 8; i+24 <= len(payload); i += 24 {
+		v := new(TableStats)
+		if err := v.UnmarshalBinary(payload[i : i+24]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type QueueStatsRequest struct {
+	openflow.Message
+	portNum uint32
+	queueID uint32
+}
+
+func NewQueueStatsRequest(xid uint32) openflow.QueueStatsRequest {
+	return &QueueStatsRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		portNum: OFPP_ANY,
+		queueID: OFPQ_ALL,
+	}
+}
+
+func (r *QueueStatsRequest) PortNumber() (ok bool, num uint32) {
+	if r.portNum == OFPP_ANY {
+		return false, 0
+	}
+
+	return true, r.portNum
+}
+
+func (r *QueueStatsRequest) SetPortNumber(num uint32) {
+	r.portNum = num
+}
+
+func (r *QueueStatsRequest) QueueID() (ok bool, id uint32) {
+	if r.queueID == OFPQ_ALL {
+		return false, 0
+	}
+
+	return true, r.queueID
+}
+
+func (r *QueueStatsRequest) SetQueueID(id uint32) {
+	r.queueID = id
+}
+
+func (r *QueueStatsRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 16)
+	// Queue stats request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_QUEUE)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.portNum)
+	binary.BigEndian.PutUint32(v[12:16], r.queueID)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type QueueStats struct {
+	portNum         uint32
+	queueID         uint32
+	txBytes         uint64
+	txPackets       uint64
+	txErrors        uint64
+	durationSec     uint32
+	durationNanoSec uint32
+}
+
+func (r *QueueStats) PortNumber() uint32 {
+	return r.portNum
+}
+
+func (r *QueueStats) QueueID() uint32 {
+	return r.queueID
+}
+
+func (r *QueueStats) TxBytes() uint64 {
+	return r.txBytes
+}
+
+func (r *QueueStats) TxPackets() uint64 {
+	return r.txPackets
+}
+
+func (r *QueueStats) TxErrors() uint64 {
+	return r.txErrors
+}
+
+func (r *QueueStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *QueueStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *QueueStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 40 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.portNum = binary.BigEndian.Uint32(data[0:4])
+	r.queueID = binary.BigEndian.Uint32(data[4:8])
+	r.txBytes = binary.BigEndian.Uint64(data[8:16])
+	r.txPackets = binary.BigEndian.Uint64(data[16:24])
+	r.txErrors = binary.BigEndian.Uint64(data[24:32])
+	r.durationSec = binary.BigEndian.Uint32(data[32:36])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[36:40])
+
+	return nil
+}
+
+type QueueStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.QueueStats
+}
+
+func (r *QueueStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *QueueStatsReply) Stats() []openflow.QueueStats {
+	return r.stats
+}
+
+func (r *QueueStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.QueueStats, 0)
+	for i := 8; i+40 <= len(payload); i += 40 {
+		v := new(QueueStats)
+		if err := v.UnmarshalBinary(payload[i : i+40]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+	}
+
+	return nil
+}
+
+type AggregateStatsRequest struct {
+	*FlowStatsRequest
+}
+
+func NewAggregateStatsRequest(xid uint32) openflow.AggregateStatsRequest {
+	return &AggregateStatsRequest{
+		FlowStatsRequest: NewFlowStatsRequest(xid).(*FlowStatsRequest),
+	}
+}
+
+func (r *AggregateStatsRequest) MarshalBinary() ([]byte, error) {
+	// Aggregate flow stats request
+	return r.marshal(OFPMP_AGGREGATE)
+}
+
+type AggregateStatsReply struct {
+	openflow.Message
+	packetCount uint64
+	byteCount   uint64
+	flowCount   uint32
+}
+
+func (r *AggregateStatsReply) PacketCount() uint64 {
+	return r.packetCount
+}
+
+func (r *AggregateStatsReply) ByteCount() uint64 {
+	return r.byteCount
+}
+
+func (r *AggregateStatsReply) FlowCount() uint32 {
+	return r.flowCount
+}
+
+func (r *AggregateStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 32 {
+		return openflow.ErrInvalidPacketLength
+	}
+	// payload[0:8] is type, flags and padding of ofp_multipart_reply
+	r.packetCount = binary.BigEndian.Uint64(payload[8:16])
+	r.byteCount = binary.BigEndian.Uint64(payload[16:24])
+	r.flowCount = binary.BigEndian.Uint32(payload[24:28])
+	// payload[28:32] is padding
+
+	return nil
+}
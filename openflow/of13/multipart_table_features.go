@@ -23,6 +23,7 @@ package of13
 
 import (
 	"encoding/binary"
+	"strings"
 
 	"github.com/superkkt/cherry/openflow"
 )
@@ -47,4 +48,255 @@ func (r *TableFeaturesRequest) MarshalBinary() ([]byte, error) {
 	return r.Message.MarshalBinary()
 }
 
-// TODO: Implement TableFeaturesReply
+var (
+	instructionTypes = map[uint16]openflow.InstructionType{
+		OFPIT_GOTO_TABLE:     openflow.InstructionGotoTable,
+		OFPIT_WRITE_METADATA: openflow.InstructionWriteMetadata,
+		OFPIT_WRITE_ACTIONS:  openflow.InstructionWriteActions,
+		OFPIT_APPLY_ACTIONS:  openflow.InstructionApplyActions,
+		OFPIT_CLEAR_ACTIONS:  openflow.InstructionClearActions,
+		OFPIT_METER:          openflow.InstructionMeter,
+	}
+	actionTypes = map[uint16]openflow.ActionType{
+		OFPAT_OUTPUT:       openflow.ActionOutput,
+		OFPAT_COPY_TTL_OUT: openflow.ActionCopyTTLOut,
+		OFPAT_COPY_TTL_IN:  openflow.ActionCopyTTLIn,
+		OFPAT_SET_MPLS_TTL: openflow.ActionSetMPLSTTL,
+		OFPAT_DEC_MPLS_TTL: openflow.ActionDecMPLSTTL,
+		OFPAT_PUSH_VLAN:    openflow.ActionPushVLAN,
+		OFPAT_POP_VLAN:     openflow.ActionPopVLAN,
+		OFPAT_PUSH_MPLS:    openflow.ActionPushMPLS,
+		OFPAT_POP_MPLS:     openflow.ActionPopMPLS,
+		OFPAT_SET_QUEUE:    openflow.ActionSetQueue,
+		OFPAT_GROUP:        openflow.ActionGroup,
+		OFPAT_SET_NW_TTL:   openflow.ActionSetNwTTL,
+		OFPAT_DEC_NW_TTL:   openflow.ActionDecNwTTL,
+		OFPAT_SET_FIELD:    openflow.ActionSetField,
+		OFPAT_PUSH_PBB:     openflow.ActionPushPBB,
+		OFPAT_POP_PBB:      openflow.ActionPopPBB,
+	}
+)
+
+type TableFeatures struct {
+	length         uint16
+	tableID        uint8
+	name           string
+	metadataMatch  uint64
+	metadataWrite  uint64
+	maxEntries     uint32
+	instructions   []openflow.InstructionType
+	nextTables     []uint8
+	writeActions   []openflow.ActionType
+	applyActions   []openflow.ActionType
+	matchFields    []openflow.MatchField
+	wildcards      []openflow.MatchField
+	writeSetFields []openflow.MatchField
+	applySetFields []openflow.MatchField
+}
+
+func (r *TableFeatures) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *TableFeatures) Name() string {
+	return r.name
+}
+
+func (r *TableFeatures) MaxEntries() uint32 {
+	return r.maxEntries
+}
+
+func (r *TableFeatures) MetadataMatch() uint64 {
+	return r.metadataMatch
+}
+
+func (r *TableFeatures) MetadataWrite() uint64 {
+	return r.metadataWrite
+}
+
+func (r *TableFeatures) Instructions() []openflow.InstructionType {
+	return r.instructions
+}
+
+func (r *TableFeatures) NextTables() []uint8 {
+	return r.nextTables
+}
+
+func (r *TableFeatures) WriteActions() []openflow.ActionType {
+	return r.writeActions
+}
+
+func (r *TableFeatures) ApplyActions() []openflow.ActionType {
+	return r.applyActions
+}
+
+func (r *TableFeatures) MatchFields() []openflow.MatchField {
+	return r.matchFields
+}
+
+func (r *TableFeatures) Wildcards() []openflow.MatchField {
+	return r.wildcards
+}
+
+func (r *TableFeatures) WriteSetFields() []openflow.MatchField {
+	return r.writeSetFields
+}
+
+func (r *TableFeatures) ApplySetFields() []openflow.MatchField {
+	return r.applySetFields
+}
+
+// parseInstructionIDs decodes a list of ofp_instruction headers. Unknown
+// instruction types, such as experimenter ones, are skipped.
+func parseInstructionIDs(data []byte) []openflow.InstructionType {
+	result := make([]openflow.InstructionType, 0)
+	for i := 0; i+4 <= len(data); {
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 4 {
+			break
+		}
+		if v, ok := instructionTypes[binary.BigEndian.Uint16(data[i:i+2])]; ok {
+			result = append(result, v)
+		}
+		i += length
+	}
+
+	return result
+}
+
+// parseActionIDs decodes a list of ofp_action headers. Unknown action types,
+// such as experimenter ones, are skipped.
+func parseActionIDs(data []byte) []openflow.ActionType {
+	result := make([]openflow.ActionType, 0)
+	for i := 0; i+4 <= len(data); {
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 4 {
+			break
+		}
+		if v, ok := actionTypes[binary.BigEndian.Uint16(data[i:i+2])]; ok {
+			result = append(result, v)
+		}
+		i += length
+	}
+
+	return result
+}
+
+// parseOXMIDs decodes a list of OXM headers. OXM fields whose class is not the
+// OpenFlow basic class are skipped.
+func parseOXMIDs(data []byte) []openflow.MatchField {
+	result := make([]openflow.MatchField, 0)
+	for i := 0; i+4 <= len(data); {
+		header := binary.BigEndian.Uint32(data[i : i+4])
+		class := header >> 16 & 0xFFFF
+		field := header >> 9 & 0x7F
+		if class == 0x8000 && field <= OFPXMT_OFB_IPV6_EXTHDR {
+			result = append(result, openflow.MatchField(field))
+		}
+		// Experimenter OXM header has an additional experimenter ID.
+		if class == 0xFFFF {
+			i += 4
+		}
+		i += 4
+	}
+
+	return result
+}
+
+func (r *TableFeatures) unmarshalProperty(t uint16, data []byte) {
+	switch t {
+	case OFPTFPT_INSTRUCTIONS:
+		r.instructions = parseInstructionIDs(data)
+	case OFPTFPT_NEXT_TABLES:
+		r.nextTables = make([]uint8, len(data))
+		copy(r.nextTables, data)
+	case OFPTFPT_WRITE_ACTIONS:
+		r.writeActions = parseActionIDs(data)
+	case OFPTFPT_APPLY_ACTIONS:
+		r.applyActions = parseActionIDs(data)
+	case OFPTFPT_MATCH:
+		r.matchFields = parseOXMIDs(data)
+	case OFPTFPT_WILDCARDS:
+		r.wildcards = parseOXMIDs(data)
+	case OFPTFPT_WRITE_SETFIELD:
+		r.writeSetFields = parseOXMIDs(data)
+	case OFPTFPT_APPLY_SETFIELD:
+		r.applySetFields = parseOXMIDs(data)
+	default:
+		// Do nothing for the table-miss and experimenter properties
+	}
+}
+
+func (r *TableFeatures) UnmarshalBinary(data []byte) error {
+	if len(data) < 64 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 64 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.tableID = data[2]
+	// data[3:8] is padding
+	r.name = strings.TrimRight(string(data[8:40]), "\x00")
+	r.metadataMatch = binary.BigEndian.Uint64(data[40:48])
+	r.metadataWrite = binary.BigEndian.Uint64(data[48:56])
+	// data[56:60] is config that is deprecated
+	r.maxEntries = binary.BigEndian.Uint32(data[60:64])
+
+	buf := data[64:r.length]
+	for len(buf) >= 4 {
+		t := binary.BigEndian.Uint16(buf[0:2])
+		length := int(binary.BigEndian.Uint16(buf[2:4]))
+		if length < 4 || len(buf) < length {
+			return openflow.ErrInvalidPacketLength
+		}
+		r.unmarshalProperty(t, buf[4:length])
+
+		// The property length does not include the padding that makes it 64-bit aligned.
+		padded := (length + 7) / 8 * 8
+		if padded > len(buf) {
+			break
+		}
+		buf = buf[padded:]
+	}
+
+	return nil
+}
+
+type TableFeaturesReply struct {
+	openflow.Message
+	flags    uint16
+	features []openflow.TableFeatures
+}
+
+func (r *TableFeaturesReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *TableFeaturesReply) Features() []openflow.TableFeatures {
+	return r.features
+}
+
+func (r *TableFeaturesReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.features = make([]openflow.TableFeatures, 0)
+	for i := 8; i < len(payload); {
+		v := new(TableFeatures)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.features = append(r.features, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
@@ -35,6 +35,7 @@ type PacketIn struct {
 	tableID  uint8
 	reason   uint8
 	cookie   uint64
+	match    openflow.Match
 	data     []byte
 }
 
@@ -66,6 +67,10 @@ func (r PacketIn) Cookie() uint64 {
 	return r.cookie
 }
 
+func (r PacketIn) Match() openflow.Match {
+	return r.match
+}
+
 func (r *PacketIn) UnmarshalBinary(data []byte) error {
 	if err := r.Message.UnmarshalBinary(data); err != nil {
 		return err
@@ -87,6 +92,7 @@ func (r *PacketIn) UnmarshalBinary(data []byte) error {
 	}
 	_, inport := match.InPort()
 	r.inPort = inport.Value()
+	r.match = match
 
 	matchLength := binary.BigEndian.Uint16(payload[18:20])
 	// Calculate padding length
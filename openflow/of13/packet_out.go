@@ -30,14 +30,16 @@ import (
 type PacketOut struct {
 	err error
 	openflow.Message
-	inPort openflow.InPort
-	action openflow.Action
-	data   []byte
+	bufferID uint32
+	inPort   openflow.InPort
+	action   openflow.Action
+	data     []byte
 }
 
 func NewPacketOut(xid uint32) openflow.PacketOut {
 	return &PacketOut{
-		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_PACKET_OUT, xid),
+		Message:  openflow.NewMessage(openflow.OF13_VERSION, OFPT_PACKET_OUT, xid),
+		bufferID: OFP_NO_BUFFER,
 	}
 }
 
@@ -45,6 +47,14 @@ func (r *PacketOut) Error() error {
 	return r.err
 }
 
+func (r *PacketOut) BufferID() uint32 {
+	return r.bufferID
+}
+
+func (r *PacketOut) SetBufferID(id uint32) {
+	r.bufferID = id
+}
+
 func (r *PacketOut) InPort() openflow.InPort {
 	return r.inPort
 }
@@ -90,7 +100,7 @@ func (r *PacketOut) MarshalBinary() ([]byte, error) {
 	}
 
 	v := make([]byte, 16)
-	binary.BigEndian.PutUint32(v[0:4], OFP_NO_BUFFER)
+	binary.BigEndian.PutUint32(v[0:4], r.bufferID)
 	port := r.inPort.Value()
 	if r.inPort.IsController() {
 		port = OFPP_CONTROLLER
@@ -99,10 +109,44 @@ func (r *PacketOut) MarshalBinary() ([]byte, error) {
 	binary.BigEndian.PutUint16(v[8:10], uint16(len(action)))
 	// v[10:16] is padding
 	v = append(v, action...)
-	if r.data != nil && len(r.data) > 0 {
+	// The data is meaningful only if the packet is not buffered.
+	if r.bufferID == OFP_NO_BUFFER && len(r.data) > 0 {
 		v = append(v, r.data...)
 	}
 
 	r.SetPayload(v)
 	return r.Message.MarshalBinary()
 }
+
+func (r *PacketOut) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 16 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.bufferID = binary.BigEndian.Uint32(payload[0:4])
+	r.inPort = openflow.NewInPort()
+	if port := binary.BigEndian.Uint32(payload[4:8]); port != OFPP_CONTROLLER {
+		r.inPort.SetValue(port)
+	}
+	length := int(binary.BigEndian.Uint16(payload[8:10]))
+	// payload[10:16] is padding
+	if 16+length > len(payload) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	r.action = nil
+	if length > 0 {
+		action := NewAction()
+		if err := action.UnmarshalBinary(payload[16 : 16+length]); err != nil {
+			return err
+		}
+		r.action = action
+	}
+	r.data = payload[16+length:]
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of13
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func getControllerRole(role openflow.ControllerRole) uint32 {
+	switch role {
+	case openflow.RoleNoChange:
+		return OFPCR_ROLE_NOCHANGE
+	case openflow.RoleEqual:
+		return OFPCR_ROLE_EQUAL
+	case openflow.RoleMaster:
+		return OFPCR_ROLE_MASTER
+	case openflow.RoleSlave:
+		return OFPCR_ROLE_SLAVE
+	default:
+		panic(fmt.Sprintf("unexpected controller role: %v", role))
+	}
+}
+
+func parseControllerRole(role uint32) (openflow.ControllerRole, error) {
+	switch role {
+	case OFPCR_ROLE_NOCHANGE:
+		return openflow.RoleNoChange, nil
+	case OFPCR_ROLE_EQUAL:
+		return openflow.RoleEqual, nil
+	case OFPCR_ROLE_MASTER:
+		return openflow.RoleMaster, nil
+	case OFPCR_ROLE_SLAVE:
+		return openflow.RoleSlave, nil
+	default:
+		return 0, fmt.Errorf("unexpected controller role: %v", role)
+	}
+}
+
+type role struct {
+	role         openflow.ControllerRole
+	generationID uint64
+}
+
+func (r *role) Role() openflow.ControllerRole {
+	return r.role
+}
+
+func (r *role) GenerationID() uint64 {
+	return r.generationID
+}
+
+func (r *role) marshal() []byte {
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint32(v[0:4], getControllerRole(r.role))
+	// v[4:8] is padding.
+	binary.BigEndian.PutUint64(v[8:16], r.generationID)
+
+	return v
+}
+
+func (r *role) unmarshal(data []byte) error {
+	if len(data) < 16 {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	v, err := parseControllerRole(binary.BigEndian.Uint32(data[0:4]))
+	if err != nil {
+		return err
+	}
+	r.role = v
+	r.generationID = binary.BigEndian.Uint64(data[8:16])
+
+	return nil
+}
+
+type RoleRequest struct {
+	openflow.Message
+	role
+}
+
+func NewRoleRequest(xid uint32) openflow.RoleRequest {
+	return &RoleRequest{
+		Message: openflow.NewMessage(openflow.OF13_VERSION, OFPT_ROLE_REQUEST, xid),
+		role: role{
+			role: openflow.RoleNoChange,
+		},
+	}
+}
+
+func (r *RoleRequest) SetRole(role openflow.ControllerRole) {
+	r.role.role = role
+}
+
+func (r *RoleRequest) SetGenerationID(id uint64) {
+	r.generationID = id
+}
+
+func (r *RoleRequest) MarshalBinary() ([]byte, error) {
+	r.SetPayload(r.marshal())
+	return r.Message.MarshalBinary()
+}
+
+type RoleReply struct {
+	openflow.Message
+	role
+}
+
+func (r *RoleReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	return r.unmarshal(r.Payload())
+}
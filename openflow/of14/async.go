@@ -0,0 +1,181 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+var (
+	packetInReasons = map[openflow.PacketInReason]uint8{
+		openflow.PacketInNoMatch:    OFPR_TABLE_MISS,
+		openflow.PacketInAction:     OFPR_APPLY_ACTION,
+		openflow.PacketInInvalidTTL: OFPR_INVALID_TTL,
+	}
+	portStatusReasons = map[openflow.PortReason]uint8{
+		openflow.PortAdded:    OFPPR_ADD,
+		openflow.PortDeleted:  OFPPR_DELETE,
+		openflow.PortModified: OFPPR_MODIFY,
+	}
+	flowRemovedReasons = map[openflow.FlowRemovedReason]uint8{
+		openflow.FlowRemovedIdleTimeout: OFPRR_IDLE_TIMEOUT,
+		openflow.FlowRemovedHardTimeout: OFPRR_HARD_TIMEOUT,
+		openflow.FlowRemovedDelete:      OFPRR_DELETE,
+		openflow.FlowRemovedGroupDelete: OFPRR_GROUP_DELETE,
+	}
+)
+
+// AsyncConfig has the same semantics with the OpenFlow 1.3 one, but it is encoded
+// as a list of the OFPACPT_* properties.
+type AsyncConfig struct {
+	of13.AsyncConfig
+}
+
+func (r *AsyncConfig) marshal() []byte {
+	v := make([]byte, 0)
+	for _, role := range []openflow.ControllerRole{openflow.RoleSlave, openflow.RoleMaster} {
+		var packetIn, portStatus, flowRemoved uint32
+		for _, reason := range r.PacketInReasons(role) {
+			packetIn |= 1 << packetInReasons[reason]
+		}
+		for _, reason := range r.PortStatusReasons(role) {
+			portStatus |= 1 << portStatusReasons[reason]
+		}
+		for _, reason := range r.FlowRemovedReasons(role) {
+			flowRemoved |= 1 << flowRemovedReasons[reason]
+		}
+
+		// The master property type is the slave one + 1.
+		var offset uint16
+		if role == openflow.RoleMaster {
+			offset = 1
+		}
+		v = append(v, marshalAsyncProperty(OFPACPT_PACKET_IN_SLAVE+offset, packetIn)...)
+		v = append(v, marshalAsyncProperty(OFPACPT_PORT_STATUS_SLAVE+offset, portStatus)...)
+		v = append(v, marshalAsyncProperty(OFPACPT_FLOW_REMOVED_SLAVE+offset, flowRemoved)...)
+	}
+
+	return v
+}
+
+func marshalAsyncProperty(propType uint16, mask uint32) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], propType)
+	binary.BigEndian.PutUint16(v[2:4], 8)
+	binary.BigEndian.PutUint32(v[4:8], mask)
+
+	return v
+}
+
+func (r *AsyncConfig) unmarshal(data []byte) error {
+	return parseProperties(data, func(propType uint16, prop []byte) error {
+		if len(prop) < 8 {
+			return openflow.ErrInvalidPacketLength
+		}
+		mask := binary.BigEndian.Uint32(prop[4:8])
+
+		role := openflow.RoleSlave
+		if propType%2 == 1 {
+			role = openflow.RoleMaster
+		}
+		switch propType {
+		case OFPACPT_PACKET_IN_SLAVE, OFPACPT_PACKET_IN_MASTER:
+			reasons := make([]openflow.PacketInReason, 0)
+			for reason, bit := range packetInReasons {
+				if mask&(1<<bit) != 0 {
+					reasons = append(reasons, reason)
+				}
+			}
+			r.SetPacketInReasons(role, reasons...)
+		case OFPACPT_PORT_STATUS_SLAVE, OFPACPT_PORT_STATUS_MASTER:
+			reasons := make([]openflow.PortReason, 0)
+			for reason, bit := range portStatusReasons {
+				if mask&(1<<bit) != 0 {
+					reasons = append(reasons, reason)
+				}
+			}
+			r.SetPortStatusReasons(role, reasons...)
+		case OFPACPT_FLOW_REMOVED_SLAVE, OFPACPT_FLOW_REMOVED_MASTER:
+			reasons := make([]openflow.FlowRemovedReason, 0)
+			for reason, bit := range flowRemovedReasons {
+				if mask&(1<<bit) != 0 {
+					reasons = append(reasons, reason)
+				}
+			}
+			r.SetFlowRemovedReasons(role, reasons...)
+		default:
+			// Ignore the properties of the messages that we do not use.
+		}
+
+		return nil
+	})
+}
+
+type SetAsync struct {
+	openflow.Message
+	AsyncConfig
+}
+
+func NewSetAsync(xid uint32) openflow.SetAsync {
+	return &SetAsync{
+		Message: openflow.NewMessage(openflow.OF14_VERSION, OFPT_SET_ASYNC, xid),
+	}
+}
+
+func (r *SetAsync) MarshalBinary() ([]byte, error) {
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+
+	r.SetPayload(r.marshal())
+	return r.Message.MarshalBinary()
+}
+
+type GetAsyncRequest struct {
+	openflow.Message
+}
+
+func NewGetAsyncRequest(xid uint32) openflow.GetAsyncRequest {
+	return &GetAsyncRequest{
+		Message: openflow.NewMessage(openflow.OF14_VERSION, OFPT_GET_ASYNC_REQUEST, xid),
+	}
+}
+
+func (r *GetAsyncRequest) MarshalBinary() ([]byte, error) {
+	return r.Message.MarshalBinary()
+}
+
+type GetAsyncReply struct {
+	openflow.Message
+	AsyncConfig
+}
+
+func (r *GetAsyncReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	return r.unmarshal(r.Payload())
+}
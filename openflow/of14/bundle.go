@@ -0,0 +1,232 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+var (
+	bundleCtrlRequests = map[openflow.BundleCtrlType]uint16{
+		openflow.BundleOpen:    OFPBCT_OPEN_REQUEST,
+		openflow.BundleClose:   OFPBCT_CLOSE_REQUEST,
+		openflow.BundleCommit:  OFPBCT_COMMIT_REQUEST,
+		openflow.BundleDiscard: OFPBCT_DISCARD_REQUEST,
+	}
+	bundleCtrlReplies = map[openflow.BundleCtrlType]uint16{
+		openflow.BundleOpen:    OFPBCT_OPEN_REPLY,
+		openflow.BundleClose:   OFPBCT_CLOSE_REPLY,
+		openflow.BundleCommit:  OFPBCT_COMMIT_REPLY,
+		openflow.BundleDiscard: OFPBCT_DISCARD_REPLY,
+	}
+)
+
+func getBundleFlags(flags openflow.BundleFlag) uint16 {
+	var v uint16
+	if flags&openflow.BundleAtomic != 0 {
+		v |= OFPBF_ATOMIC
+	}
+	if flags&openflow.BundleOrdered != 0 {
+		v |= OFPBF_ORDERED
+	}
+
+	return v
+}
+
+func toBundleFlags(flags uint16) openflow.BundleFlag {
+	var v openflow.BundleFlag
+	if flags&OFPBF_ATOMIC != 0 {
+		v |= openflow.BundleAtomic
+	}
+	if flags&OFPBF_ORDERED != 0 {
+		v |= openflow.BundleOrdered
+	}
+
+	return v
+}
+
+type BundleControl struct {
+	openflow.Message
+	bundleID uint32
+	ctrlType uint16
+	flags    uint16
+}
+
+func NewBundleControl(xid uint32) openflow.BundleControl {
+	return &BundleControl{
+		Message:  openflow.NewMessage(openflow.OF14_VERSION, OFPT_BUNDLE_CONTROL, xid),
+		ctrlType: OFPBCT_OPEN_REQUEST,
+	}
+}
+
+func (r *BundleControl) BundleID() uint32 {
+	return r.bundleID
+}
+
+func (r *BundleControl) SetBundleID(id uint32) {
+	r.bundleID = id
+}
+
+func (r *BundleControl) ControlType() openflow.BundleCtrlType {
+	for t, v := range bundleCtrlRequests {
+		if v == r.ctrlType {
+			return t
+		}
+	}
+	for t, v := range bundleCtrlReplies {
+		if v == r.ctrlType {
+			return t
+		}
+	}
+
+	return openflow.BundleCtrlType(r.ctrlType)
+}
+
+func (r *BundleControl) SetControlType(t openflow.BundleCtrlType) {
+	v, ok := bundleCtrlRequests[t]
+	if !ok {
+		panic(fmt.Sprintf("unexpected bundle control type: %v", t))
+	}
+	r.ctrlType = v
+}
+
+func (r *BundleControl) Flags() openflow.BundleFlag {
+	return toBundleFlags(r.flags)
+}
+
+func (r *BundleControl) SetFlags(flags openflow.BundleFlag) {
+	r.flags = getBundleFlags(flags)
+}
+
+func (r *BundleControl) IsReply() bool {
+	// Reply types are odd numbers.
+	return r.ctrlType%2 == 1
+}
+
+func (r *BundleControl) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint32(v[0:4], r.bundleID)
+	binary.BigEndian.PutUint16(v[4:6], r.ctrlType)
+	binary.BigEndian.PutUint16(v[6:8], r.flags)
+	// No properties
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+func (r *BundleControl) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.bundleID = binary.BigEndian.Uint32(payload[0:4])
+	r.ctrlType = binary.BigEndian.Uint16(payload[4:6])
+	r.flags = binary.BigEndian.Uint16(payload[6:8])
+	// Ignore the properties.
+
+	return nil
+}
+
+type BundleAdd struct {
+	openflow.Message
+	err      error
+	bundleID uint32
+	flags    uint16
+	msg      openflow.BundleMessage
+}
+
+func NewBundleAdd(xid uint32) openflow.BundleAdd {
+	return &BundleAdd{
+		Message: openflow.NewMessage(openflow.OF14_VERSION, OFPT_BUNDLE_ADD_MESSAGE, xid),
+	}
+}
+
+func (r *BundleAdd) Error() error {
+	return r.err
+}
+
+func (r *BundleAdd) BundleID() uint32 {
+	return r.bundleID
+}
+
+func (r *BundleAdd) SetBundleID(id uint32) {
+	r.bundleID = id
+}
+
+func (r *BundleAdd) Flags() openflow.BundleFlag {
+	return toBundleFlags(r.flags)
+}
+
+func (r *BundleAdd) SetFlags(flags openflow.BundleFlag) {
+	r.flags = getBundleFlags(flags)
+}
+
+func (r *BundleAdd) BundledMessage() openflow.BundleMessage {
+	return r.msg
+}
+
+func (r *BundleAdd) SetBundledMessage(msg openflow.BundleMessage) {
+	if msg == nil {
+		r.err = errors.New("SetBundledMessage: nil message")
+		return
+	}
+	if msg.Version() != openflow.OF14_VERSION {
+		r.err = fmt.Errorf("SetBundledMessage: mis-matched OpenFlow version: %v", msg.Version())
+		return
+	}
+	r.msg = msg
+}
+
+func (r *BundleAdd) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.msg == nil {
+		return nil, errors.New("empty bundle message")
+	}
+
+	// Use the same transaction ID with the message so that the errors caused by
+	// the message can be matched with this BundleAdd.
+	r.msg.SetTransactionID(r.TransactionID())
+	msg, err := r.msg.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint32(v[0:4], r.bundleID)
+	// v[4:6] is padding
+	binary.BigEndian.PutUint16(v[6:8], r.flags)
+	// No properties, so that the message does not need the padding.
+	v = append(v, msg...)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
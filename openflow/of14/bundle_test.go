@@ -0,0 +1,120 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func TestBundleControl(t *testing.T) {
+	req := NewBundleControl(3)
+	req.SetBundleID(7)
+	req.SetControlType(openflow.BundleDiscard)
+	req.SetFlags(openflow.BundleOrdered)
+	if req.IsReply() {
+		t.Fatal("unexpected reply")
+	}
+	packet, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packet) != 16 || packet[0] != openflow.OF14_VERSION || packet[1] != OFPT_BUNDLE_CONTROL {
+		t.Fatalf("unexpected message: length=%v, version=%v, type=%v", len(packet), packet[0], packet[1])
+	}
+	if v := binary.BigEndian.Uint32(packet[8:12]); v != 7 {
+		t.Fatalf("unexpected bundle ID: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[12:14]); v != OFPBCT_DISCARD_REQUEST {
+		t.Fatalf("unexpected control type: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[14:16]); v != OFPBF_ORDERED {
+		t.Fatalf("unexpected flags: %v", v)
+	}
+
+	// Decode the reply to the request.
+	binary.BigEndian.PutUint16(packet[12:14], OFPBCT_DISCARD_REPLY)
+	reply := new(BundleControl)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.IsReply() || reply.ControlType() != openflow.BundleDiscard {
+		t.Fatalf("unexpected reply: isReply=%v, type=%v", reply.IsReply(), reply.ControlType())
+	}
+	if reply.TransactionID() != 3 || reply.BundleID() != 7 || reply.Flags() != openflow.BundleOrdered {
+		t.Fatalf("unexpected reply: xid=%v, id=%v, flags=%v", reply.TransactionID(), reply.BundleID(), reply.Flags())
+	}
+
+	if err := new(BundleControl).UnmarshalBinary(packet[:12]); err == nil {
+		t.Fatal("expected an error for the truncated message")
+	}
+}
+
+func TestBundleAdd(t *testing.T) {
+	f := NewFactory()
+	flow, err := f.NewFlowMod(openflow.FlowAdd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, err := f.NewMatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow.SetFlowMatch(match)
+
+	add := NewBundleAdd(9)
+	add.SetBundleID(7)
+	add.SetFlags(openflow.BundleAtomic | openflow.BundleOrdered)
+	add.SetBundledMessage(flow)
+	packet, err := add.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet[0] != openflow.OF14_VERSION || packet[1] != OFPT_BUNDLE_ADD_MESSAGE {
+		t.Fatalf("unexpected message: version=%v, type=%v", packet[0], packet[1])
+	}
+	if v := binary.BigEndian.Uint32(packet[8:12]); v != 7 {
+		t.Fatalf("unexpected bundle ID: %v", v)
+	}
+	if v := binary.BigEndian.Uint16(packet[14:16]); v != OFPBF_ATOMIC|OFPBF_ORDERED {
+		t.Fatalf("unexpected flags: %v", v)
+	}
+
+	// The bundled message has the same transaction ID with the BundleAdd.
+	msg, err := flow.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow.TransactionID() != 9 || msg[0] != openflow.OF14_VERSION || !bytes.Equal(packet[16:], msg) {
+		t.Fatalf("unexpected bundled message: xid=%v, message=%v", flow.TransactionID(), packet[16:])
+	}
+
+	invalid := NewBundleAdd(10)
+	other := openflow.NewMessage(openflow.OF13_VERSION, OFPT_FLOW_MOD, 0)
+	invalid.SetBundledMessage(&other)
+	if invalid.Error() == nil {
+		t.Fatal("expected an error for the mis-matched version")
+	}
+}
@@ -0,0 +1,407 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+const (
+	/* Immutable messages. */
+	OFPT_HELLO        uint8 = iota /* Symmetric message */
+	OFPT_ERROR                     /* Symmetric message */
+	OFPT_ECHO_REQUEST              /* Symmetric message */
+	OFPT_ECHO_REPLY                /* Symmetric message */
+	OFPT_EXPERIMENTER              /* Symmetric message */
+	/* Switch configuration messages. */
+	OFPT_FEATURES_REQUEST   /* Controller/switch message */
+	OFPT_FEATURES_REPLY     /* Controller/switch message */
+	OFPT_GET_CONFIG_REQUEST /* Controller/switch message */
+	OFPT_GET_CONFIG_REPLY   /* Controller/switch message */
+	OFPT_SET_CONFIG         /* Controller/switch message */
+	/* Asynchronous messages. */
+	OFPT_PACKET_IN    /* Async message */
+	OFPT_FLOW_REMOVED /* Async message */
+	OFPT_PORT_STATUS  /* Async message */
+	/* Controller command messages. */
+	OFPT_PACKET_OUT /* Controller/switch message */
+	OFPT_FLOW_MOD   /* Controller/switch message */
+	OFPT_GROUP_MOD  /* Controller/switch message */
+	OFPT_PORT_MOD   /* Controller/switch message */
+	OFPT_TABLE_MOD  /* Controller/switch message */
+	/* Multipart messages. */
+	OFPT_MULTIPART_REQUEST /* Controller/switch message */
+	OFPT_MULTIPART_REPLY   /* Controller/switch message */
+	/* Barrier messages. */
+	OFPT_BARRIER_REQUEST /* Controller/switch message */
+	OFPT_BARRIER_REPLY   /* Controller/switch message */
+	/* Unused since OpenFlow 1.4: use OFPMP_QUEUE_DESC instead. */
+	_
+	_
+	/* Controller role change request messages. */
+	OFPT_ROLE_REQUEST /* Controller/switch message */
+	OFPT_ROLE_REPLY   /* Controller/switch message */
+	/* Asynchronous message configuration. */
+	OFPT_GET_ASYNC_REQUEST /* Controller/switch message */
+	OFPT_GET_ASYNC_REPLY   /* Controller/switch message */
+	OFPT_SET_ASYNC         /* Controller/switch message */
+	/* Meters and rate limiters configuration messages. */
+	OFPT_METER_MOD /* Controller/switch message */
+	/* Controller role change event messages. */
+	OFPT_ROLE_STATUS /* Async message */
+	/* Asynchronous messages. */
+	OFPT_TABLE_STATUS /* Async message */
+	/* Request forwarding by the switch. */
+	OFPT_REQUESTFORWARD /* Async message */
+	/* Bundle operations (multiple messages as a single operation). */
+	OFPT_BUNDLE_CONTROL     /* Controller/switch message */
+	OFPT_BUNDLE_ADD_MESSAGE /* Controller/switch message */
+)
+
+const (
+	OFPHET_VERSIONBITMAP = 1 /* Bitmap of version supported. */
+)
+
+const (
+	/* Maximum number of physical and logical switch ports. */
+	OFPP_MAX = 0xffffff00
+	/* Reserved OpenFlow Port (fake output "ports"). */
+	OFPP_IN_PORT = 0xfffffff8
+	OFPP_TABLE   = 0xfffffff9
+	OFPP_NORMAL  = 0xfffffffa /* Process with normal L2/L3 switching. */
+	/* All physical ports in VLAN, except input port and those blocked or link down. */
+	OFPP_FLOOD      = 0xfffffffb
+	OFPP_ALL        = 0xfffffffc /* All physical ports except input port. */
+	OFPP_CONTROLLER = 0xfffffffd /* Send to controller. */
+	OFPP_LOCAL      = 0xfffffffe /* Local openflow "port". */
+	OFPP_ANY        = 0xffffffff /* Wildcard */
+)
+
+const (
+	OFPG_ANY = 0xffffffff /* Wildcard group used only for flow stats requests. */
+)
+
+const (
+	/* Last usable table number. */
+	OFPTT_MAX = 0xfe
+	/* Wildcard table used for table config, flow stats and flow deletes. */
+	OFPTT_ALL = 0xff
+)
+
+const (
+	OFPQ_ALL = 0xffffffff /* All ones is used to indicate all queues in a port (for stats retrieval). */
+)
+
+const (
+	OFPFC_ADD           = 0 /* New flow. */
+	OFPFC_MODIFY        = 1 /* Modify all matching flows. */
+	OFPFC_MODIFY_STRICT = 2 /* Modify entry strictly matching wildcards and priority. */
+	OFPFC_DELETE        = 3 /* Delete all matching flows. */
+	OFPFC_DELETE_STRICT = 4 /* Delete entry strictly matching wildcards and priority */
+)
+
+const (
+	OFPGC_ADD    = 0 /* New group. */
+	OFPGC_MODIFY = 1 /* Modify all matching groups. */
+	OFPGC_DELETE = 2 /* Delete all matching groups. */
+)
+
+const (
+	OFPMC_ADD    = 0 /* New meter. */
+	OFPMC_MODIFY = 1 /* Modify specified meter. */
+	OFPMC_DELETE = 2 /* Delete specified meter. */
+)
+
+const (
+	OFPPC_PORT_DOWN    = 1 << 0 /* Port is administratively down. */
+	OFPPC_NO_RECV      = 1 << 2
+	OFPPC_NO_FWD       = 1 << 5
+	OFPPC_NO_PACKET_IN = 1 << 6
+)
+
+const (
+	OFPPS_LINK_DOWN = 1 << 0 /* No physical link present. */
+	OFPPS_BLOCKED   = 1 << 1
+	OFPPS_LIVE      = 1 << 2
+)
+
+const (
+	OFPPF_10MB_HD    = 1 << 0
+	OFPPF_10MB_FD    = 1 << 1
+	OFPPF_100MB_HD   = 1 << 2
+	OFPPF_100MB_FD   = 1 << 3
+	OFPPF_1GB_HD     = 1 << 4
+	OFPPF_1GB_FD     = 1 << 5
+	OFPPF_10GB_FD    = 1 << 6
+	OFPPF_40GB_FD    = 1 << 7
+	OFPPF_100GB_FD   = 1 << 8
+	OFPPF_1TB_FD     = 1 << 9
+	OFPPF_OTHER      = 1 << 10
+	OFPPF_COPPER     = 1 << 11
+	OFPPF_FIBER      = 1 << 12
+	OFPPF_AUTONEG    = 1 << 13
+	OFPPF_PAUSE      = 1 << 14
+	OFPPF_PAUSE_ASYM = 1 << 15
+)
+
+const (
+	OFPPR_ADD    = 0
+	OFPPR_DELETE = 1
+	OFPPR_MODIFY = 2
+)
+
+const (
+	OFPPDPT_ETHERNET     = 0      /* Ethernet property. */
+	OFPPDPT_OPTICAL      = 1      /* Optical property. */
+	OFPPDPT_EXPERIMENTER = 0xFFFF /* Experimenter property. */
+)
+
+const (
+	OFPPSPT_ETHERNET     = 0      /* Ethernet property. */
+	OFPPSPT_OPTICAL      = 1      /* Optical property. */
+	OFPPSPT_EXPERIMENTER = 0xFFFF /* Experimenter property. */
+)
+
+const (
+	OFPR_TABLE_MISS   = 0 /* No matching flow (table-miss flow entry). */
+	OFPR_APPLY_ACTION = 1 /* Output to controller in apply-actions. */
+	OFPR_INVALID_TTL  = 2 /* Packet has invalid TTL */
+	OFPR_ACTION_SET   = 3 /* Output to controller in action set. */
+	OFPR_GROUP        = 4 /* Output to controller in group bucket. */
+	OFPR_PACKET_OUT   = 5 /* Output to controller in packet-out. */
+)
+
+const (
+	OFPRR_IDLE_TIMEOUT = 0 /* Flow idle time exceeded idle_timeout. */
+	OFPRR_HARD_TIMEOUT = 1 /* Time exceeded hard_timeout. */
+	OFPRR_DELETE       = 2 /* Evicted by a DELETE flow mod. */
+	OFPRR_GROUP_DELETE = 3 /* Group was removed. */
+	OFPRR_METER_DELETE = 4 /* Meter was removed. */
+	OFPRR_EVICTION     = 5 /* Switch eviction to free resources. */
+)
+
+const (
+	OFPACPT_PACKET_IN_SLAVE       = 0 /* Packet-in mask for slave. */
+	OFPACPT_PACKET_IN_MASTER      = 1 /* Packet-in mask for master. */
+	OFPACPT_PORT_STATUS_SLAVE     = 2 /* Port-status mask for slave. */
+	OFPACPT_PORT_STATUS_MASTER    = 3 /* Port-status mask for master. */
+	OFPACPT_FLOW_REMOVED_SLAVE    = 4 /* Flow removed mask for slave. */
+	OFPACPT_FLOW_REMOVED_MASTER   = 5 /* Flow removed mask for master. */
+	OFPACPT_ROLE_STATUS_SLAVE     = 6 /* Role status mask for slave. */
+	OFPACPT_ROLE_STATUS_MASTER    = 7 /* Role status mask for master. */
+	OFPACPT_TABLE_STATUS_SLAVE    = 8 /* Table status mask for slave. */
+	OFPACPT_TABLE_STATUS_MASTER   = 9 /* Table status mask for master. */
+	OFPACPT_REQUESTFORWARD_SLAVE  = 10
+	OFPACPT_REQUESTFORWARD_MASTER = 11
+)
+
+const (
+	/* Description of this OpenFlow switch.
+	 * The request body is empty.
+	 * The reply body is struct ofp_desc. */
+	OFPMP_DESC = 0
+	/* Individual flow statistics.
+	 * The request body is struct ofp_flow_stats_request.
+	 * The reply body is an array of struct ofp_flow_stats. */
+	OFPMP_FLOW = 1
+	/* Aggregate flow statistics.
+	 * The request body is struct ofp_aggregate_stats_request.
+	 * The reply body is struct ofp_aggregate_stats_reply. */
+	OFPMP_AGGREGATE = 2
+	/* Flow table statistics.
+	 * The request body is empty.
+	 * The reply body is an array of struct ofp_table_stats. */
+	OFPMP_TABLE = 3
+	/* Port statistics.
+	 * The request body is struct ofp_port_stats_request.
+	 * The reply body is an array of struct ofp_port_stats. */
+	OFPMP_PORT_STATS = 4
+	/* Queue statistics for a port
+	 * The request body is struct ofp_queue_stats_request.
+	 * The reply body is an array of struct ofp_queue_stats */
+	OFPMP_QUEUE_STATS = 5
+	/* Group counter statistics.
+	 * The request body is struct ofp_group_stats_request.
+	 * The reply is an array of struct ofp_group_stats. */
+	OFPMP_GROUP = 6
+	/* Group description.
+	 * The request body is empty.
+	 * The reply body is an array of struct ofp_group_desc. */
+	OFPMP_GROUP_DESC = 7
+	/* Group features.
+	 * The request body is empty.
+	 * The reply body is struct ofp_group_features. */
+	OFPMP_GROUP_FEATURES = 8
+	/* Meter statistics.
+	 * The request body is struct ofp_meter_multipart_requests.
+	 * The reply body is an array of struct ofp_meter_stats. */
+	OFPMP_METER = 9
+	/* Meter configuration.
+	 * The request body is struct ofp_meter_multipart_requests.
+	 * The reply body is an array of struct ofp_meter_config. */
+	OFPMP_METER_CONFIG = 10
+	/* Meter features.
+	 * The request body is empty.
+	 * The reply body is struct ofp_meter_features. */
+	OFPMP_METER_FEATURES = 11
+	/* Table features.
+	 * The request body is either empty or contains an array of
+	 * struct ofp_table_features containing the controller's
+	 * desired view of the switch. If the switch is unable to
+	 * set the specified view an error is returned.
+	 * The reply body is an array of struct ofp_table_features. */
+	OFPMP_TABLE_FEATURES = 12
+	/* Port description.
+	 * The request body is empty.
+	 * The reply body is an array of struct ofp_port. */
+	OFPMP_PORT_DESC = 13
+	/* Table description.
+	 * The request body is empty.
+	 * The reply body is an array of struct ofp_table_desc. */
+	OFPMP_TABLE_DESC = 14
+	/* Queue description.
+	 * The request body is struct ofp_queue_desc_request.
+	 * The reply body is an array of struct ofp_queue_desc. */
+	OFPMP_QUEUE_DESC = 15
+	/* Flow monitors. Reply may be an asynchronous message.
+	 * The request body is an array of struct ofp_flow_monitor_request.
+	 * The reply body is an array of struct ofp_flow_update_header. */
+	OFPMP_FLOW_MONITOR = 16
+	/* Experimenter extension.
+	 * The request and reply bodies begin with
+	 * struct ofp_experimenter_multipart_header.
+	 * The request and reply bodies are otherwise experimenter-defined. */
+	OFPMP_EXPERIMENTER = 0xffff
+)
+
+const (
+	OFPMPF_REQ_MORE   = 1 << 0 /* More requests to follow. */
+	OFPMPF_REPLY_MORE = 1 << 0 /* More replies to follow. */
+)
+
+const (
+	OFPFMF_INITIAL      = 1 << 0 /* Initially matching flows. */
+	OFPFMF_ADD          = 1 << 1 /* New matching flows as they are added. */
+	OFPFMF_REMOVED      = 1 << 2 /* Old matching flows as they are removed. */
+	OFPFMF_MODIFY       = 1 << 3 /* Matching flows as they are changed. */
+	OFPFMF_INSTRUCTIONS = 1 << 4 /* If set, instructions are included. */
+	OFPFMF_NO_ABBREV    = 1 << 5 /* If set, include own changes in full. */
+	OFPFMF_ONLY_OWN     = 1 << 6 /* If set, don't include other controllers. */
+)
+
+const (
+	OFPFMC_ADD    = 0 /* New flow monitor. */
+	OFPFMC_MODIFY = 1 /* Modify existing flow monitor. */
+	OFPFMC_DELETE = 2 /* Delete/cancel existing flow monitor. */
+)
+
+const (
+	OFPFME_INITIAL  = 0 /* Flow present when flow monitor created. */
+	OFPFME_ADDED    = 1 /* Flow was added. */
+	OFPFME_REMOVED  = 2 /* Flow was removed. */
+	OFPFME_MODIFIED = 3 /* Flow instructions were changed. */
+	OFPFME_ABBREV   = 4 /* Abbreviated reply. */
+	OFPFME_PAUSED   = 5 /* Monitoring paused (out of buffer space). */
+	OFPFME_RESUMED  = 6 /* Monitoring resumed. */
+)
+
+const (
+	OFPBCT_OPEN_REQUEST    = 0
+	OFPBCT_OPEN_REPLY      = 1
+	OFPBCT_CLOSE_REQUEST   = 2
+	OFPBCT_CLOSE_REPLY     = 3
+	OFPBCT_COMMIT_REQUEST  = 4
+	OFPBCT_COMMIT_REPLY    = 5
+	OFPBCT_DISCARD_REQUEST = 6
+	OFPBCT_DISCARD_REPLY   = 7
+)
+
+const (
+	OFPBF_ATOMIC  = 1 << 0 /* Execute atomically. */
+	OFPBF_ORDERED = 1 << 1 /* Execute in specified order. */
+)
+
+const (
+	OFPET_HELLO_FAILED          = 0      /* Hello protocol failed. */
+	OFPET_BAD_REQUEST           = 1      /* Request was not understood. */
+	OFPET_BAD_ACTION            = 2      /* Error in action description. */
+	OFPET_BAD_INSTRUCTION       = 3      /* Error in instruction list. */
+	OFPET_BAD_MATCH             = 4      /* Error in match. */
+	OFPET_FLOW_MOD_FAILED       = 5      /* Problem modifying flow entry. */
+	OFPET_GROUP_MOD_FAILED      = 6      /* Problem modifying group entry. */
+	OFPET_PORT_MOD_FAILED       = 7      /* Port mod request failed. */
+	OFPET_TABLE_MOD_FAILED      = 8      /* Table mod request failed. */
+	OFPET_QUEUE_OP_FAILED       = 9      /* Queue operation failed. */
+	OFPET_SWITCH_CONFIG_FAILED  = 10     /* Switch config request failed. */
+	OFPET_ROLE_REQUEST_FAILED   = 11     /* Controller Role request failed. */
+	OFPET_METER_MOD_FAILED      = 12     /* Error in meter. */
+	OFPET_TABLE_FEATURES_FAILED = 13     /* Setting table features failed. */
+	OFPET_BAD_PROPERTY          = 14     /* Some property is invalid. */
+	OFPET_ASYNC_CONFIG_FAILED   = 15     /* Asynchronous config request failed. */
+	OFPET_FLOW_MONITOR_FAILED   = 16     /* Setting flow monitor failed. */
+	OFPET_BUNDLE_FAILED         = 17     /* Bundle operation failed. */
+	OFPET_EXPERIMENTER          = 0xffff /* Experimenter error messages. */
+)
+
+const (
+	OFPBPC_BAD_TYPE         = 0 /* Unknown property type. */
+	OFPBPC_BAD_LEN          = 1 /* Length problem in property. */
+	OFPBPC_BAD_VALUE        = 2 /* Unsupported property value. */
+	OFPBPC_TOO_MANY         = 3 /* Can't handle this many properties. */
+	OFPBPC_DUP_TYPE         = 4 /* A property type was duplicated. */
+	OFPBPC_BAD_EXPERIMENTER = 5 /* Unknown experimenter id specified. */
+	OFPBPC_BAD_EXP_TYPE     = 6 /* Unknown exp_type for experimenter id. */
+	OFPBPC_BAD_EXP_VALUE    = 7 /* Unknown value for experimenter id. */
+	OFPBPC_EPERM            = 8 /* Permissions error. */
+)
+
+const (
+	OFPACFC_INVALID     = 0 /* One mask is invalid. */
+	OFPACFC_UNSUPPORTED = 1 /* Requested configuration not supported. */
+	OFPACFC_EPERM       = 2 /* Permissions error. */
+)
+
+const (
+	OFPMOFC_UNKNOWN         = 0 /* Unspecified error. */
+	OFPMOFC_MONITOR_EXISTS  = 1 /* Monitor not added because a Monitor ADD attempted to replace an existing Monitor. */
+	OFPMOFC_INVALID_MONITOR = 2 /* Monitor not added because Monitor specified is invalid. */
+	OFPMOFC_UNKNOWN_MONITOR = 3 /* Monitor not modified because a Monitor MODIFY attempted to modify a non-existent Monitor. */
+	OFPMOFC_BAD_COMMAND     = 4 /* Unsupported or unknown command. */
+	OFPMOFC_BAD_FLAGS       = 5 /* Flag configuration unsupported. */
+	OFPMOFC_BAD_TABLE_ID    = 6 /* Specified table does not exist. */
+	OFPMOFC_BAD_OUT         = 7 /* Error in output port/group. */
+)
+
+const (
+	OFPBFC_UNKNOWN            = 0  /* Unspecified error. */
+	OFPBFC_EPERM              = 1  /* Permissions error. */
+	OFPBFC_BAD_ID             = 2  /* Bundle ID doesn't exist. */
+	OFPBFC_BUNDLE_EXIST       = 3  /* Bundle ID already exist. */
+	OFPBFC_BUNDLE_CLOSED      = 4  /* Bundle ID is closed. */
+	OFPBFC_OUT_OF_BUNDLES     = 5  /* Too many bundles IDs. */
+	OFPBFC_BAD_TYPE           = 6  /* Unsupported or unknown message control type. */
+	OFPBFC_BAD_FLAGS          = 7  /* Unsupported, unknown, or inconsistent flags. */
+	OFPBFC_MSG_BAD_LEN        = 8  /* Length problem in included message. */
+	OFPBFC_MSG_BAD_XID        = 9  /* Inconsistent or duplicate XID. */
+	OFPBFC_MSG_UNSUP          = 10 /* Unsupported message in this bundle. */
+	OFPBFC_MSG_CONFLICT       = 11 /* Unsupported message combination in this bundle. */
+	OFPBFC_MSG_TOO_MANY       = 12 /* Can't handle this many messages in bundle. */
+	OFPBFC_MSG_FAILED         = 13 /* One message in bundle failed. */
+	OFPBFC_TIMEOUT            = 14 /* Bundle is taking too long. */
+	OFPBFC_BUNDLE_IN_PROGRESS = 15 /* Bundle is locking the resource. */
+)
@@ -0,0 +1,108 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+// Error decodes the error types introduced in OpenFlow 1.4 by itself, and the
+// others that are same with OpenFlow 1.3 using of13.Error.
+type Error struct {
+	of13.Error
+}
+
+func NewError() openflow.Error {
+	return new(Error)
+}
+
+func (r *Error) Cause() *openflow.ErrorCause {
+	if _, ok := errorTypes[r.Class()]; ok {
+		return openflow.NewErrorCause(errorTypes, r.Class(), r.Code())
+	}
+
+	return r.Error.Cause()
+}
+
+var errorTypes = map[uint16]openflow.ErrorType{
+	OFPET_BAD_PROPERTY: {
+		Name:        "OFPET_BAD_PROPERTY",
+		Description: "some property is invalid",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBPC_BAD_TYPE:         {Name: "OFPBPC_BAD_TYPE", Description: "unknown property type"},
+			OFPBPC_BAD_LEN:          {Name: "OFPBPC_BAD_LEN", Description: "length problem in property"},
+			OFPBPC_BAD_VALUE:        {Name: "OFPBPC_BAD_VALUE", Description: "unsupported property value"},
+			OFPBPC_TOO_MANY:         {Name: "OFPBPC_TOO_MANY", Description: "can't handle this many properties"},
+			OFPBPC_DUP_TYPE:         {Name: "OFPBPC_DUP_TYPE", Description: "A property type was duplicated"},
+			OFPBPC_BAD_EXPERIMENTER: {Name: "OFPBPC_BAD_EXPERIMENTER", Description: "unknown experimenter id specified"},
+			OFPBPC_BAD_EXP_TYPE:     {Name: "OFPBPC_BAD_EXP_TYPE", Description: "unknown exp_type for experimenter id"},
+			OFPBPC_BAD_EXP_VALUE:    {Name: "OFPBPC_BAD_EXP_VALUE", Description: "unknown value for experimenter id"},
+			OFPBPC_EPERM:            {Name: "OFPBPC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_ASYNC_CONFIG_FAILED: {
+		Name:        "OFPET_ASYNC_CONFIG_FAILED",
+		Description: "asynchronous config request failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPACFC_INVALID:     {Name: "OFPACFC_INVALID", Description: "one mask is invalid"},
+			OFPACFC_UNSUPPORTED: {Name: "OFPACFC_UNSUPPORTED", Description: "requested configuration not supported"},
+			OFPACFC_EPERM:       {Name: "OFPACFC_EPERM", Description: "permissions error"},
+		},
+	},
+	OFPET_FLOW_MONITOR_FAILED: {
+		Name:        "OFPET_FLOW_MONITOR_FAILED",
+		Description: "setting flow monitor failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPMOFC_UNKNOWN:         {Name: "OFPMOFC_UNKNOWN", Description: "unspecified error"},
+			OFPMOFC_MONITOR_EXISTS:  {Name: "OFPMOFC_MONITOR_EXISTS", Description: "monitor not added because a Monitor ADD attempted to replace an existing Monitor"},
+			OFPMOFC_INVALID_MONITOR: {Name: "OFPMOFC_INVALID_MONITOR", Description: "monitor not added because Monitor specified is invalid"},
+			OFPMOFC_UNKNOWN_MONITOR: {Name: "OFPMOFC_UNKNOWN_MONITOR", Description: "monitor not modified because a Monitor MODIFY attempted to modify a non-existent Monitor"},
+			OFPMOFC_BAD_COMMAND:     {Name: "OFPMOFC_BAD_COMMAND", Description: "unsupported or unknown command"},
+			OFPMOFC_BAD_FLAGS:       {Name: "OFPMOFC_BAD_FLAGS", Description: "flag configuration unsupported"},
+			OFPMOFC_BAD_TABLE_ID:    {Name: "OFPMOFC_BAD_TABLE_ID", Description: "specified table does not exist"},
+			OFPMOFC_BAD_OUT:         {Name: "OFPMOFC_BAD_OUT", Description: "error in output port/group"},
+		},
+	},
+	OFPET_BUNDLE_FAILED: {
+		Name:        "OFPET_BUNDLE_FAILED",
+		Description: "bundle operation failed",
+		Codes: map[uint16]openflow.ErrorCode{
+			OFPBFC_UNKNOWN:            {Name: "OFPBFC_UNKNOWN", Description: "unspecified error"},
+			OFPBFC_EPERM:              {Name: "OFPBFC_EPERM", Description: "permissions error"},
+			OFPBFC_BAD_ID:             {Name: "OFPBFC_BAD_ID", Description: "bundle ID doesn't exist"},
+			OFPBFC_BUNDLE_EXIST:       {Name: "OFPBFC_BUNDLE_EXIST", Description: "bundle ID already exist"},
+			OFPBFC_BUNDLE_CLOSED:      {Name: "OFPBFC_BUNDLE_CLOSED", Description: "bundle ID is closed"},
+			OFPBFC_OUT_OF_BUNDLES:     {Name: "OFPBFC_OUT_OF_BUNDLES", Description: "too many bundles IDs"},
+			OFPBFC_BAD_TYPE:           {Name: "OFPBFC_BAD_TYPE", Description: "unsupported or unknown message control type"},
+			OFPBFC_BAD_FLAGS:          {Name: "OFPBFC_BAD_FLAGS", Description: "unsupported, unknown, or inconsistent flags"},
+			OFPBFC_MSG_BAD_LEN:        {Name: "OFPBFC_MSG_BAD_LEN", Description: "length problem in included message"},
+			OFPBFC_MSG_BAD_XID:        {Name: "OFPBFC_MSG_BAD_XID", Description: "inconsistent or duplicate XID"},
+			OFPBFC_MSG_UNSUP:          {Name: "OFPBFC_MSG_UNSUP", Description: "unsupported message in this bundle"},
+			OFPBFC_MSG_CONFLICT:       {Name: "OFPBFC_MSG_CONFLICT", Description: "unsupported message combination in this bundle"},
+			OFPBFC_MSG_TOO_MANY:       {Name: "OFPBFC_MSG_TOO_MANY", Description: "can't handle this many messages in bundle"},
+			OFPBFC_MSG_FAILED:         {Name: "OFPBFC_MSG_FAILED", Description: "one message in bundle failed"},
+			OFPBFC_TIMEOUT:            {Name: "OFPBFC_TIMEOUT", Description: "bundle is taking too long"},
+			OFPBFC_BUNDLE_IN_PROGRESS: {Name: "OFPBFC_BUNDLE_IN_PROGRESS", Description: "bundle is locking the resource"},
+		},
+	},
+}
@@ -0,0 +1,445 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+// Concrete factory. The messages whose wire format has not been changed since
+// OpenFlow 1.3 are implemented by the of13 package.
+type Factory struct {
+	xid uint32
+}
+
+func NewFactory() openflow.Factory {
+	return &Factory{}
+}
+
+func (r *Factory) ProtocolVersion() uint8 {
+	return openflow.OF14_VERSION
+}
+
+func (r *Factory) getTransactionID() uint32 {
+	// Transaction ID will be started from 1, not 0.
+	return atomic.AddUint32(&r.xid, 1)
+}
+
+// upgrade changes the protocol version of the message made by the of13 package.
+func upgrade(msg openflow.Header) {
+	v, ok := msg.(interface {
+		SetVersion(version uint8)
+	})
+	if !ok {
+		panic(fmt.Sprintf("unexpected message type: %T", msg))
+	}
+	v.SetVersion(openflow.OF14_VERSION)
+}
+
+func (r *Factory) NewHello() (openflow.Hello, error) {
+	return NewHello(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewError() (openflow.Error, error) {
+	return NewError(), nil
+}
+
+func (r *Factory) NewExperimenter() (openflow.Experimenter, error) {
+	return openflow.NewExperimenter(openflow.OF14_VERSION, OFPT_EXPERIMENTER, r.getTransactionID()), nil
+}
+
+func (r *Factory) NewAction() (openflow.Action, error) {
+	return of13.NewAction(), nil
+}
+
+func (r *Factory) NewMatch() (openflow.Match, error) {
+	return of13.NewMatch(), nil
+}
+
+func (r *Factory) NewInstruction() (openflow.Instruction, error) {
+	return new(of13.Instruction), nil
+}
+
+func (r *Factory) NewBucket() (openflow.Bucket, error) {
+	return of13.NewBucket(), nil
+}
+
+func (r *Factory) NewMeterBand() (openflow.MeterBand, error) {
+	return of13.NewMeterBand(), nil
+}
+
+func getFlowModCmd(cmd openflow.FlowModCmd) uint8 {
+	var c uint8
+
+	switch cmd {
+	case openflow.FlowAdd:
+		c = OFPFC_ADD
+	case openflow.FlowModify:
+		c = OFPFC_MODIFY
+	case openflow.FlowDelete:
+		c = OFPFC_DELETE
+	case openflow.FlowModifyStrict:
+		c = OFPFC_MODIFY_STRICT
+	case openflow.FlowDeleteStrict:
+		c = OFPFC_DELETE_STRICT
+	default:
+		panic(fmt.Sprintf("unexpected FlowModCmd: %v", cmd))
+	}
+
+	return c
+}
+
+func (r *Factory) NewFlowMod(cmd openflow.FlowModCmd) (openflow.FlowMod, error) {
+	v := of13.NewFlowMod(r.getTransactionID(), getFlowModCmd(cmd))
+	upgrade(v)
+
+	return v, nil
+}
+
+func getGroupModCmd(cmd openflow.GroupModCmd) uint16 {
+	var c uint16
+
+	switch cmd {
+	case openflow.GroupAdd:
+		c = OFPGC_ADD
+	case openflow.GroupModify:
+		c = OFPGC_MODIFY
+	case openflow.GroupDelete:
+		c = OFPGC_DELETE
+	default:
+		panic(fmt.Sprintf("unexpected GroupModCmd: %v", cmd))
+	}
+
+	return c
+}
+
+func (r *Factory) NewGroupMod(cmd openflow.GroupModCmd) (openflow.GroupMod, error) {
+	v := of13.NewGroupMod(r.getTransactionID(), getGroupModCmd(cmd))
+	upgrade(v)
+
+	return v, nil
+}
+
+func getMeterModCmd(cmd openflow.MeterModCmd) uint16 {
+	var c uint16
+
+	switch cmd {
+	case openflow.MeterAdd:
+		c = OFPMC_ADD
+	case openflow.MeterModify:
+		c = OFPMC_MODIFY
+	case openflow.MeterDelete:
+		c = OFPMC_DELETE
+	default:
+		panic(fmt.Sprintf("unexpected MeterModCmd: %v", cmd))
+	}
+
+	return c
+}
+
+func (r *Factory) NewMeterMod(cmd openflow.MeterModCmd) (openflow.MeterMod, error) {
+	v := of13.NewMeterMod(r.getTransactionID(), getMeterModCmd(cmd))
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewEchoRequest() (openflow.EchoRequest, error) {
+	v := of13.NewEchoRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewEchoReply() (openflow.EchoReply, error) {
+	v := of13.NewEchoReply(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewBarrierRequest() (openflow.BarrierRequest, error) {
+	v := of13.NewBarrierRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewSetConfig() (openflow.SetConfig, error) {
+	v := of13.NewSetConfig(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewGetConfigRequest() (openflow.GetConfigRequest, error) {
+	v := of13.NewGetConfigRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewFeaturesRequest() (openflow.FeaturesRequest, error) {
+	v := of13.NewFeaturesRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewPacketOut() (openflow.PacketOut, error) {
+	v := of13.NewPacketOut(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewPortMod() (openflow.PortMod, error) {
+	return NewPortMod(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewDescRequest() (openflow.DescRequest, error) {
+	v := of13.NewDescRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewFlowStatsRequest() (openflow.FlowStatsRequest, error) {
+	v := of13.NewFlowStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewTableFeaturesRequest() (openflow.TableFeaturesRequest, error) {
+	v := of13.NewTableFeaturesRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewGroupStatsRequest() (openflow.GroupStatsRequest, error) {
+	v := of13.NewGroupStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewGroupDescRequest() (openflow.GroupDescRequest, error) {
+	v := of13.NewGroupDescRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewGroupFeaturesRequest() (openflow.GroupFeaturesRequest, error) {
+	v := of13.NewGroupFeaturesRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewMeterStatsRequest() (openflow.MeterStatsRequest, error) {
+	v := of13.NewMeterStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewMeterConfigRequest() (openflow.MeterConfigRequest, error) {
+	v := of13.NewMeterConfigRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewMeterFeaturesRequest() (openflow.MeterFeaturesRequest, error) {
+	v := of13.NewMeterFeaturesRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewPortStatsRequest() (openflow.PortStatsRequest, error) {
+	v := of13.NewPortStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewTableStatsRequest() (openflow.TableStatsRequest, error) {
+	v := of13.NewTableStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewQueueStatsRequest() (openflow.QueueStatsRequest, error) {
+	v := of13.NewQueueStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewAggregateStatsRequest() (openflow.AggregateStatsRequest, error) {
+	v := of13.NewAggregateStatsRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewRoleRequest() (openflow.RoleRequest, error) {
+	v := of13.NewRoleRequest(r.getTransactionID())
+	upgrade(v)
+
+	return v, nil
+}
+
+func (r *Factory) NewBarrierReply() (openflow.BarrierReply, error) {
+	return new(of13.BarrierReply), nil
+}
+
+func (r *Factory) NewGetConfigReply() (openflow.GetConfigReply, error) {
+	return new(of13.GetConfigReply), nil
+}
+
+func (r *Factory) NewFeaturesReply() (openflow.FeaturesReply, error) {
+	return new(of13.FeaturesReply), nil
+}
+
+func (r *Factory) NewFlowRemoved() (openflow.FlowRemoved, error) {
+	return new(of13.FlowRemoved), nil
+}
+
+func (r *Factory) NewPacketIn() (openflow.PacketIn, error) {
+	return new(of13.PacketIn), nil
+}
+
+func (r *Factory) NewDescReply() (openflow.DescReply, error) {
+	return new(of13.DescReply), nil
+}
+
+func (r *Factory) NewFlowStatsReply() (openflow.FlowStatsReply, error) {
+	return new(of13.FlowStatsReply), nil
+}
+
+func (r *Factory) NewTableFeaturesReply() (openflow.TableFeaturesReply, error) {
+	return new(of13.TableFeaturesReply), nil
+}
+
+func (r *Factory) NewGroupStatsReply() (openflow.GroupStatsReply, error) {
+	return new(of13.GroupStatsReply), nil
+}
+
+func (r *Factory) NewGroupDescReply() (openflow.GroupDescReply, error) {
+	return new(of13.GroupDescReply), nil
+}
+
+func (r *Factory) NewGroupFeaturesReply() (openflow.GroupFeaturesReply, error) {
+	return new(of13.GroupFeaturesReply), nil
+}
+
+func (r *Factory) NewMeterStatsReply() (openflow.MeterStatsReply, error) {
+	return new(of13.MeterStatsReply), nil
+}
+
+func (r *Factory) NewMeterConfigReply() (openflow.MeterConfigReply, error) {
+	return new(of13.MeterConfigReply), nil
+}
+
+func (r *Factory) NewMeterFeaturesReply() (openflow.MeterFeaturesReply, error) {
+	return new(of13.MeterFeaturesReply), nil
+}
+
+func (r *Factory) NewTableStatsReply() (openflow.TableStatsReply, error) {
+	return new(of13.TableStatsReply), nil
+}
+
+func (r *Factory) NewAggregateStatsReply() (openflow.AggregateStatsReply, error) {
+	return new(of13.AggregateStatsReply), nil
+}
+
+func (r *Factory) NewRoleReply() (openflow.RoleReply, error) {
+	return new(of13.RoleReply), nil
+}
+
+func (r *Factory) NewPortDescRequest() (openflow.PortDescRequest, error) {
+	return NewPortDescRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewSetAsync() (openflow.SetAsync, error) {
+	return NewSetAsync(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewGetAsyncRequest() (openflow.GetAsyncRequest, error) {
+	return NewGetAsyncRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewBundleControl() (openflow.BundleControl, error) {
+	return NewBundleControl(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewBundleAdd() (openflow.BundleAdd, error) {
+	return NewBundleAdd(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewFlowMonitorRequest() (openflow.FlowMonitorRequest, error) {
+	return NewFlowMonitorRequest(r.getTransactionID()), nil
+}
+
+func (r *Factory) NewPortStatus() (openflow.PortStatus, error) {
+	return new(PortStatus), nil
+}
+
+func (r *Factory) NewPortDescReply() (openflow.PortDescReply, error) {
+	return new(PortDescReply), nil
+}
+
+func (r *Factory) NewPortStatsReply() (openflow.PortStatsReply, error) {
+	return new(PortStatsReply), nil
+}
+
+func (r *Factory) NewQueueStatsReply() (openflow.QueueStatsReply, error) {
+	return new(QueueStatsReply), nil
+}
+
+func (r *Factory) NewGetAsyncReply() (openflow.GetAsyncReply, error) {
+	return new(GetAsyncReply), nil
+}
+
+func (r *Factory) NewFlowMonitorReply() (openflow.FlowMonitorReply, error) {
+	return new(FlowMonitorReply), nil
+}
+
+func (r *Factory) NewQueueGetConfigRequest() (openflow.QueueGetConfigRequest, error) {
+	// Replaced with the queue description multipart request since OpenFlow 1.4.
+	return nil, errors.New("of14 does not support QueueGetConfigRequest")
+}
+
+func (r *Factory) NewTransactionID() uint32 {
+	return r.getTransactionID()
+}
@@ -0,0 +1,344 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+var (
+	flowMonitorFlags = map[openflow.FlowMonitorFlag]uint16{
+		openflow.FlowMonitorInitial:      OFPFMF_INITIAL,
+		openflow.FlowMonitorAdded:        OFPFMF_ADD,
+		openflow.FlowMonitorRemoved:      OFPFMF_REMOVED,
+		openflow.FlowMonitorModified:     OFPFMF_MODIFY,
+		openflow.FlowMonitorInstructions: OFPFMF_INSTRUCTIONS,
+		openflow.FlowMonitorNoAbbrev:     OFPFMF_NO_ABBREV,
+		openflow.FlowMonitorOnlyOwn:      OFPFMF_ONLY_OWN,
+	}
+	flowMonitorCmds = map[openflow.FlowMonitorCmd]uint8{
+		openflow.FlowMonitorAdd:    OFPFMC_ADD,
+		openflow.FlowMonitorModify: OFPFMC_MODIFY,
+		openflow.FlowMonitorDelete: OFPFMC_DELETE,
+	}
+	flowUpdateEvents = map[uint16]openflow.FlowUpdateEvent{
+		OFPFME_INITIAL:  openflow.FlowUpdateInitial,
+		OFPFME_ADDED:    openflow.FlowUpdateAdded,
+		OFPFME_REMOVED:  openflow.FlowUpdateRemoved,
+		OFPFME_MODIFIED: openflow.FlowUpdateModified,
+		OFPFME_ABBREV:   openflow.FlowUpdateAbbrev,
+		OFPFME_PAUSED:   openflow.FlowUpdatePaused,
+		OFPFME_RESUMED:  openflow.FlowUpdateResumed,
+	}
+)
+
+type FlowMonitorRequest struct {
+	err error
+	openflow.Message
+	monitorID uint32
+	outPort   uint32
+	outGroup  uint32
+	flags     uint16
+	tableID   uint8
+	command   uint8
+	match     openflow.Match
+}
+
+func NewFlowMonitorRequest(xid uint32) openflow.FlowMonitorRequest {
+	return &FlowMonitorRequest{
+		Message:  openflow.NewMessage(openflow.OF14_VERSION, OFPT_MULTIPART_REQUEST, xid),
+		outPort:  OFPP_ANY,
+		outGroup: OFPG_ANY,
+		tableID:  OFPTT_ALL,
+		command:  OFPFMC_ADD,
+		// Empty match that matches all the flows.
+		match: of13.NewMatch(),
+	}
+}
+
+func (r *FlowMonitorRequest) Error() error {
+	return r.err
+}
+
+func (r *FlowMonitorRequest) MonitorID() uint32 {
+	return r.monitorID
+}
+
+func (r *FlowMonitorRequest) SetMonitorID(id uint32) {
+	r.monitorID = id
+}
+
+func (r *FlowMonitorRequest) Command() openflow.FlowMonitorCmd {
+	for cmd, v := range flowMonitorCmds {
+		if v == r.command {
+			return cmd
+		}
+	}
+
+	return openflow.FlowMonitorCmd(r.command)
+}
+
+func (r *FlowMonitorRequest) SetCommand(cmd openflow.FlowMonitorCmd) {
+	v, ok := flowMonitorCmds[cmd]
+	if !ok {
+		r.err = fmt.Errorf("SetCommand: unexpected flow monitor command: %v", cmd)
+		return
+	}
+	r.command = v
+}
+
+func (r *FlowMonitorRequest) Flags() openflow.FlowMonitorFlag {
+	var flags openflow.FlowMonitorFlag
+	for flag, bit := range flowMonitorFlags {
+		if r.flags&bit != 0 {
+			flags |= flag
+		}
+	}
+
+	return flags
+}
+
+func (r *FlowMonitorRequest) SetFlags(flags openflow.FlowMonitorFlag) {
+	var v uint16
+	for flag, bit := range flowMonitorFlags {
+		if flags&flag != 0 {
+			v |= bit
+			flags &^= flag
+		}
+	}
+	if flags != 0 {
+		r.err = fmt.Errorf("SetFlags: unexpected flow monitor flags: %v", flags)
+		return
+	}
+	r.flags = v
+}
+
+func (r *FlowMonitorRequest) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *FlowMonitorRequest) SetTableID(id uint8) {
+	r.tableID = id
+}
+
+func (r *FlowMonitorRequest) OutPort() (ok bool, port uint32) {
+	if r.outPort == OFPP_ANY {
+		return false, 0
+	}
+
+	return true, r.outPort
+}
+
+func (r *FlowMonitorRequest) SetOutPort(port uint32) {
+	r.outPort = port
+}
+
+func (r *FlowMonitorRequest) Match() openflow.Match {
+	return r.match
+}
+
+func (r *FlowMonitorRequest) SetMatch(match openflow.Match) {
+	if match == nil {
+		panic("match is nil")
+	}
+	r.match = match
+}
+
+func (r *FlowMonitorRequest) MarshalBinary() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	v := make([]byte, 24)
+	// Flow monitor request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_FLOW_MONITOR)
+	// v[2:8] is flags and padding
+	binary.BigEndian.PutUint32(v[8:12], r.monitorID)
+	binary.BigEndian.PutUint32(v[12:16], r.outPort)
+	binary.BigEndian.PutUint32(v[16:20], r.outGroup)
+	binary.BigEndian.PutUint16(v[20:22], r.flags)
+	v[22] = r.tableID
+	v[23] = r.command
+
+	match, err := r.match.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	v = append(v, match...)
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type FlowUpdate struct {
+	length      uint16
+	event       openflow.FlowUpdateEvent
+	tableID     uint8
+	reason      uint8
+	idleTimeout uint16
+	hardTimeout uint16
+	priority    uint16
+	cookie      uint64
+	match       openflow.Match
+	instruction openflow.Instruction
+	xid         uint32
+}
+
+func (r *FlowUpdate) Event() openflow.FlowUpdateEvent {
+	return r.event
+}
+
+func (r *FlowUpdate) TableID() uint8 {
+	return r.tableID
+}
+
+func (r *FlowUpdate) Reason() uint8 {
+	return r.reason
+}
+
+func (r *FlowUpdate) IdleTimeout() uint16 {
+	return r.idleTimeout
+}
+
+func (r *FlowUpdate) HardTimeout() uint16 {
+	return r.hardTimeout
+}
+
+func (r *FlowUpdate) Priority() uint16 {
+	return r.priority
+}
+
+func (r *FlowUpdate) Cookie() uint64 {
+	return r.cookie
+}
+
+func (r *FlowUpdate) Match() openflow.Match {
+	return r.match
+}
+
+func (r *FlowUpdate) Instruction() openflow.Instruction {
+	return r.instruction
+}
+
+func (r *FlowUpdate) TransactionID() uint32 {
+	return r.xid
+}
+
+func (r *FlowUpdate) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 8 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+	event := binary.BigEndian.Uint16(data[2:4])
+	v, ok := flowUpdateEvents[event]
+	if !ok {
+		return fmt.Errorf("unexpected flow update event: %v", event)
+	}
+	r.event = v
+
+	switch r.event {
+	case openflow.FlowUpdateAbbrev:
+		r.xid = binary.BigEndian.Uint32(data[4:8])
+		return nil
+	case openflow.FlowUpdatePaused, openflow.FlowUpdateResumed:
+		// data[4:8] is zeros
+		return nil
+	}
+
+	if r.length < 32 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.tableID = data[4]
+	r.reason = data[5]
+	r.idleTimeout = binary.BigEndian.Uint16(data[6:8])
+	r.hardTimeout = binary.BigEndian.Uint16(data[8:10])
+	r.priority = binary.BigEndian.Uint16(data[10:12])
+	// data[12:16] is zeros
+	r.cookie = binary.BigEndian.Uint64(data[16:24])
+
+	r.match = of13.NewMatch()
+	if err := r.match.UnmarshalBinary(data[24:r.length]); err != nil {
+		return err
+	}
+	// ofp_match is padded to make it 64-bit aligned.
+	matchLength := (int(binary.BigEndian.Uint16(data[26:28])) + 7) / 8 * 8
+	if 24+matchLength > int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	// Instructions are only included if the monitor has OFPFMF_INSTRUCTIONS.
+	r.instruction = nil
+	if 24+matchLength < int(r.length) {
+		inst := new(of13.Instruction)
+		if err := inst.UnmarshalBinary(data[24+matchLength : r.length]); err != nil {
+			return err
+		}
+		r.instruction = inst
+	}
+
+	return nil
+}
+
+type FlowMonitorReply struct {
+	openflow.Message
+	flags   uint16
+	updates []openflow.FlowUpdate
+}
+
+func (r *FlowMonitorReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *FlowMonitorReply) Updates() []openflow.FlowUpdate {
+	return r.updates
+}
+
+func (r *FlowMonitorReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.updates = make([]openflow.FlowUpdate, 0)
+	for i := 8; i < len(payload); {
+		v := new(FlowUpdate)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.updates = append(r.updates, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
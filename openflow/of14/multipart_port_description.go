@@ -0,0 +1,87 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortDescRequest struct {
+	openflow.Message
+}
+
+func NewPortDescRequest(xid uint32) openflow.PortDescRequest {
+	return &PortDescRequest{
+		Message: openflow.NewMessage(openflow.OF14_VERSION, OFPT_MULTIPART_REQUEST, xid),
+	}
+}
+
+func (r *PortDescRequest) MarshalBinary() ([]byte, error) {
+	v := make([]byte, 8)
+	// Multipart description request
+	binary.BigEndian.PutUint16(v[0:2], OFPMP_PORT_DESC)
+	// No flags and body
+	r.SetPayload(v)
+
+	return r.Message.MarshalBinary()
+}
+
+type PortDescReply struct {
+	openflow.Message
+	flags uint16
+	ports []openflow.Port
+}
+
+func (r *PortDescReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r PortDescReply) Ports() []openflow.Port {
+	return r.ports
+}
+
+func (r *PortDescReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	// Ports have the variable length since OpenFlow 1.4.
+	r.ports = make([]openflow.Port, 0)
+	for i := 8; i < len(payload); {
+		v := new(Port)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.ports = append(r.ports, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
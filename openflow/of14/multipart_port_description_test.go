@@ -0,0 +1,108 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestPort(num uint32, name string, props ...[]byte) []byte {
+	v := make([]byte, 40)
+	binary.BigEndian.PutUint32(v[0:4], num)
+	copy(v[8:14], []byte{0x00, 0x11, 0x22, 0x33, 0x44, byte(num)})
+	copy(v[16:32], name)
+	binary.BigEndian.PutUint32(v[36:40], OFPPS_LIVE)
+	for _, p := range props {
+		v = append(v, p...)
+	}
+	binary.BigEndian.PutUint16(v[4:6], uint16(len(v)))
+
+	return v
+}
+
+func newTestEthernetProperty(current, speed uint32) []byte {
+	v := make([]byte, 32)
+	binary.BigEndian.PutUint16(v[0:2], OFPPDPT_ETHERNET)
+	binary.BigEndian.PutUint16(v[2:4], 32)
+	binary.BigEndian.PutUint32(v[8:12], current)
+	binary.BigEndian.PutUint32(v[24:28], speed)
+
+	return v
+}
+
+func newTestExperimenterProperty() []byte {
+	// 12 bytes of the property padded to 16 bytes.
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint16(v[0:2], OFPPDPT_EXPERIMENTER)
+	binary.BigEndian.PutUint16(v[2:4], 12)
+
+	return v
+}
+
+func TestPortDescReply(t *testing.T) {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint16(payload[0:2], OFPMP_PORT_DESC)
+	payload = append(payload, newTestPort(1, "eth1", newTestEthernetProperty(OFPPF_10GB_FD|OFPPF_FIBER, 10000000))...)
+	payload = append(payload, newTestPort(2, "eth2", newTestExperimenterProperty(), newTestEthernetProperty(OFPPF_OTHER, 25000000))...)
+	payload = append(payload, newTestPort(3, "eth3")...)
+
+	msg := openflow.NewMessage(openflow.OF14_VERSION, OFPT_MULTIPART_REPLY, 3)
+	msg.SetPayload(payload)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply := new(PortDescReply)
+	if err := reply.UnmarshalBinary(packet); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	ports := reply.Ports()
+	if len(ports) != 3 {
+		t.Fatalf("unexpected number of ports: expected=3, got=%v", len(ports))
+	}
+
+	expected := []struct {
+		name  string
+		speed uint64
+		fiber bool
+	}{
+		{"eth1", 10000, true},
+		{"eth2", 25000, false},
+		{"eth3", 0, false},
+	}
+	for i, v := range expected {
+		p := ports[i]
+		if p.Number() != uint32(i+1) || p.Name() != v.name || p.MAC()[5] != byte(i+1) {
+			t.Errorf("unexpected port: number=%v, name=%v, mac=%v", p.Number(), p.Name(), p.MAC())
+		}
+		if p.Speed() != v.speed || p.IsFiber() != v.fiber {
+			t.Errorf("unexpected port %v: speed=%v, fiber=%v", p.Name(), p.Speed(), p.IsFiber())
+		}
+		if p.IsLinkDown() || p.IsPortDown() {
+			t.Errorf("unexpected port %v state", p.Name())
+		}
+	}
+}
@@ -0,0 +1,270 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortStats struct {
+	length          uint16
+	portNum         uint32
+	durationSec     uint32
+	durationNanoSec uint32
+	counters        [8]uint64
+	// Counters of the Ethernet property.
+	rxFrameErr, rxOverErr, rxCRCErr, collisions uint64
+}
+
+func (r *PortStats) PortNumber() uint32 {
+	return r.portNum
+}
+
+func (r *PortStats) RxPackets() uint64 {
+	return r.counters[0]
+}
+
+func (r *PortStats) TxPackets() uint64 {
+	return r.counters[1]
+}
+
+func (r *PortStats) RxBytes() uint64 {
+	return r.counters[2]
+}
+
+func (r *PortStats) TxBytes() uint64 {
+	return r.counters[3]
+}
+
+func (r *PortStats) RxDropped() uint64 {
+	return r.counters[4]
+}
+
+func (r *PortStats) TxDropped() uint64 {
+	return r.counters[5]
+}
+
+func (r *PortStats) RxErrors() uint64 {
+	return r.counters[6]
+}
+
+func (r *PortStats) TxErrors() uint64 {
+	return r.counters[7]
+}
+
+func (r *PortStats) RxFrameErrors() uint64 {
+	return r.rxFrameErr
+}
+
+func (r *PortStats) RxOverErrors() uint64 {
+	return r.rxOverErr
+}
+
+func (r *PortStats) RxCRCErrors() uint64 {
+	return r.rxCRCErr
+}
+
+func (r *PortStats) Collisions() uint64 {
+	return r.collisions
+}
+
+func (r *PortStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *PortStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *PortStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 80 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 80 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	// data[2:4] is padding
+	r.portNum = binary.BigEndian.Uint32(data[4:8])
+	r.durationSec = binary.BigEndian.Uint32(data[8:12])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[12:16])
+	for i := range r.counters {
+		r.counters[i] = binary.BigEndian.Uint64(data[16+i*8 : 24+i*8])
+	}
+
+	return parseProperties(data[80:r.length], func(propType uint16, prop []byte) error {
+		// Ignore the optical and experimenter properties.
+		if propType != OFPPSPT_ETHERNET {
+			return nil
+		}
+		if len(prop) < 40 {
+			return openflow.ErrInvalidPacketLength
+		}
+		// prop[4:8] is padding
+		r.rxFrameErr = binary.BigEndian.Uint64(prop[8:16])
+		r.rxOverErr = binary.BigEndian.Uint64(prop[16:24])
+		r.rxCRCErr = binary.BigEndian.Uint64(prop[24:32])
+		r.collisions = binary.BigEndian.Uint64(prop[32:40])
+
+		return nil
+	})
+}
+
+type PortStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.PortStats
+}
+
+func (r *PortStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *PortStatsReply) Stats() []openflow.PortStats {
+	return r.stats
+}
+
+func (r *PortStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.PortStats, 0)
+	for i := 8; i < len(payload); {
+		v := new(PortStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
+
+type QueueStats struct {
+	length          uint16
+	portNum         uint32
+	queueID         uint32
+	txBytes         uint64
+	txPackets       uint64
+	txErrors        uint64
+	durationSec     uint32
+	durationNanoSec uint32
+}
+
+func (r *QueueStats) PortNumber() uint32 {
+	return r.portNum
+}
+
+func (r *QueueStats) QueueID() uint32 {
+	return r.queueID
+}
+
+func (r *QueueStats) TxBytes() uint64 {
+	return r.txBytes
+}
+
+func (r *QueueStats) TxPackets() uint64 {
+	return r.txPackets
+}
+
+func (r *QueueStats) TxErrors() uint64 {
+	return r.txErrors
+}
+
+func (r *QueueStats) DurationSec() uint32 {
+	return r.durationSec
+}
+
+func (r *QueueStats) DurationNanoSec() uint32 {
+	return r.durationNanoSec
+}
+
+func (r *QueueStats) UnmarshalBinary(data []byte) error {
+	if len(data) < 48 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.length = binary.BigEndian.Uint16(data[0:2])
+	if r.length < 48 || len(data) < int(r.length) {
+		return openflow.ErrInvalidPacketLength
+	}
+
+	// data[2:8] is padding
+	r.portNum = binary.BigEndian.Uint32(data[8:12])
+	r.queueID = binary.BigEndian.Uint32(data[12:16])
+	r.txBytes = binary.BigEndian.Uint64(data[16:24])
+	r.txPackets = binary.BigEndian.Uint64(data[24:32])
+	r.txErrors = binary.BigEndian.Uint64(data[32:40])
+	r.durationSec = binary.BigEndian.Uint32(data[40:44])
+	r.durationNanoSec = binary.BigEndian.Uint32(data[44:48])
+	// Ignore the experimenter properties.
+
+	return nil
+}
+
+type QueueStatsReply struct {
+	openflow.Message
+	flags uint16
+	stats []openflow.QueueStats
+}
+
+func (r *QueueStatsReply) More() bool {
+	return r.flags&OFPMPF_REPLY_MORE != 0
+}
+
+func (r *QueueStatsReply) Stats() []openflow.QueueStats {
+	return r.stats
+}
+
+func (r *QueueStatsReply) UnmarshalBinary(data []byte) error {
+	if err := r.Message.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	payload := r.Payload()
+	if payload == nil || len(payload) < 8 {
+		return openflow.ErrInvalidPacketLength
+	}
+	r.flags = binary.BigEndian.Uint16(payload[2:4])
+
+	r.stats = make([]openflow.QueueStats, 0)
+	for i := 8; i < len(payload); {
+		v := new(QueueStats)
+		if err := v.UnmarshalBinary(payload[i:]); err != nil {
+			return err
+		}
+		r.stats = append(r.stats, v)
+		i += int(v.length)
+	}
+
+	return nil
+}
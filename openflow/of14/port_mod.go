@@ -0,0 +1,61 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+type PortMod struct {
+	openflow.BasePortMod
+}
+
+func NewPortMod(xid uint32) openflow.PortMod {
+	return &PortMod{
+		BasePortMod: openflow.NewBasePortMod(openflow.OF14_VERSION, OFPT_PORT_MOD, xid),
+	}
+}
+
+func (r *PortMod) MarshalBinary() ([]byte, error) {
+	if r.PortNumber() > OFPP_MAX {
+		return nil, fmt.Errorf("invalid port number: %v", r.PortNumber())
+	}
+	if len(r.MAC()) != 6 {
+		return nil, openflow.ErrInvalidMACAddress
+	}
+
+	v := make([]byte, 24)
+	binary.BigEndian.PutUint32(v[0:4], r.PortNumber())
+	// v[4:8] is padding.
+	copy(v[8:14], r.MAC())
+	// v[14:16] is padding.
+	binary.BigEndian.PutUint32(v[16:20], uint32(r.Config()))
+	binary.BigEndian.PutUint32(v[20:24], uint32(r.Mask()))
+	// The advertised features are moved into the Ethernet property since OpenFlow
+	// 1.4. We do not add the property so that they are not changed.
+	r.SetPayload(v)
+
+	return r.BasePortMod.MarshalBinary()
+}
@@ -0,0 +1,55 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package of14
+
+import (
+	"encoding/binary"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// parseProperties calls fn for each property in data that is a list of the TLV
+// properties padded to make them 64-bit aligned. data of fn includes the property
+// header.
+func parseProperties(data []byte, fn func(propType uint16, data []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return openflow.ErrInvalidPacketLength
+		}
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || length > len(data) {
+			return openflow.ErrInvalidPacketLength
+		}
+		if err := fn(binary.BigEndian.Uint16(data[0:2]), data[:length]); err != nil {
+			return err
+		}
+
+		// The length of a property does not include the padding.
+		next := (length + 7) / 8 * 8
+		if next > len(data) {
+			break
+		}
+		data = data[next:]
+	}
+
+	return nil
+}
@@ -25,6 +25,13 @@ import (
 	"encoding"
 )
 
+// NoBuffer is the buffer ID meaning that the packet is not buffered in the device.
+const NoBuffer = 0xFFFFFFFF
+
+// NoBufferLength is the maximum length of the packets sent to the controller that
+// makes the device send the whole packets without buffering them.
+const NoBufferLength = 0xFFFF
+
 type PacketIn interface {
 	Header
 	BufferID() uint32
@@ -34,5 +41,9 @@ type PacketIn interface {
 	Reason() uint8
 	Cookie() uint64
 	Data() []byte
+	// Match returns the pipeline fields of the packet reported by the device,
+	// such as the ingress port. It returns nil if the fields are unknown. The
+	// OpenFlow 1.0 devices only report the ingress port.
+	Match() Match
 	encoding.BinaryUnmarshaler
 }
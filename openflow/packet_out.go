@@ -27,12 +27,19 @@ import (
 
 type PacketOut interface {
 	Action() Action
+	// BufferID returns the ID of the buffered packet in the device that is sent
+	// by this message, or NoBuffer if the packet is the data of this message.
+	BufferID() uint32
 	Data() []byte
 	encoding.BinaryMarshaler
 	Error() error
 	Header
 	InPort() InPort
 	SetAction(action Action)
+	// SetBufferID sets the ID of the buffered packet that is reported by a
+	// PACKET_IN message. The device sends the buffered packet and then releases
+	// the buffer, in which case the data of this message is not sent.
+	SetBufferID(id uint32)
 	SetData(data []byte)
 	SetInPort(port InPort)
 }
@@ -39,12 +39,15 @@ const (
 type OutPort struct {
 	logical uint8
 	value   uint32
+	// Maximum length of the packets sent to the controller.
+	maxLength uint16
 }
 
 // NewOutPort returns output port whose default value is FLOOD
 func NewOutPort() OutPort {
 	return OutPort{
-		logical: 0x1 << flood,
+		logical:   0x1 << flood,
+		maxLength: NoBufferLength,
 	}
 }
 
@@ -80,6 +83,17 @@ func (r *OutPort) IsController() bool {
 	return r.logical&(0x1<<controller) != 0
 }
 
+// SetMaxLength sets the maximum length of the packets sent to the controller if
+// the output port is CONTROLLER. The device buffers the packets and sends only
+// their first length bytes, unless length is NoBufferLength that is the default.
+func (r *OutPort) SetMaxLength(length uint16) {
+	r.maxLength = length
+}
+
+func (r *OutPort) MaxLength() uint16 {
+	return r.maxLength
+}
+
 func (r *OutPort) SetInPort() {
 	r.logical = 0x1 << inport
 }
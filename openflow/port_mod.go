@@ -0,0 +1,101 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+	"net"
+)
+
+// PortConfig is a bitmap of the administrative settings of a port. The bits are
+// same with the OFPPC_* flags of all the OpenFlow versions.
+type PortConfig uint32
+
+const (
+	// Port is administratively down.
+	PortDown PortConfig = 1 << 0
+	// Drop all the packets received by the port.
+	PortNoRecv PortConfig = 1 << 2
+	// Drop the packets forwarded to the port.
+	PortNoForward PortConfig = 1 << 5
+	// Do not send PACKET_IN messages for the port.
+	PortNoPacketIn PortConfig = 1 << 6
+)
+
+type PortMod interface {
+	Header
+	encoding.BinaryMarshaler
+	Config() PortConfig
+	// MAC returns the hardware address of the port. The switch rejects the
+	// request if it does not match with the port's one.
+	MAC() net.HardwareAddr
+	Mask() PortConfig
+	PortNumber() uint32
+	// SetConfig sets the settings of the port that are selected by mask. The
+	// settings not selected by mask are not changed.
+	SetConfig(config, mask PortConfig)
+	SetMAC(mac net.HardwareAddr)
+	SetPortNumber(num uint32)
+}
+
+// BasePortMod implements the common part of the PortMod interface.
+type BasePortMod struct {
+	Message
+	number       uint32
+	mac          net.HardwareAddr
+	config, mask PortConfig
+}
+
+func NewBasePortMod(version, msgType uint8, xid uint32) BasePortMod {
+	return BasePortMod{
+		Message: NewMessage(version, msgType, xid),
+	}
+}
+
+func (r *BasePortMod) Config() PortConfig {
+	return r.config
+}
+
+func (r *BasePortMod) MAC() net.HardwareAddr {
+	return r.mac
+}
+
+func (r *BasePortMod) Mask() PortConfig {
+	return r.mask
+}
+
+func (r *BasePortMod) PortNumber() uint32 {
+	return r.number
+}
+
+func (r *BasePortMod) SetConfig(config, mask PortConfig) {
+	r.config = config & mask
+	r.mask = mask
+}
+
+func (r *BasePortMod) SetMAC(mac net.HardwareAddr) {
+	r.mac = mac
+}
+
+func (r *BasePortMod) SetPortNumber(num uint32) {
+	r.number = num
+}
@@ -0,0 +1,72 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type ControllerRole uint8
+
+const (
+	// Don't change the current role.
+	RoleNoChange ControllerRole = iota
+	// Default role, full access.
+	RoleEqual
+	// Full access, at most one master.
+	RoleMaster
+	// Read-only access.
+	RoleSlave
+)
+
+func (r ControllerRole) String() string {
+	switch r {
+	case RoleNoChange:
+		return "NOCHANGE"
+	case RoleEqual:
+		return "EQUAL"
+	case RoleMaster:
+		return "MASTER"
+	case RoleSlave:
+		return "SLAVE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type RoleRequest interface {
+	Header
+	encoding.BinaryMarshaler
+	// GenerationID returns the master election generation ID that is used to detect
+	// stale role requests. It is ignored if the role is RoleNoChange or RoleEqual.
+	GenerationID() uint64
+	Role() ControllerRole
+	SetGenerationID(id uint64)
+	SetRole(role ControllerRole)
+}
+
+type RoleReply interface {
+	Header
+	encoding.BinaryUnmarshaler
+	GenerationID() uint64
+	Role() ControllerRole
+}
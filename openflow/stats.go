@@ -0,0 +1,137 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package openflow
+
+import (
+	"encoding"
+)
+
+type PortStatsRequest interface {
+	encoding.BinaryMarshaler
+	Header
+	// PortNumber returns false if the request queries all the ports
+	PortNumber() (ok bool, num uint32)
+	SetPortNumber(num uint32)
+}
+
+type PortStats interface {
+	PortNumber() uint32
+	RxPackets() uint64
+	TxPackets() uint64
+	RxBytes() uint64
+	TxBytes() uint64
+	RxDropped() uint64
+	TxDropped() uint64
+	RxErrors() uint64
+	TxErrors() uint64
+	RxFrameErrors() uint64
+	RxOverErrors() uint64
+	RxCRCErrors() uint64
+	Collisions() uint64
+	// DurationSec and DurationNanoSec always return 0 on OpenFlow 1.0
+	DurationSec() uint32
+	DurationNanoSec() uint32
+}
+
+type PortStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []PortStats
+	encoding.BinaryUnmarshaler
+}
+
+type TableStatsRequest interface {
+	encoding.BinaryMarshaler
+	Header
+}
+
+type TableStats interface {
+	TableID() uint8
+	// ActiveCount returns number of active flow entries
+	ActiveCount() uint32
+	// LookupCount returns number of packets looked up in the table
+	LookupCount() uint64
+	// MatchedCount returns number of packets that hit the table
+	MatchedCount() uint64
+}
+
+type TableStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []TableStats
+	encoding.BinaryUnmarshaler
+}
+
+type QueueStatsRequest interface {
+	encoding.BinaryMarshaler
+	Header
+	// PortNumber returns false if the request queries all the ports
+	PortNumber() (ok bool, num uint32)
+	// QueueID returns false if the request queries all the queues
+	QueueID() (ok bool, id uint32)
+	SetPortNumber(num uint32)
+	SetQueueID(id uint32)
+}
+
+type QueueStats interface {
+	PortNumber() uint32
+	QueueID() uint32
+	TxBytes() uint64
+	TxPackets() uint64
+	TxErrors() uint64
+	// DurationSec and DurationNanoSec always return 0 on OpenFlow 1.0
+	DurationSec() uint32
+	DurationNanoSec() uint32
+}
+
+type QueueStatsReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Stats() []QueueStats
+	encoding.BinaryUnmarshaler
+}
+
+type AggregateStatsRequest interface {
+	Cookie() uint64
+	CookieMask() uint64
+	encoding.BinaryMarshaler
+	Error() error
+	Header
+	Match() Match
+	SetCookie(cookie uint64)
+	SetCookieMask(mask uint64)
+	SetMatch(match Match)
+	// 0xFF means all table
+	SetTableID(id uint8)
+	TableID() uint8
+}
+
+type AggregateStatsReply interface {
+	Header
+	PacketCount() uint64
+	ByteCount() uint64
+	FlowCount() uint32
+	encoding.BinaryUnmarshaler
+}
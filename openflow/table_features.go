@@ -30,4 +30,34 @@ type TableFeaturesRequest interface {
 	encoding.BinaryMarshaler
 }
 
-// TODO: Implement TableFeaturesReply
+// TableFeatures describes the capabilities of a flow table. Note that the
+// properties only for the table-miss flow entry are not included.
+type TableFeatures interface {
+	TableID() uint8
+	Name() string
+	// MaxEntries returns max number of entries supported
+	MaxEntries() uint32
+	// MetadataMatch returns bits of metadata that the table can match
+	MetadataMatch() uint64
+	// MetadataWrite returns bits of metadata that the table can write
+	MetadataWrite() uint64
+	Instructions() []InstructionType
+	// NextTables returns IDs of the tables that can be directly reached from this table
+	NextTables() []uint8
+	WriteActions() []ActionType
+	ApplyActions() []ActionType
+	// MatchFields returns the fields that can be matched in this table
+	MatchFields() []MatchField
+	// Wildcards returns the fields that can be wildcarded in this table
+	Wildcards() []MatchField
+	WriteSetFields() []MatchField
+	ApplySetFields() []MatchField
+}
+
+type TableFeaturesReply interface {
+	Header
+	// More returns whether additional parts of this multipart reply follow
+	More() bool
+	Features() []TableFeatures
+	encoding.BinaryUnmarshaler
+}
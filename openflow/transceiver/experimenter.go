@@ -0,0 +1,132 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package transceiver
+
+import (
+	"sync"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+// ExperimenterCodec decodes the experimenter messages of an experimenter into
+// its own message types.
+type ExperimenterCodec interface {
+	Decode(f openflow.Factory, msg openflow.Experimenter) (openflow.Header, error)
+}
+
+// ExperimenterInitializer is an ExperimenterCodec that should configure a device
+// before the device sends the experimenter messages, e.g., Nicira's NXT_PACKET_IN2.
+type ExperimenterInitializer interface {
+	// Init configures the device described by desc. It should not send anything if
+	// the device does not support the experimenter.
+	Init(f openflow.Factory, w Writer, desc openflow.DescReply) error
+}
+
+// ExperimenterHandler is a callback that handles the experimenter messages decoded
+// by the codec of its experimenter.
+type ExperimenterHandler func(f openflow.Factory, w Writer, msg openflow.Header) error
+
+type experimenter struct {
+	codec   ExperimenterCodec
+	handler ExperimenterHandler
+}
+
+var experimenters = struct {
+	sync.RWMutex
+	m map[uint32]experimenter
+}{
+	m: make(map[uint32]experimenter),
+}
+
+// RegisterExperimenter registers the codec and handler of the experimenter whose
+// ID is id. The experimenter messages of an unregistered experimenter are passed
+// to Handler.OnExperimenter as they are. If handler is nil, the decoded messages
+// are passed to Handler.OnPacketIn if they implement openflow.PacketIn, such as
+// Nicira's NXT_PACKET_IN2, or Handler.OnExperimenter otherwise.
+func RegisterExperimenter(id uint32, codec ExperimenterCodec, handler ExperimenterHandler) {
+	if codec == nil {
+		panic("codec is nil")
+	}
+
+	experimenters.Lock()
+	defer experimenters.Unlock()
+	experimenters.m[id] = experimenter{codec: codec, handler: handler}
+}
+
+func lookupExperimenter(id uint32) (experimenter, bool) {
+	experimenters.RLock()
+	defer experimenters.RUnlock()
+	v, ok := experimenters.m[id]
+
+	return v, ok
+}
+
+// InitExperimenters calls Init of all the registered codecs that implement
+// ExperimenterInitializer. It should be called once for each connection after
+// the description of the device is received.
+func InitExperimenters(f openflow.Factory, w Writer, desc openflow.DescReply) error {
+	experimenters.RLock()
+	initializers := make([]ExperimenterInitializer, 0)
+	for _, v := range experimenters.m {
+		if init, ok := v.codec.(ExperimenterInitializer); ok {
+			initializers = append(initializers, init)
+		}
+	}
+	experimenters.RUnlock()
+
+	for _, v := range initializers {
+		if err := v.Init(f, w, desc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Transceiver) handleExperimenter(packet []byte) error {
+	msg, err := r.factory.NewExperimenter()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	exp, ok := lookupExperimenter(msg.ExperimenterID())
+	if !ok {
+		return r.observer.OnExperimenter(r.factory, r, msg)
+	}
+	v, err := exp.codec.Decode(r.factory, msg)
+	if err != nil {
+		// Drop the message that we cannot understand instead of closing the connection.
+		logger.Errorf("failed to decode the experimenter message: experimenter=%#x, type=%v, err=%v", msg.ExperimenterID(), msg.ExperimenterType(), err)
+		return nil
+	}
+	if exp.handler != nil {
+		return exp.handler(r.factory, r, v)
+	}
+	if p, ok := v.(openflow.PacketIn); ok {
+		return r.observer.OnPacketIn(r.factory, r, p)
+	}
+
+	return r.observer.OnExperimenter(r.factory, r, v)
+}
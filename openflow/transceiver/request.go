@@ -0,0 +1,478 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package transceiver
+
+import (
+	"context"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of10"
+	"github.com/superkkt/cherry/openflow/of13"
+	"github.com/superkkt/cherry/openflow/of14"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrClosed        = errors.New("closed transceiver")
+	ErrNotNegotiated = errors.New("not yet negotiated transceiver")
+)
+
+// Request is a request message whose replies have the same transaction ID as the request.
+type Request interface {
+	openflow.Header
+	encoding.BinaryMarshaler
+}
+
+// ErrorReply is returned when a device replies to a request with an ERROR message.
+type ErrorReply struct {
+	Reply openflow.Error
+}
+
+func (r *ErrorReply) Error() string {
+	return fmt.Sprintf("error reply: %v", r.Reply.Cause())
+}
+
+// Cause returns the decoded error of the reply so that errors.Cause returns it.
+func (r *ErrorReply) Cause() error {
+	return r.Reply.Cause()
+}
+
+type replyMessage interface {
+	openflow.Header
+	encoding.BinaryUnmarshaler
+}
+
+type multipartReply interface {
+	More() bool
+}
+
+// pendingRequest is a request message that waits for its reply messages whose
+// transaction ID is same with the request's one.
+type pendingRequest struct {
+	c chan openflow.Header
+	// done is closed when the request is removed from the request map.
+	done chan struct{}
+}
+
+// wait waits for the reply messages until we get the last part of the multipart
+// reply or ctx is canceled.
+func (r *pendingRequest) wait(ctx context.Context) ([]openflow.Header, error) {
+	replies := make([]openflow.Header, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-r.done:
+			return nil, ErrClosed
+		case v := <-r.c:
+			if e, ok := v.(openflow.Error); ok {
+				return nil, &ErrorReply{Reply: e}
+			}
+			replies = append(replies, v)
+			if m, ok := v.(multipartReply); ok && m.More() {
+				continue
+			}
+			return replies, nil
+		}
+	}
+}
+
+// requestMap correlates the reply messages with the pending requests by their transaction ID.
+type requestMap struct {
+	mutex   sync.Mutex
+	pending map[uint32]*pendingRequest
+	closed  bool
+}
+
+func newRequestMap() *requestMap {
+	return &requestMap{
+		pending: make(map[uint32]*pendingRequest),
+	}
+}
+
+// add adds a new pending request for msg after assigning a unique transaction
+// ID, which is obtained from f, to msg.
+func (r *requestMap) add(f openflow.Factory, msg openflow.Header) (*pendingRequest, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil, ErrClosed
+	}
+
+	xid := f.NewTransactionID()
+	for {
+		if _, ok := r.pending[xid]; !ok {
+			break
+		}
+		xid = f.NewTransactionID()
+	}
+	msg.SetTransactionID(xid)
+
+	v := &pendingRequest{
+		c:    make(chan openflow.Header, 16),
+		done: make(chan struct{}),
+	}
+	r.pending[xid] = v
+
+	return v, nil
+}
+
+func (r *requestMap) remove(xid uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.pending[xid]
+	if !ok {
+		return
+	}
+	close(v.done)
+	delete(r.pending, xid)
+}
+
+func (r *requestMap) has(xid uint32) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.pending[xid]
+	return ok
+}
+
+// deliver passes the reply message to the pending request whose transaction ID
+// is same with the reply's one. It returns false if there is no such request.
+func (r *requestMap) deliver(reply openflow.Header) bool {
+	r.mutex.Lock()
+	v, ok := r.pending[reply.TransactionID()]
+	r.mutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	select {
+	case v.c <- reply:
+	case <-v.done:
+		// The request has been removed while we are waiting.
+	}
+
+	return true
+}
+
+// close removes all the pending requests. Adding a new request will fail after calling close.
+func (r *requestMap) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for xid, v := range r.pending {
+		close(v.done)
+		delete(r.pending, xid)
+	}
+	r.closed = true
+}
+
+func (r *Transceiver) getFactory() openflow.Factory {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.factory
+}
+
+// Request sends msg to the device after assigning a unique transaction ID to it,
+// and then waits for its reply messages until we get the last part of the
+// multipart reply or ctx is canceled. It returns *ErrorReply if the device replies
+// with an ERROR message, and ErrClosed if the connection is closed while waiting.
+//
+// The replies are passed to the caller, not to the handler, so that the handler
+// can also make a request without blocking the dispatcher.
+func (r *Transceiver) Request(ctx context.Context, msg Request) ([]openflow.Header, error) {
+	f := r.getFactory()
+	if f == nil {
+		return nil, ErrNotNegotiated
+	}
+
+	pending, err := r.requests.add(f, msg)
+	if err != nil {
+		return nil, err
+	}
+	defer r.requests.remove(msg.TransactionID())
+
+	if err := r.Write(msg); err != nil {
+		return nil, err
+	}
+
+	return pending.wait(ctx)
+}
+
+// Transaction sends the messages to the device followed by a BARRIER_REQUEST
+// after assigning unique transaction IDs to them, and then waits for the barrier
+// reply. Devices process all the messages before replying to the barrier, so the
+// ERROR replies for the messages have been received before the barrier reply.
+// The returned errors are the results of the messages in order: nil if the
+// device has accepted the message, or *ErrorReply if it has rejected.
+func (r *Transceiver) Transaction(ctx context.Context, msgs []Request) ([]error, error) {
+	f := r.getFactory()
+	if f == nil {
+		return nil, ErrNotNegotiated
+	}
+	barrier, err := f.NewBarrierRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(append([]Request{}, msgs...), barrier)
+	pending := make([]*pendingRequest, 0, len(all))
+	defer func() {
+		for i := range pending {
+			r.requests.remove(all[i].TransactionID())
+		}
+	}()
+	for _, msg := range all {
+		v, err := r.requests.add(f, msg)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, v)
+	}
+	for _, msg := range all {
+		if err := r.Write(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Wait the barrier reply.
+	if _, err := pending[len(pending)-1].wait(ctx); err != nil {
+		return nil, err
+	}
+
+	// The replies are delivered in the order they are received. So, the errors
+	// caused by the messages have been already delivered.
+	result := make([]error, len(msgs))
+	for i := range msgs {
+		select {
+		case v := <-pending[i].c:
+			if e, ok := v.(openflow.Error); ok {
+				result[i] = &ErrorReply{Reply: e}
+			}
+		default:
+		}
+	}
+
+	return result, nil
+}
+
+// deliver passes the packet to the pending request whose transaction ID is same
+// with the packet's one. It returns false if there is no such request.
+func (r *Transceiver) deliver(packet []byte) (bool, error) {
+	xid := binary.BigEndian.Uint32(packet[4:8])
+	if !r.requests.has(xid) {
+		return false, nil
+	}
+
+	msg, err := r.newReply(packet)
+	if err != nil {
+		return false, err
+	}
+	// Not a reply message?
+	if msg == nil {
+		return false, nil
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return false, err
+	}
+
+	return r.requests.deliver(msg), nil
+}
+
+// newReply returns an empty reply message for the packet. It returns nil if the
+// packet is not a reply message.
+func (r *Transceiver) newReply(packet []byte) (replyMessage, error) {
+	f := r.getFactory()
+	if f == nil {
+		return nil, ErrNotNegotiated
+	}
+	if packet[0] != f.ProtocolVersion() {
+		return nil, fmt.Errorf("mis-matched OpenFlow version: negotiated=%v, packet=%v", f.ProtocolVersion(), packet[0])
+	}
+
+	switch packet[0] {
+	case openflow.OF10_VERSION:
+		return newOF10Reply(f, packet)
+	case openflow.OF13_VERSION:
+		return newOF13Reply(f, packet)
+	case openflow.OF14_VERSION:
+		return newOF14Reply(f, packet)
+	default:
+		return nil, openflow.ErrUnsupportedVersion
+	}
+}
+
+func newOF10Reply(f openflow.Factory, packet []byte) (replyMessage, error) {
+	switch packet[1] {
+	case of10.OFPT_ERROR:
+		return f.NewError()
+	case of10.OFPT_FEATURES_REPLY:
+		return f.NewFeaturesReply()
+	case of10.OFPT_GET_CONFIG_REPLY:
+		return f.NewGetConfigReply()
+	case of10.OFPT_BARRIER_REPLY:
+		return f.NewBarrierReply()
+	case of10.OFPT_STATS_REPLY:
+		if len(packet) < 10 {
+			return nil, openflow.ErrInvalidPacketLength
+		}
+		switch binary.BigEndian.Uint16(packet[8:10]) {
+		case of10.OFPST_DESC:
+			return f.NewDescReply()
+		case of10.OFPST_FLOW:
+			return f.NewFlowStatsReply()
+		case of10.OFPST_PORT:
+			return f.NewPortStatsReply()
+		case of10.OFPST_TABLE:
+			return f.NewTableStatsReply()
+		case of10.OFPST_QUEUE:
+			return f.NewQueueStatsReply()
+		case of10.OFPST_AGGREGATE:
+			return f.NewAggregateStatsReply()
+		}
+	}
+
+	return nil, nil
+}
+
+func newOF13Reply(f openflow.Factory, packet []byte) (replyMessage, error) {
+	switch packet[1] {
+	case of13.OFPT_ERROR:
+		return f.NewError()
+	case of13.OFPT_FEATURES_REPLY:
+		return f.NewFeaturesReply()
+	case of13.OFPT_GET_CONFIG_REPLY:
+		return f.NewGetConfigReply()
+	case of13.OFPT_BARRIER_REPLY:
+		return f.NewBarrierReply()
+	case of13.OFPT_ROLE_REPLY:
+		return f.NewRoleReply()
+	case of13.OFPT_GET_ASYNC_REPLY:
+		return f.NewGetAsyncReply()
+	case of13.OFPT_EXPERIMENTER:
+		if of13.IsBundleControl(packet) {
+			return f.NewBundleControl()
+		}
+	case of13.OFPT_MULTIPART_REPLY:
+		if len(packet) < 10 {
+			return nil, openflow.ErrInvalidPacketLength
+		}
+		switch binary.BigEndian.Uint16(packet[8:10]) {
+		case of13.OFPMP_DESC:
+			return f.NewDescReply()
+		case of13.OFPMP_FLOW:
+			return f.NewFlowStatsReply()
+		case of13.OFPMP_AGGREGATE:
+			return f.NewAggregateStatsReply()
+		case of13.OFPMP_TABLE:
+			return f.NewTableStatsReply()
+		case of13.OFPMP_PORT_STATS:
+			return f.NewPortStatsReply()
+		case of13.OFPMP_QUEUE:
+			return f.NewQueueStatsReply()
+		case of13.OFPMP_GROUP:
+			return f.NewGroupStatsReply()
+		case of13.OFPMP_GROUP_DESC:
+			return f.NewGroupDescReply()
+		case of13.OFPMP_GROUP_FEATURES:
+			return f.NewGroupFeaturesReply()
+		case of13.OFPMP_METER:
+			return f.NewMeterStatsReply()
+		case of13.OFPMP_METER_CONFIG:
+			return f.NewMeterConfigReply()
+		case of13.OFPMP_METER_FEATURES:
+			return f.NewMeterFeaturesReply()
+		case of13.OFPMP_TABLE_FEATURES:
+			return f.NewTableFeaturesReply()
+		case of13.OFPMP_PORT_DESC:
+			return f.NewPortDescReply()
+		}
+	}
+
+	return nil, nil
+}
+
+func newOF14Reply(f openflow.Factory, packet []byte) (replyMessage, error) {
+	switch packet[1] {
+	case of14.OFPT_ERROR:
+		return f.NewError()
+	case of14.OFPT_FEATURES_REPLY:
+		return f.NewFeaturesReply()
+	case of14.OFPT_GET_CONFIG_REPLY:
+		return f.NewGetConfigReply()
+	case of14.OFPT_BARRIER_REPLY:
+		return f.NewBarrierReply()
+	case of14.OFPT_ROLE_REPLY:
+		return f.NewRoleReply()
+	case of14.OFPT_GET_ASYNC_REPLY:
+		return f.NewGetAsyncReply()
+	case of14.OFPT_BUNDLE_CONTROL:
+		return f.NewBundleControl()
+	case of14.OFPT_MULTIPART_REPLY:
+		if len(packet) < 10 {
+			return nil, openflow.ErrInvalidPacketLength
+		}
+		switch binary.BigEndian.Uint16(packet[8:10]) {
+		case of14.OFPMP_DESC:
+			return f.NewDescReply()
+		case of14.OFPMP_FLOW:
+			return f.NewFlowStatsReply()
+		case of14.OFPMP_AGGREGATE:
+			return f.NewAggregateStatsReply()
+		case of14.OFPMP_TABLE:
+			return f.NewTableStatsReply()
+		case of14.OFPMP_PORT_STATS:
+			return f.NewPortStatsReply()
+		case of14.OFPMP_QUEUE_STATS:
+			return f.NewQueueStatsReply()
+		case of14.OFPMP_GROUP:
+			return f.NewGroupStatsReply()
+		case of14.OFPMP_GROUP_DESC:
+			return f.NewGroupDescReply()
+		case of14.OFPMP_GROUP_FEATURES:
+			return f.NewGroupFeaturesReply()
+		case of14.OFPMP_METER:
+			return f.NewMeterStatsReply()
+		case of14.OFPMP_METER_CONFIG:
+			return f.NewMeterConfigReply()
+		case of14.OFPMP_METER_FEATURES:
+			return f.NewMeterFeaturesReply()
+		case of14.OFPMP_TABLE_FEATURES:
+			return f.NewTableFeaturesReply()
+		case of14.OFPMP_PORT_DESC:
+			return f.NewPortDescReply()
+		case of14.OFPMP_FLOW_MONITOR:
+			return f.NewFlowMonitorReply()
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,389 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package transceiver
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/superkkt/cherry/openflow"
+	"github.com/superkkt/cherry/openflow/of13"
+)
+
+// testHandler receives the messages that are not the replies to the requests.
+type testHandler struct {
+	Handler
+	negotiated chan struct{}
+	barriers   chan openflow.BarrierReply
+}
+
+func (r *testHandler) OnHello(f openflow.Factory, w Writer, v openflow.Hello) error {
+	close(r.negotiated)
+	return nil
+}
+
+func (r *testHandler) OnBarrierReply(f openflow.Factory, w Writer, v openflow.BarrierReply) error {
+	r.barriers <- v
+	return nil
+}
+
+// newTestTransceiver runs an OpenFlow 1.3 transceiver connected to the returned
+// connection that acts as a switch. Call the returned function to stop the transceiver.
+func newTestTransceiver(t *testing.T) (*Transceiver, *testHandler, net.Conn, func()) {
+	local, remote := net.Pipe()
+	// Do not block the test forever.
+	remote.SetDeadline(time.Now().Add(5 * time.Second))
+	handler := &testHandler{
+		negotiated: make(chan struct{}),
+		barriers:   make(chan openflow.BarrierReply, 16),
+	}
+	r := NewTransceiver(NewStream(local, 0xFFFF), handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	stop := func() {
+		cancel()
+		local.Close()
+		remote.Close()
+		<-done
+	}
+
+	if _, err := remote.Write(newTestHello(openflow.OF13_VERSION, nil)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handler.negotiated:
+	case <-time.After(5 * time.Second):
+		stop()
+		t.Fatal("timeout on the version negotiation")
+	}
+
+	return r, handler, remote, stop
+}
+
+func readTestPacket(t *testing.T, conn net.Conn) []byte {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Fatalf("failed to read a packet: %v", err)
+	}
+	packet := make([]byte, binary.BigEndian.Uint16(header[2:4]))
+	copy(packet, header)
+	if _, err := io.ReadFull(conn, packet[8:]); err != nil {
+		t.Fatalf("failed to read a packet: %v", err)
+	}
+
+	return packet
+}
+
+func writeTestPacket(t *testing.T, conn net.Conn, version, msgType uint8, xid uint32, payload []byte) {
+	msg := openflow.NewMessage(version, msgType, xid)
+	msg.SetPayload(payload)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(packet); err != nil {
+		t.Fatalf("failed to write a packet: %v", err)
+	}
+}
+
+func newTestFlowStatsPayload(more bool) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint16(v[0:2], of13.OFPMP_FLOW)
+	if more {
+		binary.BigEndian.PutUint16(v[2:4], of13.OFPMPF_REPLY_MORE)
+	}
+
+	return v
+}
+
+func newTestErrorPayload(class, code uint16) []byte {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint16(v[0:2], class)
+	binary.BigEndian.PutUint16(v[2:4], code)
+
+	return v
+}
+
+type testResult struct {
+	replies []openflow.Header
+	err     error
+}
+
+func startRequest(ctx context.Context, r *Transceiver, msg Request) <-chan testResult {
+	c := make(chan testResult, 1)
+	go func() {
+		replies, err := r.Request(ctx, msg)
+		c <- testResult{replies, err}
+	}()
+
+	return c
+}
+
+func (r *Transceiver) numPending() int {
+	r.requests.mutex.Lock()
+	defer r.requests.mutex.Unlock()
+
+	return len(r.requests.pending)
+}
+
+func TestRequest(t *testing.T) {
+	r, handler, conn, stop := newTestTransceiver(t)
+	defer stop()
+	f := of13.NewFactory()
+
+	// Two concurrent requests whose replies are received in the reverse order.
+	first, err := f.NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstResult := startRequest(context.Background(), r, first)
+	firstXID := binary.BigEndian.Uint32(readTestPacket(t, conn)[4:8])
+	secondResult := startRequest(context.Background(), r, second)
+	secondXID := binary.BigEndian.Uint32(readTestPacket(t, conn)[4:8])
+	if firstXID == secondXID {
+		t.Fatalf("duplicated transaction ID: %v", firstXID)
+	}
+	// A reply that does not belong to any request is passed to the handler.
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_BARRIER_REPLY, firstXID+secondXID, nil)
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_BARRIER_REPLY, secondXID, nil)
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_BARRIER_REPLY, firstXID, nil)
+
+	for _, v := range []struct {
+		result <-chan testResult
+		xid    uint32
+	}{{firstResult, firstXID}, {secondResult, secondXID}} {
+		result := <-v.result
+		if result.err != nil {
+			t.Fatalf("unexpected error: %v", result.err)
+		}
+		if len(result.replies) != 1 || result.replies[0].TransactionID() != v.xid {
+			t.Fatalf("unexpected replies: expected xid=%v, got=%+v", v.xid, result.replies)
+		}
+		if _, ok := result.replies[0].(openflow.BarrierReply); !ok {
+			t.Fatalf("unexpected reply: %+v", result.replies[0])
+		}
+	}
+	select {
+	case v := <-handler.barriers:
+		if v.TransactionID() != firstXID+secondXID {
+			t.Fatalf("unexpected barrier reply passed to the handler: xid=%v", v.TransactionID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler has not received the barrier reply")
+	}
+
+	// Multipart reply whose parts are reassembled until the last one.
+	stats, err := f.NewFlowStatsRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, err := f.NewMatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats.SetMatch(match)
+	statsResult := startRequest(context.Background(), r, stats)
+	xid := binary.BigEndian.Uint32(readTestPacket(t, conn)[4:8])
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_MULTIPART_REPLY, xid, newTestFlowStatsPayload(true))
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_MULTIPART_REPLY, xid, newTestFlowStatsPayload(true))
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_MULTIPART_REPLY, xid, newTestFlowStatsPayload(false))
+	result := <-statsResult
+	if result.err != nil {
+		t.Fatalf("unexpected error: %v", result.err)
+	}
+	if len(result.replies) != 3 {
+		t.Fatalf("unexpected number of the replies: %v", len(result.replies))
+	}
+	for i, v := range result.replies {
+		reply, ok := v.(openflow.FlowStatsReply)
+		if !ok {
+			t.Fatalf("unexpected reply: %+v", v)
+		}
+		if reply.More() != (i < 2) {
+			t.Fatalf("unexpected MORE flag of the reply #%v", i)
+		}
+	}
+
+	if n := r.numPending(); n != 0 {
+		t.Fatalf("unexpected number of the pending requests: %v", n)
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	r, _, conn, stop := newTestTransceiver(t)
+	defer stop()
+
+	msg, err := of13.NewFactory().NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := startRequest(context.Background(), r, msg)
+	xid := binary.BigEndian.Uint32(readTestPacket(t, conn)[4:8])
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_ERROR, xid, newTestErrorPayload(of13.OFPET_BAD_REQUEST, of13.OFPBRC_BAD_TYPE))
+
+	result := <-c
+	e, ok := result.err.(*ErrorReply)
+	if !ok {
+		t.Fatalf("expected an error reply: %v", result.err)
+	}
+	if e.Reply.TransactionID() != xid || e.Reply.Class() != of13.OFPET_BAD_REQUEST || e.Reply.Code() != of13.OFPBRC_BAD_TYPE {
+		t.Fatalf("unexpected error reply: xid=%v, class=%v, code=%v", e.Reply.TransactionID(), e.Reply.Class(), e.Reply.Code())
+	}
+	if n := r.numPending(); n != 0 {
+		t.Fatalf("unexpected number of the pending requests: %v", n)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	r, _, conn, stop := newTestTransceiver(t)
+	defer stop()
+
+	msg, err := of13.NewFactory().NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c := startRequest(ctx, r, msg)
+	readTestPacket(t, conn)
+
+	// The switch does not reply.
+	result := <-c
+	if result.err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: expected=%v, got=%v", context.DeadlineExceeded, result.err)
+	}
+	if n := r.numPending(); n != 0 {
+		t.Fatalf("unexpected number of the pending requests: %v", n)
+	}
+}
+
+func TestRequestClosed(t *testing.T) {
+	r, _, conn, stop := newTestTransceiver(t)
+	defer stop()
+
+	f := of13.NewFactory()
+	msg, err := f.NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := startRequest(context.Background(), r, msg)
+	readTestPacket(t, conn)
+
+	// The connection is closed while waiting the reply.
+	conn.Close()
+	select {
+	case result := <-c:
+		if result.err != ErrClosed {
+			t.Fatalf("unexpected error: expected=%v, got=%v", ErrClosed, result.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request is not canceled")
+	}
+
+	// New requests also fail after the transceiver is closed.
+	msg, err = f.NewBarrierRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Request(context.Background(), msg); err != ErrClosed {
+		t.Fatalf("unexpected error: expected=%v, got=%v", ErrClosed, err)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	r, _, conn, stop := newTestTransceiver(t)
+	defer stop()
+
+	f := of13.NewFactory()
+	msgs := make([]Request, 3)
+	for i := range msgs {
+		match, err := f.NewMatch()
+		if err != nil {
+			t.Fatal(err)
+		}
+		flowmod, err := f.NewFlowMod(openflow.FlowAdd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flowmod.SetPriority(uint16(i))
+		flowmod.SetFlowMatch(match)
+		msgs[i] = flowmod
+	}
+
+	type transactionResult struct {
+		errs []error
+		err  error
+	}
+	c := make(chan transactionResult, 1)
+	go func() {
+		errs, err := r.Transaction(context.Background(), msgs)
+		c <- transactionResult{errs, err}
+	}()
+
+	// The messages are followed by a barrier.
+	xids := make([]uint32, 0)
+	for i := 0; i < len(msgs)+1; i++ {
+		packet := readTestPacket(t, conn)
+		expected := uint8(of13.OFPT_FLOW_MOD)
+		if i == len(msgs) {
+			expected = of13.OFPT_BARRIER_REQUEST
+		}
+		if packet[1] != expected {
+			t.Fatalf("unexpected message type: expected=%v, got=%v", expected, packet[1])
+		}
+		xids = append(xids, binary.BigEndian.Uint32(packet[4:8]))
+	}
+	// The switch rejects the second message, and then replies to the barrier.
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_ERROR, xids[1], newTestErrorPayload(of13.OFPET_FLOW_MOD_FAILED, of13.OFPFMFC_TABLE_FULL))
+	writeTestPacket(t, conn, openflow.OF13_VERSION, of13.OFPT_BARRIER_REPLY, xids[3], nil)
+
+	result := <-c
+	if result.err != nil {
+		t.Fatalf("unexpected error: %v", result.err)
+	}
+	if len(result.errs) != len(msgs) {
+		t.Fatalf("unexpected number of the results: %v", len(result.errs))
+	}
+	if result.errs[0] != nil || result.errs[2] != nil {
+		t.Fatalf("unexpected results: %v", result.errs)
+	}
+	e, ok := result.errs[1].(*ErrorReply)
+	if !ok || e.Reply.TransactionID() != xids[1] || e.Reply.Code() != of13.OFPFMFC_TABLE_FULL {
+		t.Fatalf("unexpected result of the rejected message: %v", result.errs[1])
+	}
+	if n := r.numPending(); n != 0 {
+		t.Fatalf("unexpected number of the pending requests: %v", n)
+	}
+}
@@ -26,11 +26,13 @@ import (
 	"encoding"
 	"encoding/binary"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/superkkt/cherry/openflow"
 	"github.com/superkkt/cherry/openflow/of10"
 	"github.com/superkkt/cherry/openflow/of13"
+	"github.com/superkkt/cherry/openflow/of14"
 
 	"github.com/pkg/errors"
 	"github.com/superkkt/go-logging"
@@ -58,12 +60,15 @@ type WriteCloser interface {
 }
 
 type Transceiver struct {
-	stream      *Stream
-	observer    Handler
-	version     uint8
+	stream   *Stream
+	observer Handler
+	version  uint8
+	// mutex protects factory that is also used by the requesters.
+	mutex       sync.Mutex
 	factory     openflow.Factory
 	pingCounter uint
 	closed      bool
+	requests    *requestMap
 }
 
 type Handler interface {
@@ -73,12 +78,28 @@ type Handler interface {
 	OnGetConfigReply(openflow.Factory, Writer, openflow.GetConfigReply) error
 	OnDescReply(openflow.Factory, Writer, openflow.DescReply) error
 	OnPortDescReply(openflow.Factory, Writer, openflow.PortDescReply) error
+	OnFlowStatsReply(openflow.Factory, Writer, openflow.FlowStatsReply) error
+	OnPortStatsReply(openflow.Factory, Writer, openflow.PortStatsReply) error
+	OnTableStatsReply(openflow.Factory, Writer, openflow.TableStatsReply) error
+	OnQueueStatsReply(openflow.Factory, Writer, openflow.QueueStatsReply) error
+	OnAggregateStatsReply(openflow.Factory, Writer, openflow.AggregateStatsReply) error
+	OnTableFeaturesReply(openflow.Factory, Writer, openflow.TableFeaturesReply) error
 	OnPortStatus(openflow.Factory, Writer, openflow.PortStatus) error
 	OnFlowRemoved(openflow.Factory, Writer, openflow.FlowRemoved) error
 	OnPacketIn(openflow.Factory, Writer, openflow.PacketIn) error
 	OnBarrierReply(openflow.Factory, Writer, openflow.BarrierReply) error
+	OnRoleReply(openflow.Factory, Writer, openflow.RoleReply) error
+	OnGetAsyncReply(openflow.Factory, Writer, openflow.GetAsyncReply) error
+	OnFlowMonitorReply(openflow.Factory, Writer, openflow.FlowMonitorReply) error
+	// OnExperimenter is called with an experimenter message that is decoded by
+	// the codec registered by RegisterExperimenter, or openflow.Experimenter if
+	// there is no codec for the experimenter.
+	OnExperimenter(openflow.Factory, Writer, openflow.Header) error
 }
 
+// supportedVersions is the OpenFlow versions that we support in the ascending order.
+var supportedVersions = []uint8{openflow.OF10_VERSION, openflow.OF13_VERSION, openflow.OF14_VERSION}
+
 func NewTransceiver(stream *Stream, handler Handler) *Transceiver {
 	if stream == nil {
 		panic("stream is nil")
@@ -90,6 +111,7 @@ func NewTransceiver(stream *Stream, handler Handler) *Transceiver {
 	return &Transceiver{
 		stream:   stream,
 		observer: handler,
+		requests: newRequestMap(),
 	}
 }
 
@@ -140,6 +162,8 @@ func (r *Transceiver) sendEchoRequest() error {
 
 func (r *Transceiver) Run(ctx context.Context) error {
 	defer logger.Info("transceiver is closed")
+	// Cancel all the pending requests.
+	defer r.requests.close()
 	r.stream.SetReadTimeout(readTimeout)
 	r.stream.SetWriteTimeout(writeTimeout)
 
@@ -199,21 +223,64 @@ func (r *Transceiver) negotiate(ctx context.Context, reader <-chan []byte) (pack
 		}
 
 		// Version negotiation
-		if packet[0] < openflow.OF13_VERSION {
-			r.version = openflow.OF10_VERSION
+		version, err := negotiateVersion(packet)
+		if err != nil {
+			return nil, err
+		}
+		r.mutex.Lock()
+		r.version = version
+		switch version {
+		case openflow.OF10_VERSION:
 			r.factory = of10.NewFactory()
 			logger.Info("negotiated to openflow version 1.0")
-		} else {
-			r.version = openflow.OF13_VERSION
+		case openflow.OF13_VERSION:
 			r.factory = of13.NewFactory()
 			logger.Info("negotiated to openflow version 1.3")
+		case openflow.OF14_VERSION:
+			r.factory = of14.NewFactory()
+			logger.Info("negotiated to openflow version 1.4")
+		default:
+			panic(fmt.Sprintf("unexpected negotiated version: %v", version))
 		}
+		r.mutex.Unlock()
+
+		// The HELLO message may have a higher version than the negotiated one.
+		// Dispatch it as a message of the negotiated version.
+		packet[0] = version
 
 		// Return the initial packet to dispatch it.
 		return packet, nil
 	}
 }
 
+// negotiateVersion returns the highest version that both of the switch and we
+// support. The versions of the switch are obtained from the version bitmap of
+// its HELLO message, or the version of the HELLO message itself if it does not
+// have the bitmap.
+func negotiateVersion(hello []byte) (uint8, error) {
+	versions, ok := openflow.ParseVersionBitmap(hello[8:])
+	if !ok {
+		// The switch supports all the versions lower than or equal to its one.
+		// Pick the highest one of our versions that does not exceed it.
+		for i := len(supportedVersions) - 1; i >= 0; i-- {
+			if supportedVersions[i] <= hello[0] {
+				return supportedVersions[i], nil
+			}
+		}
+		return 0, fmt.Errorf("unsupported OpenFlow version: %v", hello[0])
+	}
+
+	for i := len(supportedVersions) - 1; i >= 0; i-- {
+		for _, v := range versions {
+			if v == supportedVersions[i] {
+				return v, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("no common OpenFlow version: %v", versions)
+}
+
 func (r *Transceiver) runReader(ctx context.Context) <-chan []byte {
 	// Buffered channel
 	c := make(chan []byte, 4096)
@@ -260,6 +327,16 @@ func (r *Transceiver) runReader(ctx context.Context) <-chan []byte {
 				// packets because this reader handles them.
 				continue
 			}
+			// Pass the replies for the pending requests directly to the requesters
+			// so that a handler blocked on a request does not block the replies.
+			ok, err = r.deliver(packet)
+			if err != nil {
+				logger.Errorf("failed to deliver the reply to the pending request: %v", err)
+				continue
+			}
+			if ok {
+				continue
+			}
 
 			// Forward messages except the echo request and response.
 			select {
@@ -318,6 +395,8 @@ func (r *Transceiver) handleEcho(packet []byte) (ok bool, err error) {
 		return r.handleOF10Echo(packet)
 	case openflow.OF13_VERSION:
 		return r.handleOF13Echo(packet)
+	case openflow.OF14_VERSION:
+		return r.handleOF14Echo(packet)
 	default:
 		return false, openflow.ErrUnsupportedVersion
 	}
@@ -347,6 +426,18 @@ func (r *Transceiver) handleOF13Echo(packet []byte) (handled bool, err error) {
 	}
 }
 
+func (r *Transceiver) handleOF14Echo(packet []byte) (handled bool, err error) {
+	switch packet[1] {
+	case of14.OFPT_ECHO_REQUEST:
+		return true, r.handleEchoRequest(packet)
+	case of14.OFPT_ECHO_REPLY:
+		return true, r.handleEchoReply(packet)
+	default:
+		// Do not anything for other types of the message
+		return false, nil
+	}
+}
+
 func (r *Transceiver) dispatch(packet []byte) error {
 	if packet[0] != r.version {
 		return fmt.Errorf("mis-matched OpenFlow version: negotiated=%v, packet=%v", r.version, packet[0])
@@ -357,6 +448,8 @@ func (r *Transceiver) dispatch(packet []byte) error {
 		return r.handleOF10Message(packet)
 	case openflow.OF13_VERSION:
 		return r.handleOF13Message(packet)
+	case openflow.OF14_VERSION:
+		return r.handleOF14Message(packet)
 	default:
 		return openflow.ErrUnsupportedVersion
 	}
@@ -373,9 +466,22 @@ func (r *Transceiver) handleOF10Message(packet []byte) error {
 	case of10.OFPT_GET_CONFIG_REPLY:
 		return r.handleGetConfigReply(packet)
 	case of10.OFPT_STATS_REPLY:
+		if len(packet) < 10 {
+			return openflow.ErrInvalidPacketLength
+		}
 		switch binary.BigEndian.Uint16(packet[8:10]) {
 		case of10.OFPST_DESC:
 			return r.handleDescReply(packet)
+		case of10.OFPST_FLOW:
+			return r.handleFlowStatsReply(packet)
+		case of10.OFPST_PORT:
+			return r.handlePortStatsReply(packet)
+		case of10.OFPST_TABLE:
+			return r.handleTableStatsReply(packet)
+		case of10.OFPST_QUEUE:
+			return r.handleQueueStatsReply(packet)
+		case of10.OFPST_AGGREGATE:
+			return r.handleAggregateStatsReply(packet)
 		default:
 			// Unsupported message. Do nothing.
 			return nil
@@ -386,6 +492,8 @@ func (r *Transceiver) handleOF10Message(packet []byte) error {
 		return r.handleFlowRemoved(packet)
 	case of10.OFPT_PACKET_IN:
 		return r.handlePacketIn(packet)
+	case of10.OFPT_VENDOR:
+		return r.handleExperimenter(packet)
 	case of10.OFPT_BARRIER_REPLY:
 		return r.handleBarrierReply(packet)
 	default:
@@ -405,11 +513,26 @@ func (r *Transceiver) handleOF13Message(packet []byte) error {
 	case of13.OFPT_GET_CONFIG_REPLY:
 		return r.handleGetConfigReply(packet)
 	case of13.OFPT_MULTIPART_REPLY:
+		if len(packet) < 10 {
+			return openflow.ErrInvalidPacketLength
+		}
 		switch binary.BigEndian.Uint16(packet[8:10]) {
 		case of13.OFPMP_DESC:
 			return r.handleDescReply(packet)
 		case of13.OFPMP_PORT_DESC:
 			return r.handlePortDescReply(packet)
+		case of13.OFPMP_FLOW:
+			return r.handleFlowStatsReply(packet)
+		case of13.OFPMP_PORT_STATS:
+			return r.handlePortStatsReply(packet)
+		case of13.OFPMP_TABLE:
+			return r.handleTableStatsReply(packet)
+		case of13.OFPMP_QUEUE:
+			return r.handleQueueStatsReply(packet)
+		case of13.OFPMP_AGGREGATE:
+			return r.handleAggregateStatsReply(packet)
+		case of13.OFPMP_TABLE_FEATURES:
+			return r.handleTableFeaturesReply(packet)
 		default:
 			// Unsupported message. Do nothing.
 			return nil
@@ -420,8 +543,72 @@ func (r *Transceiver) handleOF13Message(packet []byte) error {
 		return r.handleFlowRemoved(packet)
 	case of13.OFPT_PACKET_IN:
 		return r.handlePacketIn(packet)
+	case of13.OFPT_EXPERIMENTER:
+		return r.handleExperimenter(packet)
 	case of13.OFPT_BARRIER_REPLY:
 		return r.handleBarrierReply(packet)
+	case of13.OFPT_ROLE_REPLY:
+		return r.handleRoleReply(packet)
+	case of13.OFPT_GET_ASYNC_REPLY:
+		return r.handleGetAsyncReply(packet)
+	default:
+		// Unsupported message. Do nothing.
+		return nil
+	}
+}
+
+func (r *Transceiver) handleOF14Message(packet []byte) error {
+	switch packet[1] {
+	case of14.OFPT_HELLO:
+		return r.handleHello(packet)
+	case of14.OFPT_ERROR:
+		return r.handleError(packet)
+	case of14.OFPT_FEATURES_REPLY:
+		return r.handleFeaturesReply(packet)
+	case of14.OFPT_GET_CONFIG_REPLY:
+		return r.handleGetConfigReply(packet)
+	case of14.OFPT_MULTIPART_REPLY:
+		if len(packet) < 10 {
+			return openflow.ErrInvalidPacketLength
+		}
+		switch binary.BigEndian.Uint16(packet[8:10]) {
+		case of14.OFPMP_DESC:
+			return r.handleDescReply(packet)
+		case of14.OFPMP_PORT_DESC:
+			return r.handlePortDescReply(packet)
+		case of14.OFPMP_FLOW:
+			return r.handleFlowStatsReply(packet)
+		case of14.OFPMP_PORT_STATS:
+			return r.handlePortStatsReply(packet)
+		case of14.OFPMP_TABLE:
+			return r.handleTableStatsReply(packet)
+		case of14.OFPMP_QUEUE_STATS:
+			return r.handleQueueStatsReply(packet)
+		case of14.OFPMP_AGGREGATE:
+			return r.handleAggregateStatsReply(packet)
+		case of14.OFPMP_TABLE_FEATURES:
+			return r.handleTableFeaturesReply(packet)
+		case of14.OFPMP_FLOW_MONITOR:
+			// Flow monitor updates are sent asynchronously.
+			return r.handleFlowMonitorReply(packet)
+		default:
+			// Unsupported message. Do nothing.
+			return nil
+		}
+	case of14.OFPT_PORT_STATUS:
+		return r.handlePortStatus(packet)
+	case of14.OFPT_FLOW_REMOVED:
+		return r.handleFlowRemoved(packet)
+	case of14.OFPT_PACKET_IN:
+		return r.handlePacketIn(packet)
+	case of14.OFPT_EXPERIMENTER:
+		return r.handleExperimenter(packet)
+	case of14.OFPT_BARRIER_REPLY:
+		return r.handleBarrierReply(packet)
+	case of14.OFPT_ROLE_REPLY:
+		return r.handleRoleReply(packet)
+	case of14.OFPT_GET_ASYNC_REPLY:
+		return r.handleGetAsyncReply(packet)
 	default:
 		// Unsupported message. Do nothing.
 		return nil
@@ -557,6 +744,78 @@ func (r *Transceiver) handlePortDescReply(packet []byte) error {
 	return r.observer.OnPortDescReply(r.factory, r, msg)
 }
 
+func (r *Transceiver) handleFlowStatsReply(packet []byte) error {
+	msg, err := r.factory.NewFlowStatsReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnFlowStatsReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handlePortStatsReply(packet []byte) error {
+	msg, err := r.factory.NewPortStatsReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnPortStatsReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleTableStatsReply(packet []byte) error {
+	msg, err := r.factory.NewTableStatsReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnTableStatsReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleQueueStatsReply(packet []byte) error {
+	msg, err := r.factory.NewQueueStatsReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnQueueStatsReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleAggregateStatsReply(packet []byte) error {
+	msg, err := r.factory.NewAggregateStatsReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnAggregateStatsReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleTableFeaturesReply(packet []byte) error {
+	msg, err := r.factory.NewTableFeaturesReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnTableFeaturesReply(r.factory, r, msg)
+}
+
 func (r *Transceiver) handlePortStatus(packet []byte) error {
 	msg, err := r.factory.NewPortStatus()
 	if err != nil {
@@ -605,6 +864,42 @@ func (r *Transceiver) handleBarrierReply(packet []byte) error {
 	return r.observer.OnBarrierReply(r.factory, r, msg)
 }
 
+func (r *Transceiver) handleRoleReply(packet []byte) error {
+	msg, err := r.factory.NewRoleReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnRoleReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleGetAsyncReply(packet []byte) error {
+	msg, err := r.factory.NewGetAsyncReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnGetAsyncReply(r.factory, r, msg)
+}
+
+func (r *Transceiver) handleFlowMonitorReply(packet []byte) error {
+	msg, err := r.factory.NewFlowMonitorReply()
+	if err != nil {
+		return err
+	}
+	if err := msg.UnmarshalBinary(packet); err != nil {
+		return err
+	}
+
+	return r.observer.OnFlowMonitorReply(r.factory, r, msg)
+}
+
 func (r *Transceiver) Close() error {
 	if r.closed {
 		return nil
@@ -614,6 +909,7 @@ func (r *Transceiver) Close() error {
 		return err
 	}
 	r.closed = true
+	r.requests.close()
 
 	return nil
 }
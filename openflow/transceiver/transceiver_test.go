@@ -0,0 +1,98 @@
+/*
+ * Cherry - An OpenFlow Controller
+ *
+ * Copyright (C) 2015 Samjung Data Service, Inc. All rights reserved.
+ * Kitae Kim <superkkt@sds.co.kr>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License along
+ * with this program; if not, write to the Free Software Foundation, Inc.,
+ * 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+ */
+
+package transceiver
+
+import (
+	"testing"
+
+	"github.com/superkkt/cherry/openflow"
+)
+
+func newTestHello(version uint8, bitmap []byte) []byte {
+	msg := openflow.NewMessage(version, 0 /* OFPT_HELLO */, 1)
+	msg.SetPayload(bitmap)
+	packet, err := msg.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+
+	return packet
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		hello    []byte
+		expected uint8
+		err      bool
+	}{
+		// Without the version bitmap
+		{newTestHello(openflow.OF10_VERSION, nil), openflow.OF10_VERSION, false},
+		{newTestHello(openflow.OF13_VERSION, nil), openflow.OF13_VERSION, false},
+		{newTestHello(openflow.OF14_VERSION, nil), openflow.OF14_VERSION, false},
+		// OF15
+		{newTestHello(0x06, nil), openflow.OF14_VERSION, false},
+		// With the version bitmap
+		{newTestHello(0x06, openflow.MarshalVersionBitmap(openflow.OF13_VERSION, 0x06)), openflow.OF13_VERSION, false},
+		{newTestHello(openflow.OF14_VERSION, openflow.MarshalVersionBitmap(openflow.OF10_VERSION, openflow.OF14_VERSION)), openflow.OF14_VERSION, false},
+		{newTestHello(0x06, openflow.MarshalVersionBitmap(0x06)), 0, true},
+	}
+
+	for i, test := range tests {
+		v, err := negotiateVersion(test.hello)
+		if test.err {
+			if err == nil {
+				t.Errorf("#%v: expected an error, but got version %v", i, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%v: unexpected error: %v", i, err)
+			continue
+		}
+		if v != test.expected {
+			t.Errorf("#%v: unexpected version: expected=%v, got=%v", i, test.expected, v)
+		}
+	}
+}
+
+func TestDispatchShortMultipartReply(t *testing.T) {
+	tests := []struct {
+		version uint8
+		msgType uint8
+	}{
+		{openflow.OF10_VERSION, 17 /* OFPT_STATS_REPLY */},
+		{openflow.OF13_VERSION, 19 /* OFPT_MULTIPART_REPLY */},
+		{openflow.OF14_VERSION, 19 /* OFPT_MULTIPART_REPLY */},
+	}
+
+	for i, test := range tests {
+		msg := openflow.NewMessage(test.version, test.msgType, 1)
+		packet, err := msg.MarshalBinary()
+		if err != nil {
+			t.Fatalf("#%v: unexpected error: %v", i, err)
+		}
+		r := &Transceiver{version: test.version}
+		if err := r.dispatch(packet); err != openflow.ErrInvalidPacketLength {
+			t.Errorf("#%v: unexpected error: expected=%v, got=%v", i, openflow.ErrInvalidPacketLength, err)
+		}
+	}
+}